@@ -3,12 +3,16 @@ package artifacts
 import (
 	"errors"
 	"io"
+	"maps"
+	"sync"
 )
 
 var ErrFileAlreadyExists = errors.New("file already exists")
 
-// MapWriter implements an ArtifactWriter storing contents in a map.
+// MapWriter implements an ArtifactWriter storing contents in a map. It is
+// safe for concurrent use, as checks may write artifacts in parallel.
 type MapWriter struct {
+	mu    sync.Mutex
 	files map[string]io.Reader
 }
 
@@ -21,6 +25,9 @@ func NewMapWriter() (*MapWriter, error) {
 
 // WriteFile places contents into files at filename.
 func (w *MapWriter) WriteFile(filename string, contents io.Reader) (string, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
 	if _, exists := w.files[filename]; exists {
 		return "", ErrFileAlreadyExists
 	}
@@ -29,6 +36,11 @@ func (w *MapWriter) WriteFile(filename string, contents io.Reader) (string, erro
 	return filename, nil
 }
 
+// Files returns a copy of the files written so far, so that it may be read
+// while checks are still writing artifacts.
 func (w *MapWriter) Files() map[string]io.Reader {
-	return w.files
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return maps.Clone(w.files)
 }
@@ -2,7 +2,9 @@ package artifacts
 
 import (
 	"bytes"
+	"fmt"
 	"io"
+	"sync"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -40,5 +42,31 @@ var _ = Describe("Map Artifact Writer", func() {
 			_, err = aw.WriteFile(filename, bytes.NewBuffer([]byte("rejected")))
 			Expect(err).To(Equal(ErrFileAlreadyExists))
 		})
+
+		It("Should accept writes from concurrent checks while files are read", func() {
+			var wg sync.WaitGroup
+			for i := range 20 {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					defer GinkgoRecover()
+					_, err := aw.WriteFile(fmt.Sprintf("check-%d.json", i), bytes.NewBuffer(contents))
+					Expect(err).ToNot(HaveOccurred())
+					for name := range aw.Files() {
+						Expect(name).ToNot(BeEmpty())
+					}
+				}()
+			}
+			wg.Wait()
+			Expect(aw.Files()).To(HaveLen(20))
+		})
+
+		It("Should not be changed by changes to the files returned", func() {
+			_, err := aw.WriteFile(filename, bytes.NewBuffer(contents))
+			Expect(err).ToNot(HaveOccurred())
+
+			delete(aw.Files(), filename)
+			Expect(aw.Files()).To(HaveKey(filename))
+		})
 	})
 })
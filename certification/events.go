@@ -0,0 +1,90 @@
+package certification
+
+import (
+	"context"
+	"time"
+)
+
+// Observer receives events as checks are executed, so callers can report
+// progress before the run completes. Observe is never called concurrently
+// for a single run, but it is called from the goroutine executing the
+// event's check, so implementations should return promptly.
+type Observer interface {
+	Observe(ctx context.Context, event Event)
+}
+
+// ObserverFunc is an adapter that allows an ordinary function to be used as
+// an Observer.
+type ObserverFunc func(ctx context.Context, event Event)
+
+// Observe calls f(ctx, event).
+func (f ObserverFunc) Observe(ctx context.Context, event Event) {
+	f(ctx, event)
+}
+
+// Event is one of ImagePulled, ExtractionDone, CheckStarted, CheckFinished
+// or RunFinished.
+type Event interface {
+	event()
+}
+
+// ImagePulled is emitted once the image under test has been resolved, either
+// from its registry or from disk. Layers are downloaded as they are
+// extracted, so extraction may still take some time.
+type ImagePulled struct {
+	// Image is the image as it was provided.
+	Image string
+	// Digest is the digest of the resolved image manifest.
+	Digest string
+}
+
+// ExtractionDone is emitted once the files required by the checks have been
+// extracted from the image.
+type ExtractionDone struct {
+	// Path is the directory the files were extracted to.
+	Path string
+	// ElapsedTime is how long extraction took.
+	ElapsedTime time.Duration
+}
+
+// CheckStarted is emitted before a check is executed.
+type CheckStarted struct {
+	// Name is the name of the check.
+	Name string
+}
+
+// CheckFinished is emitted after a check has executed.
+type CheckFinished struct {
+	// Result is the result of the check.
+	Result Result
+	// Status is the category of Results the check is reported in.
+	Status CheckStatus
+}
+
+// RunFinished is emitted once all checks have executed, or when the run
+// could not be completed.
+type RunFinished struct {
+	// Results contains the results of all checks. It is incomplete if Err is
+	// set.
+	Results Results
+	// Err is the error that stopped the run, if any.
+	Err error
+}
+
+func (ImagePulled) event()    {}
+func (ExtractionDone) event() {}
+func (CheckStarted) event()   {}
+func (CheckFinished) event()  {}
+func (RunFinished) event()    {}
+
+// CheckStatus identifies the category of Results a check is reported in.
+type CheckStatus string
+
+const (
+	StatusPassed   CheckStatus = "passed"
+	StatusFailed   CheckStatus = "failed"
+	StatusWarned   CheckStatus = "warned"
+	StatusErrored  CheckStatus = "errored"
+	StatusSkipped  CheckStatus = "skipped"
+	StatusTimedOut CheckStatus = "timed_out"
+)
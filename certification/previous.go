@@ -0,0 +1,46 @@
+package certification
+
+import (
+	"time"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+)
+
+// PreviousResults are the outcomes recorded by an earlier run against an
+// image. They allow a run to execute only the checks that did not pass,
+// carrying the remaining outcomes over.
+type PreviousResults struct {
+	// ImageDigest is the digest of the image the earlier run was executed
+	// against.
+	ImageDigest string
+	// Checks maps the name of each check in the earlier run to its outcome.
+	Checks map[string]PreviousResult
+}
+
+// PreviousResult is the outcome of a single check in an earlier run.
+type PreviousResult struct {
+	Status      CheckStatus
+	ElapsedTime time.Duration
+	Findings    []check.Finding
+	// Reason explains why the check was skipped or timed out, if it was.
+	Reason string
+	// Waived is true if the check failed, and the failure was waived.
+	Waived bool
+}
+
+// NeedsRerun reports whether the check named name must be executed again,
+// either because it failed, errored or timed out, or because it was not part
+// of the earlier run. Waived failures are executed again, since their waiver
+// may have expired or been removed.
+func (p PreviousResults) NeedsRerun(name string) bool {
+	prev, ok := p.Checks[name]
+	if !ok || prev.Waived {
+		return true
+	}
+	switch prev.Status {
+	case StatusPassed, StatusWarned, StatusSkipped:
+		return false
+	default:
+		return true
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/runtime"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/waiver"
 )
 
 type openshiftClusterVersion = runtime.OpenshiftClusterVersion
@@ -12,6 +13,14 @@ type openshiftClusterVersion = runtime.OpenshiftClusterVersion
 type Result struct {
 	check.Check
 	ElapsedTime time.Duration
+	// Findings contains the findings reported by the check, if it
+	// implements check.FindingsReporter.
+	Findings []check.Finding
+	// Waiver is the waiver that matched the failure of the check, if any.
+	// The failure is reported in Results{}.Warned, unless the waiver had
+	// expired, in which case WaiverExpired is true and the failure stands.
+	Waiver        *waiver.Waiver
+	WaiverExpired bool
 	// Err contains the error a check itself throws if it failed to run.
 	// If populated, the expectation is that this Result is in the
 	// Results{}.Errors slice.
@@ -27,6 +36,33 @@ type Results struct {
 	Failed            []Result
 	Errors            []Result
 	Warned            []Result
+	// Skipped contains checks that could not be evaluated against the
+	// asset under test. These do not affect PassedOverall.
+	Skipped []Result
+	// TimedOut contains checks that did not complete within their deadline.
+	// Like Errors, these cause PassedOverall to be false.
+	TimedOut []Result
+	// ImageDigest is the digest of the image manifest that was tested.
+	ImageDigest string
+}
+
+// IsCustom returns true if the result is of a check that is not part of a
+// Red Hat policy. Results of custom checks are never submitted to Red Hat.
+func (r Result) IsCustom() bool {
+	return check.IsCustom(r.Check)
+}
+
+// IsRemoved returns true if the check is part of a Red Hat policy, but was
+// removed from the run by a check filter. Results of a run that policy checks
+// were removed from are never submitted to Red Hat.
+func (r Result) IsRemoved() bool {
+	return check.IsRemoved(r.Check)
+}
+
+// IsWaived returns true if the failure of the check was waived. Waived
+// results are never submitted to Red Hat.
+func (r Result) IsWaived() bool {
+	return r.Waiver != nil && !r.WaiverExpired
 }
 
 func (r Result) Error() error {
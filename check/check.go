@@ -0,0 +1,97 @@
+// Package check exposes the interface implemented by preflight's checks, so
+// that library consumers can execute their own checks alongside those in
+// Red Hat's policies. Use container.WithAdditionalChecks or
+// operator.WithAdditionalChecks to add checks, and container.WithCheckFilter
+// or operator.WithCheckFilter to remove or wrap them.
+//
+// Checks that are not part of a Red Hat policy are reported in the results
+// like any other, but are marked as custom and are never submitted to Red
+// Hat.
+package check
+
+import (
+	internalcheck "github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+)
+
+// Check is implemented by every check preflight executes.
+type Check = internalcheck.Check
+
+// FindingsReporter is implemented by checks that can explain their result
+// with a list of findings.
+type FindingsReporter = internalcheck.FindingsReporter
+
+// Metadata contains useful information regarding a check.
+type Metadata = internalcheck.Metadata
+
+// HelpText is the help message associated with a check.
+type HelpText = internalcheck.HelpText
+
+// Finding is a single, specific observation a check made about the asset
+// under test.
+type Finding = internalcheck.Finding
+
+// Severity indicates how significant a Finding is.
+type Severity = internalcheck.Severity
+
+// Category groups checks by the concern they address.
+type Category = internalcheck.Category
+
+// ImageReference describes the image under test, and where the files a
+// check requires were extracted to.
+type ImageReference = image.ImageReference
+
+// ValidatorFunc describes a function that, when executed, will check that an
+// image complies with a given check.
+type ValidatorFunc = internalcheck.ValidatorFunc
+
+// The levels a check's Metadata can be assigned.
+const (
+	LevelBest     = internalcheck.LevelBest
+	LevelOptional = internalcheck.LevelOptional
+	LevelWarn     = internalcheck.LevelWarn
+)
+
+// The severities a Finding can be reported with.
+const (
+	SeverityError   = internalcheck.SeverityError
+	SeverityWarning = internalcheck.SeverityWarning
+	SeverityInfo    = internalcheck.SeverityInfo
+)
+
+// The categories a check's Metadata can be assigned.
+const (
+	CategorySecurity  = internalcheck.CategorySecurity
+	CategoryMetadata  = internalcheck.CategoryMetadata
+	CategoryLicensing = internalcheck.CategoryLicensing
+	CategoryPackaging = internalcheck.CategoryPackaging
+	CategoryCluster   = internalcheck.CategoryCluster
+)
+
+var (
+	// ErrCheckSkipped is returned, optionally wrapped with a reason, by a
+	// check's Validate method when the check cannot be evaluated against
+	// the asset under test.
+	ErrCheckSkipped = internalcheck.ErrCheckSkipped
+	// ErrCheckTimedOut is the cause of the context passed to a check's
+	// Validate method when the check exceeds its deadline.
+	ErrCheckTimedOut = internalcheck.ErrCheckTimedOut
+)
+
+// NewGenericCheck returns a check that runs validatorFn, for checks that
+// need no state of their own.
+func NewGenericCheck(
+	name string,
+	validatorFn ValidatorFunc,
+	metadata Metadata,
+	helptext HelpText,
+	requiredFilePatterns []string,
+) Check {
+	return internalcheck.NewGenericCheck(name, validatorFn, metadata, helptext, requiredFilePatterns)
+}
+
+// IsCustom returns true if c is not part of a Red Hat policy. This includes
+// checks that were added or wrapped by a library consumer.
+func IsCustom(c Check) bool {
+	return internalcheck.IsCustom(c)
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/layercache"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/viper"
+)
+
+var errNoCacheDir = errors.New("no cache directory is configured: set --cache-dir or PFLT_CACHE_DIR")
+
+func cacheCmd() *cobra.Command {
+	cacheCmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the image layer cache",
+		Long:  "This command will allow you to inspect and prune the image layer cache shared by runs configured with a cache directory.",
+	}
+
+	cacheCmd.AddCommand(cacheLsCmd())
+	cacheCmd.AddCommand(cachePruneCmd())
+
+	return cacheCmd
+}
+
+func cacheLsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "ls",
+		Short: "List the layers in the cache",
+		Long:  "This command will list the layers in the cache, most recently used first.",
+		Args:  cobra.NoArgs,
+		RunE:  cacheLsRunE,
+	}
+}
+
+func cacheLsRunE(cmd *cobra.Command, args []string) error {
+	lc, err := openLayerCache()
+	if err != nil {
+		return err
+	}
+
+	entries, err := lc.Entries()
+	if err != nil {
+		return err
+	}
+
+	return printCacheEntries(cmd.OutOrStdout(), entries)
+}
+
+// printCacheEntries writes entries to w as a table, followed by their total size.
+func printCacheEntries(w io.Writer, entries []layercache.LayerInfo) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "DIGEST\tSIZE\tLAST USED")
+
+	var total int64
+	for _, e := range entries {
+		total += e.Size
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", e.Digest, humanize.IBytes(uint64(e.Size)), humanize.Time(e.LastUsed))
+	}
+
+	if err := tw.Flush(); err != nil {
+		//coverage:ignore
+		return err
+	}
+
+	fmt.Fprintf(w, "%d layers, %s total\n", len(entries), humanize.IBytes(uint64(total)))
+	return nil
+}
+
+func cachePruneCmd() *cobra.Command {
+	cachePruneCmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Evict the least recently used layers from the cache",
+		Long:  "This command will evict the least recently used layers from the cache until it fits within the configured maximum cache size.",
+		Args:  cobra.NoArgs,
+		RunE:  cachePruneRunE,
+	}
+
+	cachePruneCmd.Flags().Bool("all", false, "Remove every layer from the cache.")
+
+	return cachePruneCmd
+}
+
+func cachePruneRunE(cmd *cobra.Command, args []string) error {
+	lc, err := openLayerCache()
+	if err != nil {
+		return err
+	}
+
+	all, _ := cmd.Flags().GetBool("all")
+	maxSize, err := cacheMaxSize()
+	if err != nil && !all {
+		return err
+	}
+	if all {
+		maxSize = 0
+	}
+
+	evicted, err := lc.Prune(maxSize)
+	if err != nil {
+		return err
+	}
+
+	var freed int64
+	for _, e := range evicted {
+		freed += e.Size
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Evicted %d layers, freeing %s\n", len(evicted), humanize.IBytes(uint64(freed)))
+
+	return nil
+}
+
+// openLayerCache opens the cache in the configured cache directory.
+func openLayerCache() (*layercache.Cache, error) {
+	dir := viper.Instance().GetString("cache_dir")
+	if dir == "" {
+		return nil, errNoCacheDir
+	}
+
+	return layercache.New(dir)
+}
+
+// cacheMaxSize returns the configured maximum cache size in bytes. Values
+// that can not be parsed are rejected, rather than pruning the whole cache.
+func cacheMaxSize() (int64, error) {
+	v := viper.Instance()
+	size := int64(v.GetSizeInBytes("cache_max_size"))
+	if size < 1 {
+		return 0, fmt.Errorf("invalid cache max size %q: must be a positive size such as 512MB or 20GB", v.GetString("cache_max_size"))
+	}
+
+	return size, nil
+}
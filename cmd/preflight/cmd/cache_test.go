@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/viper"
+)
+
+var _ = Describe("cache subcommand", func() {
+	const (
+		recentLayer = "sha256-1111111111111111111111111111111111111111111111111111111111111111"
+		oldLayer    = "sha256-2222222222222222222222222222222222222222222222222222222222222222"
+	)
+
+	var cacheDir string
+	BeforeEach(func() {
+		cacheDir = GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(cacheDir, recentLayer), make([]byte, 2048), 0o600)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(cacheDir, oldLayer), make([]byte, 2048), 0o600)).To(Succeed())
+		past := time.Now().Add(-time.Hour)
+		Expect(os.Chtimes(filepath.Join(cacheDir, oldLayer), past, past)).To(Succeed())
+	})
+
+	Context("when no cache directory is configured", func() {
+		It("should fail to list the cache", func() {
+			_, err := executeCommand(cacheCmd(), "ls")
+			Expect(err).To(MatchError(errNoCacheDir))
+		})
+		It("should fail to prune the cache", func() {
+			_, err := executeCommand(cacheCmd(), "prune")
+			Expect(err).To(MatchError(errNoCacheDir))
+		})
+	})
+
+	Context("when a cache directory is configured", func() {
+		BeforeEach(func() {
+			viper.Instance().Set("cache_dir", cacheDir)
+			DeferCleanup(viper.Instance().Set, "cache_dir", "")
+		})
+
+		It("should list the cached layers and their total size", func() {
+			out, err := executeCommand(cacheCmd(), "ls")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(out).To(ContainSubstring("DIGEST"))
+			Expect(out).To(ContainSubstring("sha256:1111"))
+			Expect(out).To(ContainSubstring("sha256:2222"))
+			Expect(out).To(ContainSubstring("2 layers, 4.0 KiB total"))
+		})
+
+		It("should evict the least recently used layers beyond the maximum size", func() {
+			viper.Instance().Set("cache_max_size", "3kb")
+			DeferCleanup(viper.Instance().Set, "cache_max_size", "")
+
+			out, err := executeCommand(cacheCmd(), "prune")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(out).To(ContainSubstring("Evicted 1 layers, freeing 2.0 KiB"))
+			Expect(filepath.Join(cacheDir, recentLayer)).To(BeAnExistingFile())
+			Expect(filepath.Join(cacheDir, oldLayer)).ToNot(BeAnExistingFile())
+		})
+
+		It("should refuse to prune with an invalid maximum size", func() {
+			viper.Instance().Set("cache_max_size", "lots")
+			DeferCleanup(viper.Instance().Set, "cache_max_size", "")
+
+			_, err := executeCommand(cacheCmd(), "prune")
+			Expect(err).To(MatchError(ContainSubstring("invalid cache max size")))
+			Expect(filepath.Join(cacheDir, recentLayer)).To(BeAnExistingFile())
+		})
+
+		It("should remove every layer with --all", func() {
+			out, err := executeCommand(cacheCmd(), "prune", "--all")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(out).To(ContainSubstring("Evicted 2 layers"))
+		})
+	})
+})
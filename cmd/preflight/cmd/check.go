@@ -1,9 +1,15 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+
 	"github.com/spf13/cobra"
 
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/certification"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/cli"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/formatters"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/runtime"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/viper"
 )
 
@@ -23,8 +29,94 @@ func checkCmd() *cobra.Command {
 	checkCmd.PersistentFlags().String("artifacts", "", "Where check-specific artifacts will be written. (env: PFLT_ARTIFACTS)")
 	_ = viper.BindPFlag("artifacts", checkCmd.PersistentFlags().Lookup("artifacts"))
 
+	checkCmd.PersistentFlags().Int("check-parallelism", 0, fmt.Sprintf("The maximum number of checks to execute at the same time. If empty, container checks\n"+
+		"use %d and operator checks run sequentially. (env: PFLT_CHECK_PARALLELISM)", runtime.DefaultCheckParallelism))
+	_ = viper.BindPFlag("check_parallelism", checkCmd.PersistentFlags().Lookup("check-parallelism"))
+
+	checkCmd.PersistentFlags().Duration("check-timeout", 0, "The maximum time each check may run before it is reported as timed out.\n"+
+		"If empty, checks are not bounded. (env: PFLT_CHECK_TIMEOUT)")
+	_ = viper.BindPFlag("check_timeout", checkCmd.PersistentFlags().Lookup("check-timeout"))
+
+	checkCmd.PersistentFlags().StringToString("check-timeouts", nil, "Timeouts for individual checks, overriding --check-timeout.\n"+
+		"E.g. HasLicense=30s,DeployableByOLM=15m (env: PFLT_CHECK_TIMEOUTS)")
+	_ = viper.BindPFlag("check_timeouts", checkCmd.PersistentFlags().Lookup("check-timeouts"))
+
+	checkCmd.PersistentFlags().StringSlice("checks", nil, "Only execute the named checks. Other checks are reported as skipped. (env: PFLT_CHECKS)")
+	_ = viper.BindPFlag("checks", checkCmd.PersistentFlags().Lookup("checks"))
+
+	checkCmd.PersistentFlags().StringSlice("skip-checks", nil, "Do not execute the named checks. They are reported as skipped. (env: PFLT_SKIP_CHECKS)")
+	_ = viper.BindPFlag("skip_checks", checkCmd.PersistentFlags().Lookup("skip-checks"))
+
+	checkCmd.PersistentFlags().String("rerun-failed", "", "The path to a results.json from an earlier run. Only the checks that failed, errored\n"+
+		"or timed out in that run are executed, and the remaining results are carried over. (env: PFLT_RERUN_FAILED)")
+	_ = viper.BindPFlag("rerun_failed", checkCmd.PersistentFlags().Lookup("rerun-failed"))
+
+	checkCmd.PersistentFlags().String("custom-checks", "", "The path to a YAML file declaring checks to execute in addition to those in the policy. (env: PFLT_CUSTOM_CHECKS)")
+	_ = viper.BindPFlag("custom_checks", checkCmd.PersistentFlags().Lookup("custom-checks"))
+
+	checkCmd.PersistentFlags().String("plugin-path", "", "A list of directories containing plugin executables that implement additional checks,\n"+
+		"separated by the OS path list separator. (env: PFLT_PLUGIN_PATH)")
+	_ = viper.BindPFlag("plugin_path", checkCmd.PersistentFlags().Lookup("plugin-path"))
+
+	checkCmd.PersistentFlags().String("policy", "", "The policy to execute checks against, instead of the one resolved for your project.\n"+
+		"May name a policy defined in the config file. (env: PFLT_POLICY)")
+	_ = viper.BindPFlag("policy", checkCmd.PersistentFlags().Lookup("policy"))
+
+	checkCmd.PersistentFlags().String("waivers", "", "The path to a YAML file of waivers, which report the failures of the checks they name as\n"+
+		"warnings until they expire. (env: PFLT_WAIVERS)")
+	_ = viper.BindPFlag("waivers", checkCmd.PersistentFlags().Lookup("waivers"))
+
 	checkCmd.AddCommand(checkOperatorCmd(cli.RunPreflight))
 	checkCmd.AddCommand(checkContainerCmd(cli.RunPreflight))
 
 	return checkCmd
 }
+
+// readPreviousResults reads the results of an earlier run from path, for use
+// with --rerun-failed.
+func readPreviousResults(path string) (certification.PreviousResults, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return certification.PreviousResults{}, fmt.Errorf("could not open previous results: %w", err)
+	}
+	defer f.Close()
+
+	return formatters.ReadPreviousResults(f)
+}
+
+// submitConflicts are the configurations results can not be submitted with,
+// because they change which checks are executed or how they are evaluated.
+var submitConflicts = []struct {
+	set    func(*runtime.Config) bool
+	reason string
+}{
+	{func(c *runtime.Config) bool { return len(c.Checks) > 0 || len(c.SkipChecks) > 0 }, "checks are selected with --checks or --skip-checks"},
+	{func(c *runtime.Config) bool { return c.RerunFailed != "" }, "checks are re-run with --rerun-failed"},
+	{func(c *runtime.Config) bool { return c.CustomChecks != "" }, "custom checks are added with --custom-checks"},
+	{func(c *runtime.Config) bool { return c.PluginPath != "" }, "plugins are added with --plugin-path"},
+	{func(c *runtime.Config) bool { return c.Policy != "" }, "a policy is selected with --policy"},
+	{func(c *runtime.Config) bool { return c.Waivers != "" }, "waivers are applied with --waivers"},
+	{func(c *runtime.Config) bool { return c.Advisories != "" }, "advisories are checked with --advisories"},
+	{func(c *runtime.Config) bool { return len(c.AllowedLicenses) > 0 || len(c.DeniedLicenses) > 0 }, "licenses are restricted with --allowed-licenses or --denied-licenses"},
+	{func(c *runtime.Config) bool { return c.ScanSecrets || c.SecretsAllowlist != "" }, "secrets are scanned for with --scan-secrets or --secrets-allowlist"},
+	{func(c *runtime.Config) bool { return c.CheckArbitraryUID || len(c.WritablePaths) > 0 }, "arbitrary UIDs are checked with --check-arbitrary-uid or --writable-paths"},
+	{func(c *runtime.Config) bool { return c.CheckFileModes }, "file modes are checked with --check-file-modes"},
+	{func(c *runtime.Config) bool { return c.SignatureKeys != "" }, "signatures are verified with --signature-keys"},
+	{func(c *runtime.Config) bool { return c.ProvenanceKeys != "" }, "provenance is verified with --provenance-keys"},
+}
+
+// validateSubmission returns an error if results are to be submitted with any
+// of the submitConflicts set in cfg.
+func validateSubmission(cfg *runtime.Config) error {
+	if !cfg.Submit {
+		return nil
+	}
+
+	for _, conflict := range submitConflicts {
+		if conflict.set(cfg) {
+			return fmt.Errorf("results cannot be submitted when %s", conflict.reason)
+		}
+	}
+
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"archive/tar"
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -12,6 +13,8 @@ import (
 	"runtime/pprof"
 	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/go-logr/logr"
 	"github.com/google/go-containerregistry/pkg/crane"
@@ -26,6 +29,7 @@ import (
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/cli"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/formatters"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/lib"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/option"
@@ -36,6 +40,13 @@ import (
 
 var submit bool
 
+const (
+	// aggregatedResultsFilename summarizes the results of every platform checked.
+	aggregatedResultsFilename = "aggregated-results.json"
+	// aggregatedJUnitFilename contains a JUnit test suite for every platform checked.
+	aggregatedJUnitFilename = "aggregated-results-junit.xml"
+)
+
 // runPreflight is introduced to make testing of this command possible, it has the same method signature as cli.RunPreflight.
 type runPreflight func(context.Context, func(ctx context.Context) (certification.Results, error), cli.CheckConfig, formatters.ResponseFormatter, lib.ResultWriter, lib.ResultSubmitter) error
 
@@ -46,7 +57,10 @@ func checkContainerCmd(runpreflight runPreflight) *cobra.Command {
 		Long:  `This command will run the Certification checks for a container image. `,
 		Args:  checkContainerPositionalArgs,
 		// this fmt.Sprintf is in place to keep spacing consistent with cobras two spaces that's used in: Usage, Flags, etc
-		Example: fmt.Sprintf("  %s", "preflight check container quay.io/repo-name/container-name:version"),
+		Example: fmt.Sprintf("  %s\n  %s\n  %s",
+			"preflight check container quay.io/repo-name/container-name:version",
+			"preflight check container oci:/path/to/layout:version --local-image-reference quay.io/repo-name/container-name:version",
+			"preflight check container docker-archive:/path/to/image.tar"),
 		PreRunE: validateCertificationComponentID,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return checkContainerRunE(cmd, args, runpreflight)
@@ -56,7 +70,8 @@ func checkContainerCmd(runpreflight runPreflight) *cobra.Command {
 	flags := checkContainerCmd.Flags()
 
 	viper := viper.Instance()
-	flags.BoolVarP(&submit, "submit", "s", false, "submit check container results to Red Hat")
+	flags.BoolVarP(&submit, "submit", "s", false, "submit check container results to Red Hat. Results can not be submitted when other flags\n"+
+		"change which checks are executed or how they are evaluated, e.g. --checks, --policy or --waivers.")
 	_ = viper.BindPFlag("submit", flags.Lookup("submit"))
 
 	flags.Bool("insecure", false, "Use insecure protocol for the registry. Default is False. Cannot be used with submit.")
@@ -90,6 +105,76 @@ func checkContainerCmd(runpreflight runPreflight) *cobra.Command {
 	flags.String("platform", rt.GOARCH, "Architecture of image to pull. Defaults to runtime platform.")
 	_ = viper.BindPFlag("platform", flags.Lookup("platform"))
 
+	flags.String("local-image-reference", "", "The registry, repository and tag to record for an image loaded from an OCI layout (oci:<dir>[:tag])\n"+
+		"or docker archive (docker-archive:<file.tar>), e.g. quay.io/repo-name/container-name:version.\n"+
+		"If empty, these values are derived from the image where possible. (env: PFLT_LOCAL_IMAGE_REFERENCE)")
+	_ = viper.BindPFlag("local_image_reference", flags.Lookup("local-image-reference"))
+
+	flags.Int("platform-parallelism", runtime.DefaultPlatformParallelism, "The maximum number of platforms of a manifest list to check at the same time.\n"+
+		"Platforms are checked one at a time when results are submitted. (env: PFLT_PLATFORM_PARALLELISM)")
+	_ = viper.BindPFlag("platform_parallelism", flags.Lookup("platform-parallelism"))
+
+	flags.StringSlice("allowed-licenses", nil, "If set, HasLicense fails for licenses in /licenses other than the SPDX licenses named,\n"+
+		"e.g. MIT,Apache-2.0,BSD-*. (env: PFLT_ALLOWED_LICENSES)")
+	_ = viper.BindPFlag("allowed_licenses", flags.Lookup("allowed-licenses"))
+
+	flags.StringSlice("denied-licenses", nil, "HasLicense fails for licenses in /licenses that are one of the SPDX licenses named,\n"+
+		"e.g. AGPL-*. (env: PFLT_DENIED_LICENSES)")
+	_ = viper.BindPFlag("denied_licenses", flags.Lookup("denied-licenses"))
+
+	flags.String("advisories", "", "The path to a Red Hat CSAF or OVAL advisory file, or a directory of them. If set, the\n"+
+		"HasNoFixableVulnerabilities check fails for RPMs with fixable Critical or Important vulnerabilities.\n"+
+		"No network access is made. (env: PFLT_ADVISORIES)")
+	_ = viper.BindPFlag("advisories", flags.Lookup("advisories"))
+
+	flags.Bool("scan-secrets", false, "If set, the HasNoEmbeddedSecrets check fails if any layer or the config of the image contains\n"+
+		"credentials. (env: PFLT_SCAN_SECRETS)")
+	_ = viper.BindPFlag("scan_secrets", flags.Lookup("scan-secrets"))
+
+	flags.String("secrets-allowlist", "", "The path to a file describing secrets HasNoEmbeddedSecrets permits, such as test keys\n"+
+		"shipped by a package. Implies --scan-secrets. (env: PFLT_SECRETS_ALLOWLIST)")
+	_ = viper.BindPFlag("secrets_allowlist", flags.Lookup("secrets-allowlist"))
+
+	flags.Bool("check-arbitrary-uid", false, "If set, the SupportsArbitraryUID check warns unless WORKDIR, VOLUMEs and HOME are owned by\n"+
+		"group 0 and group-writable. (env: PFLT_CHECK_ARBITRARY_UID)")
+	_ = viper.BindPFlag("check_arbitrary_uid", flags.Lookup("check-arbitrary-uid"))
+
+	flags.StringSlice("writable-paths", nil, "Paths the image writes to that SupportsArbitraryUID verifies are owned by group 0 and\n"+
+		"group-writable, in addition to WORKDIR, VOLUMEs and HOME, e.g. /var/cache/app. Implies --check-arbitrary-uid.\n"+
+		"(env: PFLT_WRITABLE_PATHS)")
+	_ = viper.BindPFlag("writable_paths", flags.Lookup("writable-paths"))
+
+	flags.StringSlice("sbom-format", nil, "If set, an SBOM of the image is written to the artifacts directory in each of the formats named:\n"+
+		"spdx for SPDX 2.3 JSON, or cyclonedx for CycloneDX 1.5 JSON. (env: PFLT_SBOM_FORMAT)")
+	_ = viper.BindPFlag("sbom_format", flags.Lookup("sbom-format"))
+
+	flags.Bool("check-file-modes", false, "If set, the HasNoUnsafeFileModes check warns if the image adds setuid or setgid executables,\n"+
+		"world-writable paths or device nodes over its base image.\n"+
+		"(env: PFLT_CHECK_FILE_MODES)")
+	_ = viper.BindPFlag("check_file_modes", flags.Lookup("check-file-modes"))
+
+	flags.String("signature-keys", "", "The path to a file of PEM encoded public keys or certificates. If set, the HasVerifiedSignature\n"+
+		"check fails unless the image has a cosign signature made by one of them. No transparency log is consulted.\n"+
+		"(env: PFLT_SIGNATURE_KEYS)")
+	_ = viper.BindPFlag("signature_keys", flags.Lookup("signature-keys"))
+
+	flags.String("provenance-keys", "", "The path to a file of PEM encoded public keys or certificates. If set, the HasTrustedProvenance\n"+
+		"check fails unless the image has a SLSA provenance attestation signed by one of them.\n"+
+		"(env: PFLT_PROVENANCE_KEYS)")
+	_ = viper.BindPFlag("provenance_keys", flags.Lookup("provenance-keys"))
+
+	flags.StringSlice("allowed-builders", nil, "If set, HasTrustedProvenance fails for provenance with a builder ID not matching one of\n"+
+		"the patterns named, e.g. https://konflux-ci.dev/*. Requires --provenance-keys. (env: PFLT_ALLOWED_BUILDERS)")
+	_ = viper.BindPFlag("allowed_builders", flags.Lookup("allowed-builders"))
+
+	flags.StringSlice("allowed-source-repositories", nil, "If set, HasTrustedProvenance fails for provenance with a source repository not matching\n"+
+		"one of the patterns named, e.g. https://github.com/org/*. Requires --provenance-keys. (env: PFLT_ALLOWED_SOURCE_REPOSITORIES)")
+	_ = viper.BindPFlag("allowed_source_repositories", flags.Lookup("allowed-source-repositories"))
+
+	flags.Bool("require-hermetic", false, "If set, HasTrustedProvenance fails for provenance that does not record a hermetic build.\n"+
+		"Requires --provenance-keys. (env: PFLT_REQUIRE_HERMETIC)")
+	_ = viper.BindPFlag("require_hermetic", flags.Lookup("require-hermetic"))
+
 	_ = viper.BindEnv("cpuprofile")
 	_ = viper.BindEnv("memprofile")
 	_ = viper.BindEnv("tempDir")
@@ -135,116 +220,255 @@ func checkContainerRunE(cmd *cobra.Command, args []string, runpreflight runPrefl
 
 	cfg.Image = containerImage
 
+	if image.IsLocal(containerImage) && cfg.Submit {
+		return fmt.Errorf("results for images loaded from a local path cannot be submitted: push the image to a registry first")
+	}
+
+	if err := validateSubmission(cfg); err != nil {
+		return err
+	}
+
+	if cfg.ProvenanceKeys == "" && (len(cfg.AllowedBuilders) > 0 || len(cfg.AllowedSourceRepositories) > 0 || cfg.RequireHermetic) {
+		return fmt.Errorf("provenance rules require the provenance keys to be set with --provenance-keys")
+	}
+
 	containerImagePlatforms, err := platformsToBeProcessed(cmd, cfg)
 	if err != nil {
 		return err
 	}
 
-	for _, platform := range containerImagePlatforms {
-		logger.Info(fmt.Sprintf("running checks for %s for platform %s", containerImage, platform))
-		artifactsWriter, err := artifacts.NewFilesystemWriter(artifacts.WithDirectory(filepath.Join(cfg.Artifacts, platform)))
+	opts := generateContainerCheckOptions(cfg)
+
+	if cfg.RerunFailed != "" {
+		// Previous results are for a single image digest, so only one
+		// platform can match them.
+		if len(containerImagePlatforms) > 1 {
+			return fmt.Errorf("--rerun-failed requires a single platform: use --platform to select the platform the results are for")
+		}
+		previous, err := readPreviousResults(cfg.RerunFailed)
 		if err != nil {
-			//coverage:ignore
 			return err
 		}
+		opts = append(opts, container.WithPreviousResults(previous))
+	}
+
+	// Run the container check.
+	cmd.SilenceUsage = true
+
+	// Platforms are checked concurrently, and their results are reported in
+	// the order they were listed regardless of the order they complete in.
+	// Results are submitted one platform at a time, and no more platforms
+	// are checked once one has failed, so that a manifest list is not left
+	// partially submitted.
+	platformResults := make([]formatters.PlatformResults, len(containerImagePlatforms))
+	sem := make(chan struct{}, platformParallelism(cfg))
+	var wg sync.WaitGroup
+	var failed atomic.Bool
+	checked := 0
+	for i, platform := range containerImagePlatforms {
+		sem <- struct{}{}
+		if cfg.Submit && failed.Load() {
+			break
+		}
+		checked++
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results, err := checkContainerPlatform(ctx, cfg, platform, opts, runpreflight)
+			if err != nil {
+				failed.Store(true)
+			}
+			platformResults[i] = formatters.PlatformResults{Platform: platform, Results: results, Err: err}
+		}()
+	}
+	wg.Wait()
+	platformResults = platformResults[:checked]
+
+	if err := writeAggregatedResults(ctx, cfg, platformResults); err != nil {
+		return err
+	}
 
-		// Add the artifact writer to the context for use by checks.
-		ctx := artifacts.ContextWithWriter(ctx, artifactsWriter)
+	var errs []error
+	for _, pr := range platformResults {
+		if pr.Err != nil {
+			errs = append(errs, fmt.Errorf("platform %s: %w", pr.Platform, pr.Err))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
 
-		formatter, err := formatters.NewByName(formatters.DefaultFormat)
+	if viper.Instance().IsSet("memprofile") {
+		f, err := os.Create(viper.Instance().GetString("memprofile"))
 		if err != nil {
 			//coverage:ignore
+			logger.Error(err, "could not create memory profile")
+		}
+		defer f.Close()
+
+		rt.GC() // get up-to-date statistics
+		if err := pprof.Lookup("allocs").WriteTo(f, 0); err != nil {
+			//coverage:ignore
+			logger.Error(err, "could not start memory profile")
 			return err
 		}
+	}
+	return nil
+}
 
-		opts := generateContainerCheckOptions(cfg)
-		opts = append(opts, container.WithPlatform(platform))
+// checkContainerPlatform runs the container checks against platform of the
+// image, writing its results and artifacts to a directory named after the
+// platform.
+func checkContainerPlatform(ctx context.Context, cfg *runtime.Config, platform string, opts []container.Option, runpreflight runPreflight) (certification.Results, error) {
+	logger := logr.FromContextOrDiscard(ctx).WithValues("platform", platform)
+	ctx = logr.NewContext(ctx, logger)
 
-		checkcontainer := container.NewCheck(
-			containerImage,
-			opts...,
-		)
+	logger.Info(fmt.Sprintf("running checks for %s for platform %s", cfg.Image, platform))
+	artifactsWriter, err := artifacts.NewFilesystemWriter(artifacts.WithDirectory(filepath.Join(cfg.Artifacts, platform)))
+	if err != nil {
+		//coverage:ignore
+		return certification.Results{}, err
+	}
 
-		pc := lib.NewPyxisClient(ctx, cfg.CertificationComponentID, cfg.PyxisAPIToken, cfg.PyxisHost)
-		resultSubmitter := lib.ResolveSubmitter(pc, cfg.CertificationComponentID, cfg.DockerConfig, cfg.LogFile)
+	// Add the artifact writer to the context for use by checks.
+	ctx = artifacts.ContextWithWriter(ctx, artifactsWriter)
 
-		// use a noop submitter, since the konflux system has no need to submit results to pyxis.
-		if cfg.Konflux {
-			resultSubmitter = lib.NewNoopSubmitter(true, nil)
-		}
+	formatter, err := formatters.NewByName(formatters.DefaultFormat)
+	if err != nil {
+		//coverage:ignore
+		return certification.Results{}, err
+	}
 
-		// Run the  container check.
-		cmd.SilenceUsage = true
+	opts = append(slices.Clone(opts), container.WithPlatform(platform))
+	if cfg.TempDir != "" {
+		// Platforms are checked concurrently, so each needs its own directory.
+		opts = append(opts, container.WithTempDir(filepath.Join(cfg.TempDir, platform)))
+	}
 
-		if err := runpreflight(
-			ctx,
-			checkcontainer.Run,
-			cli.CheckConfig{
-				IncludeJUnitResults: cfg.WriteJUnit,
-				SubmitResults:       cfg.Submit,
-			},
-			formatter,
-			&runtime.ResultWriterFile{},
-			resultSubmitter,
-		); err != nil {
-			return err
-		}
+	checkcontainer := container.NewCheck(
+		cfg.Image,
+		opts...,
+	)
 
-		// checking for offline flag, if present tar up the contents of the artifacts directory
-		if cfg.Offline {
-			src := artifactsWriter.Path()
-			var buf bytes.Buffer
+	pc := lib.NewPyxisClient(ctx, cfg.CertificationComponentID, cfg.PyxisAPIToken, cfg.PyxisHost)
+	resultSubmitter := lib.ResolveSubmitter(pc, cfg.CertificationComponentID, cfg.DockerConfig, cfg.LogFile)
 
-			// check to see if a tar file already exist to account for someone re-running
-			exists, err := artifactsWriter.Exists(check.DefaultArtifactsTarFileName)
-			if err != nil {
-				//coverage:ignore
-				return fmt.Errorf("unable to check if tar already exists: %v", err)
-			}
+	// use a noop submitter, since the konflux system has no need to submit results to pyxis.
+	if cfg.Konflux {
+		resultSubmitter = lib.NewNoopSubmitter(true, nil)
+	}
 
-			// remove the tar file if it exists
-			if exists {
-				//coverage:ignore
-				err = artifactsWriter.Remove(check.DefaultArtifactsTarFileName)
-				if err != nil {
-					//coverage:ignore
-					return fmt.Errorf("unable to remove existing tar: %v", err)
-				}
-			}
+	// Keep the results, so they can be aggregated with those of other platforms.
+	var results certification.Results
+	run := func(ctx context.Context) (certification.Results, error) {
+		var err error
+		results, err = checkcontainer.Run(ctx)
+		return results, err
+	}
 
-			// tar the directory
-			err = artifactsTar(ctx, src, &buf)
-			if err != nil {
-				//coverage:ignore
-				return fmt.Errorf("unable to tar up artifacts directory: %v", err)
-			}
+	if err := runpreflight(
+		ctx,
+		run,
+		cli.CheckConfig{
+			IncludeJUnitResults: cfg.WriteJUnit,
+			SubmitResults:       cfg.Submit,
+		},
+		formatter,
+		&runtime.ResultWriterFile{},
+		resultSubmitter,
+	); err != nil {
+		return results, err
+	}
+
+	// checking for offline flag, if present tar up the contents of the artifacts directory
+	if cfg.Offline {
+		src := artifactsWriter.Path()
+		var buf bytes.Buffer
 
-			// writing the tar file to disk
-			_, err = artifactsWriter.WriteFile(check.DefaultArtifactsTarFileName, &buf)
+		// check to see if a tar file already exist to account for someone re-running
+		exists, err := artifactsWriter.Exists(check.DefaultArtifactsTarFileName)
+		if err != nil {
+			//coverage:ignore
+			return results, fmt.Errorf("unable to check if tar already exists: %v", err)
+		}
+
+		// remove the tar file if it exists
+		if exists {
+			//coverage:ignore
+			err = artifactsWriter.Remove(check.DefaultArtifactsTarFileName)
 			if err != nil {
 				//coverage:ignore
-				return fmt.Errorf("could not artifacts tar to artifacts dir: %w", err)
+				return results, fmt.Errorf("unable to remove existing tar: %v", err)
 			}
+		}
 
-			logger.Info("artifact tar written to disk", "filename", check.DefaultArtifactsTarFileName)
+		// tar the directory
+		err = artifactsTar(ctx, src, &buf)
+		if err != nil {
+			//coverage:ignore
+			return results, fmt.Errorf("unable to tar up artifacts directory: %v", err)
 		}
-	}
 
-	if viper.Instance().IsSet("memprofile") {
-		f, err := os.Create(viper.Instance().GetString("memprofile"))
+		// writing the tar file to disk
+		_, err = artifactsWriter.WriteFile(check.DefaultArtifactsTarFileName, &buf)
 		if err != nil {
 			//coverage:ignore
-			logger.Error(err, "could not create memory profile")
+			return results, fmt.Errorf("could not artifacts tar to artifacts dir: %w", err)
 		}
-		defer f.Close()
 
-		rt.GC() // get up-to-date statistics
-		if err := pprof.Lookup("allocs").WriteTo(f, 0); err != nil {
+		logger.Info("artifact tar written to disk", "filename", check.DefaultArtifactsTarFileName)
+	}
+
+	return results, nil
+}
+
+// platformParallelism returns the number of platforms that may be checked at
+// the same time. Results are submitted one platform at a time.
+func platformParallelism(cfg *runtime.Config) int {
+	if cfg.Submit || cfg.PlatformParallelism < 1 {
+		return 1
+	}
+	return cfg.PlatformParallelism
+}
+
+// writeAggregatedResults writes a summary of the results of all platforms to
+// the top of the artifacts directory, along with a combined JUnit report if
+// JUnit results were requested.
+func writeAggregatedResults(ctx context.Context, cfg *runtime.Config, platformResults []formatters.PlatformResults) error {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	artifactsWriter, err := artifacts.NewFilesystemWriter(artifacts.WithDirectory(cfg.Artifacts))
+	if err != nil {
+		//coverage:ignore
+		return err
+	}
+
+	aggregated, err := formatters.AggregateJSON(cfg.Image, cfg.ManifestListDigest, platformResults)
+	if err != nil {
+		//coverage:ignore
+		return err
+	}
+	if _, err := artifactsWriter.WriteFile(aggregatedResultsFilename, bytes.NewReader(aggregated)); err != nil {
+		//coverage:ignore
+		return fmt.Errorf("could not write aggregated results: %w", err)
+	}
+
+	if cfg.WriteJUnit {
+		junit, err := formatters.AggregateJUnitXML(platformResults)
+		if err != nil {
 			//coverage:ignore
-			logger.Error(err, "could not start memory profile")
 			return err
 		}
+		if _, err := artifactsWriter.WriteFile(aggregatedJUnitFilename, bytes.NewReader(junit)); err != nil {
+			//coverage:ignore
+			return fmt.Errorf("could not write aggregated JUnit results: %w", err)
+		}
 	}
+
+	logger.V(log.DBG).Info("aggregated results written to disk", "filename", aggregatedResultsFilename)
 	return nil
 }
 
@@ -321,6 +545,7 @@ func generateContainerCheckOptions(cfg *runtime.Config) []container.Option {
 		container.WithPlatform(cfg.Platform),
 		container.WithManifestListDigest(cfg.ManifestListDigest),
 		container.WithTempDir(cfg.TempDir),
+		container.WithLocalImageReference(cfg.LocalImageReference),
 	}
 
 	// set auth information if both are present in config.
@@ -339,6 +564,87 @@ func generateContainerCheckOptions(cfg *runtime.Config) []container.Option {
 		o = append(o, container.WithKonflux())
 	}
 
+	if cfg.CheckParallelism > 0 {
+		o = append(o, container.WithCheckParallelism(cfg.CheckParallelism))
+	}
+
+	if cfg.CacheDir != "" {
+		o = append(o, container.WithCacheDir(cfg.CacheDir), container.WithCacheMaxSize(cfg.CacheMaxSize))
+	}
+
+	if cfg.CheckTimeout > 0 || len(cfg.CheckTimeouts) > 0 {
+		o = append(o, container.WithCheckTimeout(cfg.CheckTimeout, cfg.CheckTimeouts))
+	}
+
+	if len(cfg.Checks) > 0 {
+		o = append(o, container.WithIncludedChecks(cfg.Checks...))
+	}
+
+	if len(cfg.SkipChecks) > 0 {
+		o = append(o, container.WithExcludedChecks(cfg.SkipChecks...))
+	}
+
+	if cfg.CustomChecks != "" {
+		o = append(o, container.WithCustomChecks(cfg.CustomChecks))
+	}
+
+	if cfg.PluginPath != "" {
+		o = append(o, container.WithPlugins(cfg.PluginPath))
+	}
+
+	if len(cfg.Policies) > 0 {
+		o = append(o, container.WithPolicyDefinitions(cfg.Policies...))
+	}
+
+	if cfg.Policy != "" {
+		o = append(o, container.WithPolicy(cfg.Policy))
+	}
+
+	if cfg.Waivers != "" {
+		o = append(o, container.WithWaivers(cfg.Waivers))
+	}
+
+	if len(cfg.AllowedLicenses) > 0 || len(cfg.DeniedLicenses) > 0 {
+		o = append(o, container.WithLicenseRules(cfg.AllowedLicenses, cfg.DeniedLicenses))
+	}
+
+	if cfg.Advisories != "" {
+		o = append(o, container.WithAdvisories(cfg.Advisories))
+	}
+
+	if cfg.ScanSecrets {
+		o = append(o, container.WithSecretsScan())
+	}
+
+	if cfg.SecretsAllowlist != "" {
+		o = append(o, container.WithSecretsAllowlist(cfg.SecretsAllowlist))
+	}
+
+	if cfg.CheckArbitraryUID {
+		o = append(o, container.WithArbitraryUIDCheck())
+	}
+
+	if len(cfg.WritablePaths) > 0 {
+		o = append(o, container.WithWritablePaths(cfg.WritablePaths...))
+	}
+
+	if len(cfg.SBOMFormats) > 0 {
+		o = append(o, container.WithSBOMFormats(cfg.SBOMFormats...))
+	}
+
+	if cfg.CheckFileModes {
+		o = append(o, container.WithFileModesCheck())
+	}
+
+	if cfg.SignatureKeys != "" {
+		o = append(o, container.WithSignatureKeys(cfg.SignatureKeys))
+	}
+
+	if cfg.ProvenanceKeys != "" {
+		o = append(o, container.WithProvenanceKeys(cfg.ProvenanceKeys),
+			container.WithProvenanceRules(cfg.AllowedBuilders, cfg.AllowedSourceRepositories, cfg.RequireHermetic))
+	}
+
 	return o
 }
 
@@ -429,6 +735,11 @@ func platformsToBeProcessed(cmd *cobra.Command, cfg *runtime.Config) ([]string,
 
 	containerImagePlatforms := []string{cfg.Platform}
 
+	// Images loaded from disk are resolved to a single platform by the engine.
+	if image.IsLocal(cfg.Image) {
+		return containerImagePlatforms, nil
+	}
+
 	options := crane.GetOptions(option.GenerateCraneOptions(ctx, cfg)...)
 	ref, err := name.ParseReference(cfg.Image, options.Name...)
 	if err != nil {
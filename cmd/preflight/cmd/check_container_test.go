@@ -3,6 +3,7 @@ package cmd
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -32,6 +33,7 @@ import (
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/cli"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/formatters"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/lib"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/policy"
 	preruntime "github.com/redhat-openshift-ecosystem/openshift-preflight/internal/runtime"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/viper"
 )
@@ -128,6 +130,79 @@ var _ = Describe("Check Container Command", func() {
 				_, err := executeCommandWithLogger(checkContainerCmd(mockRunPreflightReturnNil), logr.Discard(), manifestListSrc)
 				Expect(err).ToNot(HaveOccurred())
 			})
+			It("should write aggregated results for every platform", func() {
+				_, err := executeCommandWithLogger(checkContainerCmd(mockRunPreflightReturnNil), logr.Discard(), manifestListSrc)
+				Expect(err).ToNot(HaveOccurred())
+
+				b, err := os.ReadFile(filepath.Join(os.Getenv("PFLT_ARTIFACTS"), aggregatedResultsFilename))
+				Expect(err).ToNot(HaveOccurred())
+				var aggregated formatters.AggregatedResponse
+				Expect(json.Unmarshal(b, &aggregated)).To(Succeed())
+				Expect(aggregated.Image).To(Equal(manifestListSrc))
+				Expect(aggregated.Platforms).ToNot(BeEmpty())
+				Expect(aggregated.Platforms[0].Platform).To(Equal("amd64"))
+			})
+		})
+		When("platforms are checked one at a time", func() {
+			It("should not error", func() {
+				_, err := executeCommandWithLogger(checkContainerCmd(mockRunPreflightReturnNil), logr.Discard(), manifestListSrc, "--platform-parallelism", "1")
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+		When("JUnit results are requested", func() {
+			BeforeEach(func() {
+				os.Setenv("PFLT_JUNIT", "true")
+				DeferCleanup(os.Unsetenv, "PFLT_JUNIT")
+			})
+			It("should write an aggregated JUnit report", func() {
+				_, err := executeCommandWithLogger(checkContainerCmd(mockRunPreflightReturnNil), logr.Discard(), manifestListSrc)
+				Expect(err).ToNot(HaveOccurred())
+
+				b, err := os.ReadFile(filepath.Join(os.Getenv("PFLT_ARTIFACTS"), aggregatedJUnitFilename))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(string(b)).To(ContainSubstring("Red Hat Certification (amd64)"))
+			})
+		})
+		When("failed checks are re-run", func() {
+			It("should require a single platform", func() {
+				viper.Instance().Set("rerun_failed", "results.json")
+				out, err := executeCommandWithLogger(checkContainerCmd(mockRunPreflightReturnNil), logr.Discard(), manifestListSrc)
+				Expect(err).To(HaveOccurred())
+				Expect(out).To(ContainSubstring("--rerun-failed requires a single platform"))
+			})
+		})
+		When("checking a platform errors", func() {
+			It("should report the error for every platform", func() {
+				_, err := executeCommandWithLogger(checkContainerCmd(mockRunPreflightReturnErr), logr.Discard(), manifestListSrc)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("platform amd64"))
+
+				b, err := os.ReadFile(filepath.Join(os.Getenv("PFLT_ARTIFACTS"), aggregatedResultsFilename))
+				Expect(err).ToNot(HaveOccurred())
+				var aggregated formatters.AggregatedResponse
+				Expect(json.Unmarshal(b, &aggregated)).To(Succeed())
+				Expect(aggregated.Passed).To(BeFalse())
+				for _, p := range aggregated.Platforms {
+					Expect(p.Error).ToNot(BeEmpty())
+				}
+			})
+			It("should stop at the first platform error when results are submitted", func() {
+				var checked []string
+				runpreflight := func(_ context.Context, _ func(context.Context) (certification.Results, error), _ cli.CheckConfig, _ formatters.ResponseFormatter, _ lib.ResultWriter, _ lib.ResultSubmitter) error {
+					checked = append(checked, "platform")
+					return errors.New("random error")
+				}
+				_, err := executeCommandWithLogger(checkContainerCmd(runpreflight), logr.Discard(), manifestListSrc,
+					"--submit", "--certification-component-id=fooid", "--pyxis-api-token=footoken")
+				Expect(err).To(MatchError(ContainSubstring("platform amd64")))
+				Expect(checked).To(HaveLen(1))
+
+				b, err := os.ReadFile(filepath.Join(os.Getenv("PFLT_ARTIFACTS"), aggregatedResultsFilename))
+				Expect(err).ToNot(HaveOccurred())
+				var aggregated formatters.AggregatedResponse
+				Expect(json.Unmarshal(b, &aggregated)).To(Succeed())
+				Expect(aggregated.Platforms).To(HaveLen(1))
+			})
 		})
 	})
 
@@ -147,6 +222,24 @@ var _ = Describe("Check Container Command", func() {
 		Entry("index manifest, invalid platform", "index", "none", HaveOccurred(), true),
 	)
 
+	When("failed checks are re-run", func() {
+		var resultsPath string
+		BeforeEach(func() {
+			resultsPath = filepath.Join(GinkgoT().TempDir(), "results.json")
+			viper.Instance().Set("rerun_failed", resultsPath)
+		})
+		It("should re-run the failed checks for the image", func() {
+			Expect(os.WriteFile(resultsPath, []byte(`{"image_digest": "sha256:abc", "results": {"failed": [{"name": "HasLicense"}]}}`), 0o644)).To(Succeed())
+			_, err := executeCommandWithLogger(checkContainerCmd(mockRunPreflightReturnNil), logr.Discard(), src)
+			Expect(err).ToNot(HaveOccurred())
+		})
+		It("should fail if the previous results can not be read", func() {
+			out, err := executeCommandWithLogger(checkContainerCmd(mockRunPreflightReturnNil), logr.Discard(), src)
+			Expect(err).To(HaveOccurred())
+			Expect(out).To(ContainSubstring("could not open previous results"))
+		})
+	})
+
 	Context("When validating check container arguments and flags", func() {
 		Context("and the user provided more than 1 positional arg", func() {
 			It("should fail to run", func() {
@@ -219,6 +312,118 @@ var _ = Describe("Check Container Command", func() {
 		})
 	})
 
+	When("the results can not be submitted", func() {
+		submitArgs := []string{"--submit", "--certification-component-id=fooid", "--pyxis-api-token=footoken"}
+		BeforeEach(func() {
+			viper.Reset()
+			DeferCleanup(viper.Reset)
+		})
+		It("should refuse to submit results for a local image", func() {
+			out, err := executeCommandWithLogger(checkContainerCmd(mockRunPreflightReturnNil), logr.Discard(), append([]string{"oci:/tmp/layout"}, submitArgs...)...)
+			Expect(err).To(HaveOccurred())
+			Expect(out).To(ContainSubstring("images loaded from a local path cannot be submitted"))
+		})
+		It("should refuse to submit results when checks are selected", func() {
+			viper.Instance().Set("checks", []string{"HasLicense"})
+			out, err := executeCommandWithLogger(checkContainerCmd(mockRunPreflightReturnNil), logr.Discard(), append([]string{"foo"}, submitArgs...)...)
+			Expect(err).To(HaveOccurred())
+			Expect(out).To(ContainSubstring("cannot be submitted when checks are selected"))
+		})
+		It("should refuse to submit results when checks are skipped", func() {
+			viper.Instance().Set("skip_checks", []string{"HasLicense"})
+			out, err := executeCommandWithLogger(checkContainerCmd(mockRunPreflightReturnNil), logr.Discard(), append([]string{"foo"}, submitArgs...)...)
+			Expect(err).To(HaveOccurred())
+			Expect(out).To(ContainSubstring("cannot be submitted when checks are selected"))
+		})
+		It("should refuse to submit results when custom checks are added", func() {
+			viper.Instance().Set("custom_checks", "rules.yaml")
+			out, err := executeCommandWithLogger(checkContainerCmd(mockRunPreflightReturnNil), logr.Discard(), append([]string{"foo"}, submitArgs...)...)
+			Expect(err).To(HaveOccurred())
+			Expect(out).To(ContainSubstring("cannot be submitted when custom checks are added"))
+		})
+		It("should refuse to submit results when plugins are added", func() {
+			viper.Instance().Set("plugin_path", "/opt/preflight/plugins")
+			out, err := executeCommandWithLogger(checkContainerCmd(mockRunPreflightReturnNil), logr.Discard(), append([]string{"foo"}, submitArgs...)...)
+			Expect(err).To(HaveOccurred())
+			Expect(out).To(ContainSubstring("cannot be submitted when plugins are added"))
+		})
+		It("should refuse to submit results when a policy is selected", func() {
+			viper.Instance().Set("policy", "root")
+			out, err := executeCommandWithLogger(checkContainerCmd(mockRunPreflightReturnNil), logr.Discard(), append([]string{"foo"}, submitArgs...)...)
+			Expect(err).To(HaveOccurred())
+			Expect(out).To(ContainSubstring("cannot be submitted when a policy is selected"))
+		})
+		It("should refuse to submit results when waivers are applied", func() {
+			viper.Instance().Set("waivers", "waivers.yaml")
+			out, err := executeCommandWithLogger(checkContainerCmd(mockRunPreflightReturnNil), logr.Discard(), append([]string{"foo"}, submitArgs...)...)
+			Expect(err).To(HaveOccurred())
+			Expect(out).To(ContainSubstring("cannot be submitted when waivers are applied"))
+		})
+		It("should refuse to submit results when advisories are checked", func() {
+			viper.Instance().Set("advisories", "/data/csaf")
+			out, err := executeCommandWithLogger(checkContainerCmd(mockRunPreflightReturnNil), logr.Discard(), append([]string{"foo"}, submitArgs...)...)
+			Expect(err).To(HaveOccurred())
+			Expect(out).To(ContainSubstring("cannot be submitted when advisories are checked"))
+		})
+		It("should refuse to submit results when secrets are scanned for", func() {
+			viper.Instance().Set("scan_secrets", true)
+			out, err := executeCommandWithLogger(checkContainerCmd(mockRunPreflightReturnNil), logr.Discard(), append([]string{"foo"}, submitArgs...)...)
+			Expect(err).To(HaveOccurred())
+			Expect(out).To(ContainSubstring("cannot be submitted when secrets are scanned for"))
+		})
+		It("should refuse to submit results when secrets are allowed", func() {
+			viper.Instance().Set("secrets_allowlist", "secrets-allowlist.yaml")
+			out, err := executeCommandWithLogger(checkContainerCmd(mockRunPreflightReturnNil), logr.Discard(), append([]string{"foo"}, submitArgs...)...)
+			Expect(err).To(HaveOccurred())
+			Expect(out).To(ContainSubstring("cannot be submitted when secrets are scanned for"))
+		})
+		It("should refuse to submit results when arbitrary UIDs are checked", func() {
+			viper.Instance().Set("writable_paths", []string{"/var/cache/app"})
+			out, err := executeCommandWithLogger(checkContainerCmd(mockRunPreflightReturnNil), logr.Discard(), append([]string{"foo"}, submitArgs...)...)
+			Expect(err).To(HaveOccurred())
+			Expect(out).To(ContainSubstring("cannot be submitted when arbitrary UIDs are checked"))
+		})
+		It("should refuse to submit results when file modes are checked", func() {
+			viper.Instance().Set("check_file_modes", true)
+			out, err := executeCommandWithLogger(checkContainerCmd(mockRunPreflightReturnNil), logr.Discard(), append([]string{"foo"}, submitArgs...)...)
+			Expect(err).To(HaveOccurred())
+			Expect(out).To(ContainSubstring("cannot be submitted when file modes are checked"))
+		})
+		It("should refuse to submit results when signatures are verified", func() {
+			viper.Instance().Set("signature_keys", "cosign.pub")
+			out, err := executeCommandWithLogger(checkContainerCmd(mockRunPreflightReturnNil), logr.Discard(), append([]string{"foo"}, submitArgs...)...)
+			Expect(err).To(HaveOccurred())
+			Expect(out).To(ContainSubstring("cannot be submitted when signatures are verified"))
+		})
+		It("should refuse to submit results when provenance is verified", func() {
+			viper.Instance().Set("provenance_keys", "konflux.pub")
+			out, err := executeCommandWithLogger(checkContainerCmd(mockRunPreflightReturnNil), logr.Discard(), append([]string{"foo"}, submitArgs...)...)
+			Expect(err).To(HaveOccurred())
+			Expect(out).To(ContainSubstring("cannot be submitted when provenance is verified"))
+		})
+		It("should refuse to submit results when licenses are restricted", func() {
+			viper.Instance().Set("denied_licenses", []string{"AGPL-*"})
+			out, err := executeCommandWithLogger(checkContainerCmd(mockRunPreflightReturnNil), logr.Discard(), append([]string{"foo"}, submitArgs...)...)
+			Expect(err).To(HaveOccurred())
+			Expect(out).To(ContainSubstring("cannot be submitted when licenses are restricted"))
+		})
+		It("should refuse to submit results when failed checks are re-run", func() {
+			viper.Instance().Set("rerun_failed", "results.json")
+			out, err := executeCommandWithLogger(checkContainerCmd(mockRunPreflightReturnNil), logr.Discard(), append([]string{"foo"}, submitArgs...)...)
+			Expect(err).To(HaveOccurred())
+			Expect(out).To(ContainSubstring("cannot be submitted when checks are re-run"))
+		})
+	})
+
+	When("provenance rules are set without provenance keys", func() {
+		It("should refuse to run", func() {
+			viper.Instance().Set("require_hermetic", true)
+			out, err := executeCommandWithLogger(checkContainerCmd(mockRunPreflightReturnNil), logr.Discard(), "foo")
+			Expect(err).To(HaveOccurred())
+			Expect(out).To(ContainSubstring("provenance rules require the provenance keys"))
+		})
+	})
+
 	Context("When validating the certification-component-id flag", func() {
 		Context("and the flag is set properly", func() {
 			BeforeEach(func() {
@@ -470,6 +675,138 @@ var _ = Describe("Check Container Command", func() {
 			Expect(opts).To(HaveLen(len(baseOpts) + 1))
 		})
 
+		It("should include the custom checks option when CustomChecks is set", func() {
+			cfg := &preruntime.Config{
+				CustomChecks: "rules.yaml",
+			}
+			baseOpts := generateContainerCheckOptions(&preruntime.Config{})
+			opts := generateContainerCheckOptions(cfg)
+			Expect(opts).To(HaveLen(len(baseOpts) + 1))
+		})
+
+		It("should include the plugins option when PluginPath is set", func() {
+			cfg := &preruntime.Config{
+				PluginPath: "/opt/preflight/plugins",
+			}
+			baseOpts := generateContainerCheckOptions(&preruntime.Config{})
+			opts := generateContainerCheckOptions(cfg)
+			Expect(opts).To(HaveLen(len(baseOpts) + 1))
+		})
+
+		It("should include the policy options when a policy is selected and policies are defined", func() {
+			cfg := &preruntime.Config{
+				Policy:   "strict",
+				Policies: []policy.Definition{{Name: "strict", Inherits: policy.PolicyContainer}},
+			}
+			baseOpts := generateContainerCheckOptions(&preruntime.Config{})
+			opts := generateContainerCheckOptions(cfg)
+			Expect(opts).To(HaveLen(len(baseOpts) + 2))
+		})
+
+		It("should include the waivers option when Waivers is set", func() {
+			cfg := &preruntime.Config{
+				Waivers: "waivers.yaml",
+			}
+			baseOpts := generateContainerCheckOptions(&preruntime.Config{})
+			opts := generateContainerCheckOptions(cfg)
+			Expect(opts).To(HaveLen(len(baseOpts) + 1))
+		})
+
+		It("should include the advisories option when Advisories is set", func() {
+			cfg := &preruntime.Config{
+				Advisories: "/data/csaf",
+			}
+			baseOpts := generateContainerCheckOptions(&preruntime.Config{})
+			opts := generateContainerCheckOptions(cfg)
+			Expect(opts).To(HaveLen(len(baseOpts) + 1))
+		})
+
+		It("should include the secrets scan option when ScanSecrets is set", func() {
+			cfg := &preruntime.Config{
+				ScanSecrets: true,
+			}
+			baseOpts := generateContainerCheckOptions(&preruntime.Config{})
+			opts := generateContainerCheckOptions(cfg)
+			Expect(opts).To(HaveLen(len(baseOpts) + 1))
+		})
+
+		It("should include the secrets allowlist option when SecretsAllowlist is set", func() {
+			cfg := &preruntime.Config{
+				SecretsAllowlist: "secrets-allowlist.yaml",
+			}
+			baseOpts := generateContainerCheckOptions(&preruntime.Config{})
+			opts := generateContainerCheckOptions(cfg)
+			Expect(opts).To(HaveLen(len(baseOpts) + 1))
+		})
+
+		It("should include the arbitrary UID option when CheckArbitraryUID is set", func() {
+			cfg := &preruntime.Config{
+				CheckArbitraryUID: true,
+			}
+			baseOpts := generateContainerCheckOptions(&preruntime.Config{})
+			opts := generateContainerCheckOptions(cfg)
+			Expect(opts).To(HaveLen(len(baseOpts) + 1))
+		})
+
+		It("should include the writable paths option when WritablePaths is set", func() {
+			cfg := &preruntime.Config{
+				WritablePaths: []string{"/var/cache/app"},
+			}
+			baseOpts := generateContainerCheckOptions(&preruntime.Config{})
+			opts := generateContainerCheckOptions(cfg)
+			Expect(opts).To(HaveLen(len(baseOpts) + 1))
+		})
+
+		It("should not write an SBOM unless a format is selected", func() {
+			Expect(checkContainerCmd(mockRunPreflightReturnNil).Flags().Lookup("sbom-format").DefValue).To(Equal("[]"))
+		})
+
+		It("should include the SBOM formats option when SBOMFormats is set", func() {
+			cfg := &preruntime.Config{
+				SBOMFormats: []string{"cyclonedx"},
+			}
+			baseOpts := generateContainerCheckOptions(&preruntime.Config{})
+			opts := generateContainerCheckOptions(cfg)
+			Expect(opts).To(HaveLen(len(baseOpts) + 1))
+		})
+
+		It("should include the file modes option when CheckFileModes is set", func() {
+			cfg := &preruntime.Config{
+				CheckFileModes: true,
+			}
+			baseOpts := generateContainerCheckOptions(&preruntime.Config{})
+			opts := generateContainerCheckOptions(cfg)
+			Expect(opts).To(HaveLen(len(baseOpts) + 1))
+		})
+
+		It("should include the signature keys option when SignatureKeys is set", func() {
+			cfg := &preruntime.Config{
+				SignatureKeys: "/etc/pki/cosign.pub",
+			}
+			baseOpts := generateContainerCheckOptions(&preruntime.Config{})
+			opts := generateContainerCheckOptions(cfg)
+			Expect(opts).To(HaveLen(len(baseOpts) + 1))
+		})
+
+		It("should include the provenance options when ProvenanceKeys is set", func() {
+			cfg := &preruntime.Config{
+				ProvenanceKeys:  "/etc/pki/konflux.pub",
+				RequireHermetic: true,
+			}
+			baseOpts := generateContainerCheckOptions(&preruntime.Config{})
+			opts := generateContainerCheckOptions(cfg)
+			Expect(opts).To(HaveLen(len(baseOpts) + 2))
+		})
+
+		It("should include the license rules option when licenses are restricted", func() {
+			cfg := &preruntime.Config{
+				AllowedLicenses: []string{"MIT"},
+			}
+			baseOpts := generateContainerCheckOptions(&preruntime.Config{})
+			opts := generateContainerCheckOptions(cfg)
+			Expect(opts).To(HaveLen(len(baseOpts) + 1))
+		})
+
 		It("should set Submit to false when Insecure is true", func() {
 			cfg := &preruntime.Config{
 				Insecure: true,
@@ -104,6 +104,10 @@ func checkOperatorRunE(cmd *cobra.Command, args []string, runpreflight runPrefli
 		return fmt.Errorf("invalid configuration: %w", err)
 	}
 
+	if err := validateSubmission(cfg); err != nil {
+		return err
+	}
+
 	ctx, _, err = configureArtifactsWriter(ctx, cfg.Artifacts)
 	if err != nil {
 		//coverage:ignore
@@ -118,6 +122,14 @@ func checkOperatorRunE(cmd *cobra.Command, args []string, runpreflight runPrefli
 
 	opts := generateOperatorCheckOptions(cfg)
 
+	if cfg.RerunFailed != "" {
+		previous, err := readPreviousResults(cfg.RerunFailed)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, operator.WithPreviousResults(previous))
+	}
+
 	kubeconfig, err := func() ([]byte, error) {
 		kubeconfigFile, err := os.Open(cfg.Kubeconfig)
 		if err != nil {
@@ -192,6 +204,46 @@ func generateOperatorCheckOptions(cfg *runtime.Config) []operator.Option {
 		opts = append(opts, operator.WithSubscriptionTimeout(cfg.SubscriptionTimeout))
 	}
 
+	if cfg.CheckParallelism > 0 {
+		opts = append(opts, operator.WithCheckParallelism(cfg.CheckParallelism))
+	}
+
+	if cfg.CacheDir != "" {
+		opts = append(opts, operator.WithCacheDir(cfg.CacheDir), operator.WithCacheMaxSize(cfg.CacheMaxSize))
+	}
+
+	if cfg.CheckTimeout > 0 || len(cfg.CheckTimeouts) > 0 {
+		opts = append(opts, operator.WithCheckTimeout(cfg.CheckTimeout, cfg.CheckTimeouts))
+	}
+
+	if len(cfg.Checks) > 0 {
+		opts = append(opts, operator.WithIncludedChecks(cfg.Checks...))
+	}
+
+	if len(cfg.SkipChecks) > 0 {
+		opts = append(opts, operator.WithExcludedChecks(cfg.SkipChecks...))
+	}
+
+	if cfg.CustomChecks != "" {
+		opts = append(opts, operator.WithCustomChecks(cfg.CustomChecks))
+	}
+
+	if cfg.PluginPath != "" {
+		opts = append(opts, operator.WithPlugins(cfg.PluginPath))
+	}
+
+	if len(cfg.Policies) > 0 {
+		opts = append(opts, operator.WithPolicyDefinitions(cfg.Policies...))
+	}
+
+	if cfg.Policy != "" {
+		opts = append(opts, operator.WithPolicy(cfg.Policy))
+	}
+
+	if cfg.Waivers != "" {
+		opts = append(opts, operator.WithWaivers(cfg.Waivers))
+	}
+
 	return opts
 }
 
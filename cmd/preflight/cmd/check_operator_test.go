@@ -8,6 +8,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/policy"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/runtime"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/viper"
 )
@@ -88,6 +89,35 @@ var _ = Describe("Check Operator", func() {
 				Expect(err).To(HaveOccurred())
 				Expect(out).To(ContainSubstring("random error"))
 			})
+			It("should refuse to run with flags results can not be submitted with when submitting", func() {
+				DeferCleanup(viper.Instance().Set, "submit", false)
+				DeferCleanup(viper.Instance().Set, "checks", []string{})
+				viper.Instance().Set("submit", true)
+				viper.Instance().Set("checks", []string{"ScorecardBasicSpecCheck"})
+				out, err := executeCommandWithLogger(checkOperatorCmd(mockRunPreflightReturnNil), logr.Discard(), "quay.io/example/image:mytag")
+				Expect(err).To(HaveOccurred())
+				Expect(out).To(ContainSubstring("cannot be submitted when checks are selected"))
+			})
+
+			When("previous results are provided", func() {
+				var resultsPath string
+				BeforeEach(func() {
+					resultsPath = filepath.Join(GinkgoT().TempDir(), "results.json")
+					DeferCleanup(viper.Instance().Set, "rerun_failed", "")
+				})
+				It("should re-run the failed checks", func() {
+					Expect(os.WriteFile(resultsPath, []byte(`{"image_digest": "sha256:abc", "results": {"failed": [{"name": "ScorecardBasicSpecCheck"}]}}`), 0o644)).To(Succeed())
+					viper.Instance().Set("rerun_failed", resultsPath)
+					_, err := executeCommandWithLogger(checkOperatorCmd(mockRunPreflightReturnNil), logr.Discard(), "quay.io/example/image:mytag")
+					Expect(err).ToNot(HaveOccurred())
+				})
+				It("should fail if the previous results can not be read", func() {
+					viper.Instance().Set("rerun_failed", resultsPath)
+					out, err := executeCommandWithLogger(checkOperatorCmd(mockRunPreflightReturnNil), logr.Discard(), "quay.io/example/image:mytag")
+					Expect(err).To(HaveOccurred())
+					Expect(out).To(ContainSubstring("could not open previous results"))
+				})
+			})
 		})
 
 		Context("With an invalid KUBECONFIG file location", func() {
@@ -210,6 +240,43 @@ var _ = Describe("Check Operator", func() {
 			Expect(opts).To(HaveLen(len(baseOpts) + 1))
 		})
 
+		It("should include the custom checks option when CustomChecks is set", func() {
+			cfg := &runtime.Config{
+				CustomChecks: "rules.yaml",
+			}
+			baseOpts := generateOperatorCheckOptions(&runtime.Config{})
+			opts := generateOperatorCheckOptions(cfg)
+			Expect(opts).To(HaveLen(len(baseOpts) + 1))
+		})
+
+		It("should include the plugins option when PluginPath is set", func() {
+			cfg := &runtime.Config{
+				PluginPath: "/opt/preflight/plugins",
+			}
+			baseOpts := generateOperatorCheckOptions(&runtime.Config{})
+			opts := generateOperatorCheckOptions(cfg)
+			Expect(opts).To(HaveLen(len(baseOpts) + 1))
+		})
+
+		It("should include the policy options when a policy is selected and policies are defined", func() {
+			cfg := &runtime.Config{
+				Policy:   "offline",
+				Policies: []policy.Definition{{Name: "offline", Inherits: policy.PolicyOperator}},
+			}
+			baseOpts := generateOperatorCheckOptions(&runtime.Config{})
+			opts := generateOperatorCheckOptions(cfg)
+			Expect(opts).To(HaveLen(len(baseOpts) + 2))
+		})
+
+		It("should include the waivers option when Waivers is set", func() {
+			cfg := &runtime.Config{
+				Waivers: "waivers.yaml",
+			}
+			baseOpts := generateOperatorCheckOptions(&runtime.Config{})
+			opts := generateOperatorCheckOptions(cfg)
+			Expect(opts).To(HaveLen(len(baseOpts) + 1))
+		})
+
 		It("should include both channel and insecure options when both are set", func() {
 			cfg := &runtime.Config{
 				Channel:  "stable",
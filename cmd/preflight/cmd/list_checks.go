@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"context"
 	"fmt"
 	"io"
 	"strings"
@@ -9,35 +8,86 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/engine"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/policy"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/runtime"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/viper"
 )
 
 func listChecksCmd() *cobra.Command {
 	listChecksCmd := &cobra.Command{
 		Use:   "list-checks",
 		Short: "List all checks that will be executed for each policy",
-		Long:  "This command will list all checks that preflight uses against an asset by policy type",
-		Run:   listChecksRunFunc,
+		Long:  "This command will list all checks that preflight uses against an asset by policy type, including policies defined in the config file",
+		RunE:  listChecksRunE,
 	}
 	return listChecksCmd
 }
 
-// listChecksRunFunc binds printChecks to cobra's Run function
+// listChecksRunE binds printChecks to cobra's RunE function
 // definition, passing the cobra command's output as an io.Writer.
-func listChecksRunFunc(cmd *cobra.Command, args []string) {
-	printChecks(cmd.OutOrStdout())
+func listChecksRunE(cmd *cobra.Command, args []string) error {
+	cfg, err := runtime.NewConfigFrom(*viper.Instance())
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	registry, err := policy.DefaultRegistry().With(cfg.Policies...)
+	if err != nil {
+		return fmt.Errorf("invalid policies: %w", err)
+	}
+
+	return printChecks(cmd.OutOrStdout(), registry)
+}
+
+// policyTitles are the titles Red Hat's policies are listed with. Other
+// policies are listed by name.
+var policyTitles = map[policy.Policy]string{
+	policy.PolicyOperator:       "Operator",
+	policy.PolicyContainer:      "Container",
+	policy.PolicyRoot:           "Container Root Exception",
+	policy.PolicyScratchNonRoot: "Container Scratch (NonRoot) Exception",
+	policy.PolicyScratchRoot:    "Container Scratch (Root) Exception",
+	policy.PolicyKonflux:        "Container Konflux",
 }
 
-// printChecks writes the formatted check list output to w.
-func printChecks(w io.Writer) {
+// printChecks writes the formatted check list of every policy in registry
+// to w.
+func printChecks(w io.Writer, registry *policy.Registry) error {
 	fmt.Fprintln(w, "These are the available checks for each policy:")
-	fmt.Fprintln(w, formattedPolicyBlock("Operator", engine.OperatorPolicy(context.TODO()), "invoked on operator bundles"))
-	fmt.Fprintln(w, formattedPolicyBlock("Container", engine.ContainerPolicy(context.TODO()), "invoked on container images"))
-	fmt.Fprintln(w, formattedPolicyBlock("Container Root Exception", engine.RootExceptionContainerPolicy(context.TODO()),
-		"automatically applied for container images if preflight determines a root exception flag has been added to your Red Hat Connect project"))
-	fmt.Fprintln(w, formattedPolicyBlock("Container Scratch (NonRoot) Exception", engine.ScratchNonRootContainerPolicy(context.TODO()),
-		"automatically applied for container checks if preflight determines a scratch exception flag has been added to your Red Hat Connect project"))
-	fmt.Fprintln(w, formattedPolicyBlock("Container Scratch (Root) Exception", engine.ScratchRootContainerPolicy(context.TODO()),
-		"automatically applied for container checks if preflight determines scratch and root exception flags have both been added to your Red Hat Connect project"))
+	for _, d := range registry.Definitions() {
+		checks, err := registry.Checks(d.Name)
+		if err != nil {
+			//coverage:ignore
+			return err
+		}
+
+		title, ok := policyTitles[d.Name]
+		if !ok {
+			title = d.Name
+		}
+		fmt.Fprintln(w, formattedPolicyBlock(title, checkEntries(checks), d.Description))
+	}
+
+	return nil
+}
+
+// checkEntries returns the name of each check in names, followed by its ID
+// and categories if it is a built-in check.
+func checkEntries(names []string) []string {
+	entries := make([]string, 0, len(names))
+	for _, name := range names {
+		meta, ok := engine.CheckMetadata(name)
+		if !ok || meta.ID == "" {
+			entries = append(entries, name)
+			continue
+		}
+		categories := make([]string, 0, len(meta.Categories))
+		for _, c := range meta.Categories {
+			categories = append(categories, string(c))
+		}
+		entries = append(entries, fmt.Sprintf("%s [%s] (%s)", name, meta.ID, strings.Join(categories, ", ")))
+	}
+	return entries
 }
 
 // formattedPolicyBlock accepts information about the checklist
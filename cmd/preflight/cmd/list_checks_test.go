@@ -8,6 +8,8 @@ import (
 	. "github.com/onsi/gomega"
 
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/engine"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/policy"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/viper"
 )
 
 var _ = Describe("list checks subcommand", func() {
@@ -41,33 +43,48 @@ var _ = Describe("list checks subcommand", func() {
 
 	Context("Printing checks", func() {
 		It("should always contain the container policy", func() {
-			expected := formatList(engine.ContainerPolicy(context.TODO()))
+			expected := formatList(checkEntries(engine.ContainerPolicy(context.TODO())))
 			buf := strings.Builder{}
-			printChecks(&buf)
+			Expect(printChecks(&buf, policy.DefaultRegistry())).To(Succeed())
 
 			Expect(buf.String()).To(ContainSubstring(expected))
 		})
 
 		It("should always contain the operator policy", func() {
-			expected := formatList(engine.OperatorPolicy(context.TODO()))
+			expected := formatList(checkEntries(engine.OperatorPolicy(context.TODO())))
 			buf := strings.Builder{}
-			printChecks(&buf)
+			Expect(printChecks(&buf, policy.DefaultRegistry())).To(Succeed())
 
 			Expect(buf.String()).To(ContainSubstring(expected))
 		})
 
 		It("should always contain the root exception policy", func() {
-			expected := formatList(engine.RootExceptionContainerPolicy(context.TODO()))
+			expected := formatList(checkEntries(engine.RootExceptionContainerPolicy(context.TODO())))
 			buf := strings.Builder{}
-			printChecks(&buf)
+			Expect(printChecks(&buf, policy.DefaultRegistry())).To(Succeed())
 
 			Expect(buf.String()).To(ContainSubstring(expected))
 		})
 
+		It("should list the ID and categories of each check", func() {
+			buf := strings.Builder{}
+			Expect(printChecks(&buf, policy.DefaultRegistry())).To(Succeed())
+
+			Expect(buf.String()).To(ContainSubstring("- RunAsNonRoot [PFLT-CNT-007] (security)\n"))
+			Expect(buf.String()).To(ContainSubstring("- SecurityContextConstraintsInCSV [PFLT-OPR-006] (security, cluster)\n"))
+		})
+
+		It("should contain the konflux policy", func() {
+			buf := strings.Builder{}
+			Expect(printChecks(&buf, policy.DefaultRegistry())).To(Succeed())
+
+			Expect(buf.String()).To(ContainSubstring("[Container Konflux Policy]"))
+		})
+
 		It("should always contain the scratch exception policy", func() {
-			expected := formatList(engine.ScratchNonRootContainerPolicy(context.TODO()))
+			expected := formatList(checkEntries(engine.ScratchNonRootContainerPolicy(context.TODO())))
 			buf := strings.Builder{}
-			printChecks(&buf)
+			Expect(printChecks(&buf, policy.DefaultRegistry())).To(Succeed())
 
 			Expect(buf.String()).To(ContainSubstring(expected))
 		})
@@ -75,10 +92,36 @@ var _ = Describe("list checks subcommand", func() {
 
 	Context("When executing the cobra command", func() {
 		BeforeEach(createAndCleanupDirForArtifactsAndLogs)
+		BeforeEach(func() {
+			viper.Reset()
+			DeferCleanup(viper.Reset)
+		})
+		It("should contain the policies defined in the config", func() {
+			viper.Instance().Set("policies", []map[string]any{
+				{"name": "strict", "description": "our policy", "inherits": "container", "checks": []string{"HasTeamLabel"}},
+			})
+
+			out, err := executeCommand(listChecksCmd())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(out).To(ContainSubstring("[strict Policy]: our policy\n"))
+			Expect(out).To(ContainSubstring("- HasTeamLabel\n"))
+		})
+		It("should fail if the policies in the config are invalid", func() {
+			viper.Instance().Set("policies", []map[string]any{{"name": "strict"}})
+
+			_, err := executeCommand(listChecksCmd())
+			Expect(err).To(MatchError(ContainSubstring("kind is required")))
+		})
+		It("should fail if the config can not be read", func() {
+			viper.Instance().Set("policies", "strict")
+
+			_, err := executeCommand(listChecksCmd())
+			Expect(err).To(MatchError(ContainSubstring("invalid configuration")))
+		})
 		It("should contain output equivalent to printChecks", func() {
 			// get the expected result
 			buf := strings.Builder{}
-			printChecks(&buf)
+			Expect(printChecks(&buf, policy.DefaultRegistry())).To(Succeed())
 			expected := buf.String()
 
 			// Run the command. Because we bind this command to the
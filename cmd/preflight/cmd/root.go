@@ -3,6 +3,7 @@ package cmd
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
@@ -60,6 +61,14 @@ func rootCmd() *cobra.Command {
 	rootCmd.PersistentFlags().String("loglevel", "", "The verbosity of the preflight tool itself. Ex. warn, debug, trace, info, error. (env: PFLT_LOGLEVEL)")
 	_ = viper.BindPFlag("loglevel", rootCmd.PersistentFlags().Lookup("loglevel"))
 
+	rootCmd.PersistentFlags().String("cache-dir", "", "A directory where image layers are cached and reused across runs.\n"+
+		"If empty, layers are only cached for the duration of a run. (env: PFLT_CACHE_DIR)")
+	_ = viper.BindPFlag("cache_dir", rootCmd.PersistentFlags().Lookup("cache-dir"))
+	rootCmd.PersistentFlags().String("cache-max-size", "", fmt.Sprintf("The size the cache directory is pruned to after a run, e.g. 512MB or 20GB.\n"+
+		"If empty, %dGB is used. (env: PFLT_CACHE_MAX_SIZE)", runtime.DefaultCacheMaxSize>>30))
+	_ = viper.BindPFlag("cache_max_size", rootCmd.PersistentFlags().Lookup("cache-max-size"))
+
+	rootCmd.AddCommand(cacheCmd())
 	rootCmd.AddCommand(checkCmd())
 	rootCmd.AddCommand(listChecksCmd())
 	rootCmd.AddCommand(runtimeAssetsCmd())
@@ -112,6 +121,9 @@ func initConfig(viper *spfviper.Viper) {
 
 	// Set up subscription timeout default
 	viper.SetDefault("subscription_timeout", runtime.DefaultSubscriptionTimeout)
+
+	// Set up layer cache size default
+	viper.SetDefault("cache_max_size", runtime.DefaultCacheMaxSize)
 }
 
 // preRunConfig is used by cobra.PreRun in all non-root commands to load all necessary configurations
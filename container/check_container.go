@@ -12,13 +12,23 @@ import (
 
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/certification"
 	preflighterr "github.com/redhat-openshift-ecosystem/openshift-preflight/errors"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/advisory"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/engine"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/lib"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/license"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/policy"
+	containerpol "github.com/redhat-openshift-ecosystem/openshift-preflight/internal/policy/container"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/policy/custom"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/policy/plugin"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/provenance"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/pyxis"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/runtime"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/sbom"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/secrets"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/signature"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/waiver"
 )
 
 type Option = func(*containerCheck)
@@ -26,9 +36,11 @@ type Option = func(*containerCheck)
 // NewCheck is a check that runs preflight's Container Policy.
 func NewCheck(image string, opts ...Option) *containerCheck {
 	c := &containerCheck{
-		image:     image,
-		pyxisHost: check.DefaultPyxisHost,
-		platform:  goruntime.GOARCH,
+		image:            image,
+		pyxisHost:        check.DefaultPyxisHost,
+		platform:         goruntime.GOARCH,
+		checkParallelism: runtime.DefaultCheckParallelism,
+		cacheMaxSize:     runtime.DefaultCacheMaxSize,
 	}
 
 	for _, opt := range opts {
@@ -44,23 +56,47 @@ func NewCheck(image string, opts ...Option) *containerCheck {
 func (c *containerCheck) Run(ctx context.Context) (certification.Results, error) {
 	err := c.resolve(ctx)
 	if err != nil {
-		return certification.Results{}, err
+		return c.runFailed(ctx, err)
 	}
 
 	cfg := runtime.Config{
-		Image:              c.image,
-		DockerConfig:       c.dockerconfigjson,
-		Scratch:            c.policy == policy.PolicyScratchNonRoot || c.policy == policy.PolicyScratchRoot,
-		Bundle:             false,
-		Insecure:           c.insecure,
-		Platform:           c.platform,
-		ManifestListDigest: c.manifestListDigest,
-		TempDir:            c.tempDir,
-	}
-	eng, err := engine.New(ctx, c.checks, nil, cfg)
+		Image:               c.image,
+		DockerConfig:        c.dockerconfigjson,
+		Scratch:             c.policy == policy.PolicyScratchNonRoot || c.policy == policy.PolicyScratchRoot,
+		Bundle:              false,
+		Insecure:            c.insecure,
+		Platform:            c.platform,
+		ManifestListDigest:  c.manifestListDigest,
+		TempDir:             c.tempDir,
+		CheckParallelism:    c.checkParallelism,
+		LocalImageReference: c.localImageReference,
+		CacheDir:            c.cacheDir,
+		CacheMaxSize:        c.cacheMaxSize,
+		CheckTimeout:        c.checkTimeout,
+		CheckTimeouts:       c.checkTimeouts,
+	}
+	engineOpts := []engine.Option{engine.WithObserver(c.observer)}
+	if c.previousResults != nil {
+		engineOpts = append(engineOpts, engine.WithPreviousResults(*c.previousResults))
+	}
+	if c.waiversFile != "" {
+		waivers, err := waiver.Load(c.waiversFile)
+		if err != nil {
+			return c.runFailed(ctx, err)
+		}
+		engineOpts = append(engineOpts, engine.WithWaivers(waivers))
+	}
+	if len(c.sbomFormats) > 0 {
+		formats, err := sbom.ParseFormats(c.sbomFormats)
+		if err != nil {
+			return c.runFailed(ctx, err)
+		}
+		engineOpts = append(engineOpts, engine.WithSBOMFormats(formats...))
+	}
+	eng, err := engine.New(ctx, c.checks, nil, cfg, engineOpts...)
 	if err != nil {
 		//coverage:ignore
-		return certification.Results{}, err
+		return c.runFailed(ctx, err)
 	}
 
 	if err := eng.ExecuteChecks(ctx); err != nil {
@@ -71,6 +107,15 @@ func (c *containerCheck) Run(ctx context.Context) (certification.Results, error)
 	return eng.Results(ctx), nil
 }
 
+// runFailed notifies the observer, if any, that the run ended with err before
+// the engine, which notifies it otherwise, executed the checks.
+func (c *containerCheck) runFailed(ctx context.Context, err error) (certification.Results, error) {
+	if c.observer != nil {
+		c.observer.Observe(ctx, certification.RunFinished{Err: err})
+	}
+	return certification.Results{}, err
+}
+
 func (c *containerCheck) resolve(ctx context.Context) error {
 	logger := logr.FromContextOrDiscard(ctx)
 	if c.resolved {
@@ -120,16 +165,85 @@ func (c *containerCheck) resolve(ctx context.Context) error {
 		c.policy = policy.PolicyKonflux
 	}
 
+	if c.selectedPolicy != "" {
+		c.policy = c.selectedPolicy
+	}
+
 	newChecks, err := engine.InitializeContainerChecks(ctx, c.policy, engine.ContainerCheckConfig{
 		DockerConfig:           c.dockerconfigjson,
 		PyxisAPIToken:          c.pyxisToken,
 		CertificationProjectID: c.certificationProjectID,
 		PyxisHost:              c.pyxisHost,
+		Policies:               c.policyDefinitions,
+		LicenseRules:           c.licenseRules,
 	})
 	if err != nil {
-		//coverage:ignore
 		return fmt.Errorf("%w: %s", preflighterr.ErrCannotInitializeChecks, err)
 	}
+	if c.customChecksFile != "" {
+		newChecks, err = custom.Append(newChecks, c.customChecksFile)
+		if err != nil {
+			return fmt.Errorf("%w: %w", preflighterr.ErrCannotInitializeChecks, err)
+		}
+	}
+	if c.pluginPath != "" {
+		newChecks, err = plugin.Append(ctx, newChecks, c.pluginPath)
+		if err != nil {
+			return fmt.Errorf("%w: %w", preflighterr.ErrCannotInitializeChecks, err)
+		}
+	}
+	if c.advisoriesPath != "" {
+		dataset, err := advisory.Load(c.advisoriesPath)
+		if err != nil {
+			return fmt.Errorf("%w: %w", preflighterr.ErrCannotInitializeChecks, err)
+		}
+		newChecks = append(newChecks, check.Custom(containerpol.NewHasNoFixableVulnerabilitiesCheck(dataset)))
+	}
+	if c.secretsScan || c.secretsAllowlistPath != "" {
+		var allowlist secrets.Allowlist
+		if c.secretsAllowlistPath != "" {
+			allowlist, err = secrets.LoadAllowlist(c.secretsAllowlistPath)
+			if err != nil {
+				return fmt.Errorf("%w: %w", preflighterr.ErrCannotInitializeChecks, err)
+			}
+		}
+		newChecks = append(newChecks, check.Custom(containerpol.NewHasNoEmbeddedSecretsCheck(allowlist)))
+	}
+	if c.arbitraryUIDCheck || len(c.writablePaths) > 0 {
+		newChecks = append(newChecks, check.Custom(containerpol.NewSupportsArbitraryUIDCheck(c.writablePaths)))
+	}
+	if c.fileModesCheck {
+		newChecks = append(newChecks, check.Custom(&containerpol.HasNoUnsafeFileModesCheck{}))
+	}
+	if c.signatureKeysPath != "" {
+		keys, err := signature.LoadKeyring(c.signatureKeysPath)
+		if err != nil {
+			return fmt.Errorf("%w: %w", preflighterr.ErrCannotInitializeChecks, err)
+		}
+		craneConfig := &runtime.Config{DockerConfig: c.dockerconfigjson, Platform: c.platform, Insecure: c.insecure}
+		newChecks = append(newChecks, check.Custom(containerpol.NewHasVerifiedSignatureCheck(keys, craneConfig)))
+	}
+	if c.provenanceKeysPath != "" {
+		keys, err := signature.LoadKeyring(c.provenanceKeysPath)
+		if err != nil {
+			return fmt.Errorf("%w: %w", preflighterr.ErrCannotInitializeChecks, err)
+		}
+		if err := c.provenanceRules.Validate(); err != nil {
+			return fmt.Errorf("%w: %w", preflighterr.ErrCannotInitializeChecks, err)
+		}
+		craneConfig := &runtime.Config{DockerConfig: c.dockerconfigjson, Platform: c.platform, Insecure: c.insecure}
+		newChecks = append(newChecks, check.Custom(containerpol.NewHasTrustedProvenanceCheck(keys, c.provenanceRules, craneConfig)))
+	}
+	for _, additional := range c.additionalChecks {
+		newChecks = append(newChecks, check.Custom(additional))
+	}
+	if c.checkFilter != nil {
+		newChecks = check.ApplyFilter(newChecks, c.checkFilter)
+	}
+	newChecks, err = check.Filter(newChecks, c.includedChecks, c.excludedChecks)
+	if err != nil {
+		return fmt.Errorf("%w: %w", preflighterr.ErrCannotInitializeChecks, err)
+	}
 	c.checks = newChecks
 	c.resolved = true
 
@@ -238,6 +352,265 @@ func WithTempDir(tempDir string) Option {
 	}
 }
 
+// WithCheckParallelism sets the maximum number of checks that are executed
+// at the same time. Results are reported in policy order regardless.
+func WithCheckParallelism(n int) Option {
+	return func(cc *containerCheck) {
+		cc.checkParallelism = n
+	}
+}
+
+// WithLocalImageReference sets the registry, repository and tag recorded for
+// the image under test, e.g. quay.io/namespace/repo:tag. This is most useful
+// when the image is loaded from an OCI layout ("oci:<dir>[:tag]") or docker
+// archive ("docker-archive:<file.tar>"), where these values can not always be
+// derived from the image itself.
+func WithLocalImageReference(ref string) Option {
+	return func(cc *containerCheck) {
+		cc.localImageReference = ref
+	}
+}
+
+// WithCacheDir sets a directory where image layers are cached, so they do not
+// need to be pulled again by later checks of images sharing those layers.
+func WithCacheDir(dir string) Option {
+	return func(cc *containerCheck) {
+		cc.cacheDir = dir
+	}
+}
+
+// WithCacheMaxSize sets the size in bytes the cache directory is pruned to,
+// least recently used layers first, after the check has run.
+func WithCacheMaxSize(size int64) Option {
+	return func(cc *containerCheck) {
+		cc.cacheMaxSize = size
+	}
+}
+
+// WithCheckTimeout bounds how long each check may run. Checks named in
+// perCheck use that timeout instead of timeout. A zero timeout leaves checks
+// unbounded. Checks that exceed their timeout are reported as timed out.
+func WithCheckTimeout(timeout time.Duration, perCheck map[string]time.Duration) Option {
+	return func(cc *containerCheck) {
+		cc.checkTimeout = timeout
+		cc.checkTimeouts = perCheck
+	}
+}
+
+// WithIncludedChecks limits the run to the named checks. The remaining checks
+// in the policy are reported as skipped. Names must match a check's Name().
+func WithIncludedChecks(names ...string) Option {
+	return func(cc *containerCheck) {
+		cc.includedChecks = names
+	}
+}
+
+// WithExcludedChecks excludes the named checks from the run. They are reported
+// as skipped. Names must match a check's Name().
+func WithExcludedChecks(names ...string) Option {
+	return func(cc *containerCheck) {
+		cc.excludedChecks = names
+	}
+}
+
+// WithObserver registers o to receive events as the check runs: when the
+// image has been pulled and extracted, as each check starts and finishes,
+// and when the run has finished, including runs that fail before any check
+// is executed. Calls to o are never concurrent.
+func WithObserver(o certification.Observer) Option {
+	return func(cc *containerCheck) {
+		cc.observer = o
+	}
+}
+
+// WithPreviousResults executes only the checks that failed, errored or timed
+// out in prev, or that were not part of it. The outcomes of the remaining
+// checks are carried over into the results. The run fails with
+// errors.ErrImageDigestChanged if the image digest differs from the one prev
+// was generated for.
+func WithPreviousResults(prev certification.PreviousResults) Option {
+	return func(cc *containerCheck) {
+		cc.previousResults = &prev
+	}
+}
+
+// WithCustomChecks appends the checks declared in the rules file at path to
+// the checks in the policy. Custom checks are executed and reported like any
+// other check, and may be selected with WithIncludedChecks and
+// WithExcludedChecks.
+func WithCustomChecks(path string) Option {
+	return func(cc *containerCheck) {
+		cc.customChecksFile = path
+	}
+}
+
+// WithPlugins appends a check for every plugin executable found in the
+// directories of pluginPath, which is separated by os.PathListSeparator.
+// Plugin checks are executed and reported like any other check, and may be
+// selected with WithIncludedChecks and WithExcludedChecks.
+func WithPlugins(pluginPath string) Option {
+	return func(cc *containerCheck) {
+		cc.pluginPath = pluginPath
+	}
+}
+
+// WithAdditionalChecks appends checks to the checks in the policy. They are
+// executed and reported like any other check, and may be selected with
+// WithIncludedChecks and WithExcludedChecks. Their results are marked as
+// custom, and are never submitted to Red Hat.
+func WithAdditionalChecks(checks ...check.Check) Option {
+	return func(cc *containerCheck) {
+		cc.additionalChecks = append(cc.additionalChecks, checks...)
+	}
+}
+
+// WithCheckFilter passes the resolved checks to filter, and executes the
+// checks it returns instead. filter may remove, reorder or wrap checks, or add
+// new ones. Checks that filter adds or wraps are marked as custom, and their
+// results are never submitted to Red Hat. Checks that filter removes are
+// reported as skipped, and the results of the run are never submitted to Red
+// Hat either. Checks named by WithIncludedChecks
+// and WithExcludedChecks are selected from the checks filter returns.
+func WithCheckFilter(filter func([]check.Check) []check.Check) Option {
+	return func(cc *containerCheck) {
+		cc.checkFilter = filter
+	}
+}
+
+// WithPolicy executes the checks of policy p, instead of the policy resolved
+// from the certification project's exceptions. p may be one of Red Hat's
+// policies, or one added with WithPolicyDefinitions.
+func WithPolicy(p policy.Policy) Option {
+	return func(cc *containerCheck) {
+		cc.selectedPolicy = p
+	}
+}
+
+// WithPolicyDefinitions adds user-defined policies that may be selected with
+// WithPolicy. A definition may inherit one of Red Hat's policies, or another
+// definition.
+func WithPolicyDefinitions(definitions ...policy.Definition) Option {
+	return func(cc *containerCheck) {
+		cc.policyDefinitions = append(cc.policyDefinitions, definitions...)
+	}
+}
+
+// WithWaivers reports the failures of checks named in the waivers file at
+// path as warnings, until their waivers expire. Waived results are annotated
+// in every format, and are never submitted to Red Hat.
+func WithWaivers(path string) Option {
+	return func(cc *containerCheck) {
+		cc.waiversFile = path
+	}
+}
+
+// WithLicenseRules restricts the licenses HasLicense permits. If allowed is
+// not empty, only the SPDX licenses it matches are permitted, and the
+// licenses denied matches are never permitted. Patterns may contain
+// path.Match wildcards, e.g. GPL-*.
+func WithLicenseRules(allowed, denied []string) Option {
+	return func(cc *containerCheck) {
+		cc.licenseRules = license.Rules{Allowed: allowed, Denied: denied}
+	}
+}
+
+// WithAdvisories executes the HasNoFixableVulnerabilities check, which fails if
+// the image contains RPMs affected by Critical or Important vulnerabilities
+// fixed by the Red Hat advisories at path. path may be a CSAF or OVAL file, or
+// a directory of them. No network access is made.
+func WithAdvisories(path string) Option {
+	return func(cc *containerCheck) {
+		cc.advisoriesPath = path
+	}
+}
+
+// WithSecretsScan executes the HasNoEmbeddedSecrets check, which fails if
+// any layer or the config of the image contains credentials.
+func WithSecretsScan() Option {
+	return func(cc *containerCheck) {
+		cc.secretsScan = true
+	}
+}
+
+// WithSecretsAllowlist executes the HasNoEmbeddedSecrets check like
+// WithSecretsScan, and permits the secrets described by the allowlist file at
+// path, so that it reports them without failing.
+func WithSecretsAllowlist(path string) Option {
+	return func(cc *containerCheck) {
+		cc.secretsAllowlistPath = path
+	}
+}
+
+// WithArbitraryUIDCheck executes the SupportsArbitraryUID check, which warns
+// unless WORKDIR, VOLUMEs and HOME are writable by group 0, so that the image
+// runs with the arbitrary UIDs OpenShift assigns.
+func WithArbitraryUIDCheck() Option {
+	return func(cc *containerCheck) {
+		cc.arbitraryUIDCheck = true
+	}
+}
+
+// WithWritablePaths executes the SupportsArbitraryUID check like
+// WithArbitraryUIDCheck, and adds paths to those it verifies are writable by
+// group 0, in addition to WORKDIR, VOLUMEs and HOME.
+func WithWritablePaths(paths ...string) Option {
+	return func(cc *containerCheck) {
+		cc.writablePaths = paths
+	}
+}
+
+// WithSBOMFormats writes an SBOM of the image to the artifacts directory in
+// each of formats, which may be "spdx" for SPDX 2.3 JSON, or "cyclonedx" for
+// CycloneDX 1.5 JSON. "none" writes no SBOM.
+func WithSBOMFormats(formats ...string) Option {
+	return func(cc *containerCheck) {
+		cc.sbomFormats = formats
+	}
+}
+
+// WithFileModesCheck executes the HasNoUnsafeFileModes check, which warns if
+// the image adds setuid or setgid executables, world-writable files or
+// directories without the sticky bit, or device nodes over its base image.
+func WithFileModesCheck() Option {
+	return func(cc *containerCheck) {
+		cc.fileModesCheck = true
+	}
+}
+
+// WithSignatureKeys executes the HasVerifiedSignature check, which fails
+// unless the image has a cosign signature made by one of the PEM encoded
+// public keys or certificates in the file at path. Signatures are read from
+// the registry the image is in, without consulting a transparency log.
+func WithSignatureKeys(path string) Option {
+	return func(cc *containerCheck) {
+		cc.signatureKeysPath = path
+	}
+}
+
+// WithProvenanceKeys executes the HasTrustedProvenance check, which fails
+// unless the image has a SLSA provenance attestation signed by one of the PEM
+// encoded public keys or certificates in the file at path, and the provenance
+// satisfies the rules set by WithProvenanceRules.
+func WithProvenanceKeys(path string) Option {
+	return func(cc *containerCheck) {
+		cc.provenanceKeysPath = path
+	}
+}
+
+// WithProvenanceRules restricts the provenance HasTrustedProvenance permits.
+// If builders or repositories are not empty, the builder ID or source
+// repository must match one of their patterns, in the syntax of path.Match.
+// If hermetic is true, the provenance must record a hermetic build.
+func WithProvenanceRules(builders, repositories []string, hermetic bool) Option {
+	return func(cc *containerCheck) {
+		cc.provenanceRules = provenance.Rules{
+			AllowedBuilders:           builders,
+			AllowedSourceRepositories: repositories,
+			RequireHermetic:           hermetic,
+		}
+	}
+}
+
 type containerCheck struct {
 	image                  string
 	dockerconfigjson       string
@@ -253,4 +626,32 @@ type containerCheck struct {
 	konflux                bool
 	pyxisClient            lib.PyxisClient // for testing purposes
 	tempDir                string
+	checkParallelism       int
+	localImageReference    string
+	cacheDir               string
+	cacheMaxSize           int64
+	checkTimeout           time.Duration
+	checkTimeouts          map[string]time.Duration
+	includedChecks         []string
+	excludedChecks         []string
+	observer               certification.Observer
+	previousResults        *certification.PreviousResults
+	customChecksFile       string
+	pluginPath             string
+	additionalChecks       []check.Check
+	checkFilter            func([]check.Check) []check.Check
+	selectedPolicy         policy.Policy
+	waiversFile            string
+	policyDefinitions      []policy.Definition
+	licenseRules           license.Rules
+	advisoriesPath         string
+	secretsScan            bool
+	secretsAllowlistPath   string
+	arbitraryUIDCheck      bool
+	writablePaths          []string
+	fileModesCheck         bool
+	sbomFormats            []string
+	signatureKeysPath      string
+	provenanceKeysPath     string
+	provenanceRules        provenance.Rules
 }
@@ -2,13 +2,18 @@ package container
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/certification"
 	preflighterr "github.com/redhat-openshift-ecosystem/openshift-preflight/errors"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/lib"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/policy"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/runtime"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/test"
 )
@@ -44,6 +49,20 @@ var _ = Describe("Container Check initialization", func() {
 			Expect(c.insecure).To(Equal(insecure))
 			Expect(c.konflux).To(Equal(konflux))
 		})
+		Context("with the WithObserver option", func() {
+			It("should store the observer", func() {
+				observer := certification.ObserverFunc(func(context.Context, certification.Event) {})
+				c := NewCheck("placeholder", WithObserver(observer))
+				Expect(c.observer).ToNot(BeNil())
+			})
+		})
+		Context("with the WithPreviousResults option", func() {
+			It("should store the previous results", func() {
+				c := NewCheck("placeholder", WithPreviousResults(certification.PreviousResults{ImageDigest: "sha256:abc"}))
+				Expect(c.previousResults).ToNot(BeNil())
+				Expect(c.previousResults.ImageDigest).To(Equal("sha256:abc"))
+			})
+		})
 		Context("with the WithCertificationComponent option", func() {
 			It("should set the project ID and token", func() {
 				c := NewCheck("placeholder",
@@ -144,6 +163,323 @@ var _ = Describe("Container Check Execution", func() {
 		})
 	})
 
+	When("checks are selected by name", func() {
+		It("should report checks that were not included as skipped", func() {
+			chk := NewCheck("placeholder", WithIncludedChecks("HasLicense"))
+			_, checks, err := chk.List(context.TODO())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(checks).To(HaveLen(10))
+			for _, c := range checks {
+				if c.Name() != "HasLicense" {
+					_, err := c.Validate(context.TODO(), image.ImageReference{})
+					Expect(err).To(MatchError(check.ErrCheckSkipped))
+				}
+			}
+		})
+
+		It("should report excluded checks as skipped", func() {
+			chk := NewCheck("placeholder", WithExcludedChecks("HasLicense", "HasUniqueTag"))
+			_, checks, err := chk.List(context.TODO())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(checks).To(HaveLen(10))
+			for _, c := range checks {
+				if c.Name() == "HasLicense" || c.Name() == "HasUniqueTag" {
+					_, err := c.Validate(context.TODO(), image.ImageReference{})
+					Expect(err).To(MatchError(check.ErrCheckSkipped))
+				}
+			}
+		})
+
+		It("should reject unknown check names", func() {
+			chk := NewCheck("placeholder", WithExcludedChecks("NotACheck"))
+			_, _, err := chk.List(context.TODO())
+			Expect(err).To(MatchError(preflighterr.ErrCannotInitializeChecks))
+			Expect(err).To(MatchError(preflighterr.ErrUnknownCheck))
+			Expect(err).To(MatchError(ContainSubstring("HasLicense")))
+		})
+	})
+
+	When("custom checks are provided", func() {
+		var rulesPath string
+		BeforeEach(func() {
+			rulesPath = filepath.Join(GinkgoT().TempDir(), "rules.yaml")
+		})
+		It("should append the custom checks to the policy", func() {
+			Expect(os.WriteFile(rulesPath, []byte("checks:\n- {name: NoDebug, type: forbidden-env, env: [DEBUG]}\n"), 0o644)).To(Succeed())
+			chk := NewCheck("placeholder", WithCustomChecks(rulesPath), WithIncludedChecks("NoDebug"))
+			_, checks, err := chk.List(context.TODO())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(checks).To(HaveLen(11))
+			Expect(checks[10].Name()).To(Equal("NoDebug"))
+		})
+		It("should fail if the custom checks are invalid", func() {
+			Expect(os.WriteFile(rulesPath, []byte("checks:\n- {name: HasLicense, type: forbidden-env, env: [DEBUG]}\n"), 0o644)).To(Succeed())
+			chk := NewCheck("placeholder", WithCustomChecks(rulesPath))
+			_, _, err := chk.List(context.TODO())
+			Expect(err).To(MatchError(preflighterr.ErrCannotInitializeChecks))
+			Expect(err).To(MatchError(ContainSubstring("same name as a policy check")))
+		})
+	})
+
+	When("additional checks and a check filter are provided", func() {
+		var extra check.Check
+		BeforeEach(func() {
+			extra = check.NewGenericCheck("HasTeamOwner", nil, check.Metadata{}, check.HelpText{}, nil)
+		})
+		It("should append the additional checks as custom checks", func() {
+			chk := NewCheck("placeholder", WithAdditionalChecks(extra))
+			_, checks, err := chk.List(context.TODO())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(checks).To(HaveLen(11))
+			Expect(checks[10].Name()).To(Equal("HasTeamOwner"))
+			Expect(check.IsCustom(checks[10])).To(BeTrue())
+			Expect(check.IsCustom(checks[0])).To(BeFalse())
+		})
+		It("should execute the checks the filter returns", func() {
+			chk := NewCheck("placeholder", WithAdditionalChecks(extra), WithCheckFilter(func(checks []check.Check) []check.Check {
+				return checks[len(checks)-2:]
+			}))
+			_, checks, err := chk.List(context.TODO())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(check.IsCustom(checks[0])).To(BeFalse())
+			Expect(check.IsCustom(checks[1])).To(BeTrue())
+			Expect(check.IsRemoved(checks[0])).To(BeFalse())
+			Expect(checks[2:]).ToNot(BeEmpty())
+			for _, c := range checks[2:] {
+				Expect(check.IsRemoved(c)).To(BeTrue())
+			}
+		})
+		It("should select checks by name from those the filter returns", func() {
+			chk := NewCheck("placeholder", WithCheckFilter(func(checks []check.Check) []check.Check {
+				return checks[:1]
+			}), WithIncludedChecks(extra.Name()))
+			_, _, err := chk.List(context.TODO())
+			Expect(err).To(MatchError(preflighterr.ErrUnknownCheck))
+		})
+	})
+
+	When("plugins are provided", func() {
+		var pluginDir string
+		BeforeEach(func() {
+			pluginDir = GinkgoT().TempDir()
+		})
+		It("should append the plugin checks to the policy", func() {
+			Expect(os.WriteFile(filepath.Join(pluginDir, "plugin"), []byte("#!/bin/sh\ncat > /dev/null\necho '{\"name\":\"HasNoSecrets\"}'\n"), 0o755)).To(Succeed())
+			chk := NewCheck("placeholder", WithPlugins(pluginDir))
+			_, checks, err := chk.List(context.TODO())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(checks[len(checks)-1].Name()).To(Equal("HasNoSecrets"))
+		})
+		It("should fail if the plugins can not be discovered", func() {
+			chk := NewCheck("placeholder", WithPlugins(filepath.Join(pluginDir, "missing")))
+			_, _, err := chk.List(context.TODO())
+			Expect(err).To(MatchError(preflighterr.ErrCannotInitializeChecks))
+		})
+	})
+
+	When("a policy is selected", func() {
+		It("should execute the checks of the selected policy", func() {
+			chk := NewCheck("placeholder", WithKonflux(), WithPolicy(policy.PolicyRoot))
+			p, checks, err := chk.List(context.TODO())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(p).To(Equal(policy.PolicyRoot))
+			Expect(checks).To(HaveLen(9))
+		})
+		It("should execute the checks of a user-defined policy", func() {
+			chk := NewCheck("placeholder", WithPolicy("minimal"), WithPolicyDefinitions(policy.Definition{
+				Name:     "minimal",
+				Inherits: policy.PolicyContainer,
+				Exclude:  []string{"HasUniqueTag", "BasedOnUbi"},
+			}))
+			p, checks, err := chk.List(context.TODO())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(p).To(Equal("minimal"))
+			Expect(checks).To(HaveLen(8))
+		})
+		It("should fail if the policy is unknown", func() {
+			chk := NewCheck("placeholder", WithPolicy("missing"))
+			_, _, err := chk.List(context.TODO())
+			Expect(err).To(MatchError(preflighterr.ErrCannotInitializeChecks))
+		})
+		It("should fail if the policy is an operator policy", func() {
+			chk := NewCheck("placeholder", WithPolicy(policy.PolicyOperator))
+			_, _, err := chk.List(context.TODO())
+			Expect(err).To(MatchError(ContainSubstring("container policy operator is unknown")))
+		})
+	})
+
+	When("advisories are provided", func() {
+		It("should append the vulnerability check to the policy", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "rhsa.json")
+			Expect(os.WriteFile(path, []byte(`{"document":{"category":"csaf_security_advisory"}}`), 0o644)).To(Succeed())
+			chk := NewCheck("placeholder", WithAdvisories(path))
+			_, checks, err := chk.List(context.TODO())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(checks[len(checks)-1].Name()).To(Equal("HasNoFixableVulnerabilities"))
+			Expect(check.IsCustom(checks[len(checks)-1])).To(BeTrue())
+		})
+		It("should fail if the advisories can not be loaded", func() {
+			chk := NewCheck("placeholder", WithAdvisories(filepath.Join(GinkgoT().TempDir(), "missing")))
+			_, _, err := chk.List(context.TODO())
+			Expect(err).To(MatchError(preflighterr.ErrCannotInitializeChecks))
+			Expect(err).To(MatchError(ContainSubstring("could not read advisories")))
+		})
+	})
+
+	When("signature keys are provided", func() {
+		It("should append the signature check to the policy", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "cosign.pub")
+			key := "-----BEGIN PUBLIC KEY-----\n" +
+				"MFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAE1Ao6a1xgXIFBIgTBjlTlSQZpbYKX\n" +
+				"HtP3x1F8LiOR/qAzfzXke0JYbfV4xnVJKU3lAoJVgBAmz/GI/x7sZBHZ1Q==\n" +
+				"-----END PUBLIC KEY-----\n"
+			Expect(os.WriteFile(path, []byte(key), 0o644)).To(Succeed())
+			chk := NewCheck("placeholder", WithSignatureKeys(path))
+			_, checks, err := chk.List(context.TODO())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(checks[len(checks)-1].Name()).To(Equal("HasVerifiedSignature"))
+			Expect(check.IsCustom(checks[len(checks)-1])).To(BeTrue())
+		})
+		It("should fail if the keys can not be loaded", func() {
+			chk := NewCheck("placeholder", WithSignatureKeys(filepath.Join(GinkgoT().TempDir(), "missing.pub")))
+			_, _, err := chk.List(context.TODO())
+			Expect(err).To(MatchError(preflighterr.ErrCannotInitializeChecks))
+			Expect(err).To(MatchError(ContainSubstring("could not read public keys")))
+		})
+	})
+
+	When("provenance keys are provided", func() {
+		var path string
+		BeforeEach(func() {
+			path = filepath.Join(GinkgoT().TempDir(), "provenance.pub")
+			key := "-----BEGIN PUBLIC KEY-----\n" +
+				"MFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAE1Ao6a1xgXIFBIgTBjlTlSQZpbYKX\n" +
+				"HtP3x1F8LiOR/qAzfzXke0JYbfV4xnVJKU3lAoJVgBAmz/GI/x7sZBHZ1Q==\n" +
+				"-----END PUBLIC KEY-----\n"
+			Expect(os.WriteFile(path, []byte(key), 0o644)).To(Succeed())
+		})
+		It("should append the provenance check to the policy", func() {
+			chk := NewCheck("placeholder", WithProvenanceKeys(path), WithProvenanceRules([]string{"https://konflux-ci.dev/*"}, nil, true))
+			_, checks, err := chk.List(context.TODO())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(checks[len(checks)-1].Name()).To(Equal("HasTrustedProvenance"))
+			Expect(check.IsCustom(checks[len(checks)-1])).To(BeTrue())
+		})
+		It("should fail if the keys can not be loaded", func() {
+			chk := NewCheck("placeholder", WithProvenanceKeys(filepath.Join(GinkgoT().TempDir(), "missing.pub")))
+			_, _, err := chk.List(context.TODO())
+			Expect(err).To(MatchError(preflighterr.ErrCannotInitializeChecks))
+			Expect(err).To(MatchError(ContainSubstring("could not read public keys")))
+		})
+		It("should fail if a rule is not a valid pattern", func() {
+			chk := NewCheck("placeholder", WithProvenanceKeys(path), WithProvenanceRules(nil, []string{"https://github.com/["}, false))
+			_, _, err := chk.List(context.TODO())
+			Expect(err).To(MatchError(preflighterr.ErrCannotInitializeChecks))
+			Expect(err).To(MatchError(ContainSubstring("invalid provenance pattern")))
+		})
+	})
+
+	When("secrets are scanned for", func() {
+		It("should append the secrets check to the policy", func() {
+			chk := NewCheck("placeholder", WithSecretsScan())
+			_, checks, err := chk.List(context.TODO())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(checks[len(checks)-1].Name()).To(Equal("HasNoEmbeddedSecrets"))
+			Expect(check.IsCustom(checks[len(checks)-1])).To(BeTrue())
+		})
+		It("should not include the secrets check in the policy otherwise", func() {
+			chk := NewCheck("placeholder")
+			_, checks, err := chk.List(context.TODO())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(checks).ToNot(ContainElement(WithTransform(check.Check.Name, Equal("HasNoEmbeddedSecrets"))))
+		})
+	})
+
+	When("a secrets allowlist is provided", func() {
+		It("should append the secrets check to the policy", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "secrets-allowlist.yaml")
+			Expect(os.WriteFile(path, []byte("allow:\n- rule: private-key\n  justification: test keys\n"), 0o644)).To(Succeed())
+			chk := NewCheck("placeholder", WithSecretsAllowlist(path))
+			_, checks, err := chk.List(context.TODO())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(checks[len(checks)-1].Name()).To(Equal("HasNoEmbeddedSecrets"))
+			Expect(check.IsCustom(checks[len(checks)-1])).To(BeTrue())
+		})
+		It("should fail if the allowlist can not be loaded", func() {
+			chk := NewCheck("placeholder", WithSecretsAllowlist(filepath.Join(GinkgoT().TempDir(), "missing")))
+			_, _, err := chk.List(context.TODO())
+			Expect(err).To(MatchError(preflighterr.ErrCannotInitializeChecks))
+			Expect(err).To(MatchError(ContainSubstring("could not read secrets allowlist")))
+		})
+	})
+
+	When("arbitrary UIDs are checked", func() {
+		It("should append the arbitrary UID check to the policy", func() {
+			chk := NewCheck("placeholder", WithArbitraryUIDCheck())
+			_, checks, err := chk.List(context.TODO())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(checks[len(checks)-1].Name()).To(Equal("SupportsArbitraryUID"))
+			Expect(check.IsCustom(checks[len(checks)-1])).To(BeTrue())
+		})
+		It("should not include the arbitrary UID check in the policy otherwise", func() {
+			chk := NewCheck("placeholder")
+			_, checks, err := chk.List(context.TODO())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(checks).ToNot(ContainElement(WithTransform(check.Check.Name, Equal("SupportsArbitraryUID"))))
+		})
+	})
+
+	When("file modes are checked", func() {
+		It("should append the file modes check to the policy", func() {
+			chk := NewCheck("placeholder", WithFileModesCheck())
+			_, checks, err := chk.List(context.TODO())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(checks[len(checks)-1].Name()).To(Equal("HasNoUnsafeFileModes"))
+			Expect(check.IsCustom(checks[len(checks)-1])).To(BeTrue())
+		})
+		It("should not include the file modes check in the policy otherwise", func() {
+			chk := NewCheck("placeholder")
+			_, checks, err := chk.List(context.TODO())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(checks).ToNot(ContainElement(WithTransform(check.Check.Name, Equal("HasNoUnsafeFileModes"))))
+		})
+	})
+
+	When("writable paths are provided", func() {
+		It("should append the arbitrary UID check to the policy", func() {
+			chk := NewCheck("placeholder", WithWritablePaths("/var/cache/app"))
+			_, checks, err := chk.List(context.TODO())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(checks[len(checks)-1].Name()).To(Equal("SupportsArbitraryUID"))
+			Expect(check.IsCustom(checks[len(checks)-1])).To(BeTrue())
+		})
+	})
+
+	When("licenses are restricted", func() {
+		It("should fail if a license pattern is malformed", func() {
+			chk := NewCheck("placeholder", WithLicenseRules([]string{"MIT"}, []string{"GPL-["}))
+			_, _, err := chk.List(context.TODO())
+			Expect(err).To(MatchError(preflighterr.ErrCannotInitializeChecks))
+			Expect(err).To(MatchError(ContainSubstring(`invalid license pattern "GPL-["`)))
+		})
+	})
+
+	When("SBOM formats are provided", func() {
+		It("should fail if a format is unknown", func() {
+			chk := NewCheck("placeholder", WithSBOMFormats("spdx", "xml"))
+			_, err := chk.Run(context.TODO())
+			Expect(err).To(MatchError(ContainSubstring(`unknown SBOM format "xml"`)))
+		})
+	})
+
+	When("waivers are provided", func() {
+		It("should fail if the waivers can not be loaded", func() {
+			chk := NewCheck("placeholder", WithWaivers(filepath.Join(GinkgoT().TempDir(), "missing.yaml")))
+			_, err := chk.Run(context.TODO())
+			Expect(err).To(MatchError(ContainSubstring("could not read waivers")))
+		})
+	})
+
 	When("Calling the check", func() {
 		It("should fail if you passed an empty image", func() {
 			chk := NewCheck("")
@@ -151,6 +487,17 @@ var _ = Describe("Container Check Execution", func() {
 			Expect(err).To(MatchError(preflighterr.ErrImageEmpty))
 		})
 
+		It("should notify the observer that the run finished if it fails before checks are executed", func() {
+			var events []certification.Event
+			observer := certification.ObserverFunc(func(_ context.Context, event certification.Event) {
+				events = append(events, event)
+			})
+			chk := NewCheck("", WithObserver(observer))
+			_, err := chk.Run(context.TODO())
+			Expect(err).To(MatchError(preflighterr.ErrImageEmpty))
+			Expect(events).To(Equal([]certification.Event{certification.RunFinished{Err: err}}))
+		})
+
 		It("should fail if it cannot use your provided pyxis data to resolve the policy", func() {
 			// This test isn't ideal because it's slow due to actually trying to use the creds to talk to Pyxis.
 			chk := NewCheck("placeholder", WithPyxisEnv("dev"), WithCertificationProject("00000", "11111"))
@@ -9,4 +9,9 @@ var (
 	ErrImageEmpty                   = errors.New("image is empty")
 	ErrCannotResolvePolicyException = errors.New("cannot resolve policy exception")
 	ErrCannotInitializeChecks       = errors.New("unable to initialize checks")
+	ErrUnknownCheck                 = errors.New("unknown check")
+	ErrImageDigestChanged           = errors.New("image digest has changed")
+	ErrCustomCheckResults           = errors.New("results of custom checks cannot be submitted")
+	ErrWaivedCheckResults           = errors.New("results with waived failures cannot be submitted")
+	ErrRemovedCheckResults          = errors.New("results cannot be submitted when policy checks were removed")
 )
@@ -0,0 +1,247 @@
+// Package advisory reads Red Hat security advisories from a local CSAF or
+// OVAL dataset, and matches installed RPMs against the fixes they describe.
+// No network access is made, so that images can be assessed in disconnected
+// environments.
+package advisory
+
+import (
+	"cmp"
+	"compress/bzip2"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Severity is the impact Red Hat rated a vulnerability with.
+type Severity string
+
+// The severities Red Hat rates vulnerabilities with, from most to least
+// significant.
+const (
+	SeverityCritical  Severity = "Critical"
+	SeverityImportant Severity = "Important"
+	SeverityModerate  Severity = "Moderate"
+	SeverityLow       Severity = "Low"
+	SeverityUnknown   Severity = "Unknown"
+)
+
+var severityRanks = map[Severity]int{
+	SeverityCritical:  4,
+	SeverityImportant: 3,
+	SeverityModerate:  2,
+	SeverityLow:       1,
+}
+
+// ParseSeverity returns the severity named s, case insensitively. Unknown
+// names are SeverityUnknown.
+func ParseSeverity(s string) Severity {
+	for sev := range severityRanks {
+		if strings.EqualFold(strings.TrimSpace(s), string(sev)) {
+			return sev
+		}
+	}
+	return SeverityUnknown
+}
+
+// AtLeast returns true if s is as significant as other, or more.
+func (s Severity) AtLeast(other Severity) bool {
+	return s.Compare(other) >= 0
+}
+
+// Compare returns -1, 0 or 1 if s is less significant than, as significant
+// as, or more significant than other.
+func (s Severity) Compare(other Severity) int {
+	return cmp.Compare(severityRanks[s], severityRanks[other])
+}
+
+// Fix describes the version of a package in which a vulnerability is fixed.
+type Fix struct {
+	// Advisory is the ID of the advisory that shipped the fix, e.g.
+	// RHSA-2024:1234. It may be empty for datasets that describe
+	// vulnerabilities rather than advisories.
+	Advisory string
+	// CVE is the ID of the vulnerability.
+	CVE      string
+	Severity Severity
+	// Package is the name of the binary RPM that is fixed.
+	Package string
+	// FixedIn is the first version of Package that is not vulnerable.
+	FixedIn EVR
+	// Stream is the RHEL stream the fix was released for, e.g. el9, from the
+	// dist tag of FixedIn. If empty, the fix applies to every stream.
+	Stream string
+	// Arch is the architecture of the fixed package, e.g. x86_64. If empty
+	// or noarch, the fix applies to every architecture.
+	Arch string
+}
+
+// distTag matches the RHEL major release in the dist tag of a release, e.g.
+// 9 in 25.el9_3 or in 1.module+el9.2.0+1234+abcd.
+var distTag = regexp.MustCompile(`(?:^|[.+])el(\d+)`)
+
+// streamOf returns the RHEL stream of the package release, e.g. el9 for
+// 25.el9_3, or an empty string if the release has no dist tag.
+func streamOf(release string) string {
+	m := distTag.FindStringSubmatch(release)
+	if m == nil {
+		return ""
+	}
+	return "el" + m[1]
+}
+
+// appliesTo returns true if f was released for the stream and arch of an
+// installed package. Unknown streams and architectures match any.
+func (f Fix) appliesTo(stream, arch string) bool {
+	if f.Stream != "" && stream != "" && f.Stream != stream {
+		return false
+	}
+	return f.Arch == "" || f.Arch == "noarch" || arch == "" || arch == "noarch" || f.Arch == arch
+}
+
+// Dataset is a collection of fixes, indexed by package name.
+type Dataset struct {
+	fixes map[string][]Fix
+}
+
+// NewDataset returns a dataset containing fixes. Duplicate fixes are
+// dropped.
+func NewDataset(fixes ...Fix) *Dataset {
+	d := &Dataset{fixes: make(map[string][]Fix)}
+	d.add(fixes...)
+	return d
+}
+
+func (d *Dataset) add(fixes ...Fix) {
+	for _, f := range fixes {
+		if slices.Contains(d.fixes[f.Package], f) {
+			continue
+		}
+		d.fixes[f.Package] = append(d.fixes[f.Package], f)
+	}
+}
+
+// Len returns the number of fixes in d.
+func (d *Dataset) Len() int {
+	n := 0
+	for _, fixes := range d.fixes {
+		n += len(fixes)
+	}
+	return n
+}
+
+// Match returns the fixes that apply to version installed of the package
+// named name built for arch, i.e. those released for the same RHEL stream,
+// taken from the dist tag of installed, and fixed in a later version. If a
+// CVE is fixed by more than one advisory, only the earliest fix that applies
+// is returned.
+func (d *Dataset) Match(name, arch string, installed EVR) []Fix {
+	stream := streamOf(installed.Release)
+	var matches []Fix
+	for _, f := range d.fixes[name] {
+		if !f.appliesTo(stream, arch) || installed.Compare(f.FixedIn) >= 0 {
+			continue
+		}
+		i := slices.IndexFunc(matches, func(m Fix) bool { return m.CVE == f.CVE })
+		switch {
+		case i < 0:
+			matches = append(matches, f)
+		case f.FixedIn.Compare(matches[i].FixedIn) < 0:
+			matches[i] = f
+		}
+	}
+	return matches
+}
+
+// Load reads the dataset at path, which may be a single advisory file or a
+// directory of them. CSAF documents (.json) and OVAL definitions (.xml) are
+// supported, and either may be compressed with bzip2 (.bz2). Other files in
+// a directory are ignored.
+func Load(path string) (*Dataset, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read advisories: %w", err)
+	}
+
+	d := NewDataset()
+	if !info.IsDir() {
+		if err := d.loadFile(path); err != nil {
+			return nil, err
+		}
+		return d, nil
+	}
+
+	err = filepath.WalkDir(path, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("could not read advisories: %w", err)
+		}
+		if entry.IsDir() || formatOf(p) == "" {
+			return nil
+		}
+		return d.loadFile(p)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// loadFile adds the fixes in the advisory file at path to d.
+func (d *Dataset) loadFile(path string) error {
+	format := formatOf(path)
+	if format == "" {
+		return fmt.Errorf("unsupported advisory file %s: expected a .json, .xml or .bz2 file", path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		//coverage:ignore
+		return fmt.Errorf("could not read advisories: %w", err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".bz2") {
+		r = bzip2.NewReader(f)
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("could not read advisories from %s: %w", path, err)
+	}
+
+	var fixes []Fix
+	switch format {
+	case formatCSAF:
+		fixes, err = parseCSAF(b)
+	case formatOVAL:
+		fixes, err = parseOVAL(b)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid advisories in %s: %w", path, err)
+	}
+
+	d.add(fixes...)
+	return nil
+}
+
+const (
+	formatCSAF = "csaf"
+	formatOVAL = "oval"
+)
+
+// formatOf returns the format of the advisory file at path, based on its
+// extension, or an empty string if it is not an advisory file.
+func formatOf(path string) string {
+	switch filepath.Ext(strings.TrimSuffix(path, ".bz2")) {
+	case ".json":
+		return formatCSAF
+	case ".xml":
+		return formatOVAL
+	}
+	return ""
+}
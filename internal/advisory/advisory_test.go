@@ -0,0 +1,169 @@
+package advisory
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var (
+	opensslFix = EVR{Epoch: 1, Version: "3.0.7", Release: "25.el9_3"}
+	glibcFix   = EVR{Version: "2.34", Release: "83.el9_3.7"}
+)
+
+var _ = Describe("Advisory datasets", func() {
+	It("should read the fixes in a CSAF advisory", func() {
+		d, err := Load(filepath.Join("testdata", "csaf", "rhsa-2024_1234.json"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(d.Len()).To(Equal(4))
+
+		Expect(d.Match("openssl-libs", "x86_64", ParseEVR("1:3.0.7-24.el9"))).To(ConsistOf(
+			Fix{Advisory: "RHSA-2024:1234", CVE: "CVE-2023-5678", Severity: SeverityModerate, Package: "openssl-libs", FixedIn: opensslFix, Stream: "el9", Arch: "x86_64"},
+			Fix{Advisory: "RHSA-2024:1234", CVE: "CVE-2024-0727", Severity: SeverityImportant, Package: "openssl-libs", FixedIn: opensslFix, Stream: "el9", Arch: "x86_64"},
+		))
+		Expect(d.Match("openssl", "x86_64", ParseEVR("1:3.0.7-25.el9_3"))).To(BeEmpty())
+		Expect(d.Match("curl", "x86_64", ParseEVR("7.76.1-26.el9"))).To(BeEmpty())
+	})
+
+	It("should only match the fixes for the stream and architecture of a package", func() {
+		d, err := Load(filepath.Join("testdata", "csaf", "cve-2024-1000.json"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(d.Len()).To(Equal(5))
+
+		el8Fix := EVR{Epoch: 1, Version: "1.1.1k", Release: "12.el8_9"}
+		el9Fix := EVR{Epoch: 1, Version: "3.0.7", Release: "27.el9"}
+		Expect(d.Match("openssl-libs", "x86_64", ParseEVR("1:1.1.1k-9.el8"))).To(ConsistOf(
+			Fix{Advisory: "CVE-2024-1000", CVE: "CVE-2024-1000", Severity: SeverityImportant, Package: "openssl-libs", FixedIn: el8Fix, Stream: "el8", Arch: "x86_64"},
+		))
+		Expect(d.Match("openssl-libs", "x86_64", ParseEVR("1:1.1.1k-12.el8_9"))).To(BeEmpty())
+		Expect(d.Match("openssl-libs", "aarch64", ParseEVR("1:3.0.7-25.el9_3"))).To(ConsistOf(
+			Fix{Advisory: "CVE-2024-1000", CVE: "CVE-2024-1000", Severity: SeverityImportant, Package: "openssl-libs", FixedIn: el9Fix, Stream: "el9", Arch: "aarch64"},
+			Fix{Advisory: "CVE-2024-1000", CVE: "CVE-2024-2000", Severity: SeverityCritical, Package: "openssl-libs", FixedIn: el9Fix, Stream: "el9", Arch: "aarch64"},
+		))
+		Expect(d.Match("openssl-libs", "s390x", ParseEVR("1:3.0.7-25.el9_3"))).To(BeEmpty())
+	})
+
+	It("should read the fixes in OVAL definitions", func() {
+		d, err := Load(filepath.Join("testdata", "oval", "rhel-9-glibc.oval.xml"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(d.Match("glibc", "x86_64", ParseEVR("2.34-83.el9_3"))).To(ConsistOf(
+			Fix{Advisory: "RHSA-2024:0100", CVE: "CVE-2023-6246", Severity: SeverityImportant, Package: "glibc", FixedIn: glibcFix, Stream: "el9"},
+			Fix{Advisory: "RHSA-2024:0100", CVE: "CVE-2023-6779", Severity: SeverityCritical, Package: "glibc", FixedIn: glibcFix, Stream: "el9"},
+		))
+		Expect(d.Match("redhat-release", "x86_64", ParseEVR("9.3-0.5.el9"))).To(BeEmpty())
+	})
+
+	It("should read compressed advisories", func() {
+		d, err := Load(filepath.Join("testdata", "rhel-9-glibc.oval.xml.bz2"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(d.Len()).To(Equal(2))
+	})
+
+	It("should read every advisory in a directory", func() {
+		dir := GinkgoT().TempDir()
+		for _, name := range []string{"csaf/rhsa-2024_1234.json", "oval/rhel-9-glibc.oval.xml", "oval/README.md"} {
+			b, err := os.ReadFile(filepath.Join("testdata", name))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0o755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, name), b, 0o644)).To(Succeed())
+		}
+
+		d, err := Load(dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(d.Len()).To(Equal(6))
+	})
+
+	It("should return the earliest fix for each CVE", func() {
+		d := NewDataset(
+			Fix{Advisory: "RHSA-2", CVE: "CVE-1", Severity: SeverityImportant, Package: "glibc", FixedIn: ParseEVR("2.34-90")},
+			Fix{Advisory: "RHSA-1", CVE: "CVE-1", Severity: SeverityImportant, Package: "glibc", FixedIn: ParseEVR("2.34-85")},
+			Fix{Advisory: "RHSA-3", CVE: "CVE-1", Severity: SeverityImportant, Package: "glibc", FixedIn: ParseEVR("2.34-88")},
+			Fix{Advisory: "RHSA-1", CVE: "CVE-1", Severity: SeverityImportant, Package: "glibc", FixedIn: ParseEVR("2.34-85")},
+		)
+		Expect(d.Len()).To(Equal(3))
+
+		matches := d.Match("glibc", "x86_64", ParseEVR("2.34-80"))
+		Expect(matches).To(HaveLen(1))
+		Expect(matches[0].Advisory).To(Equal("RHSA-1"))
+
+		matches = d.Match("glibc", "x86_64", ParseEVR("2.34-86"))
+		Expect(matches).To(HaveLen(1))
+		Expect(matches[0].Advisory).To(Equal("RHSA-3"))
+	})
+
+	DescribeTable("should fail to read invalid datasets",
+		func(contents, name, expected string) {
+			path := filepath.Join(GinkgoT().TempDir(), name)
+			Expect(os.WriteFile(path, []byte(contents), 0o644)).To(Succeed())
+			_, err := Load(path)
+			Expect(err).To(MatchError(ContainSubstring(expected)))
+		},
+		Entry("invalid JSON", "{", "advisory.json", "invalid advisories in"),
+		Entry("JSON that is not CSAF", `{"document":{"category":"something_else"}}`, "advisory.json", "not a CSAF document"),
+		Entry("invalid XML", "<oval_definitions>", "advisory.xml", "invalid advisories in"),
+		Entry("XML that is not OVAL", "<html></html>", "advisory.xml", "invalid advisories in"),
+		Entry("OVAL without definitions", "<oval_definitions></oval_definitions>", "advisory.xml", "no OVAL definitions found"),
+		Entry("an unsupported file", "advisories", "advisories.txt", "unsupported advisory file"),
+		Entry("a file that is not bzip2 compressed", "advisories", "advisories.xml.bz2", "could not read advisories from"),
+	)
+
+	It("should fail to read an invalid advisory in a directory", func() {
+		_, err := Load("testdata")
+		Expect(err).To(MatchError(ContainSubstring("not a CSAF document")))
+	})
+
+	It("should fail if the dataset does not exist", func() {
+		_, err := Load(filepath.Join("testdata", "missing"))
+		Expect(err).To(MatchError(ContainSubstring("could not read advisories")))
+	})
+
+	It("should fail if a directory can not be read", func() {
+		if os.Getuid() == 0 {
+			Skip("permissions are not enforced for root")
+		}
+		dir := GinkgoT().TempDir()
+		Expect(os.Mkdir(filepath.Join(dir, "private"), 0o000)).To(Succeed())
+		_, err := Load(dir)
+		Expect(err).To(MatchError(ContainSubstring("could not read advisories")))
+	})
+
+	DescribeTable("should parse RPM package URLs",
+		func(purl string, name string, arch string, evr EVR, ok bool) {
+			n, a, e, o := parseRPMPURL(purl)
+			Expect(o).To(Equal(ok))
+			Expect(n).To(Equal(name))
+			Expect(a).To(Equal(arch))
+			Expect(e).To(Equal(evr))
+		},
+		Entry("with an epoch", "pkg:rpm/redhat/openssl@3.0.7-25.el9_3?arch=x86_64&epoch=1", "openssl", "x86_64", opensslFix, true),
+		Entry("without a namespace", "pkg:rpm/libstdc%2B%2B@11.4.1-2.1.el9", "libstdc++", "", EVR{Version: "11.4.1", Release: "2.1.el9"}, true),
+		Entry("a source RPM", "pkg:rpm/redhat/openssl@3.0.7-25.el9_3?arch=src", "", "", EVR{}, false),
+		Entry("another type of package", "pkg:oci/ubi9@sha256:abc", "", "", EVR{}, false),
+		Entry("without a version", "pkg:rpm/redhat/openssl", "", "", EVR{}, false),
+		Entry("an invalid query", "pkg:rpm/redhat/openssl@1.0?arch=%zz", "", "", EVR{}, false),
+		Entry("an invalid name", "pkg:rpm/redhat/open%zzssl@1.0", "", "", EVR{}, false),
+		Entry("an invalid version", "pkg:rpm/redhat/openssl@1.0%zz", "", "", EVR{}, false),
+	)
+
+	DescribeTable("should find the stream of a release",
+		func(release, stream string) {
+			Expect(streamOf(release)).To(Equal(stream))
+		},
+		Entry("with a dist tag", "9.el8", "el8"),
+		Entry("with a z-stream dist tag", "83.el9_3.7", "el9"),
+		Entry("of a module", "1.module+el8.9.0+20000+abcdef12", "el8"),
+		Entry("without a dist tag", "1", ""),
+		Entry("with el in another part", "1.fc40.shell", ""),
+	)
+
+	It("should rank severities", func() {
+		Expect(ParseSeverity("critical").AtLeast(SeverityImportant)).To(BeTrue())
+		Expect(ParseSeverity(" Important ").AtLeast(SeverityImportant)).To(BeTrue())
+		Expect(ParseSeverity("Moderate").AtLeast(SeverityImportant)).To(BeFalse())
+		Expect(ParseSeverity("none")).To(Equal(SeverityUnknown))
+		Expect(SeverityLow.Compare(SeverityUnknown)).To(Equal(1))
+		Expect(SeverityCritical.Compare(SeverityCritical)).To(Equal(0))
+	})
+})
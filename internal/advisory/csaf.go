@@ -0,0 +1,150 @@
+package advisory
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// csafDocument is the subset of a CSAF 2.0 security advisory or VEX document
+// needed to find the packages that fix each vulnerability.
+type csafDocument struct {
+	Document struct {
+		Category          string `json:"category"`
+		AggregateSeverity struct {
+			Text string `json:"text"`
+		} `json:"aggregate_severity"`
+		Tracking struct {
+			ID string `json:"id"`
+		} `json:"tracking"`
+	} `json:"document"`
+	ProductTree struct {
+		Branches      []csafBranch `json:"branches"`
+		Relationships []struct {
+			FullProductName  csafProduct `json:"full_product_name"`
+			ProductReference string      `json:"product_reference"`
+		} `json:"relationships"`
+	} `json:"product_tree"`
+	Vulnerabilities []struct {
+		CVE           string `json:"cve"`
+		ProductStatus struct {
+			Fixed []string `json:"fixed"`
+		} `json:"product_status"`
+		Threats []struct {
+			Category string `json:"category"`
+			Details  string `json:"details"`
+		} `json:"threats"`
+	} `json:"vulnerabilities"`
+}
+
+type csafBranch struct {
+	Product  *csafProduct `json:"product"`
+	Branches []csafBranch `json:"branches"`
+}
+
+type csafProduct struct {
+	ProductID                   string `json:"product_id"`
+	ProductIdentificationHelper struct {
+		PURL string `json:"purl"`
+	} `json:"product_identification_helper"`
+}
+
+// parseCSAF returns the fixes described by the CSAF document b. Fixed
+// products are resolved to RPMs through the package URLs in the product
+// tree. Source RPMs are ignored, since the installed packages they are
+// matched against are binary RPMs.
+func parseCSAF(b []byte) ([]Fix, error) {
+	var doc csafDocument
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(doc.Document.Category, "csaf_") {
+		return nil, errors.New("not a CSAF document")
+	}
+
+	purls := make(map[string]string)
+	var walk func([]csafBranch)
+	walk = func(branches []csafBranch) {
+		for _, branch := range branches {
+			if branch.Product != nil && branch.Product.ProductIdentificationHelper.PURL != "" {
+				purls[branch.Product.ProductID] = branch.Product.ProductIdentificationHelper.PURL
+			}
+			walk(branch.Branches)
+		}
+	}
+	walk(doc.ProductTree.Branches)
+
+	// Relationships name a component as it ships in a product, e.g.
+	// AppStream-9.3.0.Z.MAIN:openssl-1:3.0.7-25.el9_3.x86_64.
+	for _, rel := range doc.ProductTree.Relationships {
+		if purl, ok := purls[rel.ProductReference]; ok {
+			purls[rel.FullProductName.ProductID] = purl
+		}
+	}
+
+	var fixes []Fix
+	for _, vuln := range doc.Vulnerabilities {
+		severity := ParseSeverity(doc.Document.AggregateSeverity.Text)
+		for _, threat := range vuln.Threats {
+			if threat.Category == "impact" {
+				severity = ParseSeverity(threat.Details)
+			}
+		}
+
+		for _, productID := range vuln.ProductStatus.Fixed {
+			name, arch, evr, ok := parseRPMPURL(purls[productID])
+			if !ok {
+				continue
+			}
+			fixes = append(fixes, Fix{
+				Advisory: doc.Document.Tracking.ID,
+				CVE:      vuln.CVE,
+				Severity: severity,
+				Package:  name,
+				FixedIn:  evr,
+				Stream:   streamOf(evr.Release),
+				Arch:     arch,
+			})
+		}
+	}
+
+	return fixes, nil
+}
+
+// parseRPMPURL returns the name, architecture and EVR of the binary RPM
+// identified by the package URL purl, e.g.
+// pkg:rpm/redhat/openssl@3.0.7-25.el9_3?arch=x86_64&epoch=1.
+func parseRPMPURL(purl string) (string, string, EVR, bool) {
+	rest, ok := strings.CutPrefix(purl, "pkg:rpm/")
+	if !ok {
+		return "", "", EVR{}, false
+	}
+	rest, rawQuery, _ := strings.Cut(rest, "?")
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil || query.Get("arch") == "src" {
+		return "", "", EVR{}, false
+	}
+
+	// The namespace, e.g. redhat, precedes the name.
+	if i := strings.LastIndex(rest, "/"); i >= 0 {
+		rest = rest[i+1:]
+	}
+	name, version, ok := strings.Cut(rest, "@")
+	if !ok || name == "" || version == "" {
+		return "", "", EVR{}, false
+	}
+	if name, err = url.PathUnescape(name); err != nil {
+		return "", "", EVR{}, false
+	}
+	if version, err = url.PathUnescape(version); err != nil {
+		return "", "", EVR{}, false
+	}
+
+	evr := ParseEVR(version)
+	if epoch, err := strconv.Atoi(query.Get("epoch")); err == nil {
+		evr.Epoch = epoch
+	}
+	return name, query.Get("arch"), evr, true
+}
@@ -0,0 +1,174 @@
+package advisory
+
+import (
+	"strconv"
+	"strings"
+)
+
+// EVR is the epoch, version and release of an RPM.
+type EVR struct {
+	Epoch   int
+	Version string
+	Release string
+}
+
+// ParseEVR parses an EVR in the form [epoch:]version[-release].
+func ParseEVR(s string) EVR {
+	var evr EVR
+	if e, rest, ok := strings.Cut(s, ":"); ok {
+		// An invalid epoch is treated as zero, as rpm does.
+		evr.Epoch, _ = strconv.Atoi(e)
+		s = rest
+	}
+	if i := strings.LastIndex(s, "-"); i >= 0 {
+		evr.Version, evr.Release = s[:i], s[i+1:]
+	} else {
+		evr.Version = s
+	}
+	return evr
+}
+
+// String returns the EVR in the form epoch:version-release. The epoch is
+// omitted if it is zero.
+func (e EVR) String() string {
+	s := e.Version
+	if e.Release != "" {
+		s += "-" + e.Release
+	}
+	if e.Epoch != 0 {
+		s = strconv.Itoa(e.Epoch) + ":" + s
+	}
+	return s
+}
+
+// Compare returns -1, 0 or 1 if e is older than, the same as, or newer than
+// other, following rpm's rules. The releases are only compared if both are
+// set.
+func (e EVR) Compare(other EVR) int {
+	switch {
+	case e.Epoch < other.Epoch:
+		return -1
+	case e.Epoch > other.Epoch:
+		return 1
+	}
+	if c := compareVersions(e.Version, other.Version); c != 0 {
+		return c
+	}
+	if e.Release == "" || other.Release == "" {
+		return 0
+	}
+	return compareVersions(e.Release, other.Release)
+}
+
+// compareVersions is a port of rpmvercmp. Versions are compared segment by
+// segment, where a segment is a run of digits or of letters. Numeric
+// segments are newer than alphabetic ones, a tilde sorts before anything,
+// even the end of the version, and a caret sorts after the end of the
+// version but before anything else.
+func compareVersions(a, b string) int {
+	if a == b {
+		return 0
+	}
+
+	for a != "" || b != "" {
+		a = strings.TrimLeftFunc(a, isSeparator)
+		b = strings.TrimLeftFunc(b, isSeparator)
+
+		if strings.HasPrefix(a, "~") || strings.HasPrefix(b, "~") {
+			if !strings.HasPrefix(a, "~") {
+				return 1
+			}
+			if !strings.HasPrefix(b, "~") {
+				return -1
+			}
+			a, b = a[1:], b[1:]
+			continue
+		}
+
+		if strings.HasPrefix(a, "^") || strings.HasPrefix(b, "^") {
+			switch {
+			case a == "":
+				return -1
+			case b == "":
+				return 1
+			case !strings.HasPrefix(a, "^"):
+				return 1
+			case !strings.HasPrefix(b, "^"):
+				return -1
+			}
+			a, b = a[1:], b[1:]
+			continue
+		}
+
+		if a == "" || b == "" {
+			break
+		}
+
+		numeric := isDigit(rune(a[0]))
+		var segA, segB string
+		segA, a = splitSegment(a, numeric)
+		segB, b = splitSegment(b, numeric)
+
+		// Segments of different types: numeric segments are newer.
+		if segB == "" {
+			if numeric {
+				return 1
+			}
+			return -1
+		}
+
+		if numeric {
+			segA = strings.TrimLeft(segA, "0")
+			segB = strings.TrimLeft(segB, "0")
+			if len(segA) != len(segB) {
+				return compareInts(len(segA), len(segB))
+			}
+		}
+		if c := strings.Compare(segA, segB); c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case a == "" && b == "":
+		return 0
+	case a == "":
+		return -1
+	default:
+		return 1
+	}
+}
+
+// splitSegment returns the leading run of digits, or of letters, in s and
+// the remainder of s.
+func splitSegment(s string, numeric bool) (string, string) {
+	i := strings.IndexFunc(s, func(r rune) bool {
+		if numeric {
+			return !isDigit(r)
+		}
+		return !isLetter(r)
+	})
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i], s[i:]
+}
+
+func compareInts(a, b int) int {
+	if a < b {
+		return -1
+	}
+	return 1
+}
+
+func isSeparator(r rune) bool {
+	return !isDigit(r) && !isLetter(r) && r != '~' && r != '^'
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func isLetter(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
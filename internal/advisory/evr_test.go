@@ -0,0 +1,36 @@
+package advisory
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EVR", func() {
+	DescribeTable("should compare versions as rpm does",
+		func(a, b string, expected int) {
+			Expect(ParseEVR(a).Compare(ParseEVR(b))).To(Equal(expected))
+			Expect(ParseEVR(b).Compare(ParseEVR(a))).To(Equal(-expected))
+		},
+		Entry("equal", "1.0-1", "1.0-1", 0),
+		Entry("numeric segments", "1.10-1", "1.9-1", 1),
+		Entry("leading zeros", "1.010", "1.10", 0),
+		Entry("numeric and alphabetic segments", "1.0a", "1.0.1", -1),
+		Entry("alphabetic segments", "1.0b", "1.0a", 1),
+		Entry("separators", "1_0", "1.0", 0),
+		Entry("a longer version", "1.0.1", "1.0", 1),
+		Entry("a tilde", "1.0~rc1", "1.0", -1),
+		Entry("two tildes", "1.0~rc1", "1.0~rc2", -1),
+		Entry("a caret", "1.0^git1", "1.0", 1),
+		Entry("a caret and a longer version", "1.0^git1", "1.0.1", -1),
+		Entry("two carets", "1.0^git2", "1.0^git1", 1),
+		Entry("the epoch", "1:1.0-1", "2.0-1", 1),
+		Entry("the release", "2.34-83.el9_3.7", "2.34-83.el9_3", 1),
+		Entry("a missing release", "2.34", "2.34-83.el9_3", 0),
+	)
+
+	It("should parse and format EVRs", func() {
+		Expect(ParseEVR("1:3.0.7-25.el9_3")).To(Equal(EVR{Epoch: 1, Version: "3.0.7", Release: "25.el9_3"}))
+		Expect(ParseEVR("1:3.0.7-25.el9_3").String()).To(Equal("1:3.0.7-25.el9_3"))
+		Expect(ParseEVR("0:2.34").String()).To(Equal("2.34"))
+	})
+})
@@ -0,0 +1,150 @@
+package advisory
+
+import (
+	"encoding/xml"
+	"errors"
+	"strings"
+)
+
+// ovalDefinitions is the subset of an OVAL 5 definitions document, as
+// published by Red Hat, needed to find the packages that fix each
+// vulnerability. Elements are matched by their local names, so the
+// namespaces of the OVAL and Red Hat schemas are not significant.
+type ovalDefinitions struct {
+	XMLName     xml.Name         `xml:"oval_definitions"`
+	Definitions []ovalDefinition `xml:"definitions>definition"`
+	Tests       []struct {
+		ID     string `xml:"id,attr"`
+		Object struct {
+			Ref string `xml:"object_ref,attr"`
+		} `xml:"object"`
+		State struct {
+			Ref string `xml:"state_ref,attr"`
+		} `xml:"state"`
+	} `xml:"tests>rpminfo_test"`
+	Objects []struct {
+		ID   string `xml:"id,attr"`
+		Name string `xml:"name"`
+	} `xml:"objects>rpminfo_object"`
+	States []struct {
+		ID  string `xml:"id,attr"`
+		EVR struct {
+			Operation string `xml:"operation,attr"`
+			Value     string `xml:",chardata"`
+		} `xml:"evr"`
+	} `xml:"states>rpminfo_state"`
+}
+
+type ovalDefinition struct {
+	Class    string `xml:"class,attr"`
+	Metadata struct {
+		References []struct {
+			Source string `xml:"source,attr"`
+			RefID  string `xml:"ref_id,attr"`
+		} `xml:"reference"`
+		Advisory struct {
+			Severity string `xml:"severity"`
+			CVEs     []struct {
+				Impact string `xml:"impact,attr"`
+				ID     string `xml:",chardata"`
+			} `xml:"cve"`
+		} `xml:"advisory"`
+	} `xml:"metadata"`
+	Criteria ovalCriteria `xml:"criteria"`
+}
+
+type ovalCriteria struct {
+	Criteria  []ovalCriteria `xml:"criteria"`
+	Criterion []struct {
+		TestRef string `xml:"test_ref,attr"`
+	} `xml:"criterion"`
+}
+
+// testRefs returns the tests c refers to, including those of nested
+// criteria.
+func (c ovalCriteria) testRefs() []string {
+	var refs []string
+	for _, criterion := range c.Criterion {
+		refs = append(refs, criterion.TestRef)
+	}
+	for _, nested := range c.Criteria {
+		refs = append(refs, nested.testRefs()...)
+	}
+	return refs
+}
+
+// parseOVAL returns the fixes described by the OVAL definitions b. Every
+// "package is earlier than version" test in a patch definition is a fix;
+// the remaining criteria, such as the release of the operating system, are
+// not evaluated, so the dataset should be the one published for the release
+// the image is based on. Fixes are limited to the stream of the dist tag of
+// the fixed version, and apply to every architecture.
+func parseOVAL(b []byte) ([]Fix, error) {
+	var doc ovalDefinitions
+	if err := xml.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Definitions) == 0 {
+		return nil, errors.New("no OVAL definitions found")
+	}
+
+	names := make(map[string]string, len(doc.Objects))
+	for _, o := range doc.Objects {
+		names[o.ID] = o.Name
+	}
+	fixedIn := make(map[string]EVR, len(doc.States))
+	for _, s := range doc.States {
+		if s.EVR.Operation == "less than" && s.EVR.Value != "" {
+			fixedIn[s.ID] = ParseEVR(strings.TrimSpace(s.EVR.Value))
+		}
+	}
+	type packageFix struct {
+		name string
+		evr  EVR
+	}
+	tests := make(map[string]packageFix, len(doc.Tests))
+	for _, t := range doc.Tests {
+		name, hasName := names[t.Object.Ref]
+		evr, hasEVR := fixedIn[t.State.Ref]
+		if hasName && hasEVR {
+			tests[t.ID] = packageFix{name: name, evr: evr}
+		}
+	}
+
+	var fixes []Fix
+	for _, def := range doc.Definitions {
+		if def.Class != "patch" {
+			continue
+		}
+
+		var advisoryID string
+		for _, ref := range def.Metadata.References {
+			if ref.Source == "RHSA" || ref.Source == "RHBA" || ref.Source == "RHEA" {
+				advisoryID = ref.RefID
+			}
+		}
+
+		for _, ref := range def.Criteria.testRefs() {
+			pkg, ok := tests[ref]
+			if !ok {
+				continue
+			}
+			for _, cve := range def.Metadata.Advisory.CVEs {
+				severity := ParseSeverity(cve.Impact)
+				if severity == SeverityUnknown {
+					severity = ParseSeverity(def.Metadata.Advisory.Severity)
+				}
+				fixes = append(fixes, Fix{
+					Advisory: advisoryID,
+					CVE:      strings.TrimSpace(cve.ID),
+					Severity: severity,
+					Package:  pkg.name,
+					FixedIn:  pkg.evr,
+					Stream:   streamOf(pkg.evr.Release),
+				})
+			}
+		}
+	}
+
+	return fixes, nil
+}
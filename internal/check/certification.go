@@ -2,6 +2,7 @@ package check
 
 import (
 	"context"
+	"errors"
 
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
 )
@@ -13,6 +14,16 @@ const (
 	LevelWarn     = "warn"
 )
 
+// ErrCheckSkipped is returned, optionally wrapped with a reason, by a check's
+// Validate method when the check cannot be evaluated against the asset under
+// test. Skipped checks are reported separately and do not fail the run.
+var ErrCheckSkipped = errors.New("check skipped")
+
+// ErrCheckTimedOut is the cause of the context passed to a check's Validate
+// method when the check exceeds its deadline. Timed out checks are reported
+// separately from checks that returned an error.
+var ErrCheckTimedOut = errors.New("check timed out")
+
 // Check as an interface containing all methods necessary
 // to use and identify a given check.
 type Check interface {
@@ -30,8 +41,38 @@ type Check interface {
 	Help() HelpText
 }
 
+// Category groups checks by the concern they address.
+type Category string
+
+// The categories a check's Metadata can be assigned.
+const (
+	// CategorySecurity checks address the security of the asset.
+	CategorySecurity Category = "security"
+	// CategoryMetadata checks address labels, tags, annotations and other
+	// descriptive data.
+	CategoryMetadata Category = "metadata"
+	// CategoryLicensing checks address licenses and the redistribution of
+	// content.
+	CategoryLicensing Category = "licensing"
+	// CategoryPackaging checks address how the asset is built and what it
+	// contains.
+	CategoryPackaging Category = "packaging"
+	// CategoryCluster checks address the behavior of the asset when
+	// deployed to a cluster.
+	CategoryCluster Category = "cluster"
+)
+
 // Metadata contains useful information regarding the check.
 type Metadata struct {
+	// ID is a stable identifier for the requirement the check tests. Unlike
+	// the check's name, it does not change across releases: a renamed check
+	// keeps its ID, and the parts of a check that is split share it.
+	ID string `json:"id,omitempty" xml:"id,omitempty"`
+	// PolicyVersion is the version of the policy in which the requirement
+	// the check tests was last changed.
+	PolicyVersion string `json:"policy_version,omitempty" xml:"policyVersion,omitempty"`
+	// Categories are the concerns the check addresses.
+	Categories []Category `json:"categories,omitempty" xml:"categories>category,omitempty"`
 	// Description contains a brief text detailing the overall goal of the check.
 	Description string `json:"description" xml:"description"`
 	// Level describes the certification level associated with the given check.
@@ -0,0 +1,120 @@
+package check
+
+import (
+	"context"
+	"reflect"
+	"slices"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+)
+
+var (
+	_ Check            = &customCheck{}
+	_ FindingsReporter = &customCheck{}
+)
+
+// customMarker is implemented by checks that are not part of a Red Hat
+// policy. The method is unexported so that only Custom can mark a check.
+type customMarker interface {
+	custom()
+}
+
+// unwrapper is implemented by checks that wrap another check without
+// changing what it is, such as a check that was filtered out of a run.
+type unwrapper interface {
+	Unwrap() Check
+}
+
+// customCheck marks the check it wraps as not being part of a Red Hat
+// policy.
+type customCheck struct {
+	Check
+}
+
+func (c *customCheck) custom() {}
+
+// Unwrap returns the marked check.
+func (c *customCheck) Unwrap() Check {
+	return c.Check
+}
+
+// ValidateWithFindings forwards to the marked check, so its findings are
+// still reported.
+func (c *customCheck) ValidateWithFindings(ctx context.Context, imgRef image.ImageReference) (bool, []Finding, error) {
+	return Validate(ctx, c.Check, imgRef)
+}
+
+// Custom marks c as a check that is not part of a Red Hat policy. Results of
+// custom checks are reported like any other, but are never submitted to Red
+// Hat.
+func Custom(c Check) Check {
+	if IsCustom(c) {
+		return c
+	}
+	return &customCheck{Check: c}
+}
+
+// IsCustom returns true if c, or a check it wraps, was marked with Custom.
+func IsCustom(c Check) bool {
+	for c != nil {
+		if _, ok := c.(customMarker); ok {
+			return true
+		}
+		u, ok := c.(unwrapper)
+		if !ok {
+			return false
+		}
+		c = u.Unwrap()
+	}
+	return false
+}
+
+// ApplyFilter passes checks to filter, and returns the checks it replies
+// with. Checks that filter adds or wraps are marked with Custom, since they
+// no longer match what the policy defines. Checks that filter removes are
+// appended as checks that report themselves as skipped, and are marked as
+// removed, so that the results of the run are not submitted.
+func ApplyFilter(checks []Check, filter func([]Check) []Check) []Check {
+	filtered := filter(slices.Clone(checks))
+	names := make([]string, 0, len(filtered))
+	for i, c := range filtered {
+		names = append(names, c.Name())
+		if !containsCheck(checks, c) {
+			filtered[i] = Custom(c)
+		}
+	}
+	for _, c := range checks {
+		if !slices.Contains(names, c.Name()) {
+			filtered = append(filtered, &filteredCheck{Check: c, reason: "removed by the check filter", removed: true})
+		}
+	}
+	return filtered
+}
+
+// IsRemoved returns true if c, or a check it wraps, is a check of the policy
+// that a filter passed to ApplyFilter removed.
+func IsRemoved(c Check) bool {
+	for c != nil {
+		if f, ok := c.(*filteredCheck); ok && f.removed {
+			return true
+		}
+		u, ok := c.(unwrapper)
+		if !ok {
+			return false
+		}
+		c = u.Unwrap()
+	}
+	return false
+}
+
+// containsCheck returns true if checks contains the same check as c. Checks
+// of types that can not be compared are never considered the same.
+func containsCheck(checks []Check, c Check) bool {
+	for _, existing := range checks {
+		t := reflect.TypeOf(existing)
+		if t == reflect.TypeOf(c) && t.Comparable() && existing == c {
+			return true
+		}
+	}
+	return false
+}
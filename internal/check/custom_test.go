@@ -0,0 +1,122 @@
+package check
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+)
+
+// findingsCheck reports a single finding.
+type findingsCheck struct {
+	Check
+}
+
+func (findingsCheck) ValidateWithFindings(context.Context, image.ImageReference) (bool, []Finding, error) {
+	return false, []Finding{{Message: "found"}}, nil
+}
+
+// wrappedCheck wraps a check without unwrapping to it, like a library
+// consumer's wrapper would.
+type wrappedCheck struct {
+	Check
+}
+
+// uncomparableCheck can not be compared with ==.
+type uncomparableCheck struct {
+	Check
+	patterns []string
+}
+
+var _ = Describe("Custom checks", func() {
+	var policy []Check
+	BeforeEach(func() {
+		passing := func(context.Context, image.ImageReference) (bool, error) { return true, nil }
+		policy = []Check{
+			NewGenericCheck("first", passing, Metadata{}, HelpText{}, nil),
+			NewGenericCheck("second", passing, Metadata{}, HelpText{}, nil),
+		}
+	})
+
+	It("should not consider policy checks custom", func() {
+		Expect(IsCustom(policy[0])).To(BeFalse())
+	})
+
+	It("should mark checks as custom", func() {
+		c := Custom(policy[0])
+		Expect(IsCustom(c)).To(BeTrue())
+		Expect(c.Name()).To(Equal("first"))
+		Expect(Custom(c)).To(BeIdenticalTo(c))
+
+		cc, ok := c.(*customCheck)
+		Expect(ok).To(BeTrue())
+		cc.custom()
+		Expect(cc.Unwrap()).To(BeIdenticalTo(policy[0]))
+	})
+
+	It("should not consider a wrapper of nothing custom", func() {
+		Expect(IsCustom(&filteredCheck{})).To(BeFalse())
+		Expect(IsRemoved(&filteredCheck{})).To(BeFalse())
+	})
+
+	It("should still consider custom checks custom when they are filtered out", func() {
+		filtered, err := Filter([]Check{Custom(policy[0]), policy[1]}, nil, []string{"first", "second"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(IsCustom(filtered[0])).To(BeTrue())
+		Expect(IsCustom(filtered[1])).To(BeFalse())
+	})
+
+	It("should report the findings of the marked check", func() {
+		passed, findings, err := Validate(context.TODO(), Custom(findingsCheck{Check: policy[0]}), image.ImageReference{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(passed).To(BeFalse())
+		Expect(findings).To(Equal([]Finding{{Message: "found"}}))
+	})
+
+	Context("when applying a filter", func() {
+		It("should not mark the checks it keeps", func() {
+			filtered := ApplyFilter(policy, func(checks []Check) []Check { return checks[1:] })
+			Expect(filtered).To(HaveLen(2))
+			Expect(IsCustom(filtered[0])).To(BeFalse())
+			Expect(IsRemoved(filtered[0])).To(BeFalse())
+		})
+		It("should record the checks it removes as skipped", func() {
+			filtered := ApplyFilter(policy, func(checks []Check) []Check { return checks[1:] })
+			Expect(filtered[1].Name()).To(Equal("first"))
+			Expect(IsRemoved(filtered[1])).To(BeTrue())
+			_, err := filtered[1].Validate(context.TODO(), image.ImageReference{})
+			Expect(err).To(MatchError(ErrCheckSkipped))
+			Expect(err).To(MatchError(ContainSubstring("removed by the check filter")))
+		})
+		It("should still consider removed checks removed when they are filtered out", func() {
+			filtered := ApplyFilter(policy, func(checks []Check) []Check { return checks[1:] })
+			filtered, err := Filter(filtered, nil, []string{"first"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(IsRemoved(filtered[1])).To(BeTrue())
+			Expect(IsRemoved(filtered[0])).To(BeFalse())
+		})
+		It("should mark the checks it adds", func() {
+			extra := NewGenericCheck("extra", nil, Metadata{}, HelpText{}, nil)
+			filtered := ApplyFilter(policy, func(checks []Check) []Check { return append(checks, extra) })
+			Expect(filtered).To(HaveLen(3))
+			Expect(IsCustom(filtered[0])).To(BeFalse())
+			Expect(IsCustom(filtered[2])).To(BeTrue())
+		})
+		It("should mark the checks it wraps, even when wrapped in place", func() {
+			filtered := ApplyFilter(policy, func(checks []Check) []Check {
+				checks[0] = wrappedCheck{Check: checks[0]}
+				return checks
+			})
+			Expect(IsCustom(filtered[0])).To(BeTrue())
+			Expect(IsCustom(filtered[1])).To(BeFalse())
+			Expect(IsCustom(policy[0])).To(BeFalse())
+		})
+		It("should mark checks that can not be compared", func() {
+			c := uncomparableCheck{Check: policy[0]}
+			filtered := ApplyFilter([]Check{c}, func(checks []Check) []Check { return checks })
+			Expect(IsCustom(filtered[0])).To(BeTrue())
+		})
+	})
+})
@@ -1,11 +1,20 @@
 package check
 
 var (
-	DefaultCertImageFilename    = "cert-image.json"
-	DefaultRPMManifestFilename  = "rpm-manifest.json"
-	DefaultTestResultsFilename  = "results.json"
-	DefaultArtifactsTarFileName = "artifacts.tar"
-	DefaultPyxisHost            = "catalog.redhat.com/api/containers"
-	DefaultPyxisEnv             = "prod"
-	SystemdDir                  = "/etc/systemd/system"
+	DefaultCertImageFilename       = "cert-image.json"
+	DefaultRPMManifestFilename     = "rpm-manifest.json"
+	DefaultInventoryFilename       = "inventory.json"
+	DefaultSPDXFilename            = "sbom.spdx.json"
+	DefaultCycloneDXFilename       = "sbom.cdx.json"
+	DefaultLicensesFilename        = "licenses.json"
+	DefaultVulnerabilitiesFilename = "vulnerabilities.json"
+	DefaultSecretsFilename         = "secrets.json"
+	DefaultUnsafeFilesFilename     = "unsafe-files.json"
+	DefaultSignaturesFilename      = "signatures.json"
+	DefaultProvenanceFilename      = "provenance.json"
+	DefaultTestResultsFilename     = "results.json"
+	DefaultArtifactsTarFileName    = "artifacts.tar"
+	DefaultPyxisHost               = "catalog.redhat.com/api/containers"
+	DefaultPyxisEnv                = "prod"
+	SystemdDir                     = "/etc/systemd/system"
 )
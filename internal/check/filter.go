@@ -0,0 +1,71 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	preflighterr "github.com/redhat-openshift-ecosystem/openshift-preflight/errors"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+)
+
+// Filter narrows checks down to those named in include, if it is not empty,
+// less those named in exclude. Checks that are filtered out are not dropped.
+// Instead they are replaced with a check that reports itself as skipped,
+// so they are still recorded in the results. An error wrapping
+// preflighterr.ErrUnknownCheck is returned if include or exclude names a
+// check that is not in checks.
+func Filter(checks []Check, include, exclude []string) ([]Check, error) {
+	if len(include) == 0 && len(exclude) == 0 {
+		return checks, nil
+	}
+
+	names := make([]string, 0, len(checks))
+	for _, c := range checks {
+		names = append(names, c.Name())
+	}
+
+	for _, name := range slices.Concat(include, exclude) {
+		if !slices.Contains(names, name) {
+			return nil, fmt.Errorf("%w %q: valid checks are %s", preflighterr.ErrUnknownCheck, name, strings.Join(names, ", "))
+		}
+	}
+
+	filtered := make([]Check, 0, len(checks))
+	for _, c := range checks {
+		switch {
+		case slices.Contains(exclude, c.Name()):
+			filtered = append(filtered, &filteredCheck{Check: c, reason: "excluded from this run"})
+		case len(include) > 0 && !slices.Contains(include, c.Name()):
+			filtered = append(filtered, &filteredCheck{Check: c, reason: "not included in this run"})
+		default:
+			filtered = append(filtered, c)
+		}
+	}
+
+	return filtered, nil
+}
+
+// filteredCheck is a check that was filtered out of a run. It never runs the
+// underlying check, and needs no files from the image.
+type filteredCheck struct {
+	Check
+	reason string
+	// removed is set if a filter passed to ApplyFilter removed the check,
+	// rather than it being excluded by name.
+	removed bool
+}
+
+func (f *filteredCheck) Validate(context.Context, image.ImageReference) (bool, error) {
+	return false, fmt.Errorf("%w: %s", ErrCheckSkipped, f.reason)
+}
+
+func (f *filteredCheck) RequiredFilePatterns() []string {
+	return nil
+}
+
+// Unwrap returns the check that was filtered out.
+func (f *filteredCheck) Unwrap() Check {
+	return f.Check
+}
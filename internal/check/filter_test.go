@@ -0,0 +1,67 @@
+package check
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"
+	. "github.com/onsi/gomega"
+
+	preflighterr "github.com/redhat-openshift-ecosystem/openshift-preflight/errors"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+)
+
+var _ = Describe("Filtering checks", func() {
+	var checks []Check
+	BeforeEach(func() {
+		passing := func(context.Context, image.ImageReference) (bool, error) { return true, nil }
+		checks = []Check{
+			NewGenericCheck("first", passing, Metadata{}, HelpText{}, []string{"etc/first"}),
+			NewGenericCheck("second", passing, Metadata{}, HelpText{}, []string{"etc/second"}),
+			NewGenericCheck("third", passing, Metadata{}, HelpText{}, nil),
+		}
+	})
+
+	skipped := func(checks []Check) []string {
+		names := []string{}
+		for _, c := range checks {
+			if _, err := c.Validate(context.TODO(), image.ImageReference{}); err != nil {
+				Expect(err).To(MatchError(ErrCheckSkipped))
+				Expect(c.RequiredFilePatterns()).To(BeEmpty())
+				names = append(names, c.Name())
+			}
+		}
+		return names
+	}
+
+	It("should return the checks unchanged without a filter", func() {
+		filtered, err := Filter(checks, nil, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(filtered).To(Equal(checks))
+	})
+
+	It("should skip checks that were not included", func() {
+		filtered, err := Filter(checks, []string{"second"}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(filtered).To(HaveLen(3))
+		Expect(skipped(filtered)).To(Equal([]string{"first", "third"}))
+		Expect(filtered[1].RequiredFilePatterns()).To(Equal([]string{"etc/second"}))
+	})
+
+	It("should skip checks that were excluded", func() {
+		filtered, err := Filter(checks, nil, []string{"first"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(skipped(filtered)).To(Equal([]string{"first"}))
+	})
+
+	It("should let exclusions take precedence over inclusions", func() {
+		filtered, err := Filter(checks, []string{"first", "second"}, []string{"first"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(skipped(filtered)).To(Equal([]string{"first", "third"}))
+	})
+
+	It("should reject unknown names and list the valid ones", func() {
+		_, err := Filter(checks, []string{"fourth"}, nil)
+		Expect(err).To(MatchError(preflighterr.ErrUnknownCheck))
+		Expect(err).To(MatchError(ContainSubstring("first, second, third")))
+	})
+})
@@ -0,0 +1,50 @@
+package check
+
+import (
+	"context"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+)
+
+// Severity indicates how significant a Finding is.
+type Severity string
+
+// The severities a Finding can be reported with.
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// Finding is a single, specific observation a check made about the asset
+// under test, such as a missing label or a modified file.
+type Finding struct {
+	// Message describes what was found.
+	Message string `json:"message" xml:"message"`
+	// Object identifies what the finding is about, e.g. a file path,
+	// package or label name. It is empty if the finding applies to the
+	// asset as a whole.
+	Object string `json:"object,omitempty" xml:"object,omitempty"`
+	// Severity indicates how significant the finding is.
+	Severity Severity `json:"severity" xml:"severity"`
+}
+
+// FindingsReporter is implemented by checks that can explain their result
+// with a list of findings. Checks implementing it should also implement
+// Validate, typically by discarding the findings.
+type FindingsReporter interface {
+	// ValidateWithFindings behaves like Check.Validate, additionally
+	// returning the findings that led to the result.
+	ValidateWithFindings(ctx context.Context, imageReference image.ImageReference) (result bool, findings []Finding, err error)
+}
+
+// Validate runs c against imageReference, returning its findings if c
+// implements FindingsReporter.
+func Validate(ctx context.Context, c Check, imageReference image.ImageReference) (bool, []Finding, error) {
+	if r, ok := c.(FindingsReporter); ok {
+		return r.ValidateWithFindings(ctx, imageReference)
+	}
+
+	passed, err := c.Validate(ctx, imageReference)
+	return passed, nil, err
+}
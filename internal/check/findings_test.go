@@ -0,0 +1,53 @@
+package check
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+)
+
+var _ = Describe("Validating checks with findings", func() {
+	It("should return the findings of a check that reports them", func() {
+		c := &reportingCheck{
+			Check:    NewGenericCheck("reporting", nil, Metadata{}, HelpText{}, nil),
+			findings: []Finding{{Message: "missing", Object: "label", Severity: SeverityError}},
+		}
+		passed, findings, err := Validate(context.TODO(), c, image.ImageReference{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(passed).To(BeFalse())
+		Expect(findings).To(Equal(c.findings))
+	})
+
+	It("should return no findings for a check that does not report them", func() {
+		passing := func(context.Context, image.ImageReference) (bool, error) { return true, nil }
+		passed, findings, err := Validate(context.TODO(), NewGenericCheck("plain", passing, Metadata{}, HelpText{}, nil), image.ImageReference{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(passed).To(BeTrue())
+		Expect(findings).To(BeNil())
+	})
+
+	It("should not report findings for a filtered check", func() {
+		c := &reportingCheck{
+			Check:    NewGenericCheck("reporting", nil, Metadata{}, HelpText{}, nil),
+			findings: []Finding{{Message: "missing", Severity: SeverityError}},
+		}
+		filtered, err := Filter([]Check{c}, nil, []string{"reporting"})
+		Expect(err).ToNot(HaveOccurred())
+		_, findings, err := Validate(context.TODO(), filtered[0], image.ImageReference{})
+		Expect(err).To(MatchError(ErrCheckSkipped))
+		Expect(findings).To(BeNil())
+	})
+})
+
+// reportingCheck fails with a fixed set of findings.
+type reportingCheck struct {
+	Check
+	findings []Finding
+}
+
+func (c *reportingCheck) ValidateWithFindings(context.Context, image.ImageReference) (bool, []Finding, error) {
+	return false, c.findings, nil
+}
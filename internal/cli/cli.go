@@ -7,12 +7,14 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/go-logr/logr"
 
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/certification"
+	preflighterr "github.com/redhat-openshift-ecosystem/openshift-preflight/errors"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/formatters"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/lib"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
@@ -80,6 +82,15 @@ func RunPreflight(
 	}
 
 	if cfg.SubmitResults {
+		if custom := customCheckNames(results); len(custom) > 0 {
+			return fmt.Errorf("%w: %s", preflighterr.ErrCustomCheckResults, strings.Join(custom, ", "))
+		}
+		if removed := removedCheckNames(results); len(removed) > 0 {
+			return fmt.Errorf("%w: %s", preflighterr.ErrRemovedCheckResults, strings.Join(removed, ", "))
+		}
+		if waived := waivedCheckNames(results); len(waived) > 0 {
+			return fmt.Errorf("%w: %s", preflighterr.ErrWaivedCheckResults, strings.Join(waived, ", "))
+		}
 		if err := rs.Submit(ctx); err != nil {
 			return err
 		}
@@ -119,6 +130,42 @@ func writeJUnit(ctx context.Context, results certification.Results) error {
 	return nil
 }
 
+// customCheckNames returns the names of the checks in results that are not
+// part of a Red Hat policy.
+func customCheckNames(results certification.Results) []string {
+	var names []string
+	for _, r := range slices.Concat(results.Passed, results.Failed, results.Errors, results.Warned, results.Skipped, results.TimedOut) {
+		if r.IsCustom() {
+			names = append(names, r.Name())
+		}
+	}
+	return names
+}
+
+// removedCheckNames returns the names of the checks in results that are part
+// of a Red Hat policy, but were removed from the run by a check filter.
+func removedCheckNames(results certification.Results) []string {
+	var names []string
+	for _, r := range results.Skipped {
+		if r.IsRemoved() {
+			names = append(names, r.Name())
+		}
+	}
+	return names
+}
+
+// waivedCheckNames returns the names of the checks in results whose
+// failures were matched by a waiver, whether or not it had expired.
+func waivedCheckNames(results certification.Results) []string {
+	var names []string
+	for _, r := range slices.Concat(results.Failed, results.Warned) {
+		if r.Waiver != nil {
+			names = append(names, r.Name())
+		}
+	}
+	return names
+}
+
 func convertPassedOverall(passedOverall bool) string {
 	if passedOverall {
 		return "PASSED"
@@ -14,12 +14,14 @@ import (
 
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/certification"
+	preflighterr "github.com/redhat-openshift-ecosystem/openshift-preflight/errors"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/formatters"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/lib"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/runtime"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/waiver"
 )
 
 var _ = Describe("CLI Library function", func() {
@@ -166,6 +168,79 @@ var _ = Describe("CLI Library function", func() {
 					Expect(err).To(HaveOccurred())
 					Expect(err.Error()).To(ContainSubstring(submissionError))
 				})
+
+				It("Should refuse to submit the results of custom checks", func() {
+					c := CheckConfig{
+						SubmitResults: true,
+					}
+
+					err := RunPreflight(testcontext, func(ctx context.Context) (certification.Results, error) {
+						return certification.Results{
+							TestedImage:   "testSubmission",
+							PassedOverall: true,
+							Passed: []certification.Result{
+								{
+									Check: check.Custom(check.NewGenericCheck(
+										"testCustom",
+										func(ctx context.Context, ir image.ImageReference) (bool, error) { return true, nil },
+										check.Metadata{},
+										check.HelpText{},
+										nil,
+									)),
+									ElapsedTime: 1,
+								},
+							},
+						}, nil
+					}, c, testFormatter, &runtime.ResultWriterFile{}, &badResultSubmitter{"should not be called"})
+					Expect(err).To(MatchError(preflighterr.ErrCustomCheckResults))
+					Expect(err).To(MatchError(ContainSubstring("testCustom")))
+				})
+
+				It("Should refuse to submit results when policy checks were removed", func() {
+					c := CheckConfig{
+						SubmitResults: true,
+					}
+
+					removed := check.ApplyFilter([]check.Check{
+						check.NewGenericCheck("testRemoved", nil, check.Metadata{}, check.HelpText{}, nil),
+					}, func([]check.Check) []check.Check { return nil })
+					err := RunPreflight(testcontext, func(ctx context.Context) (certification.Results, error) {
+						return certification.Results{
+							TestedImage:   "testSubmission",
+							PassedOverall: true,
+							Skipped: []certification.Result{
+								{
+									Check:       removed[0],
+									ElapsedTime: 1,
+								},
+							},
+						}, nil
+					}, c, testFormatter, &runtime.ResultWriterFile{}, &badResultSubmitter{"should not be called"})
+					Expect(err).To(MatchError(preflighterr.ErrRemovedCheckResults))
+					Expect(err).To(MatchError(ContainSubstring("testRemoved")))
+				})
+
+				It("Should refuse to submit results with waived failures", func() {
+					c := CheckConfig{
+						SubmitResults: true,
+					}
+
+					err := RunPreflight(testcontext, func(ctx context.Context) (certification.Results, error) {
+						return certification.Results{
+							TestedImage:   "testSubmission",
+							PassedOverall: true,
+							Warned: []certification.Result{
+								{
+									Check:       check.NewGenericCheck("testWaived", nil, check.Metadata{}, check.HelpText{}, nil),
+									ElapsedTime: 1,
+									Waiver:      &waiver.Waiver{Check: "testWaived"},
+								},
+							},
+						}, nil
+					}, c, testFormatter, &runtime.ResultWriterFile{}, &badResultSubmitter{"should not be called"})
+					Expect(err).To(MatchError(preflighterr.ErrWaivedCheckResults))
+					Expect(err).To(MatchError(ContainSubstring("testWaived")))
+				})
 			})
 		})
 	})
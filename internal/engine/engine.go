@@ -17,18 +17,22 @@ import (
 	"regexp"
 	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
-	"github.com/google/go-containerregistry/pkg/crane"
-	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/cache"
 	rpmdb "github.com/knqyf263/go-rpmdb/pkg"
 
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/certification"
+	preflighterr "github.com/redhat-openshift-ecosystem/openshift-preflight/errors"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/inventory"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/layercache"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/license"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/openshift"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/operatorsdk"
@@ -39,26 +43,79 @@ import (
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/pyxis"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/rpm"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/runtime"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/sbom"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/waiver"
 )
 
+// Option configures optional behavior of the engine.
+type Option func(*craneEngine)
+
+// WithObserver registers o to receive events as checks are executed.
+func WithObserver(o certification.Observer) Option {
+	return func(c *craneEngine) {
+		if o != nil {
+			c.observer = &lockedObserver{observer: o}
+		}
+	}
+}
+
+// WithPreviousResults carries the outcomes of checks that passed, warned or
+// were skipped in prev over, instead of executing them again. The run is
+// refused if the image no longer has the digest recorded in prev.
+func WithPreviousResults(prev certification.PreviousResults) Option {
+	return func(c *craneEngine) {
+		c.previous = &prev
+	}
+}
+
+// WithWaivers moves the failures of checks that waivers match into Warned,
+// as long as the matching waiver has not expired. Failures matched by an
+// expired waiver are reported as failed, and are annotated as such.
+func WithWaivers(waivers []waiver.Waiver) Option {
+	return func(c *craneEngine) {
+		c.waivers = waivers
+	}
+}
+
+// WithSBOMFormats writes an SBOM of the image in each of formats to the
+// artifacts directory.
+func WithSBOMFormats(formats ...sbom.Format) Option {
+	return func(c *craneEngine) {
+		c.sbomFormats = formats
+	}
+}
+
 // New creates a new CraneEngine from the passed params
 func New(ctx context.Context,
 	checks []check.Check,
 	kubeconfig []byte,
 	cfg runtime.Config,
+	opts ...Option,
 ) (craneEngine, error) {
-	return craneEngine{
-		kubeconfig:         kubeconfig,
-		dockerConfig:       cfg.DockerConfig,
-		image:              cfg.Image,
-		checks:             checks,
-		isBundle:           cfg.Bundle,
-		isScratch:          cfg.Scratch,
-		platform:           cfg.Platform,
-		insecure:           cfg.Insecure,
-		manifestListDigest: cfg.ManifestListDigest,
-		tempDir:            cfg.TempDir,
-	}, nil
+	c := craneEngine{
+		kubeconfig:          kubeconfig,
+		dockerConfig:        cfg.DockerConfig,
+		image:               cfg.Image,
+		checks:              checks,
+		isBundle:            cfg.Bundle,
+		isScratch:           cfg.Scratch,
+		platform:            cfg.Platform,
+		insecure:            cfg.Insecure,
+		manifestListDigest:  cfg.ManifestListDigest,
+		tempDir:             cfg.TempDir,
+		checkParallelism:    cfg.CheckParallelism,
+		localImageReference: cfg.LocalImageReference,
+		cacheDir:            cfg.CacheDir,
+		cacheMaxSize:        cfg.CacheMaxSize,
+		checkTimeout:        cfg.CheckTimeout,
+		checkTimeouts:       cfg.CheckTimeouts,
+	}
+
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	return c, nil
 }
 
 // CraneEngine implements a certification.CheckEngine, and leverage crane to interact with
@@ -94,6 +151,44 @@ type craneEngine struct {
 	// tempDir is optional. If empty, will use an OS tmp dir.
 	tempDir string
 
+	// checkParallelism is the maximum number of checks executed at the
+	// same time. Values less than 1 run checks sequentially.
+	checkParallelism int
+
+	// localImageReference optionally overrides the registry, repository and
+	// tag recorded for the image under test. It is primarily used when the
+	// image is loaded from an OCI layout or docker archive.
+	localImageReference string
+
+	// cacheDir is optional. If set, image layers are cached there and reused
+	// by later runs. Otherwise, layers are cached in the temp directory for
+	// the duration of the run.
+	cacheDir string
+
+	// cacheMaxSize is the size in bytes the persistent cache is pruned to
+	// after a run. Values less than 1 disable pruning.
+	cacheMaxSize int64
+
+	// checkTimeout bounds how long each check may run. Zero means checks are
+	// not bounded.
+	checkTimeout time.Duration
+
+	// checkTimeouts overrides checkTimeout for the checks it names.
+	checkTimeouts map[string]time.Duration
+
+	// observer is optional. If set, it is notified as the run progresses.
+	observer *lockedObserver
+
+	// previous is optional. If set, only the checks that did not pass in the
+	// previous run are executed.
+	previous *certification.PreviousResults
+
+	// waivers is optional. Failures they match are reported as warnings.
+	waivers []waiver.Waiver
+
+	// sbomFormats are the formats an SBOM of the image is written in.
+	sbomFormats []sbom.Format
+
 	imageRef image.ImageReference
 	results  certification.Results
 }
@@ -112,7 +207,15 @@ func (c *craneEngine) CraneInsecure() bool {
 
 var _ option.CraneConfig = &craneEngine{}
 
+// ExecuteChecks pulls and extracts the image, then runs the checks against
+// it. The observer, if any, is notified of each step.
 func (c *craneEngine) ExecuteChecks(ctx context.Context) error {
+	err := c.executeChecks(ctx)
+	c.notify(ctx, certification.RunFinished{Results: c.results, Err: err})
+	return err
+}
+
+func (c *craneEngine) executeChecks(ctx context.Context) error {
 	logger := logr.FromContextOrDiscard(ctx)
 	logger.Info("target image", "image", c.image)
 
@@ -136,20 +239,29 @@ func (c *craneEngine) ExecuteChecks(ctx context.Context) error {
 		}()
 	}
 
-	imageTarPath := path.Join(tempdir, "cache")
-	if err := os.MkdirAll(imageTarPath, 0o755); err != nil && !errors.Is(err, fs.ErrExist) {
-		//coverage:ignore
-		return fmt.Errorf("failed to create cache directory: %s: %v", imageTarPath, err)
+	layerCache, err := c.layerCache(ctx, tempdir)
+	if err != nil {
+		return err
+	}
+	if lc, ok := layerCache.(*layercache.Cache); ok && c.cacheMaxSize > 0 {
+		defer c.pruneCache(ctx, lc)
 	}
 
-	// pull the image manifest
-	logger.V(log.DBG).Info("pulling image from target registry")
-	options := option.GenerateCraneOptions(ctx, c)
-	img, err := crane.Pull(c.image, options...)
+	// pull the image manifest, or load it from disk
+	src, err := c.loadImage(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to pull remote container: %v", err)
+		return err
 	}
-	img = cache.Image(img, cache.NewFilesystemCache(imageTarPath))
+	img := src.img
+	digest, err := img.Digest()
+	if err == nil {
+		c.results.ImageDigest = digest.String()
+		c.notify(ctx, certification.ImagePulled{Image: c.image, Digest: digest.String()})
+	}
+	if c.previous != nil && c.previous.ImageDigest != c.results.ImageDigest {
+		return fmt.Errorf("%w: previous results are for %s, but %s resolves to %s", preflighterr.ErrImageDigestChanged, c.previous.ImageDigest, c.image, c.results.ImageDigest)
+	}
+	img = cache.Image(img, layerCache)
 
 	containerFSPath := path.Join(tempdir, "fs")
 	if err := os.MkdirAll(containerFSPath, 0o755); err != nil && !os.IsExist(err) {
@@ -164,6 +276,10 @@ func (c *craneEngine) ExecuteChecks(ctx context.Context) error {
 
 	requiredFilePatterns := make([]string, 0, requiredFilePatternsCount)
 	for _, check := range c.checks {
+		if _, ok := c.previousOutcome(check); ok {
+			// The check will not be executed, so its files are not needed.
+			continue
+		}
 		requiredFilePatterns = append(requiredFilePatterns, check.RequiredFilePatterns()...)
 	}
 	for i, pattern := range requiredFilePatterns {
@@ -171,28 +287,35 @@ func (c *craneEngine) ExecuteChecks(ctx context.Context) error {
 		requiredFilePatterns[i] = strings.TrimLeft(pattern, "/")
 	}
 
+	// The inventory is collected for every container image, so its files
+	// are always extracted. Bundles only contain manifests.
+	if !c.isBundle {
+		var imageConfig v1.Config
+		if configFile, err := img.ConfigFile(); err == nil {
+			imageConfig = configFile.Config
+		}
+		requiredFilePatterns = append(requiredFilePatterns, inventory.RequiredFilePatterns(imageConfig)...)
+	}
+
 	slices.Sort(requiredFilePatterns)
 	requiredFilePatterns = slices.Compact(requiredFilePatterns)
 
+	extractStartTime := time.Now()
 	if err := untar(ctx, containerFSPath, img, requiredFilePatterns); err != nil {
 		return err
 	}
-
-	reference, err := name.ParseReference(c.image)
-	if err != nil {
-		//coverage:ignore
-		return fmt.Errorf("image uri could not be parsed: %v", err)
-	}
+	c.notify(ctx, certification.ExtractionDone{Path: containerFSPath, ElapsedTime: time.Since(extractStartTime)})
 
 	// store the image internals in the engine image reference to pass to validations.
 	c.imageRef = image.ImageReference{
 		ImageURI:           c.image,
 		ImageFSPath:        containerFSPath,
 		ImageInfo:          img,
-		ImageRegistry:      reference.Context().RegistryStr(),
-		ImageRepository:    reference.Context().RepositoryStr(),
-		ImageTagOrSha:      reference.Identifier(),
+		ImageRegistry:      src.registry,
+		ImageRepository:    src.repository,
+		ImageTagOrSha:      src.identifier,
 		ManifestListDigest: c.manifestListDigest,
+		Local:              src.local,
 	}
 
 	if err := writeCertImage(ctx, c.imageRef); err != nil {
@@ -200,13 +323,30 @@ func (c *craneEngine) ExecuteChecks(ctx context.Context) error {
 		return fmt.Errorf("could not write cert image: %v", err)
 	}
 
+	var pkgList []*rpmdb.PackageInfo
 	if !c.isScratch {
-		if err := writeRPMManifest(ctx, containerFSPath); err != nil {
+		pkgList, err = rpm.GetPackageList(ctx, containerFSPath)
+		if err != nil {
+			logger.Error(err, "could not get rpm list, continuing without it")
+		}
+		if err := writeRPMManifest(ctx, pkgList); err != nil {
 			//coverage:ignore
 			return fmt.Errorf("could not write rpm manifest: %v", err)
 		}
 	}
 
+	if !c.isBundle {
+		if err := writeInventory(ctx, &c.imageRef); err != nil {
+			//coverage:ignore
+			return fmt.Errorf("could not write inventory: %v", err)
+		}
+	}
+
+	if err := c.writeSBOMs(ctx, pkgList); err != nil {
+		//coverage:ignore
+		return fmt.Errorf("could not write sbom: %v", err)
+	}
+
 	if c.isBundle {
 		// Record test cluster version
 		version, err := openshift.GetOpenshiftClusterVersion(ctx, c.kubeconfig)
@@ -220,46 +360,26 @@ func (c *craneEngine) ExecuteChecks(ctx context.Context) error {
 	}
 
 	// execute checks
-	logger.V(log.DBG).Info("executing checks")
-	for _, executedCheck := range c.checks {
-		logger := logger.WithValues("check", executedCheck.Name())
-		ctx := logr.NewContext(ctx, logger)
-		c.results.TestedImage = c.image
-
-		logger.V(log.DBG).Info("running check")
-		if executedCheck.Metadata().Level == check.LevelOptional || executedCheck.Metadata().Level == check.LevelWarn {
-			logger.Info(fmt.Sprintf("Check %s is not currently being enforced.", executedCheck.Name()))
-		}
-
-		// run the validation
-		checkStartTime := time.Now()
-		checkPassed, err := executedCheck.Validate(ctx, c.imageRef)
-		checkElapsedTime := time.Since(checkStartTime)
-
-		if err != nil {
-			logger.WithValues("result", "ERROR", "err", err.Error()).Info("check completed")
-			result := certification.Result{Check: executedCheck, ElapsedTime: checkElapsedTime}
-			c.results.Errors = appendUnlessOptional(c.results.Errors, *result.WithError(err))
-			continue
-		}
-
-		if !checkPassed {
-			// if a test doesn't pass but is of level warn include it in warning results, instead of failed results
-			if executedCheck.Metadata().Level == check.LevelWarn {
-				logger.WithValues("result", "WARNING").Info("check completed")
-				c.results.Warned = appendUnlessOptional(c.results.Warned, certification.Result{Check: executedCheck, ElapsedTime: checkElapsedTime})
-				continue
-			}
-			logger.WithValues("result", "FAILED").Info("check completed")
-			c.results.Failed = appendUnlessOptional(c.results.Failed, certification.Result{Check: executedCheck, ElapsedTime: checkElapsedTime})
-			continue
+	logger.V(log.DBG).Info("executing checks", "parallelism", c.parallelism())
+	c.results.TestedImage = c.image
+	for _, executed := range c.runChecks(ctx) {
+		switch executed.outcome {
+		case outcomeErrored:
+			c.results.Errors = appendUnlessOptional(c.results.Errors, executed.result)
+		case outcomeFailed:
+			c.results.Failed = appendUnlessOptional(c.results.Failed, executed.result)
+		case outcomeWarned:
+			c.results.Warned = appendUnlessOptional(c.results.Warned, executed.result)
+		case outcomePassed:
+			c.results.Passed = appendUnlessOptional(c.results.Passed, executed.result)
+		case outcomeSkipped:
+			c.results.Skipped = appendUnlessOptional(c.results.Skipped, executed.result)
+		case outcomeTimedOut:
+			c.results.TimedOut = appendUnlessOptional(c.results.TimedOut, executed.result)
 		}
-
-		logger.WithValues("result", "PASSED").Info("check completed")
-		c.results.Passed = appendUnlessOptional(c.results.Passed, certification.Result{Check: executedCheck, ElapsedTime: checkElapsedTime})
 	}
 
-	if len(c.results.Errors) > 0 || len(c.results.Failed) > 0 {
+	if len(c.results.Errors) > 0 || len(c.results.Failed) > 0 || len(c.results.TimedOut) > 0 {
 		c.results.PassedOverall = false
 	} else {
 		//coverage:ignore
@@ -294,6 +414,271 @@ func (c *craneEngine) ExecuteChecks(ctx context.Context) error {
 	return nil
 }
 
+// checkOutcome is the bucket of certification.Results a check execution
+// belongs in.
+type checkOutcome int
+
+const (
+	outcomePassed checkOutcome = iota
+	outcomeFailed
+	outcomeWarned
+	outcomeErrored
+	outcomeSkipped
+	outcomeTimedOut
+)
+
+// status returns the certification.CheckStatus corresponding to o.
+func (o checkOutcome) status() certification.CheckStatus {
+	switch o {
+	case outcomeFailed:
+		return certification.StatusFailed
+	case outcomeWarned:
+		return certification.StatusWarned
+	case outcomeErrored:
+		return certification.StatusErrored
+	case outcomeSkipped:
+		return certification.StatusSkipped
+	case outcomeTimedOut:
+		return certification.StatusTimedOut
+	default:
+		return certification.StatusPassed
+	}
+}
+
+// executedCheck pairs the result of a single check execution with its outcome.
+type executedCheck struct {
+	result  certification.Result
+	outcome checkOutcome
+}
+
+// layerCache returns the cache image layers are read through. When a cache
+// directory is configured, the persistent cache is used.
+func (c *craneEngine) layerCache(ctx context.Context, tempdir string) (cache.Cache, error) {
+	if c.cacheDir == "" {
+		imageTarPath := path.Join(tempdir, "cache")
+		if err := os.MkdirAll(imageTarPath, 0o755); err != nil && !errors.Is(err, fs.ErrExist) {
+			//coverage:ignore
+			return nil, fmt.Errorf("failed to create cache directory: %s: %v", imageTarPath, err)
+		}
+		return cache.NewFilesystemCache(imageTarPath), nil
+	}
+
+	logger := logr.FromContextOrDiscard(ctx)
+	logger.V(log.DBG).Info("using persistent layer cache", "path", c.cacheDir)
+	lc, err := layercache.New(c.cacheDir)
+	if err != nil {
+		return nil, err
+	}
+
+	return lc, nil
+}
+
+// pruneCache evicts the least recently used layers from the persistent cache
+// until it fits within the configured size. Failing to prune does not fail
+// the run.
+func (c *craneEngine) pruneCache(ctx context.Context, lc *layercache.Cache) {
+	logger := logr.FromContextOrDiscard(ctx)
+	evicted, err := lc.Prune(c.cacheMaxSize)
+	if err != nil {
+		//coverage:ignore
+		logger.Error(err, "unable to prune layer cache", "path", lc.Dir())
+		return
+	}
+	logger.V(log.DBG).Info("pruned layer cache", "path", lc.Dir(), "evicted", len(evicted))
+}
+
+// parallelism returns the number of checks that may be executed at the same time.
+func (c *craneEngine) parallelism() int {
+	if c.checkParallelism < 1 {
+		return 1
+	}
+	return c.checkParallelism
+}
+
+// runChecks executes all checks using a bounded pool of workers. The returned
+// slice is in the same order as c.checks, regardless of the order in which the
+// checks completed.
+func (c *craneEngine) runChecks(ctx context.Context) []executedCheck {
+	executed := make([]executedCheck, len(c.checks))
+
+	sem := make(chan struct{}, c.parallelism())
+	var wg sync.WaitGroup
+	for i, chk := range c.checks {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			c.notify(ctx, certification.CheckStarted{Name: chk.Name()})
+			executed[i] = c.runCheck(ctx, chk)
+			c.notify(ctx, certification.CheckFinished{Result: executed[i].result, Status: executed[i].outcome.status()})
+		}()
+	}
+	wg.Wait()
+
+	return executed
+}
+
+// runCheck executes a single check with a logger scoped to that check.
+func (c *craneEngine) runCheck(ctx context.Context, chk check.Check) executedCheck {
+	logger := logr.FromContextOrDiscard(ctx).WithValues("check", chk.Name())
+	ctx = logr.NewContext(ctx, logger)
+
+	if executed, ok := c.previousOutcome(chk); ok {
+		logger.WithValues("result", strings.ToUpper(string(executed.outcome.status()))).Info("check carried over from previous results")
+		return executed
+	}
+
+	logger.V(log.DBG).Info("running check")
+	if chk.Metadata().Level == check.LevelOptional || chk.Metadata().Level == check.LevelWarn {
+		logger.Info(fmt.Sprintf("Check %s is not currently being enforced.", chk.Name()))
+	}
+
+	// run the validation
+	timeout := c.timeoutFor(chk.Name())
+	checkStartTime := time.Now()
+	checkPassed, findings, err := c.validate(ctx, chk, timeout)
+	checkElapsedTime := time.Since(checkStartTime)
+
+	result := certification.Result{Check: chk, ElapsedTime: checkElapsedTime, Findings: findings}
+
+	if errors.Is(err, check.ErrCheckTimedOut) {
+		logger.WithValues("result", "TIMED OUT", "timeout", timeout.String()).Info("check completed")
+		return executedCheck{result: *result.WithError(err), outcome: outcomeTimedOut}
+	}
+
+	if errors.Is(err, check.ErrCheckSkipped) {
+		logger.WithValues("result", "SKIPPED", "reason", err.Error()).Info("check completed")
+		return executedCheck{result: *result.WithError(err), outcome: outcomeSkipped}
+	}
+
+	if err != nil {
+		logger.WithValues("result", "ERROR", "err", err.Error()).Info("check completed")
+		return executedCheck{result: *result.WithError(err), outcome: outcomeErrored}
+	}
+
+	if !checkPassed {
+		// if a test doesn't pass but is of level warn include it in warning results, instead of failed results
+		if chk.Metadata().Level == check.LevelWarn {
+			logger.WithValues("result", "WARNING").Info("check completed")
+			return executedCheck{result: result, outcome: outcomeWarned}
+		}
+		now := time.Now()
+		if w, ok := waiver.Lookup(c.waivers, now, chk.Name(), c.waiverImages()...); ok {
+			result.Waiver = &w
+			if !w.Expired(now) {
+				logger.WithValues("result", "WAIVED", "expires", w.Expires.String(), "justification", w.Justification).Info("check completed")
+				return executedCheck{result: result, outcome: outcomeWarned}
+			}
+			result.WaiverExpired = true
+			logger.Error(fmt.Errorf("the waiver for check %s expired on %s", chk.Name(), w.Expires), "waiver has expired and no longer applies, the failure stands", "justification", w.Justification)
+		}
+		logger.WithValues("result", "FAILED").Info("check completed")
+		return executedCheck{result: result, outcome: outcomeFailed}
+	}
+
+	logger.WithValues("result", "PASSED").Info("check completed")
+	return executedCheck{result: result, outcome: outcomePassed}
+}
+
+// waiverImages returns the references a waiver's image pattern is matched
+// against: the image as provided, and its registry and repository.
+func (c *craneEngine) waiverImages() []string {
+	return []string{c.image, path.Join(c.imageRef.ImageRegistry, c.imageRef.ImageRepository)}
+}
+
+// previousOutcome returns the outcome recorded for chk by the previous run, if
+// previous results were provided and chk does not need to be executed again.
+func (c *craneEngine) previousOutcome(chk check.Check) (executedCheck, bool) {
+	if c.previous == nil || c.previous.NeedsRerun(chk.Name()) {
+		return executedCheck{}, false
+	}
+
+	prev := c.previous.Checks[chk.Name()]
+	result := certification.Result{Check: chk, ElapsedTime: prev.ElapsedTime, Findings: prev.Findings}
+	switch prev.Status {
+	case certification.StatusSkipped:
+		err := check.ErrCheckSkipped
+		if prev.Reason != "" {
+			err = errors.New(prev.Reason)
+		}
+		return executedCheck{result: *result.WithError(err), outcome: outcomeSkipped}, true
+	case certification.StatusWarned:
+		return executedCheck{result: result, outcome: outcomeWarned}, true
+	default:
+		return executedCheck{result: result, outcome: outcomePassed}, true
+	}
+}
+
+// timeoutFor returns the deadline for the check named name. A timeout
+// configured for the check takes precedence over the global timeout. Zero
+// means the check is not bounded. Names are matched case-insensitively,
+// since keys read from config files are lower-cased.
+func (c *craneEngine) timeoutFor(name string) time.Duration {
+	for checkName, timeout := range c.checkTimeouts {
+		if strings.EqualFold(checkName, name) {
+			return timeout
+		}
+	}
+	return c.checkTimeout
+}
+
+// validate runs chk, collecting its findings if it reports any. If timeout is set, the context passed
+// to the check is cancelled with check.ErrCheckTimedOut as its cause once the
+// timeout elapses. Checks that do not return promptly after their context is
+// cancelled are abandoned, so that they do not block the rest of the run.
+func (c *craneEngine) validate(ctx context.Context, chk check.Check, timeout time.Duration) (bool, []check.Finding, error) {
+	if timeout <= 0 {
+		return check.Validate(ctx, chk, c.imageRef)
+	}
+
+	ctx, cancel := context.WithTimeoutCause(ctx, timeout, check.ErrCheckTimedOut)
+	defer cancel()
+
+	type validation struct {
+		passed   bool
+		findings []check.Finding
+		err      error
+	}
+	done := make(chan validation, 1)
+	go func() {
+		passed, findings, err := check.Validate(ctx, chk, c.imageRef)
+		done <- validation{passed: passed, findings: findings, err: err}
+	}()
+
+	select {
+	case v := <-done:
+		if v.err != nil && errors.Is(context.Cause(ctx), check.ErrCheckTimedOut) {
+			return false, v.findings, fmt.Errorf("%w after %s: %v", check.ErrCheckTimedOut, timeout, v.err)
+		}
+		return v.passed, v.findings, v.err
+	case <-ctx.Done():
+		if errors.Is(context.Cause(ctx), check.ErrCheckTimedOut) {
+			return false, nil, fmt.Errorf("%w after %s", check.ErrCheckTimedOut, timeout)
+		}
+		return false, nil, context.Cause(ctx)
+	}
+}
+
+// lockedObserver serializes calls to an observer, since checks are executed
+// concurrently.
+type lockedObserver struct {
+	mu       sync.Mutex
+	observer certification.Observer
+}
+
+// notify sends event to the observer, if one is registered.
+func (c *craneEngine) notify(ctx context.Context, event certification.Event) {
+	if c.observer == nil {
+		return
+	}
+	c.observer.mu.Lock()
+	defer c.observer.mu.Unlock()
+	c.observer.observer.Observe(ctx, event)
+}
+
 func appendUnlessOptional(results []certification.Result, result certification.Result) []certification.Result {
 	if result.Check.Metadata().Level == "optional" {
 		return results
@@ -532,12 +917,8 @@ func getBgName(srcrpm string) string {
 	return strings.Join(parts[0:len(parts)-2], "-")
 }
 
-func writeRPMManifest(ctx context.Context, containerFSPath string) error {
+func writeRPMManifest(ctx context.Context, pkgList []*rpmdb.PackageInfo) error {
 	logger := logr.FromContextOrDiscard(ctx)
-	pkgList, err := rpm.GetPackageList(ctx, containerFSPath)
-	if err != nil {
-		logger.Error(err, "could not get rpm list, continuing without it")
-	}
 
 	// covert rpm struct to pxyis struct
 	rpms := convertToRPMs(ctx, pkgList)
@@ -566,6 +947,85 @@ func writeRPMManifest(ctx context.Context, containerFSPath string) error {
 	return nil
 }
 
+// writeInventory collects the software in imageRef that is not installed with
+// RPM, stores it in imageRef for checks to consume, and writes it to the
+// inventory artifact.
+func writeInventory(ctx context.Context, imageRef *image.ImageReference) error {
+	logger := logr.FromContextOrDiscard(ctx)
+	layers, err := imageRef.ImageInfo.Layers()
+	if err != nil {
+		//coverage:ignore
+		return fmt.Errorf("could not read the image layers: %w", err)
+	}
+	inv, err := inventory.Collect(ctx, imageRef.ImageFSPath, layers)
+	if err != nil {
+		return err
+	}
+	imageRef.Inventory = inv
+
+	// calling MarshalIndent so the json file written to disk is human-readable when opened
+	inventoryJSON, err := json.MarshalIndent(inv, "", "    ")
+	if err != nil {
+		//coverage:ignore
+		return fmt.Errorf("could not marshal inventory: %w", err)
+	}
+
+	if artifactWriter := artifacts.WriterFromContext(ctx); artifactWriter != nil {
+		fileName, err := artifactWriter.WriteFile(check.DefaultInventoryFilename, bytes.NewReader(inventoryJSON))
+		if err != nil {
+			//coverage:ignore
+			return fmt.Errorf("failed to save file to artifacts directory: %w", err)
+		}
+
+		logger.V(log.TRC).Info("inventory written to disk", "filename", fileName)
+	}
+
+	return nil
+}
+
+// writeSBOMs writes an SBOM of the image, its RPMs pkgList and its inventory,
+// in each of the formats the engine is configured with.
+func (c *craneEngine) writeSBOMs(ctx context.Context, pkgList []*rpmdb.PackageInfo) error {
+	logger := logr.FromContextOrDiscard(ctx)
+	artifactWriter := artifacts.WriterFromContext(ctx)
+	if len(c.sbomFormats) == 0 || artifactWriter == nil {
+		return nil
+	}
+
+	img := sbom.Image{
+		Reference: c.image,
+		Digest:    c.results.ImageDigest,
+		Packages:  pkgList,
+		Inventory: c.imageRef.Inventory,
+		Created:   time.Now(),
+	}
+	if !c.imageRef.Local {
+		img.Repository = path.Join(c.imageRef.ImageRegistry, c.imageRef.ImageRepository)
+	}
+	if configFile, err := c.imageRef.ImageInfo.ConfigFile(); err == nil {
+		img.Architecture = configFile.Architecture
+	}
+
+	for _, format := range c.sbomFormats {
+		b, err := sbom.Generate(format, img)
+		if err != nil {
+			//coverage:ignore
+			return err
+		}
+		filename := check.DefaultSPDXFilename
+		if format == sbom.FormatCycloneDX {
+			filename = check.DefaultCycloneDXFilename
+		}
+		fileName, err := artifactWriter.WriteFile(filename, bytes.NewReader(b))
+		if err != nil {
+			//coverage:ignore
+			return fmt.Errorf("failed to save file to artifacts directory: %w", err)
+		}
+		logger.V(log.TRC).Info("sbom written to disk", "filename", fileName, "format", format)
+	}
+	return nil
+}
+
 // convertToRPMs converts a list of rpmdb.PackageInfo to a list of pyxis.RPM structs.
 func convertToRPMs(ctx context.Context, pkgList []*rpmdb.PackageInfo) []pyxis.RPM {
 	logger := logr.FromContextOrDiscard(ctx)
@@ -644,137 +1104,158 @@ type OperatorCheckConfig struct {
 	Kubeconfig                                                                     []byte
 	CSVTimeout                                                                     time.Duration
 	SubscriptionTimeout                                                            time.Duration
+	// Policies are user-defined policies that may be initialized in
+	// addition to Red Hat's.
+	Policies []policy.Definition
+}
+
+// operatorChecks constructs each check an operator policy may include, by name.
+var operatorChecks = map[string]func(cfg OperatorCheckConfig) check.Check{
+	"ScorecardBasicSpecCheck": func(cfg OperatorCheckConfig) check.Check {
+		return operatorpol.NewScorecardBasicSpecCheck(operatorsdk.New(cfg.ScorecardImage, exec.Command), cfg.ScorecardNamespace, cfg.ScorecardServiceAccount, cfg.Kubeconfig, cfg.ScorecardWaitTime)
+	},
+	"ScorecardOlmSuiteCheck": func(cfg OperatorCheckConfig) check.Check {
+		return operatorpol.NewScorecardOlmSuiteCheck(operatorsdk.New(cfg.ScorecardImage, exec.Command), cfg.ScorecardNamespace, cfg.ScorecardServiceAccount, cfg.Kubeconfig, cfg.ScorecardWaitTime)
+	},
+	"DeployableByOLM": func(cfg OperatorCheckConfig) check.Check {
+		return operatorpol.NewDeployableByOlmCheck(cfg.IndexImage, cfg.DockerConfig, cfg.Channel, operatorpol.WithCSVTimeout(cfg.CSVTimeout), operatorpol.WithSubscriptionTimeout(cfg.SubscriptionTimeout))
+	},
+	"ValidateOperatorBundle": func(OperatorCheckConfig) check.Check {
+		return operatorpol.NewValidateOperatorBundleCheck()
+	},
+	"BundleImageRefsAreCertified": func(OperatorCheckConfig) check.Check {
+		return operatorpol.NewCertifiedImagesCheck(pyxis.NewPyxisClient(
+			check.DefaultPyxisHost,
+			"",
+			"",
+			&http.Client{Timeout: 60 * time.Second}),
+		)
+	},
+	"SecurityContextConstraintsInCSV": func(OperatorCheckConfig) check.Check {
+		return operatorpol.NewSecurityContextConstraintsCheck()
+	},
+	"AllImageRefsInRelatedImages": func(OperatorCheckConfig) check.Check {
+		return &operatorpol.RelatedImagesCheck{}
+	},
+	"FollowsRestrictedNetworkEnablementGuidelines": func(OperatorCheckConfig) check.Check {
+		return operatorpol.FollowsRestrictedNetworkEnablementGuidelines{}
+	},
+	"RequiredAnnotations": func(OperatorCheckConfig) check.Check {
+		return operatorpol.RequiredAnnotations{}
+	},
 }
 
 // InitializeOperatorChecks returns opeartor checks for policy p give cfg.
 func InitializeOperatorChecks(ctx context.Context, p policy.Policy, cfg OperatorCheckConfig) ([]check.Check, error) {
-	switch p {
-	case policy.PolicyOperator:
-		return []check.Check{
-			operatorpol.NewScorecardBasicSpecCheck(operatorsdk.New(cfg.ScorecardImage, exec.Command), cfg.ScorecardNamespace, cfg.ScorecardServiceAccount, cfg.Kubeconfig, cfg.ScorecardWaitTime),
-			operatorpol.NewScorecardOlmSuiteCheck(operatorsdk.New(cfg.ScorecardImage, exec.Command), cfg.ScorecardNamespace, cfg.ScorecardServiceAccount, cfg.Kubeconfig, cfg.ScorecardWaitTime),
-			operatorpol.NewDeployableByOlmCheck(cfg.IndexImage, cfg.DockerConfig, cfg.Channel, operatorpol.WithCSVTimeout(cfg.CSVTimeout), operatorpol.WithSubscriptionTimeout(cfg.SubscriptionTimeout)),
-			operatorpol.NewValidateOperatorBundleCheck(),
-			operatorpol.NewCertifiedImagesCheck(pyxis.NewPyxisClient(
-				check.DefaultPyxisHost,
-				"",
-				"",
-				&http.Client{Timeout: 60 * time.Second}),
-			),
-			operatorpol.NewSecurityContextConstraintsCheck(),
-			&operatorpol.RelatedImagesCheck{},
-			operatorpol.FollowsRestrictedNetworkEnablementGuidelines{},
-			operatorpol.RequiredAnnotations{},
-		}, nil
-	}
-
-	return nil, fmt.Errorf("provided operator policy %s is unknown", p)
+	return initializeChecks(p, policy.KindOperator, cfg.Policies, operatorChecks, cfg)
 }
 
 // ContainerCheckConfig contains configuration relevant to an individual check's execution.
 type ContainerCheckConfig struct {
 	DockerConfig, PyxisAPIToken, CertificationProjectID, PyxisHost string
+	// Policies are user-defined policies that may be initialized in
+	// addition to Red Hat's.
+	Policies []policy.Definition
+	// LicenseRules restrict the licenses HasLicense permits.
+	LicenseRules license.Rules
+}
+
+// containerChecks constructs each check a container policy may include, by name.
+var containerChecks = map[string]func(cfg ContainerCheckConfig) check.Check{
+	"HasLicense": func(cfg ContainerCheckConfig) check.Check {
+		return containerpol.NewHasLicenseCheck(cfg.LicenseRules)
+	},
+	"HasUniqueTag": func(cfg ContainerCheckConfig) check.Check {
+		return containerpol.NewHasUniqueTagCheck(cfg.DockerConfig)
+	},
+	"LayerCountAcceptable": func(ContainerCheckConfig) check.Check {
+		return &containerpol.MaxLayersCheck{}
+	},
+	"HasNoProhibitedPackages": func(ContainerCheckConfig) check.Check {
+		return containerpol.NewHasNoProhibitedPackagesCheck()
+	},
+	"HasRequiredLabel": func(ContainerCheckConfig) check.Check {
+		return &containerpol.HasRequiredLabelsCheck{}
+	},
+	"HasNoProhibitedLabels": func(ContainerCheckConfig) check.Check {
+		return &containerpol.HasNoProhibitedLabelsCheck{}
+	},
+	"RunAsNonRoot": func(ContainerCheckConfig) check.Check {
+		return &containerpol.RunAsNonRootCheck{}
+	},
+	"HasModifiedFiles": func(ContainerCheckConfig) check.Check {
+		return &containerpol.HasModifiedFilesCheck{}
+	},
+	"BasedOnUbi": func(cfg ContainerCheckConfig) check.Check {
+		return containerpol.NewBasedOnUbiCheck(pyxis.NewPyxisClient(
+			cfg.PyxisHost,
+			cfg.PyxisAPIToken,
+			cfg.CertificationProjectID,
+			&http.Client{Timeout: 60 * time.Second}))
+	},
+	"HasProhibitedContainerName": func(ContainerCheckConfig) check.Check {
+		return &containerpol.HasProhibitedContainerName{}
+	},
 }
 
 // InitializeContainerChecks returns the appropriate checks for policy p given cfg.
 func InitializeContainerChecks(ctx context.Context, p policy.Policy, cfg ContainerCheckConfig) ([]check.Check, error) {
-	switch p {
-	case policy.PolicyContainer:
-		return []check.Check{
-			&containerpol.HasLicenseCheck{},
-			containerpol.NewHasUniqueTagCheck(cfg.DockerConfig),
-			&containerpol.MaxLayersCheck{},
-			containerpol.NewHasNoProhibitedPackagesCheck(),
-			&containerpol.HasRequiredLabelsCheck{},
-			&containerpol.HasNoProhibitedLabelsCheck{},
-			&containerpol.RunAsNonRootCheck{},
-			&containerpol.HasModifiedFilesCheck{},
-			containerpol.NewBasedOnUbiCheck(pyxis.NewPyxisClient(
-				cfg.PyxisHost,
-				cfg.PyxisAPIToken,
-				cfg.CertificationProjectID,
-				&http.Client{Timeout: 60 * time.Second})),
-			&containerpol.HasProhibitedContainerName{},
-		}, nil
-	case policy.PolicyRoot:
-		return []check.Check{
-			&containerpol.HasLicenseCheck{},
-			containerpol.NewHasUniqueTagCheck(cfg.DockerConfig),
-			&containerpol.MaxLayersCheck{},
-			containerpol.NewHasNoProhibitedPackagesCheck(),
-			&containerpol.HasRequiredLabelsCheck{},
-			&containerpol.HasNoProhibitedLabelsCheck{},
-			&containerpol.HasModifiedFilesCheck{},
-			containerpol.NewBasedOnUbiCheck(pyxis.NewPyxisClient(
-				cfg.PyxisHost,
-				cfg.PyxisAPIToken,
-				cfg.CertificationProjectID,
-				&http.Client{Timeout: 60 * time.Second})),
-			&containerpol.HasProhibitedContainerName{},
-		}, nil
-	case policy.PolicyScratchNonRoot:
-		return []check.Check{
-			&containerpol.HasLicenseCheck{},
-			containerpol.NewHasUniqueTagCheck(cfg.DockerConfig),
-			&containerpol.MaxLayersCheck{},
-			&containerpol.HasRequiredLabelsCheck{},
-			&containerpol.HasNoProhibitedLabelsCheck{},
-			&containerpol.RunAsNonRootCheck{},
-			&containerpol.HasProhibitedContainerName{},
-		}, nil
-	case policy.PolicyScratchRoot:
-		return []check.Check{
-			&containerpol.HasLicenseCheck{},
-			containerpol.NewHasUniqueTagCheck(cfg.DockerConfig),
-			&containerpol.MaxLayersCheck{},
-			&containerpol.HasRequiredLabelsCheck{},
-			&containerpol.HasNoProhibitedLabelsCheck{},
-			&containerpol.HasProhibitedContainerName{},
-		}, nil
-	case policy.PolicyKonflux:
-		return []check.Check{
-			&containerpol.HasLicenseCheck{},
-			containerpol.NewHasUniqueTagCheck(cfg.DockerConfig),
-			&containerpol.MaxLayersCheck{},
-			containerpol.NewHasNoProhibitedPackagesCheck(),
-			&containerpol.HasRequiredLabelsCheck{},
-			&containerpol.RunAsNonRootCheck{},
-			&containerpol.HasModifiedFilesCheck{},
-			containerpol.NewBasedOnUbiCheck(pyxis.NewPyxisClient(
-				cfg.PyxisHost,
-				cfg.PyxisAPIToken,
-				cfg.CertificationProjectID,
-				&http.Client{Timeout: 60 * time.Second})),
-		}, nil
-	}
-
-	return nil, fmt.Errorf("provided container policy %s is unknown", p)
-}
-
-// makeCheckList returns a list of check names.
-func makeCheckList(checks []check.Check) []string {
-	checkNames := make([]string, len(checks))
-
-	for i, check := range checks {
-		checkNames[i] = check.Name()
-	}
-
-	return checkNames
+	if err := cfg.LicenseRules.Validate(); err != nil {
+		return nil, err
+	}
+	return initializeChecks(p, policy.KindContainer, cfg.Policies, containerChecks, cfg)
+}
+
+// initializeChecks resolves the checks of policy p, which must be of the
+// given kind, and constructs each with cfg.
+func initializeChecks[C any](p policy.Policy, kind policy.Kind, definitions []policy.Definition, constructors map[string]func(C) check.Check, cfg C) ([]check.Check, error) {
+	registry, err := policy.DefaultRegistry().With(definitions...)
+	if err != nil {
+		return nil, err
+	}
+
+	if k, err := registry.Kind(p); err != nil || k != kind {
+		return nil, fmt.Errorf("provided %s policy %s is unknown", kind, p)
+	}
+
+	names, err := registry.Checks(p)
+	if err != nil {
+		//coverage:ignore
+		return nil, err
+	}
+
+	checks := make([]check.Check, 0, len(names))
+	for _, name := range names {
+		newCheck, ok := constructors[name]
+		if !ok {
+			return nil, fmt.Errorf("policy %s includes unknown %s check %q", p, kind, name)
+		}
+		checks = append(checks, newCheck(cfg))
+	}
+
+	return checks, nil
+}
+
+// CheckMetadata returns the metadata of the check a policy includes by the
+// name name. It returns false if no such check exists.
+func CheckMetadata(name string) (check.Metadata, bool) {
+	if newCheck, ok := containerChecks[name]; ok {
+		return newCheck(ContainerCheckConfig{}).Metadata(), true
+	}
+	if newCheck, ok := operatorChecks[name]; ok {
+		return newCheck(OperatorCheckConfig{}).Metadata(), true
+	}
+	return check.Metadata{}, false
 }
 
 // checkNamesFor produces a slice of names for checks in the requested policy.
 func checkNamesFor(ctx context.Context, p policy.Policy) []string {
-	var c []check.Check
-	switch p {
-	case policy.PolicyContainer, policy.PolicyRoot, policy.PolicyScratchNonRoot, policy.PolicyScratchRoot, policy.PolicyKonflux:
-		c, _ = InitializeContainerChecks(ctx, p, ContainerCheckConfig{})
-	case policy.PolicyOperator:
-		c, _ = InitializeOperatorChecks(ctx, p, OperatorCheckConfig{})
-	default:
+	names, err := policy.DefaultRegistry().Checks(p)
+	if err != nil {
 		return []string{}
 	}
 
-	return makeCheckList(c)
+	return names
 }
 
 // OperatorPolicy returns the names of checks in the operator policy.
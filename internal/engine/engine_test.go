@@ -11,23 +11,33 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/google/go-containerregistry/pkg/registry"
+	cranev1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
 	"github.com/google/go-containerregistry/pkg/v1/mutate"
 	"github.com/google/go-containerregistry/pkg/v1/random"
 	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
 	"github.com/google/go-containerregistry/pkg/v1/types"
 	rpmdb "github.com/knqyf263/go-rpmdb/pkg"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/certification"
+	preflighterr "github.com/redhat-openshift-ecosystem/openshift-preflight/errors"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/policy"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/runtime"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/sbom"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/waiver"
 )
 
 var _ = Describe("Execute Checks tests", func() {
@@ -160,6 +170,381 @@ var _ = Describe("Execute Checks tests", func() {
 			Expect(engine.results.Errors).To(HaveLen(1))
 			Expect(engine.results.Warned).To(HaveLen(1))
 			Expect(engine.results.CertificationHash).To(BeEmpty())
+			Expect(engine.imageRef.Inventory).ToNot(BeNil())
+		})
+		Context("an observer is registered", func() {
+			var events []certification.Event
+			BeforeEach(func() {
+				events = nil
+				WithObserver(certification.ObserverFunc(func(_ context.Context, e certification.Event) {
+					events = append(events, e)
+				}))(&engine)
+			})
+			It("should notify the observer as the run progresses", func() {
+				err := engine.ExecuteChecks(testcontext)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(events).To(HaveLen(2 + 2*len(engine.checks) + 1))
+				Expect(events[0]).To(BeAssignableToTypeOf(certification.ImagePulled{}))
+				Expect(events[0].(certification.ImagePulled).Image).To(Equal(src))
+				Expect(events[0].(certification.ImagePulled).Digest).To(HavePrefix("sha256:"))
+				Expect(events[1]).To(BeAssignableToTypeOf(certification.ExtractionDone{}))
+				Expect(events[2]).To(Equal(certification.CheckStarted{Name: "testcheck"}))
+				Expect(events[3]).To(BeAssignableToTypeOf(certification.CheckFinished{}))
+				Expect(events[3].(certification.CheckFinished).Status).To(Equal(certification.StatusPassed))
+				Expect(events[5].(certification.CheckFinished).Result.Name()).To(Equal("errorCheck"))
+				Expect(events[5].(certification.CheckFinished).Status).To(Equal(certification.StatusErrored))
+
+				last := events[len(events)-1]
+				Expect(last).To(BeAssignableToTypeOf(certification.RunFinished{}))
+				Expect(last.(certification.RunFinished).Err).ToNot(HaveOccurred())
+				Expect(last.(certification.RunFinished).Results.Failed).To(HaveLen(1))
+			})
+			It("should notify the observer when the run fails", func() {
+				engine.image = "oci:" + filepath.Join(GinkgoT().TempDir(), "missing")
+				err := engine.ExecuteChecks(testcontext)
+				Expect(err).To(HaveOccurred())
+				Expect(events).To(HaveLen(1))
+				Expect(events[0].(certification.RunFinished).Err).To(MatchError(err))
+			})
+			It("should report the status of each outcome", func() {
+				Expect(outcomePassed.status()).To(Equal(certification.StatusPassed))
+				Expect(outcomeFailed.status()).To(Equal(certification.StatusFailed))
+				Expect(outcomeWarned.status()).To(Equal(certification.StatusWarned))
+				Expect(outcomeErrored.status()).To(Equal(certification.StatusErrored))
+				Expect(outcomeSkipped.status()).To(Equal(certification.StatusSkipped))
+				Expect(outcomeTimedOut.status()).To(Equal(certification.StatusTimedOut))
+			})
+		})
+		Context("waivers are provided", func() {
+			var waivers []waiver.Waiver
+			BeforeEach(func() {
+				waivers = []waiver.Waiver{{
+					Check:         "failedCheck",
+					Image:         u.Host + "/test/*",
+					Justification: "granted by our certification contact",
+					Expires:       time.Now().Add(time.Hour),
+				}}
+			})
+			It("should report a waived failure as a warning", func() {
+				WithWaivers(waivers)(&engine)
+				err := engine.ExecuteChecks(testcontext)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(engine.results.Failed).To(BeEmpty())
+				Expect(engine.results.Warned).To(HaveLen(2))
+				Expect(engine.results.Warned[0].Name()).To(Equal("failedCheck"))
+				Expect(engine.results.Warned[0].Waiver).To(Equal(&waivers[0]))
+				Expect(engine.results.Warned[0].IsWaived()).To(BeTrue())
+			})
+			It("should report a failure as failed when its waiver has expired", func() {
+				waivers[0].Expires = time.Now().Add(-time.Hour)
+				WithWaivers(waivers)(&engine)
+				err := engine.ExecuteChecks(testcontext)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(engine.results.Failed).To(HaveLen(1))
+				Expect(engine.results.Failed[0].Waiver).To(Equal(&waivers[0]))
+				Expect(engine.results.Failed[0].WaiverExpired).To(BeTrue())
+				Expect(engine.results.Failed[0].IsWaived()).To(BeFalse())
+			})
+			It("should not waive failures of other images", func() {
+				waivers[0].Image = "quay.io/example/*"
+				WithWaivers(waivers)(&engine)
+				err := engine.ExecuteChecks(testcontext)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(engine.results.Failed).To(HaveLen(1))
+				Expect(engine.results.Failed[0].Waiver).To(BeNil())
+			})
+		})
+		Context("previous results are provided", func() {
+			var previous certification.PreviousResults
+			BeforeEach(func() {
+				digest, err := crane.Digest(src)
+				Expect(err).ToNot(HaveOccurred())
+				previous = certification.PreviousResults{
+					ImageDigest: digest,
+					Checks: map[string]certification.PreviousResult{
+						"testcheck": {
+							Status:      certification.StatusPassed,
+							ElapsedTime: 5 * time.Second,
+							Findings:    []check.Finding{{Message: "carried over", Severity: check.SeverityInfo}},
+						},
+						"failedCheck":          {Status: certification.StatusFailed},
+						"warnCheckFailing":     {Status: certification.StatusWarned},
+						"optionalCheckPassing": {Status: certification.StatusSkipped, Reason: "check skipped: registry required"},
+					},
+				}
+			})
+			It("should only execute the checks that did not pass", func() {
+				WithPreviousResults(previous)(&engine)
+				err := engine.ExecuteChecks(testcontext)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(engine.results.ImageDigest).To(Equal(previous.ImageDigest))
+
+				Expect(engine.results.Passed).To(HaveLen(2))
+				Expect(engine.results.Passed[0].Name()).To(Equal("testcheck"))
+				Expect(engine.results.Passed[0].ElapsedTime).To(Equal(5 * time.Second))
+				Expect(engine.results.Passed[0].Findings).To(Equal(previous.Checks["testcheck"].Findings))
+				Expect(engine.results.Failed).To(HaveLen(1))
+				Expect(engine.results.Errors).To(HaveLen(1))
+				Expect(engine.results.Warned).To(HaveLen(1))
+				Expect(engine.results.Warned[0].Name()).To(Equal("warnCheckFailing"))
+			})
+			It("should refuse to run if the image digest has changed", func() {
+				previous.ImageDigest = "sha256:0000000000000000000000000000000000000000000000000000000000000000"
+				WithPreviousResults(previous)(&engine)
+				err := engine.ExecuteChecks(testcontext)
+				Expect(err).To(MatchError(preflighterr.ErrImageDigestChanged))
+				Expect(engine.results.Passed).To(BeEmpty())
+			})
+			It("should execute waived checks again", func() {
+				previous.Checks["warnCheckFailing"] = certification.PreviousResult{Status: certification.StatusWarned, Waived: true}
+				WithPreviousResults(previous)(&engine)
+				_, ok := engine.previousOutcome(engine.checks[6])
+				Expect(ok).To(BeFalse())
+			})
+			It("should carry over the reason a check was skipped", func() {
+				WithPreviousResults(previous)(&engine)
+				executed, ok := engine.previousOutcome(engine.checks[3])
+				Expect(ok).To(BeTrue())
+				Expect(executed.outcome).To(Equal(outcomeSkipped))
+				Expect(executed.result.Error()).To(MatchError("check skipped: registry required"))
+
+				previous.Checks["optionalCheckPassing"] = certification.PreviousResult{Status: certification.StatusSkipped}
+				WithPreviousResults(previous)(&engine)
+				executed, _ = engine.previousOutcome(engine.checks[3])
+				Expect(executed.result.Error()).To(MatchError(check.ErrCheckSkipped))
+			})
+		})
+		Context("checks are executed in parallel", func() {
+			BeforeEach(func() {
+				slowCheck := func(name string, delay time.Duration) check.Check {
+					return check.NewGenericCheck(
+						name,
+						func(context.Context, image.ImageReference) (bool, error) {
+							time.Sleep(delay)
+							return true, nil
+						},
+						check.Metadata{},
+						check.HelpText{},
+						nil,
+					)
+				}
+				engine.checks = []check.Check{
+					slowCheck("first", 30*time.Millisecond),
+					slowCheck("second", 20*time.Millisecond),
+					slowCheck("third", 10*time.Millisecond),
+					slowCheck("fourth", 0),
+				}
+				engine.checkParallelism = 4
+			})
+			It("should report results in check order", func() {
+				err := engine.ExecuteChecks(testcontext)
+				Expect(err).ToNot(HaveOccurred())
+				names := make([]string, 0, len(engine.results.Passed))
+				for _, r := range engine.results.Passed {
+					names = append(names, r.Name())
+				}
+				Expect(names).To(Equal([]string{"first", "second", "third", "fourth"}))
+				Expect(engine.results.PassedOverall).To(BeTrue())
+			})
+		})
+		Context("SBOM formats are selected", func() {
+			BeforeEach(func() {
+				engine.sbomFormats = []sbom.Format{sbom.FormatSPDX, sbom.FormatCycloneDX}
+			})
+			It("should write an SBOM in each format", func() {
+				err := engine.ExecuteChecks(testcontext)
+				Expect(err).ToNot(HaveOccurred())
+				aw := artifacts.WriterFromContext(testcontext).(*artifacts.FilesystemWriter)
+				for _, filename := range []string{check.DefaultSPDXFilename, check.DefaultCycloneDXFilename} {
+					exists, err := aw.Exists(filename)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(exists).To(BeTrue(), filename)
+				}
+			})
+		})
+		Context("a persistent layer cache is configured", func() {
+			BeforeEach(func() {
+				engine.cacheDir = filepath.Join(GinkgoT().TempDir(), "layers")
+			})
+			It("should keep the image layers after the run", func() {
+				err := engine.ExecuteChecks(testcontext)
+				Expect(err).ToNot(HaveOccurred())
+				files, err := os.ReadDir(engine.cacheDir)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(files).To(HaveLen(5))
+			})
+			It("should prune the cache to its maximum size", func() {
+				engine.cacheMaxSize = 1
+				err := engine.ExecuteChecks(testcontext)
+				Expect(err).ToNot(HaveOccurred())
+				files, err := os.ReadDir(engine.cacheDir)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(files).To(BeEmpty())
+			})
+			It("should fail when the cache directory can not be created", func() {
+				f := filepath.Join(GinkgoT().TempDir(), "file")
+				Expect(os.WriteFile(f, nil, 0o600)).To(Succeed())
+				engine.cacheDir = filepath.Join(f, "layers")
+				err := engine.ExecuteChecks(testcontext)
+				Expect(err).To(HaveOccurred())
+			})
+		})
+		Context("checks have timeouts", func() {
+			var release chan struct{}
+			BeforeEach(func() {
+				release = make(chan struct{})
+				DeferCleanup(func() { close(release) })
+				engine.checks = []check.Check{
+					check.NewGenericCheck(
+						"honorsContext",
+						func(ctx context.Context, _ image.ImageReference) (bool, error) {
+							<-ctx.Done()
+							return false, ctx.Err()
+						},
+						check.Metadata{},
+						check.HelpText{},
+						nil,
+					),
+					check.NewGenericCheck(
+						"ignoresContext",
+						func(context.Context, image.ImageReference) (bool, error) {
+							<-release
+							return true, nil
+						},
+						check.Metadata{},
+						check.HelpText{},
+						nil,
+					),
+					check.NewGenericCheck(
+						"slowButAllowed",
+						func(context.Context, image.ImageReference) (bool, error) {
+							time.Sleep(50 * time.Millisecond)
+							return true, nil
+						},
+						check.Metadata{},
+						check.HelpText{},
+						nil,
+					),
+				}
+				engine.checkTimeout = 10 * time.Millisecond
+				engine.checkTimeouts = map[string]time.Duration{"slowbutallowed": time.Minute}
+			})
+			It("should report checks exceeding their deadline as timed out", func() {
+				err := engine.ExecuteChecks(testcontext)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(engine.results.TimedOut).To(HaveLen(2))
+				Expect(engine.results.TimedOut[0].Name()).To(Equal("honorsContext"))
+				Expect(engine.results.TimedOut[0].Error()).To(MatchError(check.ErrCheckTimedOut))
+				Expect(engine.results.TimedOut[1].Name()).To(Equal("ignoresContext"))
+				Expect(engine.results.TimedOut[1].Error()).To(MatchError(check.ErrCheckTimedOut))
+				Expect(engine.results.Errors).To(BeEmpty())
+				Expect(engine.results.Passed).To(HaveLen(1))
+				Expect(engine.results.PassedOverall).To(BeFalse())
+			})
+			It("should report a check as errored when the run itself is cancelled", func() {
+				ctx, cancel := context.WithCancel(testcontext)
+				cancel()
+				passed, _, err := engine.validate(ctx, engine.checks[1], time.Minute)
+				Expect(err).To(MatchError(context.Canceled))
+				Expect(passed).To(BeFalse())
+			})
+		})
+		Context("a check is skipped", func() {
+			BeforeEach(func() {
+				engine.checks = append(engine.checks[:1], check.NewGenericCheck(
+					"skippedCheck",
+					func(context.Context, image.ImageReference) (bool, error) {
+						return false, fmt.Errorf("%w: needs a registry", check.ErrCheckSkipped)
+					},
+					check.Metadata{},
+					check.HelpText{},
+					nil,
+				))
+			})
+			It("should report the check as skipped without failing", func() {
+				err := engine.ExecuteChecks(testcontext)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(engine.results.Passed).To(HaveLen(1))
+				Expect(engine.results.Skipped).To(HaveLen(1))
+				Expect(engine.results.Skipped[0].Error()).To(MatchError(check.ErrCheckSkipped))
+				Expect(engine.results.PassedOverall).To(BeTrue())
+			})
+		})
+		Context("a check reports findings", func() {
+			findings := []check.Finding{
+				{Message: "label is missing", Object: "vendor", Severity: check.SeverityError},
+			}
+			BeforeEach(func() {
+				engine.checks = append(engine.checks[:1], &findingsCheck{
+					Check:    check.NewGenericCheck("findingsCheck", nil, check.Metadata{}, check.HelpText{}, nil),
+					findings: findings,
+				})
+			})
+			It("should carry the findings on the result", func() {
+				err := engine.ExecuteChecks(testcontext)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(engine.results.Failed).To(HaveLen(1))
+				Expect(engine.results.Failed[0].Findings).To(Equal(findings))
+				Expect(engine.results.Passed[0].Findings).To(BeNil())
+			})
+			It("should carry the findings when the check has a timeout", func() {
+				engine.checkTimeout = time.Minute
+				err := engine.ExecuteChecks(testcontext)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(engine.results.Failed[0].Findings).To(Equal(findings))
+			})
+		})
+		Context("the image is an OCI layout", func() {
+			BeforeEach(func() {
+				img, err := random.Image(1024, 2)
+				Expect(err).ToNot(HaveOccurred())
+				dir := filepath.Join(GinkgoT().TempDir(), "my-image")
+				p, err := layout.Write(dir, empty.Index)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(p.AppendImage(img, layout.WithAnnotations(map[string]string{ociRefNameAnnotation: "v1.0"}))).To(Succeed())
+				engine.image = "oci:" + dir + ":v1.0"
+			})
+			It("should derive the image reference from the layout", func() {
+				err := engine.ExecuteChecks(testcontext)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(engine.imageRef.ImageRegistry).To(BeEmpty())
+				Expect(engine.imageRef.ImageRepository).To(Equal("my-image"))
+				Expect(engine.imageRef.ImageTagOrSha).To(Equal("v1.0"))
+				Expect(engine.imageRef.Local).To(BeTrue())
+			})
+			It("should use an explicit image reference when provided", func() {
+				engine.localImageReference = "quay.io/example/my-image:v1.0"
+				err := engine.ExecuteChecks(testcontext)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(engine.imageRef.ImageRegistry).To(Equal("quay.io"))
+				Expect(engine.imageRef.ImageRepository).To(Equal("example/my-image"))
+				Expect(engine.imageRef.ImageTagOrSha).To(Equal("v1.0"))
+			})
+			It("should fail when the explicit image reference is invalid", func() {
+				engine.localImageReference = "INVALID@@@"
+				err := engine.ExecuteChecks(testcontext)
+				Expect(err).To(HaveOccurred())
+			})
+		})
+		Context("the image is a docker archive", func() {
+			BeforeEach(func() {
+				img, err := random.Image(1024, 2)
+				Expect(err).ToNot(HaveOccurred())
+				tag, err := name.NewTag("example.com/archived/image:v2")
+				Expect(err).ToNot(HaveOccurred())
+				path := filepath.Join(GinkgoT().TempDir(), "image.tar")
+				Expect(tarball.WriteToFile(path, tag, img)).To(Succeed())
+				engine.image = "docker-archive:" + path
+			})
+			It("should derive the image reference from the archive", func() {
+				err := engine.ExecuteChecks(testcontext)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(engine.imageRef.ImageRegistry).To(Equal("example.com"))
+				Expect(engine.imageRef.ImageRepository).To(Equal("archived/image"))
+				Expect(engine.imageRef.ImageTagOrSha).To(Equal("v2"))
+				Expect(engine.imageRef.Local).To(BeTrue())
+			})
 		})
 		Context("it is a bundle", func() {
 			It("should succeed and generate a bundle hash", func() {
@@ -168,6 +553,15 @@ var _ = Describe("Execute Checks tests", func() {
 				Expect(err).ToNot(HaveOccurred())
 				Expect(engine.results.CertificationHash).ToNot(BeEmpty())
 			})
+			It("should not collect an inventory", func() {
+				engine.isBundle = true
+				err := engine.ExecuteChecks(testcontext)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(engine.imageRef.Inventory).To(BeNil())
+				exists, err := artifacts.WriterFromContext(testcontext).(*artifacts.FilesystemWriter).Exists(check.DefaultInventoryFilename)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(exists).To(BeFalse())
+			})
 		})
 		Context("the image is invalid", func() {
 			It("should throw a crane error on pull", func() {
@@ -248,6 +642,102 @@ var _ = Describe("Execute Checks tests", func() {
 	})
 })
 
+var _ = Describe("Local image sources", func() {
+	var img cranev1.Image
+	BeforeEach(func() {
+		var err error
+		img, err = random.Image(1024, 1)
+		Expect(err).ToNot(HaveOccurred())
+	})
+	Context("loading an OCI layout", func() {
+		var dir string
+		BeforeEach(func() {
+			dir = filepath.Join(GinkgoT().TempDir(), "layout")
+			_, err := layout.Write(dir, empty.Index)
+			Expect(err).ToNot(HaveOccurred())
+		})
+		It("should fail when the directory is not a layout", func() {
+			_, err := loadOCILayout(GinkgoT().TempDir(), "", "amd64")
+			Expect(err).To(HaveOccurred())
+		})
+		It("should fail when the layout is empty", func() {
+			_, err := loadOCILayout(dir, "", "amd64")
+			Expect(err).To(MatchError(ContainSubstring("no image found")))
+		})
+		Context("with multiple images", func() {
+			BeforeEach(func() {
+				p, err := layout.FromPath(dir)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(p.AppendImage(img, layout.WithAnnotations(map[string]string{ociRefNameAnnotation: "quay.io/example/image:v1"}))).To(Succeed())
+				other, err := random.Image(1024, 1)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(p.AppendImage(other, layout.WithAnnotations(map[string]string{ociRefNameAnnotation: "v2"}))).To(Succeed())
+			})
+			It("should require a tag", func() {
+				_, err := loadOCILayout(dir, "", "amd64")
+				Expect(err).To(MatchError(ContainSubstring("a tag must be provided")))
+			})
+			It("should fail when no image has the tag", func() {
+				_, err := loadOCILayout(dir, "v3", "amd64")
+				Expect(err).To(MatchError(ContainSubstring("no image tagged v3")))
+			})
+			It("should use a fully qualified ref name", func() {
+				src, err := loadOCILayout(dir, "v1", "amd64")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(src.registry).To(Equal("quay.io"))
+				Expect(src.repository).To(Equal("example/image"))
+				Expect(src.identifier).To(Equal("v1"))
+			})
+		})
+		Context("with an image index", func() {
+			BeforeEach(func() {
+				idx := mutate.AppendManifests(empty.Index, mutate.IndexAddendum{
+					Add: img,
+					Descriptor: cranev1.Descriptor{
+						Platform: &cranev1.Platform{OS: "linux", Architecture: "arm64"},
+					},
+				})
+				p, err := layout.FromPath(dir)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(p.AppendIndex(idx)).To(Succeed())
+			})
+			It("should select the image for the platform", func() {
+				src, err := loadOCILayout(dir, "", "arm64")
+				Expect(err).ToNot(HaveOccurred())
+				want, err := img.Digest()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(src.img.Digest()).To(Equal(want))
+			})
+			It("should fail when the platform is not in the index", func() {
+				_, err := loadOCILayout(dir, "", "s390x")
+				Expect(err).To(MatchError(ContainSubstring("no image found for platform s390x")))
+			})
+		})
+	})
+	Context("loading a docker archive", func() {
+		var path string
+		BeforeEach(func() {
+			path = filepath.Join(GinkgoT().TempDir(), "archive.tar")
+			tag, err := name.NewTag("example.com/archived/image:v2")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tarball.WriteToFile(path, tag, img)).To(Succeed())
+		})
+		It("should select the named image", func() {
+			src, err := loadDockerArchive(path, "example.com/archived/image:v2")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(src.repository).To(Equal("archived/image"))
+		})
+		It("should fail on an invalid image name", func() {
+			_, err := loadDockerArchive(path, "INVALID@@@")
+			Expect(err).To(HaveOccurred())
+		})
+		It("should fail when the file does not exist", func() {
+			_, err := loadDockerArchive(filepath.Join(GinkgoT().TempDir(), "missing.tar"), "")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
+
 var _ = Describe("Source RPM name function", func() {
 	Context("With a source rpm name", func() {
 		Context("And a normal source rpm name", func() {
@@ -327,6 +817,64 @@ var _ = Describe("Check Initialization", func() {
 			_, err := InitializeContainerChecks(context.TODO(), policy.Policy("foo"), ContainerCheckConfig{})
 			Expect(err).To(HaveOccurred())
 		})
+		It("should return checks for a user-defined policy", func() {
+			checks, err := InitializeContainerChecks(context.TODO(), "labels", ContainerCheckConfig{
+				Policies: []policy.Definition{{Name: "labels", Kind: policy.KindContainer, Checks: []string{"HasRequiredLabel", "HasNoProhibitedLabels"}}},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(checks).To(HaveLen(2))
+			Expect(checks[1].Name()).To(Equal("HasNoProhibitedLabels"))
+		})
+		It("should throw an error if a user-defined policy is invalid", func() {
+			_, err := InitializeContainerChecks(context.TODO(), "labels", ContainerCheckConfig{
+				Policies: []policy.Definition{{Name: "labels"}},
+			})
+			Expect(err).To(MatchError(ContainSubstring("kind is required")))
+		})
+		It("should throw an error if a user-defined policy includes an unknown check", func() {
+			_, err := InitializeContainerChecks(context.TODO(), "labels", ContainerCheckConfig{
+				Policies: []policy.Definition{{Name: "labels", Inherits: policy.PolicyContainer, Checks: []string{"HasTeamLabel"}}},
+			})
+			Expect(err).To(MatchError(ContainSubstring(`unknown container check "HasTeamLabel"`)))
+		})
+		It("should throw an error if the policy is an operator policy", func() {
+			_, err := InitializeContainerChecks(context.TODO(), policy.PolicyOperator, ContainerCheckConfig{})
+			Expect(err).To(MatchError(ContainSubstring("container policy operator is unknown")))
+		})
+	})
+
+	It("should return the metadata of a check by name", func() {
+		meta, ok := CheckMetadata("RunAsNonRoot")
+		Expect(ok).To(BeTrue())
+		Expect(meta.ID).To(Equal("PFLT-CNT-007"))
+		meta, ok = CheckMetadata("DeployableByOLM")
+		Expect(ok).To(BeTrue())
+		Expect(meta.ID).To(Equal("PFLT-OPR-003"))
+		_, ok = CheckMetadata("HasTeamLabel")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should assign every check a unique ID", func() {
+		ids := map[string]string{}
+		for name, newCheck := range containerChecks {
+			id := newCheck(ContainerCheckConfig{}).Metadata().ID
+			Expect(ids).ToNot(HaveKey(id), "%s has the same ID as %s", name, ids[id])
+			ids[id] = name
+		}
+		for name, newCheck := range operatorChecks {
+			id := newCheck(OperatorCheckConfig{}).Metadata().ID
+			Expect(ids).ToNot(HaveKey(id), "%s has the same ID as %s", name, ids[id])
+			ids[id] = name
+		}
+	})
+
+	It("should construct every check by the name it is registered with", func() {
+		for name, newCheck := range containerChecks {
+			Expect(newCheck(ContainerCheckConfig{}).Name()).To(Equal(name))
+		}
+		for name, newCheck := range operatorChecks {
+			Expect(newCheck(OperatorCheckConfig{}).Name()).To(Equal(name))
+		}
 	})
 
 	When("initializing operator checks", func() {
@@ -422,6 +970,16 @@ var _ = Describe("Check Name Queries", func() {
 // writeTarball writes a tar archive to out with filename containing contents at the base path
 // with extra bytes written at the end of length extraBytes.
 // note: this should only be used as a helper function in tests
+// findingsCheck fails with a fixed set of findings.
+type findingsCheck struct {
+	check.Check
+	findings []check.Finding
+}
+
+func (c *findingsCheck) ValidateWithFindings(context.Context, image.ImageReference) (bool, []check.Finding, error) {
+	return false, c.findings, nil
+}
+
 func writeTarball(out io.Writer, contents []byte, filename string, extraBytes uint) error {
 	tw := tar.NewWriter(out)
 	defer tw.Close()
@@ -0,0 +1,275 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	cranev1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/option"
+)
+
+// ociRefNameAnnotation is the annotation used in an OCI layout's index.json
+// to name the image a manifest descriptor refers to.
+const ociRefNameAnnotation = "org.opencontainers.image.ref.name"
+
+// imageSource is the image under test, along with the location details
+// used to populate an image.ImageReference.
+type imageSource struct {
+	img        cranev1.Image
+	registry   string
+	repository string
+	identifier string
+	local      bool
+}
+
+// loadImage retrieves the image under test, either from a registry or from
+// the local filesystem, depending on the engine's image uri.
+func (c *craneEngine) loadImage(ctx context.Context) (imageSource, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	var src imageSource
+	var err error
+	switch transport, path, ref := image.SplitLocal(c.image); transport {
+	case image.OCILayoutPrefix:
+		logger.V(log.DBG).Info("loading image from OCI layout", "path", path, "tag", ref)
+		src, err = loadOCILayout(path, ref, c.platform)
+	case image.DockerArchivePrefix:
+		logger.V(log.DBG).Info("loading image from docker archive", "path", path, "name", ref)
+		src, err = loadDockerArchive(path, ref)
+	default:
+		logger.V(log.DBG).Info("pulling image from target registry")
+		src, err = c.pullImage(ctx)
+	}
+	if err != nil {
+		return imageSource{}, err
+	}
+
+	if c.localImageReference != "" {
+		reference, err := name.ParseReference(c.localImageReference)
+		if err != nil {
+			return imageSource{}, fmt.Errorf("invalid image reference %s: %w", c.localImageReference, err)
+		}
+		src.registry = reference.Context().RegistryStr()
+		src.repository = reference.Context().RepositoryStr()
+		src.identifier = reference.Identifier()
+	}
+
+	if src.identifier == "" {
+		digest, err := src.img.Digest()
+		if err != nil {
+			//coverage:ignore
+			return imageSource{}, fmt.Errorf("failed to get image digest: %w", err)
+		}
+		src.identifier = digest.String()
+	}
+
+	if src.local && c.localImageReference == "" {
+		logger.Info("image loaded from local path; registry, repository and tag were derived from the image and may be supplemented with an explicit image reference",
+			"registry", src.registry, "repository", src.repository, "tag", src.identifier)
+	}
+
+	return src, nil
+}
+
+// pullImage pulls the image from its registry.
+func (c *craneEngine) pullImage(ctx context.Context) (imageSource, error) {
+	options := option.GenerateCraneOptions(ctx, c)
+	img, err := crane.Pull(c.image, options...)
+	if err != nil {
+		return imageSource{}, fmt.Errorf("failed to pull remote container: %v", err)
+	}
+
+	reference, err := name.ParseReference(c.image)
+	if err != nil {
+		//coverage:ignore
+		return imageSource{}, fmt.Errorf("image uri could not be parsed: %v", err)
+	}
+
+	return imageSource{
+		img:        img,
+		registry:   reference.Context().RegistryStr(),
+		repository: reference.Context().RepositoryStr(),
+		identifier: reference.Identifier(),
+	}, nil
+}
+
+// loadOCILayout reads an image from the OCI layout at dir. If tag is set, the
+// image whose ref name annotation matches it is selected. Otherwise the layout
+// must contain a single image, or a single image for platform.
+func loadOCILayout(dir, tag, platform string) (imageSource, error) {
+	idx, err := layout.ImageIndexFromPath(dir)
+	if err != nil {
+		return imageSource{}, fmt.Errorf("failed to read OCI layout %s: %w", dir, err)
+	}
+
+	manifest, err := idx.IndexManifest()
+	if err != nil {
+		//coverage:ignore
+		return imageSource{}, fmt.Errorf("failed to read OCI layout index %s: %w", dir, err)
+	}
+
+	candidates := make([]cranev1.Descriptor, 0, len(manifest.Manifests))
+	for _, desc := range manifest.Manifests {
+		if tag == "" || refNameMatches(desc.Annotations[ociRefNameAnnotation], tag) {
+			candidates = append(candidates, desc)
+		}
+	}
+
+	if len(candidates) > 1 {
+		if matching := descriptorsForPlatform(candidates, platform); len(matching) > 0 {
+			candidates = matching
+		}
+	}
+
+	switch {
+	case len(candidates) == 0 && tag != "":
+		return imageSource{}, fmt.Errorf("no image tagged %s found in OCI layout %s", tag, dir)
+	case len(candidates) == 0:
+		return imageSource{}, fmt.Errorf("no image found in OCI layout %s", dir)
+	case len(candidates) > 1:
+		return imageSource{}, fmt.Errorf("OCI layout %s contains multiple images: a tag must be provided", dir)
+	}
+
+	desc := candidates[0]
+	var img cranev1.Image
+	if desc.MediaType.IsIndex() {
+		img, err = imageFromIndexForPlatform(idx, desc.Digest, platform)
+	} else {
+		img, err = idx.Image(desc.Digest)
+	}
+	if err != nil {
+		return imageSource{}, fmt.Errorf("failed to load image from OCI layout %s: %w", dir, err)
+	}
+
+	src := imageSource{
+		img:        img,
+		repository: filepath.Base(filepath.Clean(dir)),
+		identifier: tag,
+		local:      true,
+	}
+
+	// Some tools record the fully qualified image name as the ref name.
+	if refName := desc.Annotations[ociRefNameAnnotation]; strings.Contains(refName, "/") {
+		if reference, err := name.ParseReference(refName); err == nil {
+			src.registry = reference.Context().RegistryStr()
+			src.repository = reference.Context().RepositoryStr()
+			src.identifier = reference.Identifier()
+		}
+	}
+
+	return src, nil
+}
+
+// refNameMatches reports whether an OCI ref name annotation refers to tag. The
+// annotation may hold either just the tag or a fully qualified image name.
+func refNameMatches(refName, tag string) bool {
+	return refName == tag || strings.HasSuffix(refName, ":"+tag)
+}
+
+// descriptorsForPlatform returns the descriptors in descs that are for the
+// linux platform with architecture arch.
+func descriptorsForPlatform(descs []cranev1.Descriptor, arch string) []cranev1.Descriptor {
+	matching := make([]cranev1.Descriptor, 0, len(descs))
+	for _, desc := range descs {
+		if desc.Platform != nil && desc.Platform.OS == "linux" && desc.Platform.Architecture == arch {
+			matching = append(matching, desc)
+		}
+	}
+	return matching
+}
+
+// imageFromIndexForPlatform returns the image for platform from the nested
+// index identified by digest.
+func imageFromIndexForPlatform(idx cranev1.ImageIndex, digest cranev1.Hash, platform string) (cranev1.Image, error) {
+	child, err := idx.ImageIndex(digest)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := child.IndexManifest()
+	if err != nil {
+		//coverage:ignore
+		return nil, err
+	}
+
+	matching := descriptorsForPlatform(manifest.Manifests, platform)
+	if len(matching) == 0 {
+		return nil, fmt.Errorf("no image found for platform %s", platform)
+	}
+
+	return child.Image(matching[0].Digest)
+}
+
+// loadDockerArchive reads an image from the docker-archive tarball at path.
+// If imageName is set, the image with that name is selected. Otherwise the
+// archive must contain a single image.
+func loadDockerArchive(path, imageName string) (imageSource, error) {
+	var tag *name.Tag
+	if imageName != "" {
+		t, err := name.NewTag(imageName)
+		if err != nil {
+			return imageSource{}, fmt.Errorf("invalid image name %s: %w", imageName, err)
+		}
+		tag = &t
+	}
+
+	img, err := tarball.ImageFromPath(path, tag)
+	if err != nil {
+		return imageSource{}, fmt.Errorf("failed to read docker archive %s: %w", path, err)
+	}
+
+	src := imageSource{
+		img:        img,
+		repository: strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+		local:      true,
+	}
+
+	repoTag, err := archiveRepoTag(path, tag)
+	if err != nil {
+		return imageSource{}, err
+	}
+	if repoTag != nil {
+		src.registry = repoTag.RegistryStr()
+		src.repository = repoTag.RepositoryStr()
+		src.identifier = repoTag.TagStr()
+	}
+
+	return src, nil
+}
+
+// archiveRepoTag returns the tag recorded in the docker archive at path for
+// the selected image, or nil if the archive does not record one.
+func archiveRepoTag(path string, tag *name.Tag) (*name.Tag, error) {
+	if tag != nil {
+		return tag, nil
+	}
+
+	manifest, err := tarball.LoadManifest(func() (io.ReadCloser, error) {
+		return os.Open(path)
+	})
+	if err != nil {
+		//coverage:ignore
+		return nil, fmt.Errorf("failed to read docker archive manifest %s: %w", path, err)
+	}
+	if len(manifest) != 1 || len(manifest[0].RepoTags) == 0 {
+		return nil, nil
+	}
+
+	t, err := name.NewTag(manifest[0].RepoTags[0])
+	if err != nil {
+		return nil, fmt.Errorf("docker archive %s has an invalid repo tag: %w", path, err)
+	}
+	return &t, nil
+}
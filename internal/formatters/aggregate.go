@@ -0,0 +1,130 @@
+package formatters
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/certification"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/version"
+)
+
+// PlatformResults are the results of checking one platform of an image.
+type PlatformResults struct {
+	Platform string
+	Results  certification.Results
+	// Err is set if the checks could not be executed for the platform.
+	Err error
+}
+
+// AggregatedResponse summarizes the results of checking every platform of
+// an image, such as the images in a manifest list.
+type AggregatedResponse struct {
+	Image              string                 `json:"image"`
+	ManifestListDigest string                 `json:"manifest_list_digest,omitempty"`
+	Passed             bool                   `json:"passed"`
+	LibraryInfo        version.VersionContext `json:"test_library"`
+	Platforms          []platformSummary      `json:"platforms"`
+	// FailedChecks maps the name of each check that failed, errored or
+	// timed out to the platforms it did so on.
+	FailedChecks map[string][]string `json:"failed_checks,omitempty"`
+}
+
+// platformSummary is the outcome of checking a single platform.
+type platformSummary struct {
+	Platform string   `json:"platform"`
+	Passed   bool     `json:"passed"`
+	Failed   []string `json:"failed,omitempty"`
+	Errors   []string `json:"errors,omitempty"`
+	TimedOut []string `json:"timed_out,omitempty"`
+	// Waived names the checks whose failures were waived.
+	Waived []string `json:"waived,omitempty"`
+	Error  string   `json:"error,omitempty"`
+}
+
+// Aggregate summarizes the results of each platform of image. The image
+// passes only if every platform was checked and passed.
+func Aggregate(image, manifestListDigest string, platforms []PlatformResults) AggregatedResponse {
+	response := AggregatedResponse{
+		Image:              image,
+		ManifestListDigest: manifestListDigest,
+		Passed:             len(platforms) > 0,
+		LibraryInfo:        version.Version,
+		Platforms:          make([]platformSummary, 0, len(platforms)),
+	}
+
+	failedOn := func(results []certification.Result, platform string) []string {
+		names := make([]string, 0, len(results))
+		for _, r := range results {
+			names = append(names, r.Name())
+			if response.FailedChecks == nil {
+				response.FailedChecks = make(map[string][]string)
+			}
+			if !slices.Contains(response.FailedChecks[r.Name()], platform) {
+				response.FailedChecks[r.Name()] = append(response.FailedChecks[r.Name()], platform)
+			}
+		}
+		return names
+	}
+
+	for _, p := range platforms {
+		summary := platformSummary{
+			Platform: p.Platform,
+			Passed:   p.Err == nil && p.Results.PassedOverall,
+			Failed:   failedOn(p.Results.Failed, p.Platform),
+			Errors:   failedOn(p.Results.Errors, p.Platform),
+			TimedOut: failedOn(p.Results.TimedOut, p.Platform),
+		}
+		for _, r := range p.Results.Warned {
+			if r.IsWaived() {
+				summary.Waived = append(summary.Waived, r.Name())
+			}
+		}
+		if p.Err != nil {
+			summary.Error = p.Err.Error()
+		}
+		response.Passed = response.Passed && summary.Passed
+		response.Platforms = append(response.Platforms, summary)
+	}
+
+	return response
+}
+
+// AggregateJSON formats the summary of the results of each platform of image
+// as JSON.
+func AggregateJSON(image, manifestListDigest string, platforms []PlatformResults) ([]byte, error) {
+	b, err := json.MarshalIndent(Aggregate(image, manifestListDigest, platforms), "", "    ")
+	if err != nil {
+		//coverage:ignore
+		return nil, fmt.Errorf("error formatting aggregated results: %w", err)
+	}
+
+	return b, nil
+}
+
+// AggregateJUnitXML formats the results of each platform as JUnit XML, with
+// one test suite per platform. A platform that could not be checked is
+// reported as a suite with a single errored test case.
+func AggregateJUnitXML(platforms []PlatformResults) ([]byte, error) {
+	suites := JUnitTestSuites{Suites: make([]JUnitTestSuite, 0, len(platforms))}
+	for _, p := range platforms {
+		suite := junitTestSuite(fmt.Sprintf("Red Hat Certification (%s)", p.Platform), p.Results)
+		if p.Err != nil {
+			suite.Tests++
+			suite.Errors++
+			suite.TestCases = append(suite.TestCases, JUnitTestCase{
+				Classname: p.Platform,
+				Name:      "Preflight",
+				Time:      "0s",
+				Error: &JUnitMessage{
+					Message:  "Error",
+					Type:     "error",
+					Contents: p.Err.Error(),
+				},
+			})
+		}
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	return marshalJUnit(suites)
+}
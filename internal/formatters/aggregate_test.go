@@ -0,0 +1,125 @@
+package formatters
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/certification"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/waiver"
+)
+
+var _ = Describe("Aggregating platform results", func() {
+	newResult := func(name string) certification.Result {
+		return certification.Result{
+			Check: check.NewGenericCheck(
+				name,
+				func(ctx context.Context, ir image.ImageReference) (bool, error) { return true, nil },
+				check.Metadata{},
+				check.HelpText{},
+				nil),
+		}
+	}
+
+	var platforms []PlatformResults
+	BeforeEach(func() {
+		platforms = []PlatformResults{
+			{
+				Platform: "amd64",
+				Results: certification.Results{
+					PassedOverall: true,
+					Passed:        []certification.Result{newResult("PassedCheck")},
+				},
+			},
+			{
+				Platform: "arm64",
+				Results: certification.Results{
+					Failed:   []certification.Result{newResult("FailedCheck")},
+					Errors:   []certification.Result{newResult("ErroredCheck")},
+					TimedOut: []certification.Result{newResult("TimedOutCheck")},
+				},
+			},
+			{
+				Platform: "ppc64le",
+				Results: certification.Results{
+					Failed: []certification.Result{newResult("FailedCheck")},
+				},
+				Err: errors.New("pull failed"),
+			},
+		}
+	})
+
+	Context("when summarizing the results", func() {
+		It("should fail if any platform failed", func() {
+			aggregated := Aggregate("example.com/repo/image:tag", "sha256:abc", platforms)
+			Expect(aggregated.Passed).To(BeFalse())
+			Expect(aggregated.ManifestListDigest).To(Equal("sha256:abc"))
+			Expect(aggregated.Platforms).To(HaveLen(3))
+		})
+		It("should summarize each platform", func() {
+			aggregated := Aggregate("example.com/repo/image:tag", "", platforms)
+			Expect(aggregated.Platforms[0]).To(Equal(platformSummary{Platform: "amd64", Passed: true, Failed: []string{}, Errors: []string{}, TimedOut: []string{}}))
+			Expect(aggregated.Platforms[1].Failed).To(ConsistOf("FailedCheck"))
+			Expect(aggregated.Platforms[1].Errors).To(ConsistOf("ErroredCheck"))
+			Expect(aggregated.Platforms[1].TimedOut).To(ConsistOf("TimedOutCheck"))
+			Expect(aggregated.Platforms[2].Error).To(Equal("pull failed"))
+		})
+		It("should list the platforms each check failed on", func() {
+			aggregated := Aggregate("example.com/repo/image:tag", "", platforms)
+			Expect(aggregated.FailedChecks).To(Equal(map[string][]string{
+				"FailedCheck":   {"arm64", "ppc64le"},
+				"ErroredCheck":  {"arm64"},
+				"TimedOutCheck": {"arm64"},
+			}))
+		})
+		It("should list the checks whose failures were waived", func() {
+			waived := newResult("WaivedCheck")
+			waived.Waiver = &waiver.Waiver{Check: "WaivedCheck"}
+			platforms[0].Results.Warned = []certification.Result{waived, newResult("WarnedCheck")}
+			aggregated := Aggregate("example.com/repo/image:tag", "", platforms)
+			Expect(aggregated.Platforms[0].Waived).To(Equal([]string{"WaivedCheck"}))
+			Expect(aggregated.Platforms[1].Waived).To(BeEmpty())
+		})
+		It("should pass if every platform passed", func() {
+			aggregated := Aggregate("example.com/repo/image:tag", "", platforms[:1])
+			Expect(aggregated.Passed).To(BeTrue())
+			Expect(aggregated.FailedChecks).To(BeNil())
+		})
+		It("should not pass if no platforms were checked", func() {
+			Expect(Aggregate("example.com/repo/image:tag", "", nil).Passed).To(BeFalse())
+		})
+	})
+
+	Context("when formatting the summary as JSON", func() {
+		It("should format without error", func() {
+			out, err := AggregateJSON("example.com/repo/image:tag", "", platforms)
+			Expect(err).ToNot(HaveOccurred())
+
+			var aggregated AggregatedResponse
+			Expect(json.Unmarshal(out, &aggregated)).To(Succeed())
+			Expect(aggregated.Image).To(Equal("example.com/repo/image:tag"))
+			Expect(aggregated.Platforms).To(HaveLen(3))
+		})
+	})
+
+	Context("when formatting the results as JUnit XML", func() {
+		It("should include a test suite for every platform", func() {
+			out, err := AggregateJUnitXML(platforms)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(out)).To(ContainSubstring(`name="Red Hat Certification (amd64)"`))
+			Expect(string(out)).To(ContainSubstring(`name="Red Hat Certification (arm64)"`))
+			Expect(string(out)).To(ContainSubstring(`name="Red Hat Certification (ppc64le)"`))
+		})
+		It("should report a platform that could not be checked as an error", func() {
+			out, err := AggregateJUnitXML(platforms[2:])
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(out)).To(ContainSubstring(`tests="2" failures="1" errors="1"`))
+			Expect(string(out)).To(ContainSubstring(`<error message="Error" type="error">pull failed</error>`))
+		})
+	})
+})
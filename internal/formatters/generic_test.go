@@ -22,14 +22,22 @@ func TestGenericJSONFormatter(t *testing.T) {
 			PassedOverall: passed,
 			Passed: []certification.Result{
 				{
-					Check:       check.NewGenericCheck("passed1", nil, check.Metadata{}, check.HelpText{}, nil),
+					Check: check.NewGenericCheck("passed1", nil, check.Metadata{
+						ID:            "PFLT-TST-001",
+						PolicyVersion: "1.0",
+						Categories:    []check.Category{check.CategorySecurity, check.CategoryMetadata},
+					}, check.HelpText{}, nil),
 					ElapsedTime: 1000 * time.Millisecond,
 				},
 			},
 			Failed: []certification.Result{
 				{
-					Check:       check.NewGenericCheck("failed1", nil, check.Metadata{}, check.HelpText{}, nil),
+					Check:       check.Custom(check.NewGenericCheck("failed1", nil, check.Metadata{}, check.HelpText{}, nil)),
 					ElapsedTime: 1001 * time.Millisecond,
+					Findings: []check.Finding{
+						{Message: "file was modified", Object: "/etc/os-release", Severity: check.SeverityError},
+						{Message: "image is large", Severity: check.SeverityWarning},
+					},
 				},
 			},
 		}
@@ -79,11 +87,17 @@ func TestGenericJSONFormatter(t *testing.T) {
 
 			for index, i := range tc.results.Passed {
 				assert.Equal(t, i.Name(), testResponseObj.Results.Passed[index].Name)
+				assert.Equal(t, false, testResponseObj.Results.Passed[index].Custom)
+				assert.Equal(t, i.Metadata().ID, testResponseObj.Results.Passed[index].ID)
+				assert.Equal(t, i.Metadata().PolicyVersion, testResponseObj.Results.Passed[index].PolicyVersion)
+				assert.DeepEqual(t, i.Metadata().Categories, testResponseObj.Results.Passed[index].Categories)
 				assert.Equal(t, float64(i.ElapsedTime/time.Millisecond), testResponseObj.Results.Passed[index].ElapsedTime)
 			}
 			for index, i := range tc.results.Failed {
 				assert.Equal(t, i.Name(), testResponseObj.Results.Failed[index].Name)
+				assert.Equal(t, true, testResponseObj.Results.Failed[index].Custom)
 				assert.Equal(t, float64(i.ElapsedTime/time.Millisecond), testResponseObj.Results.Failed[index].ElapsedTime)
+				assert.DeepEqual(t, i.Findings, testResponseObj.Results.Failed[index].Findings)
 			}
 		} else {
 			assert.Equal(t, true, strings.Contains(err.Error(), tc.expectedErrString))
@@ -98,7 +112,11 @@ func TestGenericXMLFormatter(t *testing.T) {
 			PassedOverall: passed,
 			Passed: []certification.Result{
 				{
-					Check:       check.NewGenericCheck("passed1", nil, check.Metadata{}, check.HelpText{}, nil),
+					Check: check.NewGenericCheck("passed1", nil, check.Metadata{
+						ID:            "PFLT-TST-001",
+						PolicyVersion: "1.0",
+						Categories:    []check.Category{check.CategorySecurity, check.CategoryMetadata},
+					}, check.HelpText{}, nil),
 					ElapsedTime: 1000 * time.Millisecond,
 				},
 			},
@@ -106,6 +124,10 @@ func TestGenericXMLFormatter(t *testing.T) {
 				{
 					Check:       check.NewGenericCheck("failed1", nil, check.Metadata{}, check.HelpText{}, nil),
 					ElapsedTime: 1001 * time.Millisecond,
+					Findings: []check.Finding{
+						{Message: "file was modified", Object: "/etc/os-release", Severity: check.SeverityError},
+						{Message: "image is large", Severity: check.SeverityWarning},
+					},
 				},
 			},
 		}
@@ -155,11 +177,14 @@ func TestGenericXMLFormatter(t *testing.T) {
 
 			for index, i := range tc.results.Passed {
 				assert.Equal(t, i.Name(), testResponseObj.Results.Passed[index].Name)
+				assert.Equal(t, i.Metadata().ID, testResponseObj.Results.Passed[index].ID)
+				assert.DeepEqual(t, i.Metadata().Categories, testResponseObj.Results.Passed[index].Categories)
 				assert.Equal(t, float64(i.ElapsedTime/time.Millisecond), testResponseObj.Results.Passed[index].ElapsedTime)
 			}
 			for index, i := range tc.results.Failed {
 				assert.Equal(t, i.Name(), testResponseObj.Results.Failed[index].Name)
 				assert.Equal(t, float64(i.ElapsedTime/time.Millisecond), testResponseObj.Results.Failed[index].ElapsedTime)
+				assert.DeepEqual(t, i.Findings, testResponseObj.Results.Failed[index].Findings)
 			}
 		} else {
 			assert.Equal(t, true, strings.Contains(err.Error(), tc.expectedErrString))
@@ -4,9 +4,11 @@ import (
 	"context"
 	"encoding/xml"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/certification"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
 )
 
 type JUnitTestSuites struct {
@@ -18,7 +20,9 @@ type JUnitTestSuite struct {
 	XMLName    xml.Name        `xml:"testsuite"`
 	Tests      int             `xml:"tests,attr"`
 	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr,omitempty"`
 	Warnings   int             `xml:"warnings,attr"`
+	Skipped    int             `xml:"skipped,attr,omitempty"`
 	Time       string          `xml:"time,attr"`
 	Name       string          `xml:"name,attr"`
 	Properties []JUnitProperty `xml:"properties>property,omitempty"`
@@ -30,8 +34,10 @@ type JUnitTestCase struct {
 	Classname   string            `xml:"classname,attr"`
 	Name        string            `xml:"name,attr"`
 	Time        string            `xml:"time,attr"`
+	Properties  []JUnitProperty   `xml:"properties>property,omitempty"`
 	SkipMessage *JUnitSkipMessage `xml:"skipped,omitempty"`
 	Failure     *JUnitMessage     `xml:"failure,omitempty"`
+	Error       *JUnitMessage     `xml:"error,omitempty"`
 	Warning     *JUnitMessage     `xml:"warning,omitempty"`
 	SystemOut   string            `xml:"system-out,omitempty"`
 	Message     string            `xml:",chardata"`
@@ -53,14 +59,25 @@ type JUnitMessage struct {
 }
 
 func junitXMLFormatter(_ context.Context, r certification.Results) ([]byte, error) {
+	suites := JUnitTestSuites{
+		Suites: []JUnitTestSuite{junitTestSuite("Red Hat Certification", r)},
+	}
+
+	return marshalJUnit(suites)
+}
+
+// junitTestSuite returns a test suite named name containing a test case for
+// each check in r.
+func junitTestSuite(name string, r certification.Results) JUnitTestSuite {
 	response := getResponse(r)
-	suites := JUnitTestSuites{}
 	testsuite := JUnitTestSuite{
-		Tests:      len(r.Errors) + len(r.Failed) + len(r.Passed) + len(r.Warned),
+		Tests:      len(r.Errors) + len(r.Failed) + len(r.Passed) + len(r.Warned) + len(r.Skipped) + len(r.TimedOut),
 		Failures:   len(r.Errors) + len(r.Failed),
+		Errors:     len(r.TimedOut),
 		Warnings:   len(r.Warned),
+		Skipped:    len(r.Skipped),
 		Time:       "0s",
-		Name:       "Red Hat Certification",
+		Name:       name,
 		Properties: []JUnitProperty{},
 		TestCases:  []JUnitTestCase{},
 	}
@@ -68,11 +85,13 @@ func junitXMLFormatter(_ context.Context, r certification.Results) ([]byte, erro
 	totalDuration := time.Duration(0)
 	for _, result := range r.Passed {
 		testCase := JUnitTestCase{
-			Classname: response.Image,
-			Name:      result.Name(),
-			Time:      fmt.Sprintf("%f", result.ElapsedTime.Seconds()),
-			Failure:   nil,
-			Message:   result.Metadata().Description,
+			Classname:  response.Image,
+			Name:       result.Name(),
+			Properties: checkProperties(result.Metadata()),
+			Time:       fmt.Sprintf("%f", result.ElapsedTime.Seconds()),
+			Failure:    nil,
+			SystemOut:  findingsText(result.Findings),
+			Message:    result.Metadata().Description,
 		}
 		testsuite.TestCases = append(testsuite.TestCases, testCase)
 		totalDuration += result.ElapsedTime
@@ -80,14 +99,19 @@ func junitXMLFormatter(_ context.Context, r certification.Results) ([]byte, erro
 
 	for _, result := range append(r.Errors, r.Failed...) {
 		testCase := JUnitTestCase{
-			Classname: response.Image,
-			Name:      result.Name(),
-			Time:      result.ElapsedTime.String(),
+			Classname:  response.Image,
+			Name:       result.Name(),
+			Properties: checkProperties(result.Metadata()),
+			Time:       result.ElapsedTime.String(),
 			Failure: &JUnitMessage{
 				Message:  "Failed",
 				Type:     "",
-				Contents: fmt.Sprintf("%s: Suggested Fix: %s", result.Help().Message, result.Help().Suggestion),
+				Contents: waiverText(result) + fmt.Sprintf("%s: Suggested Fix: %s", result.Help().Message, result.Help().Suggestion),
 			},
+			SystemOut: findingsText(result.Findings),
+		}
+		if result.WaiverExpired {
+			testCase.Failure.Message = "Failed (waiver expired)"
 		}
 		testsuite.TestCases = append(testsuite.TestCases, testCase)
 		totalDuration += result.ElapsedTime
@@ -95,22 +119,66 @@ func junitXMLFormatter(_ context.Context, r certification.Results) ([]byte, erro
 
 	for _, result := range r.Warned {
 		testCase := JUnitTestCase{
-			Classname: response.Image,
-			Name:      result.Name(),
-			Time:      result.ElapsedTime.String(),
+			Classname:  response.Image,
+			Name:       result.Name(),
+			Properties: checkProperties(result.Metadata()),
+			Time:       result.ElapsedTime.String(),
 			Warning: &JUnitMessage{
 				Message:  "Warn",
 				Type:     "",
-				Contents: fmt.Sprintf("%s: Suggested Fix: %s", result.Help().Message, result.Help().Suggestion),
+				Contents: waiverText(result) + fmt.Sprintf("%s: Suggested Fix: %s", result.Help().Message, result.Help().Suggestion),
 			},
+			SystemOut: findingsText(result.Findings),
+		}
+		if result.IsWaived() {
+			testCase.Warning.Message = "Waived"
+		}
+		testsuite.TestCases = append(testsuite.TestCases, testCase)
+		totalDuration += result.ElapsedTime
+	}
+
+	for _, result := range r.Skipped {
+		testCase := JUnitTestCase{
+			Classname:   response.Image,
+			Name:        result.Name(),
+			Properties:  checkProperties(result.Metadata()),
+			Time:        result.ElapsedTime.String(),
+			SkipMessage: &JUnitSkipMessage{Message: "Skipped"},
+			SystemOut:   findingsText(result.Findings),
+		}
+		if err := result.Error(); err != nil {
+			testCase.SkipMessage.Message = err.Error()
+		}
+		testsuite.TestCases = append(testsuite.TestCases, testCase)
+		totalDuration += result.ElapsedTime
+	}
+
+	for _, result := range r.TimedOut {
+		testCase := JUnitTestCase{
+			Classname:  response.Image,
+			Name:       result.Name(),
+			Properties: checkProperties(result.Metadata()),
+			Time:       result.ElapsedTime.String(),
+			Error: &JUnitMessage{
+				Message: "Timed out",
+				Type:    "timeout",
+			},
+			SystemOut: findingsText(result.Findings),
+		}
+		if err := result.Error(); err != nil {
+			testCase.Error.Contents = err.Error()
 		}
 		testsuite.TestCases = append(testsuite.TestCases, testCase)
 		totalDuration += result.ElapsedTime
 	}
 
 	testsuite.Time = fmt.Sprintf("%f", totalDuration.Seconds())
-	suites.Suites = append(suites.Suites, testsuite)
 
+	return testsuite
+}
+
+// marshalJUnit renders suites as indented XML.
+func marshalJUnit(suites JUnitTestSuites) ([]byte, error) {
 	bytes, err := xml.MarshalIndent(suites, "", "\t")
 	if err != nil {
 		//coverage:ignore
@@ -124,3 +192,46 @@ func junitXMLFormatter(_ context.Context, r certification.Results) ([]byte, erro
 
 	return bytes, nil
 }
+
+// checkProperties returns the identifying metadata of a check as test case
+// properties.
+func checkProperties(m check.Metadata) []JUnitProperty {
+	var properties []JUnitProperty
+	if m.ID != "" {
+		properties = append(properties, JUnitProperty{Name: "id", Value: m.ID})
+	}
+	if m.PolicyVersion != "" {
+		properties = append(properties, JUnitProperty{Name: "policy_version", Value: m.PolicyVersion})
+	}
+	for _, c := range m.Categories {
+		properties = append(properties, JUnitProperty{Name: "category", Value: string(c)})
+	}
+	return properties
+}
+
+// waiverText describes the waiver that matched the failure of result, as a
+// prefix for its message. It is empty if no waiver matched.
+func waiverText(result certification.Result) string {
+	switch {
+	case result.Waiver == nil:
+		return ""
+	case result.WaiverExpired:
+		return fmt.Sprintf("WAIVER EXPIRED on %s (%s): ", result.Waiver.Expires.Format(time.DateOnly), result.Waiver.Justification)
+	default:
+		return fmt.Sprintf("Waived until %s (%s): ", result.Waiver.Expires.Format(time.DateOnly), result.Waiver.Justification)
+	}
+}
+
+// findingsText renders findings one per line, for inclusion in a test case's
+// system-out.
+func findingsText(findings []check.Finding) string {
+	var b strings.Builder
+	for _, f := range findings {
+		b.WriteString("[" + string(f.Severity) + "] ")
+		if f.Object != "" {
+			b.WriteString(f.Object + ": ")
+		}
+		b.WriteString(f.Message + "\n")
+	}
+	return b.String()
+}
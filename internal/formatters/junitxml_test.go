@@ -3,6 +3,8 @@ package formatters
 import (
 	"context"
 	"errors"
+	"fmt"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -11,6 +13,7 @@ import (
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/runtime"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/waiver"
 )
 
 var _ = Describe("JUnitXML Formatter", func() {
@@ -31,6 +34,9 @@ var _ = Describe("JUnitXML Formatter", func() {
 							"PassedCheck",
 							func(ctx context.Context, ir image.ImageReference) (bool, error) { return true, nil },
 							check.Metadata{
+								ID:               "PFLT-TST-001",
+								PolicyVersion:    "1.0",
+								Categories:       []check.Category{check.CategorySecurity, check.CategoryMetadata},
 								Description:      "description",
 								KnowledgeBaseURL: "kburl",
 								CheckURL:         "checkurl",
@@ -59,6 +65,10 @@ var _ = Describe("JUnitXML Formatter", func() {
 							},
 							nil),
 						ElapsedTime: 0,
+						Findings: []check.Finding{
+							{Message: "label is missing", Object: "vendor", Severity: check.SeverityError},
+							{Message: "image is large", Severity: check.SeverityWarning},
+						},
 					},
 				},
 				Errors: []certification.Result{
@@ -115,6 +125,32 @@ var _ = Describe("JUnitXML Formatter", func() {
 						ElapsedTime: 0,
 					},
 				},
+				Skipped: []certification.Result{
+					*(&certification.Result{
+						Check: check.NewGenericCheck(
+							"SkippedCheck",
+							func(ctx context.Context, ir image.ImageReference) (bool, error) { return false, check.ErrCheckSkipped },
+							check.Metadata{
+								Description: "description",
+							},
+							check.HelpText{},
+							nil),
+						ElapsedTime: 0,
+					}).WithError(fmt.Errorf("%w: registry required", check.ErrCheckSkipped)),
+				},
+				TimedOut: []certification.Result{
+					*(&certification.Result{
+						Check: check.NewGenericCheck(
+							"TimedOutCheck",
+							func(ctx context.Context, ir image.ImageReference) (bool, error) { return false, nil },
+							check.Metadata{
+								Description: "description",
+							},
+							check.HelpText{},
+							nil),
+						ElapsedTime: time.Second,
+					}).WithError(fmt.Errorf("%w after 1s", check.ErrCheckTimedOut)),
+				},
 			}
 		})
 		It("should format without error", func() {
@@ -124,5 +160,40 @@ var _ = Describe("JUnitXML Formatter", func() {
 			Expect(string(out)).To(ContainSubstring("FailedCheck"))
 			Expect(string(out)).To(ContainSubstring("ErroredCheck"))
 		})
+		It("should report findings in the test case output", func() {
+			out, err := junitXMLFormatter(context.TODO(), response)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(out)).To(ContainSubstring("<system-out>[error] vendor: label is missing&#xA;[warning] image is large&#xA;</system-out>"))
+		})
+		It("should report the identifying metadata of checks as properties", func() {
+			out, err := junitXMLFormatter(context.TODO(), response)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(out)).To(ContainSubstring(`<property name="id" value="PFLT-TST-001"></property>`))
+			Expect(string(out)).To(ContainSubstring(`<property name="policy_version" value="1.0"></property>`))
+			Expect(string(out)).To(ContainSubstring(`<property name="category" value="security"></property>`))
+			Expect(string(out)).To(ContainSubstring(`<property name="category" value="metadata"></property>`))
+		})
+		It("should report skipped checks with their reason", func() {
+			out, err := junitXMLFormatter(context.TODO(), response)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(out)).To(ContainSubstring(`skipped="1"`))
+			Expect(string(out)).To(ContainSubstring(`<skipped message="check skipped: registry required"></skipped>`))
+		})
+		It("should annotate waived failures, and failures whose waiver expired", func() {
+			expires := time.Date(2025, time.June, 30, 0, 0, 0, 0, time.UTC)
+			response.Warned[1].Waiver = &waiver.Waiver{Check: "WarningCheckFail", Justification: "case 01234567", Expires: expires}
+			response.Failed[0].Waiver = &waiver.Waiver{Check: "FailedCheck", Justification: "case 07654321", Expires: expires}
+			response.Failed[0].WaiverExpired = true
+			out, err := junitXMLFormatter(context.TODO(), response)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(out)).To(ContainSubstring(`<warning message="Waived" type="">Waived until 2025-06-30 (case 01234567): helptext`))
+			Expect(string(out)).To(ContainSubstring(`<failure message="Failed (waiver expired)" type="">WAIVER EXPIRED on 2025-06-30 (case 07654321): helptext`))
+		})
+		It("should report timed out checks as errors, separately from failures", func() {
+			out, err := junitXMLFormatter(context.TODO(), response)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(out)).To(ContainSubstring(`failures="2" errors="1"`))
+			Expect(string(out)).To(ContainSubstring(`<error message="Timed out" type="timeout">check timed out after 1s</error>`))
+		})
 	})
 })
@@ -0,0 +1,49 @@
+package formatters
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/certification"
+)
+
+// ReadPreviousResults reads results written by the JSON formatter, so that a
+// later run may execute only the checks that did not pass.
+func ReadPreviousResults(r io.Reader) (certification.PreviousResults, error) {
+	var response UserResponse
+	if err := json.NewDecoder(r).Decode(&response); err != nil {
+		return certification.PreviousResults{}, fmt.Errorf("could not parse previous results: %w", err)
+	}
+
+	if response.ImageDigest == "" {
+		return certification.PreviousResults{}, errors.New("previous results do not record the digest of the image they were generated for")
+	}
+
+	previous := certification.PreviousResults{
+		ImageDigest: response.ImageDigest,
+		Checks:      make(map[string]certification.PreviousResult),
+	}
+	for status, checks := range map[certification.CheckStatus][]checkExecutionInfo{
+		certification.StatusPassed:   response.Results.Passed,
+		certification.StatusFailed:   response.Results.Failed,
+		certification.StatusErrored:  response.Results.Errors,
+		certification.StatusWarned:   response.Results.Warnings,
+		certification.StatusSkipped:  response.Results.Skipped,
+		certification.StatusTimedOut: response.Results.TimedOut,
+	} {
+		for _, c := range checks {
+			previous.Checks[c.Name] = certification.PreviousResult{
+				Status:      status,
+				ElapsedTime: time.Duration(c.ElapsedTime) * time.Millisecond,
+				Findings:    c.Findings,
+				Reason:      c.Reason,
+				Waived:      c.Waiver != nil && !c.Waiver.Expired,
+			}
+		}
+	}
+
+	return previous, nil
+}
@@ -0,0 +1,105 @@
+package formatters
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/certification"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/waiver"
+)
+
+var _ = Describe("Reading previous results", func() {
+	newResult := func(name string) certification.Result {
+		return certification.Result{
+			Check: check.NewGenericCheck(
+				name,
+				func(ctx context.Context, ir image.ImageReference) (bool, error) { return true, nil },
+				check.Metadata{},
+				check.HelpText{},
+				nil),
+			ElapsedTime: 2 * time.Second,
+		}
+	}
+
+	It("should read the outcome of every check written by the JSON formatter", func() {
+		failed := newResult("FailedCheck")
+		failed.Findings = []check.Finding{{Message: "label is missing", Object: "vendor", Severity: check.SeverityError}}
+		out, err := genericJSONFormatter(context.TODO(), certification.Results{
+			TestedImage: "example.com/repo/image:tag",
+			ImageDigest: "sha256:abc",
+			Passed:      []certification.Result{newResult("PassedCheck")},
+			Failed:      []certification.Result{failed},
+			Errors:      []certification.Result{newResult("ErroredCheck")},
+			Warned:      []certification.Result{newResult("WarnedCheck")},
+			Skipped: []certification.Result{
+				*(&certification.Result{Check: newResult("SkippedCheck").Check}).WithError(fmt.Errorf("%w: registry required", check.ErrCheckSkipped)),
+			},
+			TimedOut: []certification.Result{newResult("TimedOutCheck")},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		previous, err := ReadPreviousResults(bytes.NewReader(out))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(previous.ImageDigest).To(Equal("sha256:abc"))
+		Expect(previous.Checks).To(HaveLen(6))
+		Expect(previous.Checks["PassedCheck"]).To(Equal(certification.PreviousResult{Status: certification.StatusPassed, ElapsedTime: 2 * time.Second}))
+		Expect(previous.Checks["FailedCheck"].Status).To(Equal(certification.StatusFailed))
+		Expect(previous.Checks["FailedCheck"].Findings).To(Equal(failed.Findings))
+		Expect(previous.Checks["ErroredCheck"].Status).To(Equal(certification.StatusErrored))
+		Expect(previous.Checks["WarnedCheck"].Status).To(Equal(certification.StatusWarned))
+		Expect(previous.Checks["SkippedCheck"].Status).To(Equal(certification.StatusSkipped))
+		Expect(previous.Checks["SkippedCheck"].Reason).To(Equal("check skipped: registry required"))
+		Expect(previous.Checks["TimedOutCheck"].Status).To(Equal(certification.StatusTimedOut))
+
+		Expect(previous.NeedsRerun("PassedCheck")).To(BeFalse())
+		Expect(previous.NeedsRerun("WarnedCheck")).To(BeFalse())
+		Expect(previous.NeedsRerun("SkippedCheck")).To(BeFalse())
+		Expect(previous.NeedsRerun("FailedCheck")).To(BeTrue())
+		Expect(previous.NeedsRerun("ErroredCheck")).To(BeTrue())
+		Expect(previous.NeedsRerun("TimedOutCheck")).To(BeTrue())
+		Expect(previous.NeedsRerun("NewCheck")).To(BeTrue())
+	})
+
+	It("should read waivers written by the JSON formatter, and execute waived checks again", func() {
+		w := &waiver.Waiver{Check: "WaivedCheck", Image: "example.com/repo/*", Justification: "case 01234567", Expires: time.Date(2025, time.June, 30, 0, 0, 0, 0, time.UTC)}
+		waived := newResult("WaivedCheck")
+		waived.Waiver = w
+		expired := newResult("ExpiredCheck")
+		expired.Waiver = w
+		expired.WaiverExpired = true
+		out, err := genericJSONFormatter(context.TODO(), certification.Results{
+			TestedImage: "example.com/repo/image:tag",
+			ImageDigest: "sha256:abc",
+			Failed:      []certification.Result{expired},
+			Warned:      []certification.Result{waived},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(out)).To(ContainSubstring(`"justification": "case 01234567"`))
+		Expect(string(out)).To(ContainSubstring(`"expires": "2025-06-30T00:00:00Z"`))
+		Expect(string(out)).To(ContainSubstring(`"expired": true`))
+
+		previous, err := ReadPreviousResults(bytes.NewReader(out))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(previous.Checks["WaivedCheck"].Waived).To(BeTrue())
+		Expect(previous.Checks["ExpiredCheck"].Waived).To(BeFalse())
+		Expect(previous.NeedsRerun("WaivedCheck")).To(BeTrue())
+	})
+
+	It("should fail if the results are not JSON", func() {
+		_, err := ReadPreviousResults(strings.NewReader("<results/>"))
+		Expect(err).To(MatchError(ContainSubstring("could not parse previous results")))
+	})
+
+	It("should fail if the results do not record the image digest", func() {
+		_, err := ReadPreviousResults(strings.NewReader(`{"image": "example.com/repo/image:tag", "results": {}}`))
+		Expect(err).To(MatchError(ContainSubstring("do not record the digest")))
+	})
+})
@@ -1,7 +1,10 @@
 package formatters
 
 import (
+	"time"
+
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/certification"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/version"
 )
 
@@ -12,13 +15,20 @@ func getResponse(r certification.Results) UserResponse {
 	failedChecks := make([]checkExecutionInfo, 0, len(r.Failed))
 	erroredChecks := make([]checkExecutionInfo, 0, len(r.Errors))
 	warnedChecks := make([]checkExecutionInfo, 0, len(r.Warned))
+	skippedChecks := make([]checkExecutionInfo, 0, len(r.Skipped))
+	timedOutChecks := make([]checkExecutionInfo, 0, len(r.TimedOut))
 
 	if len(r.Passed) > 0 {
 		for _, check := range r.Passed {
 			passedChecks = append(passedChecks, checkExecutionInfo{
-				Name:        check.Name(),
-				ElapsedTime: float64(check.ElapsedTime.Milliseconds()),
-				Description: check.Metadata().Description,
+				Name:          check.Name(),
+				Custom:        check.IsCustom(),
+				ID:            check.Metadata().ID,
+				PolicyVersion: check.Metadata().PolicyVersion,
+				Categories:    check.Metadata().Categories,
+				ElapsedTime:   float64(check.ElapsedTime.Milliseconds()),
+				Description:   check.Metadata().Description,
+				Findings:      check.Findings,
 			})
 		}
 	}
@@ -27,12 +37,18 @@ func getResponse(r certification.Results) UserResponse {
 		for _, check := range r.Failed {
 			failedChecks = append(failedChecks, checkExecutionInfo{
 				Name:             check.Name(),
+				Custom:           check.IsCustom(),
+				ID:               check.Metadata().ID,
+				PolicyVersion:    check.Metadata().PolicyVersion,
+				Categories:       check.Metadata().Categories,
 				ElapsedTime:      float64(check.ElapsedTime.Milliseconds()),
 				Description:      check.Metadata().Description,
 				Help:             check.Help().Message,
 				Suggestion:       check.Help().Suggestion,
 				KnowledgeBaseURL: check.Metadata().KnowledgeBaseURL,
 				CheckURL:         check.Metadata().CheckURL,
+				Findings:         check.Findings,
+				Waiver:           newWaiverInfo(check),
 			})
 		}
 	}
@@ -40,10 +56,15 @@ func getResponse(r certification.Results) UserResponse {
 	if len(r.Errors) > 0 {
 		for _, check := range r.Errors {
 			erroredChecks = append(erroredChecks, checkExecutionInfo{
-				Name:        check.Name(),
-				ElapsedTime: float64(check.ElapsedTime.Milliseconds()),
-				Description: check.Metadata().Description,
-				Help:        check.Help().Message,
+				Name:          check.Name(),
+				Custom:        check.IsCustom(),
+				ID:            check.Metadata().ID,
+				PolicyVersion: check.Metadata().PolicyVersion,
+				Categories:    check.Metadata().Categories,
+				ElapsedTime:   float64(check.ElapsedTime.Milliseconds()),
+				Description:   check.Metadata().Description,
+				Help:          check.Help().Message,
+				Findings:      check.Findings,
 			})
 		}
 	}
@@ -52,18 +73,60 @@ func getResponse(r certification.Results) UserResponse {
 		for _, check := range r.Warned {
 			warnedChecks = append(warnedChecks, checkExecutionInfo{
 				Name:             check.Name(),
+				Custom:           check.IsCustom(),
+				ID:               check.Metadata().ID,
+				PolicyVersion:    check.Metadata().PolicyVersion,
+				Categories:       check.Metadata().Categories,
 				ElapsedTime:      float64(check.ElapsedTime.Milliseconds()),
 				Description:      check.Metadata().Description,
 				Help:             check.Help().Message,
 				Suggestion:       check.Help().Suggestion,
 				KnowledgeBaseURL: check.Metadata().KnowledgeBaseURL,
 				CheckURL:         check.Metadata().CheckURL,
+				Findings:         check.Findings,
+				Waiver:           newWaiverInfo(check),
 			})
 		}
 	}
 
+	for _, check := range r.Skipped {
+		info := checkExecutionInfo{
+			Name:          check.Name(),
+			Custom:        check.IsCustom(),
+			ID:            check.Metadata().ID,
+			PolicyVersion: check.Metadata().PolicyVersion,
+			Categories:    check.Metadata().Categories,
+			ElapsedTime:   float64(check.ElapsedTime.Milliseconds()),
+			Description:   check.Metadata().Description,
+			Findings:      check.Findings,
+		}
+		if err := check.Error(); err != nil {
+			info.Reason = err.Error()
+		}
+		skippedChecks = append(skippedChecks, info)
+	}
+
+	for _, check := range r.TimedOut {
+		info := checkExecutionInfo{
+			Name:          check.Name(),
+			Custom:        check.IsCustom(),
+			ID:            check.Metadata().ID,
+			PolicyVersion: check.Metadata().PolicyVersion,
+			Categories:    check.Metadata().Categories,
+			ElapsedTime:   float64(check.ElapsedTime.Milliseconds()),
+			Description:   check.Metadata().Description,
+			Help:          check.Help().Message,
+			Findings:      check.Findings,
+		}
+		if err := check.Error(); err != nil {
+			info.Reason = err.Error()
+		}
+		timedOutChecks = append(timedOutChecks, info)
+	}
+
 	response := UserResponse{
 		Image:             r.TestedImage,
+		ImageDigest:       r.ImageDigest,
 		Passed:            r.PassedOverall,
 		LibraryInfo:       version.Version,
 		CertificationHash: r.CertificationHash,
@@ -72,6 +135,8 @@ func getResponse(r certification.Results) UserResponse {
 			Failed:   failedChecks,
 			Errors:   erroredChecks,
 			Warnings: warnedChecks,
+			Skipped:  skippedChecks,
+			TimedOut: timedOutChecks,
 		},
 	}
 
@@ -81,6 +146,7 @@ func getResponse(r certification.Results) UserResponse {
 // UserResponse is the standard user-facing response.
 type UserResponse struct {
 	Image             string                 `json:"image" xml:"image"`
+	ImageDigest       string                 `json:"image_digest,omitempty" xml:"image_digest,omitempty"`
 	Passed            bool                   `json:"passed" xml:"passed"`
 	CertificationHash string                 `json:"certification_hash,omitempty" xml:"certification_hash,omitempty"`
 	LibraryInfo       version.VersionContext `json:"test_library" xml:"test_library"`
@@ -93,16 +159,51 @@ type resultsText struct {
 	Failed   []checkExecutionInfo `json:"failed" xml:"failed"`
 	Errors   []checkExecutionInfo `json:"errors" xml:"errors"`
 	Warnings []checkExecutionInfo `json:"warning,omitempty" xml:"warning,omitempty"`
+	Skipped  []checkExecutionInfo `json:"skipped,omitempty" xml:"skipped,omitempty"`
+	TimedOut []checkExecutionInfo `json:"timed_out,omitempty" xml:"timed_out,omitempty"`
 }
 
 // checkExecutionInfo contains all possible output fields that a user might see in their result.
 // Empty fields will be omitted.
 type checkExecutionInfo struct {
-	Name             string  `json:"name,omitempty" xml:"name,omitempty"`
-	ElapsedTime      float64 `json:"elapsed_time" xml:"elapsed_time"`
-	Description      string  `json:"description,omitempty" xml:"description,omitempty"`
-	Help             string  `json:"help,omitempty" xml:"help,omitempty"`
-	Suggestion       string  `json:"suggestion,omitempty" xml:"suggestion,omitempty"`
-	KnowledgeBaseURL string  `json:"knowledgebase_url,omitempty" xml:"knowledgebase_url,omitempty"`
-	CheckURL         string  `json:"check_url,omitempty" xml:"check_url,omitempty"`
+	Name string `json:"name,omitempty" xml:"name,omitempty"`
+	// Custom is true if the check is not part of a Red Hat policy.
+	Custom bool `json:"custom,omitempty" xml:"custom,omitempty"`
+	// ID, PolicyVersion and Categories are copied from the check's metadata.
+	ID               string           `json:"id,omitempty" xml:"id,omitempty"`
+	PolicyVersion    string           `json:"policy_version,omitempty" xml:"policy_version,omitempty"`
+	Categories       []check.Category `json:"categories,omitempty" xml:"categories>category,omitempty"`
+	ElapsedTime      float64          `json:"elapsed_time" xml:"elapsed_time"`
+	Description      string           `json:"description,omitempty" xml:"description,omitempty"`
+	Help             string           `json:"help,omitempty" xml:"help,omitempty"`
+	Suggestion       string           `json:"suggestion,omitempty" xml:"suggestion,omitempty"`
+	KnowledgeBaseURL string           `json:"knowledgebase_url,omitempty" xml:"knowledgebase_url,omitempty"`
+	CheckURL         string           `json:"check_url,omitempty" xml:"check_url,omitempty"`
+	Reason           string           `json:"reason,omitempty" xml:"reason,omitempty"`
+	Findings         []check.Finding  `json:"findings,omitempty" xml:"findings>finding,omitempty"`
+	// Waiver is set if a waiver matched the failure of the check.
+	Waiver *waiverInfo `json:"waiver,omitempty" xml:"waiver,omitempty"`
+}
+
+// waiverInfo describes the waiver that matched the failure of a check.
+type waiverInfo struct {
+	Justification string    `json:"justification" xml:"justification"`
+	Image         string    `json:"image,omitempty" xml:"image,omitempty"`
+	Expires       time.Time `json:"expires" xml:"expires"`
+	// Expired is true if the waiver had expired, so the failure stands.
+	Expired bool `json:"expired,omitempty" xml:"expired,omitempty"`
+}
+
+// newWaiverInfo returns the waiver that matched the failure of r, or nil if
+// none did.
+func newWaiverInfo(r certification.Result) *waiverInfo {
+	if r.Waiver == nil {
+		return nil
+	}
+	return &waiverInfo{
+		Justification: r.Waiver.Justification,
+		Image:         r.Waiver.Image,
+		Expires:       r.Waiver.Expires,
+		Expired:       r.WaiverExpired,
+	}
 }
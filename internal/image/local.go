@@ -0,0 +1,33 @@
+package image
+
+import "strings"
+
+const (
+	// OCILayoutPrefix identifies an image stored in an OCI image layout
+	// directory, e.g. oci:/path/to/layout[:tag].
+	OCILayoutPrefix = "oci:"
+	// DockerArchivePrefix identifies an image stored in a docker-archive
+	// tarball as produced by `docker save`, e.g. docker-archive:/path/to/image.tar[:name:tag].
+	DockerArchivePrefix = "docker-archive:"
+)
+
+// IsLocal reports whether uri refers to an image on the local filesystem
+// rather than an image in a registry.
+func IsLocal(uri string) bool {
+	return strings.HasPrefix(uri, OCILayoutPrefix) || strings.HasPrefix(uri, DockerArchivePrefix)
+}
+
+// SplitLocal splits a local image uri into its transport prefix, the path on
+// disk, and the optional reference following the path. For OCI layouts the
+// reference is a tag; for docker archives it is an image name as stored in
+// the archive's RepoTags. If uri is not local, all values are empty.
+func SplitLocal(uri string) (transport, path, ref string) {
+	for _, prefix := range []string{OCILayoutPrefix, DockerArchivePrefix} {
+		if rest, ok := strings.CutPrefix(uri, prefix); ok {
+			path, ref, _ = strings.Cut(rest, ":")
+			return prefix, path, ref
+		}
+	}
+
+	return "", "", ""
+}
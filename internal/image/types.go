@@ -1,6 +1,10 @@
 package image
 
-import v1 "github.com/google/go-containerregistry/pkg/v1"
+import (
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/inventory"
+)
 
 // ImageReference holds all things image-related
 type ImageReference struct {
@@ -11,4 +15,10 @@ type ImageReference struct {
 	ImageRegistry      string
 	ImageTagOrSha      string
 	ManifestListDigest string
+	// Local is true when the image was loaded from the local filesystem
+	// instead of a registry. Checks requiring registry access should skip.
+	Local bool
+	// Inventory is the software the image contains that is not installed
+	// with RPM. It is nil until the engine has collected it.
+	Inventory *inventory.Inventory
 }
@@ -0,0 +1,34 @@
+package inventory
+
+import (
+	"debug/buildinfo"
+	"runtime/debug"
+)
+
+// goComponents returns the Go toolchain, main module and dependencies
+// compiled into the executable at p. Executables not built by Go, and Go
+// executables built without module support, are an error.
+func goComponents(p string) ([]Component, error) {
+	info, err := buildinfo.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	return buildInfoComponents(info), nil
+}
+
+// buildInfoComponents returns the Go toolchain, main module and dependencies
+// info describes.
+func buildInfoComponents(info *debug.BuildInfo) []Component {
+	components := []Component{{Ecosystem: EcosystemGo, Name: "stdlib", Version: info.GoVersion}}
+	if info.Main.Path != "" {
+		components = append(components, Component{Ecosystem: EcosystemGo, Name: info.Main.Path, Version: info.Main.Version})
+	}
+	for _, dep := range info.Deps {
+		// A replaced module is built from its replacement.
+		if dep.Replace != nil {
+			dep = dep.Replace
+		}
+		components = append(components, Component{Ecosystem: EcosystemGo, Name: dep.Path, Version: dep.Version})
+	}
+	return components
+}
@@ -0,0 +1,209 @@
+// Package inventory finds the application dependencies in an extracted image
+// filesystem that are not installed with RPM: Go modules compiled into
+// executables, Python distributions, Node.js packages and Java archives.
+package inventory
+
+import (
+	"archive/tar"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/go-logr/logr"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
+)
+
+// The ecosystems components are found in.
+const (
+	EcosystemGo     = "go"
+	EcosystemPython = "python"
+	EcosystemNode   = "node"
+	EcosystemJava   = "java"
+)
+
+// requiredFilePatterns are the files the inventory is built from. Go
+// executables have no distinguishing name, so only the directories
+// executables are installed in, and the entrypoint, are searched.
+var requiredFilePatterns = []string{
+	"bin/*",
+	"sbin/*",
+	"usr/bin/*",
+	"usr/sbin/*",
+	"usr/local/bin/*",
+	"usr/local/sbin/*",
+	"**/site-packages/*.dist-info/METADATA",
+	"**/site-packages/*.egg-info",
+	"**/site-packages/*.egg-info/PKG-INFO",
+	"**/dist-packages/*.dist-info/METADATA",
+	"**/dist-packages/*.egg-info",
+	"**/dist-packages/*.egg-info/PKG-INFO",
+	"**/node_modules/*/package.json",
+	"**/node_modules/@*/*/package.json",
+	"**/*.jar",
+	"**/*.war",
+	"**/*.ear",
+}
+
+// Inventory is written to the inventory.json artifact.
+type Inventory struct {
+	Components []Component `json:"components"`
+}
+
+// Component is a dependency found in the image.
+type Component struct {
+	Ecosystem string `json:"ecosystem"`
+	// Name is the module path of Go modules, and groupId:artifactId of
+	// Java archives with Maven metadata.
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// Path is the file the component was found in. Archives nested in
+	// Java archives are separated from the outer archive by "!/".
+	Path string `json:"path"`
+	// Layer is the index of the layer that added the file, or -1 if it
+	// is not known.
+	Layer       int    `json:"layer"`
+	LayerDigest string `json:"layer_digest"`
+}
+
+// RequiredFilePatterns returns the patterns of the files Collect reads, which
+// must be extracted from an image with config.
+func RequiredFilePatterns(config v1.Config) []string {
+	patterns := slices.Clone(requiredFilePatterns)
+	entrypoint := config.Entrypoint
+	if len(entrypoint) == 0 {
+		entrypoint = config.Cmd
+	}
+	if len(entrypoint) > 0 && path.IsAbs(entrypoint[0]) {
+		patterns = append(patterns, strings.TrimLeft(path.Clean(entrypoint[0]), "/"))
+	}
+	return patterns
+}
+
+// Collect returns the components in the image filesystem extracted to fsPath
+// from layers. Files that can not be parsed are skipped.
+func Collect(ctx context.Context, fsPath string, layers []v1.Layer) (*Inventory, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	fileLayers, digests, err := indexLayers(layers)
+	if err != nil {
+		return nil, err
+	}
+
+	inv := &Inventory{Components: []Component{}}
+	err = filepath.WalkDir(fsPath, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(fsPath, p)
+		if err != nil {
+			//coverage:ignore
+			return err
+		}
+		name := "/" + filepath.ToSlash(rel)
+
+		var components []Component
+		switch {
+		case isPythonMetadata(name):
+			components, err = pythonComponents(p)
+		case isNodePackage(name):
+			components, err = nodeComponents(p)
+		case isJavaArchive(name):
+			components, err = javaComponents(p, name)
+		default:
+			info, infoErr := d.Info()
+			if infoErr != nil || info.Mode()&0o111 == 0 {
+				return nil
+			}
+			components, err = goComponents(p)
+		}
+		if err != nil {
+			logger.V(log.TRC).Info("skipping file", "path", name, "reason", err.Error())
+			return nil
+		}
+
+		layer, ok := fileLayers[strings.TrimPrefix(name, "/")]
+		digest := ""
+		if ok {
+			digest = digests[layer]
+		} else {
+			layer = -1
+		}
+		for _, c := range components {
+			if c.Path == "" {
+				c.Path = name
+			}
+			c.Layer = layer
+			c.LayerDigest = digest
+			inv.Components = append(inv.Components, c)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not read the image filesystem: %w", err)
+	}
+
+	slices.SortFunc(inv.Components, func(a, b Component) int {
+		return cmp.Or(
+			strings.Compare(a.Path, b.Path),
+			strings.Compare(a.Ecosystem, b.Ecosystem),
+			strings.Compare(a.Name, b.Name),
+			strings.Compare(a.Version, b.Version),
+		)
+	})
+	logger.V(log.DBG).Info("inventory collected", "componentCount", len(inv.Components))
+	return inv, nil
+}
+
+// indexLayers returns the index of the last layer that added each file, by
+// path without a leading slash, and the digest of each layer. That layer
+// added the file in the extracted filesystem, since a file a later layer
+// deletes is not extracted.
+func indexLayers(layers []v1.Layer) (map[string]int, []string, error) {
+	fileLayers := map[string]int{}
+	digests := make([]string, 0, len(layers))
+	for idx, layer := range layers {
+		digest, err := layer.Digest()
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to retrieve digest for layer %d: %w", idx, err)
+		}
+		digests = append(digests, digest.String())
+
+		if err := indexLayer(fileLayers, idx, layer); err != nil {
+			return nil, nil, fmt.Errorf("reading layer %d: %w", idx, err)
+		}
+	}
+	return fileLayers, digests, nil
+}
+
+// indexLayer records idx as the layer of each file in layer.
+func indexLayer(fileLayers map[string]int, idx int, layer v1.Layer) error {
+	layerReader, err := layer.Uncompressed()
+	if err != nil {
+		return fmt.Errorf("reading layer contents: %w", err)
+	}
+	defer layerReader.Close()
+
+	tarReader := tar.NewReader(layerReader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading tar: %w", err)
+		}
+		fileLayers[strings.TrimPrefix(path.Clean("/"+header.Name), "/")] = idx
+	}
+}
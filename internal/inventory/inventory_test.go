@@ -0,0 +1,161 @@
+package inventory
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"debug/buildinfo"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// writeFile writes contents to name in the filesystem at fsPath.
+func writeFile(fsPath string, name string, contents []byte, mode os.FileMode) {
+	p := filepath.Join(fsPath, name)
+	Expect(os.MkdirAll(filepath.Dir(p), 0o755)).To(Succeed())
+	Expect(os.WriteFile(p, contents, mode)).To(Succeed())
+}
+
+// fileLayer returns a layer adding empty files with names.
+func fileLayer(names ...string) v1.Layer {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, name := range names {
+		Expect(tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0o644})).To(Succeed())
+	}
+	Expect(tw.Close()).To(Succeed())
+	return static.NewLayer(buf.Bytes(), types.DockerUncompressedLayer)
+}
+
+type digestErrorLayer struct{ v1.Layer }
+
+func (digestErrorLayer) Digest() (v1.Hash, error) {
+	return v1.Hash{}, errors.New("no digest")
+}
+
+type unreadableLayer struct{ v1.Layer }
+
+func (unreadableLayer) Uncompressed() (io.ReadCloser, error) {
+	return nil, errors.New("unreadable")
+}
+
+var _ = Describe("Inventory", func() {
+	Context("When listing the files to extract", func() {
+		It("should include the entrypoint", func() {
+			patterns := RequiredFilePatterns(v1.Config{Entrypoint: []string{"/manager"}, Cmd: []string{"/usr/bin/other"}})
+			Expect(patterns).To(ContainElements("usr/local/bin/*", "**/*.jar", "manager"))
+			Expect(patterns).ToNot(ContainElement("usr/bin/other"))
+		})
+
+		It("should include the command if there is no entrypoint", func() {
+			Expect(RequiredFilePatterns(v1.Config{Cmd: []string{"/opt/app/./server", "--port"}})).To(ContainElement("opt/app/server"))
+		})
+
+		It("should not include a relative entrypoint", func() {
+			Expect(RequiredFilePatterns(v1.Config{Entrypoint: []string{"sh", "-c"}})).To(Equal(requiredFilePatterns))
+		})
+	})
+
+	Context("When collecting the inventory", func() {
+		var (
+			fsPath   string
+			appLayer v1.Layer
+			layers   []v1.Layer
+		)
+
+		BeforeEach(func() {
+			fsPath = GinkgoT().TempDir()
+
+			exe, err := os.Executable()
+			Expect(err).ToNot(HaveOccurred())
+			b, err := os.ReadFile(exe)
+			Expect(err).ToNot(HaveOccurred())
+			writeFile(fsPath, "usr/local/bin/app", b, 0o755)
+			writeFile(fsPath, "usr/bin/script.sh", []byte("#!/bin/sh\n"), 0o755)
+			writeFile(fsPath, "usr/share/doc/README", []byte("not an executable"), 0o644)
+
+			writeFile(fsPath, "usr/lib/python3.9/site-packages/requests-2.31.0.dist-info/METADATA",
+				[]byte("Metadata-Version: 2.1\nName: requests\nVersion: 2.31.0\n\nName: not a header\n"), 0o644)
+			writeFile(fsPath, "usr/lib/python3.9/site-packages/legacy-1.0.egg-info/PKG-INFO",
+				[]byte("Metadata-Version: 1.0\nName: legacy\nVersion: 1.0\nSummary\n"), 0o644)
+			writeFile(fsPath, "usr/lib/python3/dist-packages/six-1.16.0.egg-info",
+				[]byte("Name: six\nVersion: 1.16.0\n"), 0o644)
+			writeFile(fsPath, "usr/lib/python3.9/site-packages/broken.dist-info/METADATA", []byte("Version: 1\n"), 0o644)
+			writeFile(fsPath, "usr/lib/python3.9/notpackages/other.dist-info/METADATA", []byte("Name: other\n"), 0o644)
+
+			writeFile(fsPath, "app/package.json", []byte(`{"name": "app", "version": "1.0.0"}`), 0o644)
+			writeFile(fsPath, "app/node_modules/express/package.json", []byte(`{"name": "express", "version": "4.18.2"}`), 0o644)
+			writeFile(fsPath, "app/node_modules/@types/node/package.json", []byte(`{"name": "@types/node", "version": "20.1.0"}`), 0o644)
+			writeFile(fsPath, "app/node_modules/invalid/package.json", []byte(`not json`), 0o644)
+			writeFile(fsPath, "app/node_modules/unnamed/package.json", []byte(`{}`), 0o644)
+
+			writeFile(fsPath, "opt/app/app.jar", zipArchive(map[string][]byte{
+				"META-INF/maven/org.example/app/pom.properties": []byte("groupId=org.example\nartifactId=app\nversion=1.2.3\n"),
+			}), 0o644)
+
+			appLayer = fileLayer("./usr/local/bin/app", "app/node_modules/express/package.json")
+			layers = []v1.Layer{
+				fileLayer("usr/lib/python3.9/site-packages/requests-2.31.0.dist-info/METADATA", "usr/local/bin/app"),
+				appLayer,
+			}
+		})
+
+		It("should find the components of each ecosystem, and the layer that added them", func() {
+			inv, err := Collect(context.Background(), fsPath, layers)
+			Expect(err).ToNot(HaveOccurred())
+
+			info, err := buildinfo.ReadFile(filepath.Join(fsPath, "usr/local/bin/app"))
+			Expect(err).ToNot(HaveOccurred())
+			digest, err := appLayer.Digest()
+			Expect(err).ToNot(HaveOccurred())
+			baseDigest, err := layers[0].Digest()
+			Expect(err).ToNot(HaveOccurred())
+
+			var goComponents []Component
+			for _, c := range buildInfoComponents(info) {
+				c.Path = "/usr/local/bin/app"
+				c.Layer = 1
+				c.LayerDigest = digest.String()
+				goComponents = append(goComponents, c)
+			}
+			Expect(inv.Components).To(HaveLen(6 + len(goComponents)))
+			Expect(inv.Components[6:]).To(ConsistOf(goComponents))
+			Expect(inv.Components[:6]).To(Equal([]Component{
+				{Ecosystem: EcosystemNode, Name: "@types/node", Version: "20.1.0", Path: "/app/node_modules/@types/node/package.json", Layer: -1},
+				{Ecosystem: EcosystemNode, Name: "express", Version: "4.18.2", Path: "/app/node_modules/express/package.json", Layer: 1, LayerDigest: digest.String()},
+				{Ecosystem: EcosystemJava, Name: "org.example:app", Version: "1.2.3", Path: "/opt/app/app.jar", Layer: -1},
+				{Ecosystem: EcosystemPython, Name: "legacy", Version: "1.0", Path: "/usr/lib/python3.9/site-packages/legacy-1.0.egg-info/PKG-INFO", Layer: -1},
+				{Ecosystem: EcosystemPython, Name: "requests", Version: "2.31.0", Path: "/usr/lib/python3.9/site-packages/requests-2.31.0.dist-info/METADATA", Layer: 0, LayerDigest: baseDigest.String()},
+				{Ecosystem: EcosystemPython, Name: "six", Version: "1.16.0", Path: "/usr/lib/python3/dist-packages/six-1.16.0.egg-info", Layer: -1},
+			}))
+		})
+
+		It("should fail if the filesystem can not be read", func() {
+			_, err := Collect(context.Background(), filepath.Join(fsPath, "missing"), layers)
+			Expect(err).To(MatchError(ContainSubstring("could not read the image filesystem")))
+		})
+
+		It("should fail if a layer digest can not be read", func() {
+			_, err := Collect(context.Background(), fsPath, []v1.Layer{digestErrorLayer{appLayer}})
+			Expect(err).To(MatchError(ContainSubstring("unable to retrieve digest for layer 0: no digest")))
+		})
+
+		It("should fail if a layer can not be read", func() {
+			_, err := Collect(context.Background(), fsPath, []v1.Layer{appLayer, unreadableLayer{appLayer}})
+			Expect(err).To(MatchError(ContainSubstring("reading layer 1: reading layer contents: unreadable")))
+		})
+
+		It("should fail if a layer is not a tar", func() {
+			_, err := Collect(context.Background(), fsPath, []v1.Layer{static.NewLayer([]byte("not a tar"), types.DockerUncompressedLayer)})
+			Expect(err).To(MatchError(ContainSubstring("reading layer 0: reading tar")))
+		})
+	})
+})
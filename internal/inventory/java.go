@@ -0,0 +1,196 @@
+package inventory
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+)
+
+const (
+	// maxNestedArchiveSize is the size of the largest archive nested in a
+	// Java archive that is read, since nested archives are read into memory.
+	maxNestedArchiveSize = 64 << 20
+	// maxArchiveDepth is how deeply archives are nested in the archives
+	// that are read, e.g. the dependencies of a Spring Boot executable JAR.
+	maxArchiveDepth = 1
+	// manifestPath is the manifest of a Java archive.
+	manifestPath = "META-INF/MANIFEST.MF"
+)
+
+// archiveVersion splits the file name of an archive without Maven metadata
+// into its name and version, e.g. commons-lang3-3.12.0.
+var archiveVersion = regexp.MustCompile(`^(.*?)-(\d.*)$`)
+
+// isJavaArchive returns true if name is a JAR, WAR or EAR.
+func isJavaArchive(name string) bool {
+	switch path.Ext(name) {
+	case ".jar", ".war", ".ear":
+		return true
+	}
+	return false
+}
+
+// javaComponents returns the components of the Java archive at p, which is
+// name in the image.
+func javaComponents(p string, name string) ([]Component, error) {
+	r, err := zip.OpenReader(p)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return archiveComponents(&r.Reader, name, 0)
+}
+
+// archiveComponents returns the components of the Java archive r, which is
+// name in the image, and of the archives nested in it. An archive with Maven
+// metadata is a component for each pom.properties, since shaded archives
+// include their dependencies. Otherwise, it is described by its manifest, or
+// by its file name.
+func archiveComponents(r *zip.Reader, name string, depth int) ([]Component, error) {
+	var components, nested []Component
+	var manifest map[string]string
+	for _, f := range r.File {
+		switch {
+		case f.Name == manifestPath:
+			b, err := readZipFile(f)
+			if err != nil {
+				return nil, err
+			}
+			manifest = parseManifest(b)
+		case strings.HasPrefix(f.Name, "META-INF/maven/") && path.Base(f.Name) == "pom.properties":
+			b, err := readZipFile(f)
+			if err != nil {
+				return nil, err
+			}
+			props := parseProperties(b)
+			if props["groupId"] == "" || props["artifactId"] == "" {
+				continue
+			}
+			components = append(components, Component{
+				Ecosystem: EcosystemJava,
+				Name:      props["groupId"] + ":" + props["artifactId"],
+				Version:   props["version"],
+				Path:      name,
+			})
+		case isJavaArchive(f.Name) && depth < maxArchiveDepth && f.UncompressedSize64 <= maxNestedArchiveSize:
+			b, err := readZipFile(f)
+			if err != nil {
+				return nil, err
+			}
+			nestedReader, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+			if err != nil {
+				// An entry named like an archive that is not one is
+				// not a component.
+				continue
+			}
+			found, err := archiveComponents(nestedReader, name+"!/"+f.Name, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			nested = append(nested, found...)
+		}
+	}
+
+	if len(components) == 0 {
+		components = append(components, manifestComponent(manifest, name))
+	}
+	return append(components, nested...), nil
+}
+
+// manifestComponent returns the component the manifest of the archive name
+// describes. The name and version are taken from its file name if the
+// manifest does not include them.
+func manifestComponent(manifest map[string]string, name string) Component {
+	base := path.Base(name)
+	base = strings.TrimSuffix(base, path.Ext(base))
+	fileName, fileVersion := base, ""
+	if m := archiveVersion.FindStringSubmatch(base); m != nil {
+		fileName, fileVersion = m[1], m[2]
+	}
+
+	// The symbolic name of an OSGi bundle may be followed by directives,
+	// e.g. org.example.bundle;singleton:=true.
+	symbolicName, _, _ := strings.Cut(manifest["Bundle-SymbolicName"], ";")
+	return Component{
+		Ecosystem: EcosystemJava,
+		Name:      firstNonEmpty(manifest["Implementation-Title"], strings.TrimSpace(symbolicName), fileName),
+		Version:   firstNonEmpty(manifest["Implementation-Version"], manifest["Bundle-Version"], fileVersion),
+		Path:      name,
+	}
+}
+
+// readZipFile returns the contents of f.
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("could not open %s: %w", f.Name, err)
+	}
+	defer rc.Close()
+	b, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", f.Name, err)
+	}
+	return b, nil
+}
+
+// parseManifest returns the attributes of the main section of a JAR
+// manifest. Lines beginning with a space continue the previous line.
+func parseManifest(b []byte) map[string]string {
+	attrs := map[string]string{}
+	var key string
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			break
+		}
+		if strings.HasPrefix(line, " ") {
+			if key != "" {
+				attrs[key] += line[1:]
+			}
+			continue
+		}
+		k, v, ok := strings.Cut(line, ":")
+		if !ok {
+			key = ""
+			continue
+		}
+		key = strings.TrimSpace(k)
+		attrs[key] = strings.TrimSpace(v)
+	}
+	return attrs
+}
+
+// parseProperties returns the key=value pairs of a Java properties file, as
+// written by Maven. Escapes and continuation lines are not supported.
+func parseProperties(b []byte) map[string]string {
+	props := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+		k, v, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		props[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return props
+}
+
+// firstNonEmpty returns the first of values that is not empty.
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
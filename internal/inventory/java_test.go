@@ -0,0 +1,133 @@
+package inventory
+
+import (
+	"archive/zip"
+	"bytes"
+	"maps"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"slices"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// zipArchive returns a zip archive of files, by name.
+func zipArchive(files map[string][]byte) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		w, err := zw.Create(name)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = w.Write(files[name])
+		Expect(err).ToNot(HaveOccurred())
+	}
+	Expect(zw.Close()).To(Succeed())
+	return buf.Bytes()
+}
+
+var _ = Describe("Java archives", func() {
+	var dir string
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+	})
+
+	collect := func(name string, contents []byte) ([]Component, error) {
+		p := filepath.Join(dir, filepath.Base(name))
+		Expect(os.WriteFile(p, contents, 0o644)).To(Succeed())
+		return javaComponents(p, name)
+	}
+
+	It("should find the dependencies nested in an executable archive", func() {
+		components, err := collect("/opt/app/app.jar", zipArchive(map[string][]byte{
+			"META-INF/MANIFEST.MF":                                 []byte("Manifest-Version: 1.0\nImplementation-Title: app\n"),
+			"META-INF/maven/org.example/app/pom.properties":        []byte("#Generated by Maven\ngroupId=org.example\nartifactId=app\nversion=1.2.3\nmalformed\n"),
+			"META-INF/maven/org.example/incomplete/pom.properties": []byte("artifactId=incomplete\n"),
+			"BOOT-INF/lib/commons-lang3-3.12.0.jar":                zipArchive(map[string][]byte{"README": []byte("no metadata")}),
+			"BOOT-INF/lib/guava.jar": zipArchive(map[string][]byte{
+				"META-INF/MANIFEST.MF": []byte("Manifest-Version: 1.0\r\nBundle-SymbolicName: com.google.guava;\r\n singleton:=true\r\nBundle-Version: 31.1.0.jre\r\n\r\nName: section\r\nImplementation-Version: 0\r\n"),
+				"nested.jar":           zipArchive(map[string][]byte{"META-INF/MANIFEST.MF": []byte("Implementation-Title: too deep\n")}),
+			}),
+			"BOOT-INF/lib/fake.jar": []byte("not an archive"),
+		}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(components).To(Equal([]Component{
+			{Ecosystem: EcosystemJava, Name: "org.example:app", Version: "1.2.3", Path: "/opt/app/app.jar"},
+			{Ecosystem: EcosystemJava, Name: "commons-lang3", Version: "3.12.0", Path: "/opt/app/app.jar!/BOOT-INF/lib/commons-lang3-3.12.0.jar"},
+			{Ecosystem: EcosystemJava, Name: "com.google.guava", Version: "31.1.0.jre", Path: "/opt/app/app.jar!/BOOT-INF/lib/guava.jar"},
+		}))
+	})
+
+	It("should describe an archive without Maven metadata by its manifest", func() {
+		components, err := collect("/opt/app/plain.war", zipArchive(map[string][]byte{
+			"META-INF/MANIFEST.MF": []byte("Implementation-Title: plain\nImplementation-Version: 2.0\n-continued\n\n"),
+		}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(components).To(Equal([]Component{{Ecosystem: EcosystemJava, Name: "plain", Version: "2.0", Path: "/opt/app/plain.war"}}))
+	})
+
+	It("should describe an archive without metadata by its file name", func() {
+		components, err := collect("/opt/app/lib.ear", zipArchive(map[string][]byte{"META-INF/MANIFEST.MF": []byte("Manifest-Version: 1.0\n continued\n")}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(components).To(Equal([]Component{{Ecosystem: EcosystemJava, Name: "lib", Path: "/opt/app/lib.ear"}}))
+	})
+
+	It("should fail if the file is not an archive", func() {
+		_, err := collect("/opt/app/broken.jar", []byte("not an archive"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should fail if the manifest can not be opened", func() {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		_, err := zw.CreateRaw(&zip.FileHeader{Name: "META-INF/MANIFEST.MF", Method: 99})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(zw.Close()).To(Succeed())
+
+		_, err = collect("/opt/app/unsupported.jar", buf.Bytes())
+		Expect(err).To(MatchError(ContainSubstring("could not open META-INF/MANIFEST.MF")))
+	})
+
+	DescribeTable("should fail if an entry is corrupt",
+		func(name string) {
+			b := zipArchive(map[string][]byte{name: []byte("groupId=org.example")})
+			// Corrupt the contents, so their checksum does not match.
+			b = bytes.Replace(b, []byte("groupId=org.example"), []byte("groupId=org.exampl_"), 1)
+			_, err := collect("/opt/app/corrupt.jar", b)
+			Expect(err).To(MatchError(ContainSubstring("could not read " + name)))
+		},
+		Entry("manifest", "META-INF/MANIFEST.MF"),
+		Entry("pom.properties", "META-INF/maven/org.example/app/pom.properties"),
+		Entry("nested archive", "BOOT-INF/lib/nested.jar"),
+	)
+
+	It("should fail if a nested archive fails", func() {
+		var nested bytes.Buffer
+		zw := zip.NewWriter(&nested)
+		_, err := zw.CreateRaw(&zip.FileHeader{Name: "META-INF/MANIFEST.MF", Method: 99})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(zw.Close()).To(Succeed())
+
+		_, err = collect("/opt/app/outer.jar", zipArchive(map[string][]byte{"BOOT-INF/lib/nested.jar": nested.Bytes()}))
+		Expect(err).To(MatchError(ContainSubstring("could not open META-INF/MANIFEST.MF")))
+	})
+})
+
+var _ = Describe("Go executables", func() {
+	It("should use the replacement of a replaced module", func() {
+		components := buildInfoComponents(&debug.BuildInfo{
+			GoVersion: "go1.22.1",
+			Deps: []*debug.Module{
+				{Path: "golang.org/x/net", Version: "v0.20.0"},
+				{Path: "example.com/old", Version: "v1.0.0", Replace: &debug.Module{Path: "example.com/fork", Version: "v1.0.1"}},
+			},
+		})
+		Expect(components).To(Equal([]Component{
+			{Ecosystem: EcosystemGo, Name: "stdlib", Version: "go1.22.1"},
+			{Ecosystem: EcosystemGo, Name: "golang.org/x/net", Version: "v0.20.0"},
+			{Ecosystem: EcosystemGo, Name: "example.com/fork", Version: "v1.0.1"},
+		}))
+	})
+})
@@ -0,0 +1,44 @@
+package inventory
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+)
+
+// isNodePackage returns true if name is the package.json of a package
+// installed in node_modules, including scoped packages.
+func isNodePackage(name string) bool {
+	dir, base := path.Split(name)
+	if base != "package.json" {
+		return false
+	}
+	parent := path.Dir(strings.TrimSuffix(dir, "/"))
+	if strings.HasPrefix(path.Base(parent), "@") {
+		parent = path.Dir(parent)
+	}
+	return path.Base(parent) == "node_modules"
+}
+
+// nodeComponents returns the package described by the package.json at p.
+func nodeComponents(p string) ([]Component, error) {
+	b, err := os.ReadFile(p)
+	if err != nil {
+		//coverage:ignore
+		return nil, err
+	}
+
+	var pkg struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal(b, &pkg); err != nil {
+		return nil, fmt.Errorf("invalid package.json: %w", err)
+	}
+	if pkg.Name == "" {
+		return nil, fmt.Errorf("no name in package.json")
+	}
+	return []Component{{Ecosystem: EcosystemNode, Name: pkg.Name, Version: pkg.Version}}, nil
+}
@@ -0,0 +1,70 @@
+package inventory
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+)
+
+// isPythonMetadata returns true if name is the metadata of a distribution
+// installed in site-packages or dist-packages: the METADATA file of a wheel,
+// or the PKG-INFO of an egg, which may be a file or directory.
+func isPythonMetadata(name string) bool {
+	dir, base := path.Split(name)
+	dir = strings.TrimSuffix(dir, "/")
+	switch {
+	case base == "METADATA" && strings.HasSuffix(dir, ".dist-info"):
+		return isPackagesDir(path.Dir(dir))
+	case base == "PKG-INFO" && strings.HasSuffix(dir, ".egg-info"):
+		return isPackagesDir(path.Dir(dir))
+	case strings.HasSuffix(base, ".egg-info"):
+		return isPackagesDir(dir)
+	}
+	return false
+}
+
+// isPackagesDir returns true if dir is a directory Python installs
+// distributions in.
+func isPackagesDir(dir string) bool {
+	base := path.Base(dir)
+	return base == "site-packages" || base == "dist-packages"
+}
+
+// pythonComponents returns the distribution described by the metadata file at
+// p. The metadata is a set of email-style headers, of which only Name and
+// Version are read.
+func pythonComponents(p string) ([]Component, error) {
+	b, err := os.ReadFile(p)
+	if err != nil {
+		//coverage:ignore
+		return nil, err
+	}
+
+	var name, version string
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		line := scanner.Text()
+		// The headers end at the first blank line, and are followed by
+		// the description.
+		if line == "" {
+			break
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "Name":
+			name = strings.TrimSpace(value)
+		case "Version":
+			version = strings.TrimSpace(value)
+		}
+	}
+	if name == "" {
+		return nil, fmt.Errorf("no Name in Python package metadata")
+	}
+	return []Component{{Ecosystem: EcosystemPython, Name: name, Version: version}}, nil
+}
@@ -0,0 +1,298 @@
+// Package layercache provides a persistent, content-addressed cache of image
+// layers that can be shared across preflight runs.
+package layercache
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	cranev1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/cache"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+)
+
+// tempFilePrefix marks files that are still being written to the cache.
+// They are never returned by Get or Entries.
+const tempFilePrefix = ".tmp-"
+
+// staleTempFileAge is how long a temporary file may go unmodified before
+// Prune considers it abandoned, e.g. by a run that was interrupted.
+const staleTempFileAge = time.Hour
+
+var _ cache.Cache = &Cache{}
+
+// Cache is a cache.Cache that stores layers in a directory, keyed by their
+// digest. Layers are written to a temporary file as they are read and only
+// become visible once they have been read completely, so multiple preflight
+// runs can share the same directory.
+type Cache struct {
+	dir string
+}
+
+// LayerInfo describes a layer stored in the cache.
+type LayerInfo struct {
+	Digest   cranev1.Hash
+	Size     int64
+	LastUsed time.Time
+}
+
+// New returns a Cache backed by dir, creating it if it does not exist.
+func New(dir string) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory %s: %w", dir, err)
+	}
+
+	return &Cache{dir: dir}, nil
+}
+
+// Dir returns the directory backing the cache.
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// Put returns a layer that writes its content to the cache as it is read.
+func (c *Cache) Put(l cranev1.Layer) (cranev1.Layer, error) {
+	digest, err := l.Digest()
+	if err != nil {
+		return nil, err
+	}
+	diffID, err := l.DiffID()
+	if err != nil {
+		return nil, err
+	}
+
+	return &layer{
+		Layer:  l,
+		cache:  c,
+		digest: digest,
+		diffID: diffID,
+	}, nil
+}
+
+// Get returns the cached layer for h, or cache.ErrNotFound. Retrieving a
+// layer marks it as recently used, so it is evicted last.
+func (c *Cache) Get(h cranev1.Hash) (cranev1.Layer, error) {
+	path := c.path(h)
+	now := time.Now()
+	if err := os.Chtimes(path, now, now); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, cache.ErrNotFound
+		}
+		//coverage:ignore
+		return nil, err
+	}
+
+	return tarball.LayerFromFile(path)
+}
+
+// Delete removes the layer for h from the cache.
+func (c *Cache) Delete(h cranev1.Hash) error {
+	err := os.Remove(c.path(h))
+	if errors.Is(err, fs.ErrNotExist) {
+		return cache.ErrNotFound
+	}
+
+	return err
+}
+
+// Entries returns the layers in the cache, most recently used first.
+func (c *Cache) Entries() ([]LayerInfo, error) {
+	dirEntries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache directory %s: %w", c.dir, err)
+	}
+
+	entries := make([]LayerInfo, 0, len(dirEntries))
+	for _, de := range dirEntries {
+		h, ok := hashFromFileName(de.Name())
+		if !ok || !de.Type().IsRegular() {
+			continue
+		}
+		info, err := de.Info()
+		if err != nil {
+			//coverage:ignore
+			continue
+		}
+		entries = append(entries, LayerInfo{
+			Digest:   h,
+			Size:     info.Size(),
+			LastUsed: info.ModTime(),
+		})
+	}
+
+	slices.SortFunc(entries, func(a, b LayerInfo) int {
+		return b.LastUsed.Compare(a.LastUsed)
+	})
+
+	return entries, nil
+}
+
+// Prune evicts the least recently used layers until the cache holds no more
+// than maxSize bytes, and removes temporary files left behind by interrupted
+// runs. It returns the evicted entries.
+func (c *Cache) Prune(maxSize int64) ([]LayerInfo, error) {
+	entries, err := c.Entries()
+	if err != nil {
+		return nil, err
+	}
+
+	var total int64
+	var evicted []LayerInfo
+	for _, e := range entries {
+		if total+e.Size <= maxSize {
+			total += e.Size
+			continue
+		}
+		if err := c.Delete(e.Digest); err != nil && !errors.Is(err, cache.ErrNotFound) {
+			return evicted, fmt.Errorf("failed to evict layer %s: %w", e.Digest, err)
+		}
+		evicted = append(evicted, e)
+	}
+
+	if err := c.removeStaleTempFiles(); err != nil {
+		return evicted, err
+	}
+
+	return evicted, nil
+}
+
+// removeStaleTempFiles deletes temporary files that have not been written to
+// within staleTempFileAge.
+func (c *Cache) removeStaleTempFiles() error {
+	dirEntries, err := os.ReadDir(c.dir)
+	if err != nil {
+		//coverage:ignore
+		return fmt.Errorf("failed to read cache directory %s: %w", c.dir, err)
+	}
+
+	for _, de := range dirEntries {
+		if !strings.HasPrefix(de.Name(), tempFilePrefix) {
+			continue
+		}
+		info, err := de.Info()
+		if err != nil || time.Since(info.ModTime()) < staleTempFileAge {
+			continue
+		}
+		if err := os.Remove(filepath.Join(c.dir, de.Name())); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			//coverage:ignore
+			return fmt.Errorf("failed to remove temporary file %s: %w", de.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+// path returns the location of the layer for h. The algorithm and hex are
+// separated by a dash so the name is valid on every platform.
+func (c *Cache) path(h cranev1.Hash) string {
+	return filepath.Join(c.dir, h.Algorithm+"-"+h.Hex)
+}
+
+// hashFromFileName is the inverse of path.
+func hashFromFileName(name string) (cranev1.Hash, bool) {
+	algorithm, hex, ok := strings.Cut(name, "-")
+	if !ok {
+		return cranev1.Hash{}, false
+	}
+	h, err := cranev1.NewHash(algorithm + ":" + hex)
+	if err != nil {
+		return cranev1.Hash{}, false
+	}
+
+	return h, true
+}
+
+// layer is a cranev1.Layer whose content is written to the cache as it is
+// read.
+type layer struct {
+	cranev1.Layer
+	cache          *Cache
+	digest, diffID cranev1.Hash
+}
+
+func (l *layer) Compressed() (io.ReadCloser, error) {
+	rc, err := l.Layer.Compressed()
+	if err != nil {
+		return nil, err
+	}
+
+	return l.cache.tee(rc, l.digest)
+}
+
+func (l *layer) Uncompressed() (io.ReadCloser, error) {
+	rc, err := l.Layer.Uncompressed()
+	if err != nil {
+		return nil, err
+	}
+
+	return l.cache.tee(rc, l.diffID)
+}
+
+// tee returns a reader that copies rc into a temporary file, which is moved
+// into place as the layer for h once rc has been read to the end.
+func (c *Cache) tee(rc io.ReadCloser, h cranev1.Hash) (io.ReadCloser, error) {
+	f, err := os.CreateTemp(c.dir, tempFilePrefix+h.Hex+"-*")
+	if err != nil {
+		rc.Close()
+		return nil, fmt.Errorf("failed to create cache file for %s: %w", h, err)
+	}
+
+	return &cachingReader{
+		rc:   rc,
+		f:    f,
+		r:    io.TeeReader(rc, f),
+		dest: c.path(h),
+	}, nil
+}
+
+type cachingReader struct {
+	rc   io.ReadCloser
+	f    *os.File
+	r    io.Reader
+	dest string
+	done bool
+}
+
+func (cr *cachingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	if errors.Is(err, io.EOF) {
+		cr.done = true
+	}
+
+	return n, err
+}
+
+// Close closes the underlying reader and either commits or discards the
+// cached copy. Readers such as archive/tar stop at the end-of-archive marker
+// and leave trailing padding unread, so the remainder of the layer is read
+// before it is committed.
+func (cr *cachingReader) Close() error {
+	if !cr.done {
+		if _, err := io.Copy(io.Discard, cr); err == nil {
+			cr.done = true
+		}
+	}
+
+	rcErr := cr.rc.Close()
+	fErr := cr.f.Close()
+
+	if !cr.done || rcErr != nil || fErr != nil {
+		_ = os.Remove(cr.f.Name())
+		return errors.Join(rcErr, fErr)
+	}
+
+	if err := os.Rename(cr.f.Name(), cr.dest); err != nil {
+		//coverage:ignore
+		_ = os.Remove(cr.f.Name())
+		return fmt.Errorf("failed to commit cache file %s: %w", cr.dest, err)
+	}
+
+	return nil
+}
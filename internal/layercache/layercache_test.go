@@ -0,0 +1,203 @@
+package layercache
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	cranev1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/cache"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Layer cache", func() {
+	var dir string
+	var lc *Cache
+	var layer cranev1.Layer
+
+	BeforeEach(func() {
+		dir = filepath.Join(GinkgoT().TempDir(), "cache")
+		var err error
+		lc, err = New(dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(lc.Dir()).To(Equal(dir))
+
+		layer, err = random.Layer(1024, types.DockerLayer)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should fail to create a cache below a file", func() {
+		f := filepath.Join(GinkgoT().TempDir(), "file")
+		Expect(os.WriteFile(f, nil, 0o600)).To(Succeed())
+		_, err := New(filepath.Join(f, "cache"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should not find layers that were never cached", func() {
+		digest, err := layer.Digest()
+		Expect(err).ToNot(HaveOccurred())
+		_, err = lc.Get(digest)
+		Expect(err).To(MatchError(cache.ErrNotFound))
+		Expect(lc.Delete(digest)).To(MatchError(cache.ErrNotFound))
+	})
+
+	Context("when a layer is read through the cache", func() {
+		It("should cache the uncompressed layer by its diff ID", func() {
+			cached, err := lc.Put(layer)
+			Expect(err).ToNot(HaveOccurred())
+			want := readAll(cached.Uncompressed())
+
+			diffID, err := layer.DiffID()
+			Expect(err).ToNot(HaveOccurred())
+			got, err := lc.Get(diffID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(readAll(got.Uncompressed())).To(Equal(want))
+
+			entries, err := lc.Entries()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(HaveLen(1))
+			Expect(entries[0].Digest).To(Equal(diffID))
+			Expect(entries[0].Size).To(BeEquivalentTo(len(want)))
+		})
+
+		It("should cache the whole compressed layer when it is only partially read", func() {
+			cached, err := lc.Put(layer)
+			Expect(err).ToNot(HaveOccurred())
+			rc, err := cached.Compressed()
+			Expect(err).ToNot(HaveOccurred())
+			_, err = rc.Read(make([]byte, 10))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rc.Close()).To(Succeed())
+
+			digest, err := layer.Digest()
+			Expect(err).ToNot(HaveOccurred())
+			got, err := lc.Get(digest)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(got.Digest()).To(Equal(digest))
+		})
+
+		It("should not cache a layer that could not be read", func() {
+			cached, err := lc.Put(&failingLayer{Layer: layer})
+			Expect(err).ToNot(HaveOccurred())
+			rc, err := cached.Uncompressed()
+			Expect(err).ToNot(HaveOccurred())
+			_, err = io.ReadAll(rc)
+			Expect(err).To(MatchError(errRead))
+			Expect(rc.Close()).To(Succeed())
+
+			files, err := os.ReadDir(dir)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(files).To(BeEmpty())
+		})
+	})
+
+	Context("with several cached layers", func() {
+		var old, older, newest cranev1.Hash
+		BeforeEach(func() {
+			now := time.Now()
+			old = writeEntry(dir, 100, now.Add(-time.Hour))
+			older = writeEntry(dir, 100, now.Add(-2*time.Hour))
+			newest = writeEntry(dir, 100, now)
+			Expect(os.WriteFile(filepath.Join(dir, "unrelated"), nil, 0o600)).To(Succeed())
+		})
+
+		It("should list layers most recently used first", func() {
+			entries, err := lc.Entries()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(digests(entries)).To(Equal([]cranev1.Hash{newest, old, older}))
+		})
+
+		It("should evict the least recently used layers beyond the maximum size", func() {
+			evicted, err := lc.Prune(250)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(digests(evicted)).To(Equal([]cranev1.Hash{older}))
+
+			entries, err := lc.Entries()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(digests(entries)).To(Equal([]cranev1.Hash{newest, old}))
+		})
+
+		It("should evict everything when the maximum size is zero", func() {
+			evicted, err := lc.Prune(0)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(evicted).To(HaveLen(3))
+			Expect(filepath.Join(dir, "unrelated")).To(BeAnExistingFile())
+		})
+
+		It("should remove abandoned temporary files only", func() {
+			stale := filepath.Join(dir, tempFilePrefix+"stale")
+			fresh := filepath.Join(dir, tempFilePrefix+"fresh")
+			Expect(os.WriteFile(stale, nil, 0o600)).To(Succeed())
+			Expect(os.WriteFile(fresh, nil, 0o600)).To(Succeed())
+			past := time.Now().Add(-2 * staleTempFileAge)
+			Expect(os.Chtimes(stale, past, past)).To(Succeed())
+
+			_, err := lc.Prune(1000)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stale).ToNot(BeAnExistingFile())
+			Expect(fresh).To(BeAnExistingFile())
+		})
+	})
+
+	It("should fail to list a cache whose directory was removed", func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+		_, err := lc.Entries()
+		Expect(err).To(HaveOccurred())
+		_, err = lc.Prune(0)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var errRead = errors.New("read failed")
+
+// failingLayer is a layer whose uncompressed content can not be read.
+type failingLayer struct {
+	cranev1.Layer
+}
+
+func (l *failingLayer) Uncompressed() (io.ReadCloser, error) {
+	return io.NopCloser(io.MultiReader(bytes.NewReader([]byte("partial")), errReader{})), nil
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) {
+	return 0, errRead
+}
+
+func readAll(rc io.ReadCloser, err error) []byte {
+	Expect(err).ToNot(HaveOccurred())
+	b, err := io.ReadAll(rc)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(rc.Close()).To(Succeed())
+	return b
+}
+
+// writeEntry writes a cache entry of size bytes that was last used at lastUsed.
+func writeEntry(dir string, size int, lastUsed time.Time) cranev1.Hash {
+	content := make([]byte, size)
+	_, err := rand.Read(content)
+	Expect(err).ToNot(HaveOccurred())
+	h, _, err := cranev1.SHA256(bytes.NewReader(content))
+	Expect(err).ToNot(HaveOccurred())
+
+	path := filepath.Join(dir, h.Algorithm+"-"+h.Hex)
+	Expect(os.WriteFile(path, content, 0o600)).To(Succeed())
+	Expect(os.Chtimes(path, lastUsed, lastUsed)).To(Succeed())
+	return h
+}
+
+func digests(entries []LayerInfo) []cranev1.Hash {
+	hashes := make([]cranev1.Hash, 0, len(entries))
+	for _, e := range entries {
+		hashes = append(hashes, e.Digest)
+	}
+	return hashes
+}
@@ -32,21 +32,11 @@ func GetContainerPolicyExceptions(certProject *pyxis.CertProject) policy.Policy
 		return policy.PolicyContainer
 	}
 
-	// if the partner has gotten a scratch exception from the business and os_content_type == "Scratch Image"
-	// and a partner sets `Host Level Access` in connect to `Privileged`, enable ScratchRootContainerPolicy checks
-	if certProject.ScratchProject() && certProject.Container.Privileged {
-		return policy.PolicyScratchRoot
-	}
-
-	// if the partner has gotten a scratch exception from the business and os_content_type == "Scratch Image",
-	// enable ScratchNonRootContainerPolicy checks
-	if certProject.ScratchProject() {
-		return policy.PolicyScratchNonRoot
-	}
-
-	// if a partner sets `Host Level Access` in connect to `Privileged`, enable RootExceptionContainerPolicy checks
-	if certProject.Container.Privileged {
-		return policy.PolicyRoot
-	}
-	return policy.PolicyContainer
+	// a scratch project is one where the partner has gotten a scratch exception from the business and
+	// os_content_type == "Scratch Image". A privileged project is one where the partner sets
+	// `Host Level Access` in connect to `Privileged`.
+	return policy.DefaultRegistry().ForException(policy.Exception{
+		Scratch:    certProject.ScratchProject(),
+		Privileged: certProject.Container.Privileged,
+	})
 }
@@ -0,0 +1,154 @@
+// Package license identifies the licenses in license files, using the corpus
+// of SPDX license texts embedded in github.com/google/licensecheck. No network
+// access is required.
+package license
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/google/licensecheck"
+)
+
+// identifierTag is the prefix of an SPDX short-form identifier, which names
+// the license of a file without including its text.
+const identifierTag = "SPDX-License-Identifier:"
+
+// Classification is the result of identifying the licenses in a text.
+type Classification struct {
+	// Licenses are the SPDX identifiers of the licenses found, in the
+	// order they were first found.
+	Licenses []string
+	// Coverage is the percentage of the text that matched a license in the
+	// corpus. Texts identified only by an SPDX-License-Identifier tag have
+	// a coverage of zero.
+	Coverage float64
+}
+
+// Identified returns true if at least one license was found.
+func (c Classification) Identified() bool {
+	return len(c.Licenses) > 0
+}
+
+// Classify identifies the licenses in text, either by matching it against
+// the license corpus, or by reading its SPDX-License-Identifier tags.
+func Classify(text []byte) Classification {
+	cov := licensecheck.Scan(text)
+
+	var ids []string
+	for _, m := range cov.Match {
+		ids = appendUnique(ids, m.ID)
+	}
+	for _, id := range identifierTags(text) {
+		ids = appendUnique(ids, id)
+	}
+
+	return Classification{
+		Licenses: ids,
+		Coverage: cov.Percent,
+	}
+}
+
+// identifierTags returns the licenses named in SPDX-License-Identifier tags
+// in text. Compound expressions, e.g. "MIT OR Apache-2.0", name each of their
+// licenses.
+func identifierTags(text []byte) []string {
+	var ids []string
+	scanner := bufio.NewScanner(bytes.NewReader(text))
+	for scanner.Scan() {
+		_, expr, ok := strings.Cut(scanner.Text(), identifierTag)
+		if !ok {
+			continue
+		}
+		expr = strings.NewReplacer("(", " ", ")", " ").Replace(expr)
+		for _, field := range strings.Fields(expr) {
+			switch strings.ToUpper(field) {
+			case "AND", "OR", "WITH":
+				continue
+			}
+			// Comment terminators, e.g. "*/", are not part of the
+			// expression.
+			if strings.Trim(field, "*/-#") == "" {
+				continue
+			}
+			ids = appendUnique(ids, field)
+		}
+	}
+	return ids
+}
+
+// identifiers are the SPDX identifiers of the licenses in the corpus, by
+// their lower case form.
+var identifiers = sync.OnceValue(func() map[string]string {
+	ids := map[string]string{}
+	for _, l := range licensecheck.BuiltinLicenses() {
+		ids[strings.ToLower(l.ID)] = l.ID
+	}
+	return ids
+})
+
+// Identifier returns the SPDX identifier of the license in the corpus that id
+// names, ignoring case as SPDX does, and true if there is one.
+func Identifier(id string) (string, bool) {
+	canonical, ok := identifiers()[strings.ToLower(id)]
+	return canonical, ok
+}
+
+func appendUnique(ids []string, id string) []string {
+	if slices.Contains(ids, id) {
+		return ids
+	}
+	return append(ids, id)
+}
+
+// Rules restrict the licenses an image may include. Patterns are SPDX
+// identifiers, which may contain path.Match wildcards, e.g. GPL-*, and are
+// matched case insensitively.
+type Rules struct {
+	// Allowed, if not empty, are the only licenses permitted.
+	Allowed []string
+	// Denied are licenses that are never permitted, even if allowed.
+	Denied []string
+}
+
+// IsZero returns true if r permits every license.
+func (r Rules) IsZero() bool {
+	return len(r.Allowed) == 0 && len(r.Denied) == 0
+}
+
+// Validate returns an error if a pattern in r is malformed.
+func (r Rules) Validate() error {
+	for _, pattern := range slices.Concat(r.Allowed, r.Denied) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid license pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// Evaluate returns an empty string if the license id is permitted by r.
+// Otherwise, it returns the reason it is not.
+func (r Rules) Evaluate(id string) string {
+	if matchAny(r.Denied, id) {
+		return "license is denied"
+	}
+	if len(r.Allowed) > 0 && !matchAny(r.Allowed, id) {
+		return "license is not allowed"
+	}
+	return ""
+}
+
+func matchAny(patterns []string, id string) bool {
+	for _, pattern := range patterns {
+		// Patterns are checked by Validate.
+		if ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(id)); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package license
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const mitText = `MIT License
+
+Copyright (c) 2024 Example Corp
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+`
+
+var _ = Describe("License classification", func() {
+	It("should identify a license by its text", func() {
+		c := Classify([]byte(mitText))
+		Expect(c.Identified()).To(BeTrue())
+		Expect(c.Licenses).To(Equal([]string{"MIT"}))
+		Expect(c.Coverage).To(BeNumerically(">", 90))
+	})
+
+	It("should identify licenses by their SPDX-License-Identifier tags", func() {
+		c := Classify([]byte("/* SPDX-License-Identifier: (MIT OR Apache-2.0) AND GPL-2.0-only WITH Classpath-exception-2.0 */\n# SPDX-License-Identifier: MIT\n"))
+		Expect(c.Licenses).To(Equal([]string{"MIT", "Apache-2.0", "GPL-2.0-only", "Classpath-exception-2.0"}))
+	})
+
+	It("should return the identifier of a license in the corpus", func() {
+		id, ok := Identifier("gpl-2.0-OR-later")
+		Expect(ok).To(BeTrue())
+		Expect(id).To(Equal("GPL-2.0-or-later"))
+
+		_, ok = Identifier("GPLv2+")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should not identify text that is not a license", func() {
+		c := Classify([]byte("This is a license"))
+		Expect(c.Identified()).To(BeFalse())
+		Expect(c.Coverage).To(BeZero())
+	})
+})
+
+var _ = Describe("License rules", func() {
+	DescribeTable("should evaluate licenses",
+		func(rules Rules, id string, expected string) {
+			Expect(rules.Evaluate(id)).To(Equal(expected))
+		},
+		Entry("without rules", Rules{}, "GPL-3.0", ""),
+		Entry("an allowed license", Rules{Allowed: []string{"mit", "Apache-*"}}, "Apache-2.0", ""),
+		Entry("a license that is not allowed", Rules{Allowed: []string{"MIT"}}, "GPL-3.0", "license is not allowed"),
+		Entry("a denied license", Rules{Denied: []string{"AGPL-*"}}, "AGPL-3.0", "license is denied"),
+		Entry("a denied license that is also allowed", Rules{Allowed: []string{"*"}, Denied: []string{"AGPL-3.0"}}, "AGPL-3.0", "license is denied"),
+	)
+
+	It("should report whether it permits every license", func() {
+		Expect(Rules{}.IsZero()).To(BeTrue())
+		Expect(Rules{Denied: []string{"MIT"}}.IsZero()).To(BeFalse())
+	})
+
+	It("should refuse malformed patterns", func() {
+		Expect(Rules{Allowed: []string{"MIT"}, Denied: []string{"GPL-["}}.Validate()).To(MatchError(ContainSubstring(`invalid license pattern "GPL-["`)))
+		Expect(Rules{Allowed: []string{"MIT"}}.Validate()).To(Succeed())
+	})
+})
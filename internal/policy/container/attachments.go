@@ -0,0 +1,45 @@
+package container
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/option"
+)
+
+// attachmentSubjects returns the repository of imgRef, and the digests cosign
+// may have attached signatures and attestations of the image to: the image,
+// and the manifest list it was selected from, if any. cosign signs the
+// manifest list of a multi-platform image, unless each platform is signed
+// separately. The options access the registry as the image was pulled.
+// Images that were not pulled from a registry have no attachments, and the
+// check is skipped.
+func attachmentSubjects(ctx context.Context, imgRef image.ImageReference, craneConfig option.CraneConfig) (name.Repository, []v1.Hash, []remote.Option, error) {
+	imgRepo := fmt.Sprintf("%s/%s", imgRef.ImageRegistry, imgRef.ImageRepository)
+	if imgRef.Local {
+		return name.Repository{}, nil, nil, fmt.Errorf("%w: attachments of %s are read from a registry, but the image was loaded from a local path", check.ErrCheckSkipped, imgRepo)
+	}
+
+	digest, err := imgRef.ImageInfo.Digest()
+	if err != nil {
+		return name.Repository{}, nil, nil, fmt.Errorf("could not get the image digest: %w", err)
+	}
+	options := crane.GetOptions(option.GenerateCraneOptions(ctx, craneConfig)...)
+	repo, err := name.NewRepository(imgRepo, options.Name...)
+	if err != nil {
+		return name.Repository{}, nil, nil, fmt.Errorf("failed to parse repository name: %w", err)
+	}
+
+	digests := []v1.Hash{digest}
+	if listDigest, err := v1.NewHash(imgRef.ManifestListDigest); err == nil && listDigest != digest {
+		digests = append(digests, listDigest)
+	}
+	return repo, digests, options.Remote, nil
+}
@@ -77,6 +77,9 @@ func (p *BasedOnUBICheck) Name() string {
 
 func (p *BasedOnUBICheck) Metadata() check.Metadata {
 	return check.Metadata{
+		ID:               "PFLT-CNT-009",
+		PolicyVersion:    "1.0",
+		Categories:       []check.Category{check.CategoryPackaging},
 		Description:      "Checking if the container's base image is based upon the Red Hat Universal Base Image (UBI)",
 		Level:            "best",
 		KnowledgeBaseURL: certDocumentationURL,
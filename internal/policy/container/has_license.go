@@ -1,25 +1,40 @@
 package container
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
-	"slices"
 	"strings"
 
 	"github.com/go-logr/logr"
 
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/license"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
 )
 
 const (
 	licensePath         = "/licenses"
 	minLicenseFileCount = 1
+	// maxLicenseFileSize is the number of bytes of each license file that
+	// are classified.
+	maxLicenseFileSize = 1 << 20
+)
+
+// The statuses of a file in the license report.
+const (
+	licenseStatusIdentified   = "identified"
+	licenseStatusUnidentified = "unidentified"
+	licenseStatusEmpty        = "empty"
 )
 
 var errLicensesNotADir = errors.New("licenses is not a directory")
@@ -49,39 +64,77 @@ func pathWithinMount(mount, resolved string) bool {
 	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
 }
 
-// resolvedDirEntry wraps a WalkDir DirEntry so Info() returns metadata from
-// os.Stat (the followed target) instead of the symlink's DirEntry metadata.
-type resolvedDirEntry struct {
-	fs.DirEntry
-	fi fs.FileInfo
+// licenseFile is a regular file found beneath /licenses.
+type licenseFile struct {
+	// name is the path of the file in the image, e.g. /licenses/LICENSE.
+	name string
+	// path is the path the file can be read from, with symlinks resolved.
+	path string
+	size int64
 }
 
-func (r resolvedDirEntry) Info() (fs.FileInfo, error) {
-	return r.fi, nil
+// licenseReport is written to the licenses.json artifact.
+type licenseReport struct {
+	Files []licenseFileReport `json:"files"`
 }
 
-var _ check.Check = &HasLicenseCheck{}
+// licenseFileReport describes the licenses identified in a single file.
+type licenseFileReport struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+	// Status is one of identified, unidentified or empty.
+	Status   string   `json:"status"`
+	Licenses []string `json:"licenses"`
+	// Coverage is the percentage of the file that matched a known license.
+	Coverage float64 `json:"coverage"`
+}
+
+var (
+	_ check.Check            = &HasLicenseCheck{}
+	_ check.FindingsReporter = &HasLicenseCheck{}
+)
 
 // HasLicenseCheck evaluates that the image contains a license definition available at
-// /licenses.
-type HasLicenseCheck struct{}
+// /licenses. The SPDX licenses in each file are identified, and may be restricted
+// with rules.
+type HasLicenseCheck struct {
+	rules license.Rules
+}
+
+// NewHasLicenseCheck returns a HasLicenseCheck that fails if the image
+// includes a license rules does not permit.
+func NewHasLicenseCheck(rules license.Rules) *HasLicenseCheck {
+	return &HasLicenseCheck{rules: rules}
+}
 
 func (p *HasLicenseCheck) Validate(ctx context.Context, imgRef image.ImageReference) (bool, error) {
+	passed, _, err := p.ValidateWithFindings(ctx, imgRef)
+	return passed, err
+}
+
+// ValidateWithFindings reports the licenses identified in each license file,
+// along with files that are empty, could not be identified, or contain a
+// license the rules do not permit.
+func (p *HasLicenseCheck) ValidateWithFindings(ctx context.Context, imgRef image.ImageReference) (bool, []check.Finding, error) {
 	logger := logr.FromContextOrDiscard(ctx)
 	licenseFileList, err := p.getDataToValidate(ctx, imgRef.ImageFSPath)
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) || errors.Is(err, errLicensesNotADir) {
 			logger.Info(fmt.Sprintf("warning: licenses directory does not exist or all of its children are empty directories: %s", err))
-			return false, nil
+			return false, []check.Finding{{
+				Message:  "licenses directory does not exist",
+				Object:   licensePath,
+				Severity: check.SeverityError,
+			}}, nil
 		}
 		//coverage:ignore
-		return false, fmt.Errorf("could not get license file list: %v", err)
+		return false, nil, fmt.Errorf("could not get license file list: %v", err)
 	}
 	return p.validate(ctx, licenseFileList)
 }
 
 //nolint:unparam // ctx is unused. Keep for future use.
-func (p *HasLicenseCheck) getDataToValidate(ctx context.Context, mountedPath string) ([]fs.DirEntry, error) {
+func (p *HasLicenseCheck) getDataToValidate(ctx context.Context, mountedPath string) ([]licenseFile, error) {
 	logger := logr.FromContextOrDiscard(ctx)
 	mountRoot := canonicalMountRoot(mountedPath)
 	fullPath := filepath.Join(mountedPath, licensePath)
@@ -102,7 +155,7 @@ func (p *HasLicenseCheck) getDataToValidate(ctx context.Context, mountedPath str
 		walkRoot = rp
 	}
 
-	var files []fs.DirEntry
+	var files []licenseFile
 	err = filepath.WalkDir(walkRoot, func(p string, d fs.DirEntry, err error) error {
 		if err != nil {
 			//coverage:ignore
@@ -121,7 +174,16 @@ func (p *HasLicenseCheck) getDataToValidate(ctx context.Context, mountedPath str
 		if errSt != nil || !fi.Mode().IsRegular() {
 			return nil
 		}
-		files = append(files, resolvedDirEntry{DirEntry: d, fi: fi})
+		rel, errRel := filepath.Rel(walkRoot, p)
+		if errRel != nil {
+			//coverage:ignore
+			return errRel
+		}
+		files = append(files, licenseFile{
+			name: path.Join(licensePath, filepath.ToSlash(rel)),
+			path: resolved,
+			size: fi.Size(),
+		})
 		return nil
 	})
 	if err != nil {
@@ -131,21 +193,92 @@ func (p *HasLicenseCheck) getDataToValidate(ctx context.Context, mountedPath str
 	return files, nil
 }
 
-//nolint:unparam // ctx is unused. Keep for future use.
-func (p *HasLicenseCheck) validate(ctx context.Context, licenseFileList []fs.DirEntry) (bool, error) {
+func (p *HasLicenseCheck) validate(ctx context.Context, licenseFileList []licenseFile) (bool, []check.Finding, error) {
 	logger := logr.FromContextOrDiscard(ctx)
 
-	nonZeroLength := slices.ContainsFunc(licenseFileList, func(f fs.DirEntry) bool {
-		info, err := f.Info()
+	var findings []check.Finding
+	report := licenseReport{Files: make([]licenseFileReport, 0, len(licenseFileList))}
+	nonZeroLength := false
+	permitted := true
+	for _, f := range licenseFileList {
+		fileReport := licenseFileReport{Path: f.name, Size: f.size, Licenses: []string{}}
+		if f.size == 0 {
+			fileReport.Status = licenseStatusEmpty
+			report.Files = append(report.Files, fileReport)
+			findings = append(findings, check.Finding{
+				Message:  "license file is empty",
+				Object:   f.name,
+				Severity: check.SeverityWarning,
+			})
+			continue
+		}
+		nonZeroLength = true
+
+		text, err := readLicenseFile(f.path)
 		if err != nil {
 			//coverage:ignore
-			return false
+			return false, nil, fmt.Errorf("could not read license file %s: %w", f.name, err)
 		}
-		return info.Size() > 0
-	})
+
+		classification := license.Classify(text)
+		fileReport.Coverage = classification.Coverage
+		if !classification.Identified() {
+			fileReport.Status = licenseStatusUnidentified
+			report.Files = append(report.Files, fileReport)
+			findings = append(findings, check.Finding{
+				Message:  "license could not be identified",
+				Object:   f.name,
+				Severity: check.SeverityWarning,
+			})
+			continue
+		}
+
+		fileReport.Status = licenseStatusIdentified
+		fileReport.Licenses = classification.Licenses
+		report.Files = append(report.Files, fileReport)
+		for _, id := range classification.Licenses {
+			if reason := p.rules.Evaluate(id); reason != "" {
+				permitted = false
+				findings = append(findings, check.Finding{
+					Message:  fmt.Sprintf("%s: %s", reason, id),
+					Object:   f.name,
+					Severity: check.SeverityError,
+				})
+				continue
+			}
+			findings = append(findings, check.Finding{
+				Message:  fmt.Sprintf("identified license %s", id),
+				Object:   f.name,
+				Severity: check.SeverityInfo,
+			})
+		}
+	}
+
+	if artifactWriter := artifacts.WriterFromContext(ctx); artifactWriter != nil {
+		reportJSON, err := json.MarshalIndent(report, "", "    ")
+		if err != nil {
+			//coverage:ignore
+			return false, nil, fmt.Errorf("could not marshal the license report: %w", err)
+		}
+		if _, err := artifactWriter.WriteFile(check.DefaultLicensesFilename, bytes.NewReader(reportJSON)); err != nil {
+			//coverage:ignore
+			return false, nil, fmt.Errorf("could not write the license report: %w", err)
+		}
+	}
 
 	logger.V(log.DBG).Info("number of licenses found", "licenseCount", len(licenseFileList))
-	return len(licenseFileList) >= minLicenseFileCount && nonZeroLength, nil
+	return len(licenseFileList) >= minLicenseFileCount && nonZeroLength && permitted, findings, nil
+}
+
+// readLicenseFile reads at most maxLicenseFileSize bytes of the file at path.
+func readLicenseFile(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		//coverage:ignore
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(io.LimitReader(f, maxLicenseFileSize))
 }
 
 func (p *HasLicenseCheck) Name() string {
@@ -154,6 +287,9 @@ func (p *HasLicenseCheck) Name() string {
 
 func (p *HasLicenseCheck) Metadata() check.Metadata {
 	return check.Metadata{
+		ID:               "PFLT-CNT-001",
+		PolicyVersion:    "1.0",
+		Categories:       []check.Category{check.CategoryLicensing},
 		Description:      "Checking if terms and conditions applicable to the software including open source licensing information are present. The license must be at /licenses",
 		Level:            "best",
 		KnowledgeBaseURL: certDocumentationURL,
@@ -164,7 +300,7 @@ func (p *HasLicenseCheck) Metadata() check.Metadata {
 func (p *HasLicenseCheck) Help() check.HelpText {
 	return check.HelpText{
 		Message:    "Check HasLicense encountered an error. Please review the preflight.log file for more information.",
-		Suggestion: "Create a directory named /licenses and include all relevant licensing and/or terms and conditions as text file(s) in that directory.",
+		Suggestion: "Create a directory named /licenses and include all relevant licensing and/or terms and conditions as text file(s) in that directory. Each file should contain the full text of a license, or name it with an SPDX-License-Identifier tag.",
 	}
 }
 
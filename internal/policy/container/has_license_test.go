@@ -2,6 +2,7 @@ package container
 
 import (
 	"context"
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -9,7 +10,10 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/license"
 )
 
 const (
@@ -18,6 +22,29 @@ const (
 	licenses     = "licenses"
 )
 
+const mitLicenseText = `MIT License
+
+Copyright (c) 2024 Example Corp
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+`
+
 func setupTmpDir() string {
 	tmpDir, err := os.MkdirTemp("", "license-check-*")
 	Expect(err).ToNot(HaveOccurred())
@@ -229,6 +256,89 @@ var _ = Describe("HasLicense", func() {
 			})
 		})
 
+		Context("When licenses are classified", func() {
+			var (
+				tmpDir string
+				aw     *artifacts.MapWriter
+				ctx    context.Context
+			)
+			BeforeEach(func() {
+				tmpDir = setupTmpDir()
+				createLicenseDir(tmpDir)
+				Expect(os.WriteFile(filepath.Join(tmpDir, licenses, "LICENSE"), []byte(mitLicenseText), 0o644)).To(Succeed())
+				Expect(os.MkdirAll(filepath.Join(tmpDir, licenses, "vendor"), 0o755)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(tmpDir, licenses, "vendor", "NOTICE"), []byte("SPDX-License-Identifier: Apache-2.0\n"), 0o644)).To(Succeed())
+				createLicenseFile(tmpDir, validLicense)
+				createEmptyFile(tmpDir, emptyLicense)
+
+				var err error
+				aw, err = artifacts.NewMapWriter()
+				Expect(err).ToNot(HaveOccurred())
+				ctx = artifacts.ContextWithWriter(context.Background(), aw)
+			})
+			It("Should pass and report the licenses of each file", func() {
+				ok, findings, err := hasLicense.ValidateWithFindings(ctx, image.ImageReference{ImageFSPath: tmpDir})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ok).To(BeTrue())
+				Expect(findings).To(ConsistOf(
+					check.Finding{Message: "identified license MIT", Object: "/licenses/LICENSE", Severity: check.SeverityInfo},
+					check.Finding{Message: "identified license Apache-2.0", Object: "/licenses/vendor/NOTICE", Severity: check.SeverityInfo},
+					check.Finding{Message: "license could not be identified", Object: "/licenses/" + validLicense, Severity: check.SeverityWarning},
+					check.Finding{Message: "license file is empty", Object: "/licenses/" + emptyLicense, Severity: check.SeverityWarning},
+				))
+			})
+			It("Should write the licenses artifact", func() {
+				_, _, err := hasLicense.ValidateWithFindings(ctx, image.ImageReference{ImageFSPath: tmpDir})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(aw.Files()).To(HaveKey(check.DefaultLicensesFilename))
+
+				var report licenseReport
+				Expect(json.NewDecoder(aw.Files()[check.DefaultLicensesFilename]).Decode(&report)).To(Succeed())
+				Expect(report.Files).To(HaveLen(4))
+				Expect(report.Files).To(ContainElement(SatisfyAll(
+					HaveField("Path", "/licenses/LICENSE"),
+					HaveField("Status", "identified"),
+					HaveField("Licenses", []string{"MIT"}),
+					HaveField("Coverage", BeNumerically(">", 90)),
+				)))
+				Expect(report.Files).To(ContainElement(SatisfyAll(
+					HaveField("Path", "/licenses/"+emptyLicense),
+					HaveField("Status", "empty"),
+					HaveField("Licenses", BeEmpty()),
+				)))
+				Expect(report.Files).To(ContainElement(SatisfyAll(
+					HaveField("Path", "/licenses/"+validLicense),
+					HaveField("Status", "unidentified"),
+				)))
+			})
+			It("Should fail for a denied license", func() {
+				chk := NewHasLicenseCheck(license.Rules{Denied: []string{"apache-*"}})
+				ok, findings, err := chk.ValidateWithFindings(ctx, image.ImageReference{ImageFSPath: tmpDir})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ok).To(BeFalse())
+				Expect(findings).To(ContainElement(check.Finding{Message: "license is denied: Apache-2.0", Object: "/licenses/vendor/NOTICE", Severity: check.SeverityError}))
+			})
+			It("Should fail for a license that is not allowed", func() {
+				chk := NewHasLicenseCheck(license.Rules{Allowed: []string{"Apache-2.0"}})
+				ok, findings, err := chk.ValidateWithFindings(ctx, image.ImageReference{ImageFSPath: tmpDir})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ok).To(BeFalse())
+				Expect(findings).To(ContainElement(check.Finding{Message: "license is not allowed: MIT", Object: "/licenses/LICENSE", Severity: check.SeverityError}))
+			})
+			It("Should pass when every license is allowed", func() {
+				chk := NewHasLicenseCheck(license.Rules{Allowed: []string{"MIT", "Apache-2.0"}})
+				ok, err := chk.Validate(ctx, image.ImageReference{ImageFSPath: tmpDir})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ok).To(BeTrue())
+			})
+			It("Should report a missing licenses directory", func() {
+				_, findings, err := hasLicense.ValidateWithFindings(ctx, image.ImageReference{ImageFSPath: "/invalid"})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(findings).To(Equal([]check.Finding{{Message: "licenses directory does not exist", Object: "/licenses", Severity: check.SeverityError}}))
+				Expect(aw.Files()).ToNot(HaveKey(check.DefaultLicensesFilename))
+			})
+		})
+
 		AssertMetaData(&hasLicense)
 	})
 })
@@ -11,6 +11,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/go-logr/logr"
@@ -24,7 +25,10 @@ import (
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/rpm"
 )
 
-var _ check.Check = &HasModifiedFilesCheck{}
+var (
+	_ check.Check            = &HasModifiedFilesCheck{}
+	_ check.FindingsReporter = &HasModifiedFilesCheck{}
+)
 
 // HasModifiedFilesCheck evaluates that no files from the base layer have been modified by
 // subsequent layers by comparing the file list installed by Packages against the file list
@@ -61,17 +65,24 @@ type packageFilesRef struct {
 
 // Validate runs the check of whether any Red Hat files were modified
 func (p *HasModifiedFilesCheck) Validate(ctx context.Context, imgRef image.ImageReference) (bool, error) {
+	passed, _, err := p.ValidateWithFindings(ctx, imgRef)
+	return passed, err
+}
+
+// ValidateWithFindings reports each file that was modified outside of RPM as
+// a finding.
+func (p *HasModifiedFilesCheck) ValidateWithFindings(ctx context.Context, imgRef image.ImageReference) (bool, []check.Finding, error) {
 	fs := afero.NewOsFs()
 	layerIDs, packageFiles, err := p.gatherDataToValidate(ctx, imgRef, fs)
 	if err != nil {
-		return false, fmt.Errorf("could not generate modified files list: %v", err)
+		return false, nil, fmt.Errorf("could not generate modified files list: %v", err)
 	}
 
 	//coverage:ignore
 	packageDist, err := p.parsePackageDist(ctx, imgRef.ImageFSPath, fs)
 	if err != nil {
 		//coverage:ignore
-		return false, fmt.Errorf("could not generate modified files list: %v", err)
+		return false, nil, fmt.Errorf("could not generate modified files list: %v", err)
 	}
 
 	//coverage:ignore
@@ -227,11 +238,13 @@ func (p *HasModifiedFilesCheck) gatherDataToValidate(ctx context.Context, imgRef
 
 // validate compares the list of LayerFiles and PackageFiles to see what PackageFiles
 // have been modified within the additional layers. packageDist is the value we expect
-// to find in the base package's Release field.
-func (p *HasModifiedFilesCheck) validate(ctx context.Context, layerIDs []string, packageFiles map[string]packageFilesRef, packageDist string) (bool, error) {
+// to find in the base package's Release field. A finding is returned for each
+// modified file, sorted by path.
+func (p *HasModifiedFilesCheck) validate(ctx context.Context, layerIDs []string, packageFiles map[string]packageFilesRef, packageDist string) (bool, []check.Finding, error) {
 	logger := logr.FromContextOrDiscard(ctx)
 
 	disallowedModifications := false
+	var findings []check.Finding
 	for idx, layerID := range layerIDs {
 		logger := logger.WithValues("layer", layerID)
 		ref := packageFiles[layerID]
@@ -280,6 +293,11 @@ func (p *HasModifiedFilesCheck) validate(ctx context.Context, layerIDs []string,
 				if !strings.Contains(currentPackage.Release, packageDist) && packageDist != "unknown" {
 					// This means it's _probably_ not a RH package. If the file is changed, warn, but don't fail
 					logger.Info("WARN: an rpm-installed file was modified outside of rpm, but appears to be from a third-party. This could be a failure in the future")
+					findings = append(findings, check.Finding{
+						Message:  fmt.Sprintf("file from third-party package %s was modified outside of rpm in layer %s", currentPackage.Name, layerID),
+						Object:   modifiedFile,
+						Severity: check.SeverityWarning,
+					})
 					continue
 				}
 
@@ -287,6 +305,11 @@ func (p *HasModifiedFilesCheck) validate(ctx context.Context, layerIDs []string,
 					//coverage:ignore
 					// This means it's _probably_ not a RH package. If the file is changed, warn, but don't fail
 					logger.Info("WARN: an rpm-installed file was modified outside of rpm, but appears to be from a third-party. This could be a failure in the future")
+					findings = append(findings, check.Finding{
+						Message:  fmt.Sprintf("file from third-party package %s was modified outside of rpm in layer %s", currentPackage.Name, layerID),
+						Object:   modifiedFile,
+						Severity: check.SeverityWarning,
+					})
 					continue
 				}
 
@@ -303,6 +326,11 @@ func (p *HasModifiedFilesCheck) validate(ctx context.Context, layerIDs []string,
 				// Nope, nope, nope. File was modified without using RPM
 				logger.Info("found disallowed modification in layer", "file", modifiedFile)
 				disallowedModifications = true
+				findings = append(findings, check.Finding{
+					Message:  fmt.Sprintf("file from package %s was modified outside of rpm in layer %s", currentPackage.Name, layerID),
+					Object:   modifiedFile,
+					Severity: check.SeverityError,
+				})
 				continue
 			}
 
@@ -314,6 +342,11 @@ func (p *HasModifiedFilesCheck) validate(ctx context.Context, layerIDs []string,
 			if previousOsRelease && !currentOsRelease {
 				logger.Info("mismatch in OS release", "file", modifiedFile)
 				disallowedModifications = true
+				findings = append(findings, check.Finding{
+					Message:  fmt.Sprintf("package %s was replaced with a build for a different OS release in layer %s", currentPackage.Name, layerID),
+					Object:   modifiedFile,
+					Severity: check.SeverityError,
+				})
 				continue
 			}
 
@@ -321,6 +354,11 @@ func (p *HasModifiedFilesCheck) validate(ctx context.Context, layerIDs []string,
 			if previousPackage.Arch != currentPackage.Arch {
 				logger.Info("mismatch in package architecture", "file", modifiedFile)
 				disallowedModifications = true
+				findings = append(findings, check.Finding{
+					Message:  fmt.Sprintf("package %s was replaced with a build for a different architecture in layer %s", currentPackage.Name, layerID),
+					Object:   modifiedFile,
+					Severity: check.SeverityError,
+				})
 				continue
 			}
 
@@ -328,7 +366,12 @@ func (p *HasModifiedFilesCheck) validate(ctx context.Context, layerIDs []string,
 			// No further action required
 		}
 	}
-	return !disallowedModifications, nil
+
+	slices.SortStableFunc(findings, func(a, b check.Finding) int {
+		return strings.Compare(a.Object, b.Object)
+	})
+
+	return !disallowedModifications, findings, nil
 }
 
 func (p HasModifiedFilesCheck) Name() string {
@@ -344,6 +387,9 @@ func (p HasModifiedFilesCheck) Help() check.HelpText {
 
 func (p HasModifiedFilesCheck) Metadata() check.Metadata {
 	return check.Metadata{
+		ID:               "PFLT-CNT-008",
+		PolicyVersion:    "1.0",
+		Categories:       []check.Category{check.CategorySecurity, check.CategoryPackaging},
 		Description:      "Checks that no files installed via RPM in the base Red Hat layer have been modified",
 		Level:            "best",
 		KnowledgeBaseURL: certDocumentationURL,
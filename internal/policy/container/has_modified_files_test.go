@@ -15,6 +15,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
 
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
 )
 
@@ -184,7 +185,7 @@ var _ = Describe("HasModifiedFiles", func() {
 	Context("Checking if it has any modified RPM files", func() {
 		When("there are no modified RPM files found", func() {
 			It("should pass validate", func() {
-				ok, err := hasModifiedFiles.validate(context.Background(), layers, pkgRef, dist)
+				ok, _, err := hasModifiedFiles.validate(context.Background(), layers, pkgRef, dist)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(ok).To(BeTrue())
 			})
@@ -203,10 +204,19 @@ var _ = Describe("HasModifiedFiles", func() {
 					pkgs["secondlayer"] = pkgSecondLayer
 				})
 				It("should not pass Validate", func() {
-					ok, err := hasModifiedFiles.validate(context.Background(), layers, pkgs, dist)
+					ok, _, err := hasModifiedFiles.validate(context.Background(), layers, pkgs, dist)
 					Expect(err).ToNot(HaveOccurred())
 					Expect(ok).To(BeFalse())
 				})
+				It("should report the modified file as a finding", func() {
+					_, findings, err := hasModifiedFiles.validate(context.Background(), layers, pkgs, dist)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(findings).To(Equal([]check.Finding{{
+						Message:  "file from package foo was modified outside of rpm in layer secondlayer",
+						Object:   "this",
+						Severity: check.SeverityError,
+					}}))
+				})
 			})
 			When("setuid is removed", func() {
 				BeforeEach(func() {
@@ -217,7 +227,7 @@ var _ = Describe("HasModifiedFiles", func() {
 					pkgs["secondlayer"] = pkgSecondLayer
 				})
 				It("should pass Validate", func() {
-					ok, err := hasModifiedFiles.validate(context.Background(), layers, pkgs, dist)
+					ok, _, err := hasModifiedFiles.validate(context.Background(), layers, pkgs, dist)
 					Expect(err).ToNot(HaveOccurred())
 					Expect(ok).To(BeTrue())
 				})
@@ -231,7 +241,7 @@ var _ = Describe("HasModifiedFiles", func() {
 					pkgs["secondlayer"] = pkgSecondLayer
 				})
 				It("should pass Validate", func() {
-					ok, err := hasModifiedFiles.validate(context.Background(), layers, pkgs, dist)
+					ok, _, err := hasModifiedFiles.validate(context.Background(), layers, pkgs, dist)
 					Expect(err).ToNot(HaveOccurred())
 					Expect(ok).To(BeTrue())
 				})
@@ -245,7 +255,7 @@ var _ = Describe("HasModifiedFiles", func() {
 					pkgs["secondlayer"] = pkgSecondLayer
 				})
 				It("should pass Validate", func() {
-					ok, err := hasModifiedFiles.validate(context.Background(), layers, pkgs, dist)
+					ok, _, err := hasModifiedFiles.validate(context.Background(), layers, pkgs, dist)
 					Expect(err).ToNot(HaveOccurred())
 					Expect(ok).To(BeTrue())
 				})
@@ -259,7 +269,7 @@ var _ = Describe("HasModifiedFiles", func() {
 					pkgs["secondlayer"] = pkgSecondLayer
 				})
 				It("should not pass Validate", func() {
-					ok, err := hasModifiedFiles.validate(context.Background(), layers, pkgs, dist)
+					ok, _, err := hasModifiedFiles.validate(context.Background(), layers, pkgs, dist)
 					Expect(err).ToNot(HaveOccurred())
 					Expect(ok).To(BeFalse())
 				})
@@ -273,7 +283,7 @@ var _ = Describe("HasModifiedFiles", func() {
 					pkgs["secondlayer"] = pkgSecondLayer
 				})
 				It("should not pass Validate", func() {
-					ok, err := hasModifiedFiles.validate(context.Background(), layers, pkgs, dist)
+					ok, _, err := hasModifiedFiles.validate(context.Background(), layers, pkgs, dist)
 					Expect(err).ToNot(HaveOccurred())
 					Expect(ok).To(BeFalse())
 				})
@@ -287,7 +297,7 @@ var _ = Describe("HasModifiedFiles", func() {
 					pkgs["secondlayer"] = pkgSecondLayer
 				})
 				It("should not pass Validate", func() {
-					ok, err := hasModifiedFiles.validate(context.Background(), layers, pkgs, dist)
+					ok, _, err := hasModifiedFiles.validate(context.Background(), layers, pkgs, dist)
 					Expect(err).ToNot(HaveOccurred())
 					Expect(ok).To(BeFalse())
 				})
@@ -304,7 +314,7 @@ var _ = Describe("HasModifiedFiles", func() {
 				pkgs["secondlayer"] = pkgSecondLayer
 			})
 			It("should pass validate", func() {
-				ok, err := hasModifiedFiles.validate(context.Background(), layers, pkgs, dist)
+				ok, _, err := hasModifiedFiles.validate(context.Background(), layers, pkgs, dist)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(ok).To(BeTrue())
 			})
@@ -320,7 +330,7 @@ var _ = Describe("HasModifiedFiles", func() {
 				pkgs["secondlayer"] = pkgSecondLayer
 			})
 			It("should pass validate", func() {
-				ok, err := hasModifiedFiles.validate(context.Background(), layers, pkgs, dist)
+				ok, _, err := hasModifiedFiles.validate(context.Background(), layers, pkgs, dist)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(ok).To(BeTrue())
 			})
@@ -355,9 +365,10 @@ var _ = Describe("HasModifiedFiles", func() {
 				}
 			})
 			It("should fail because of different release dist", func() {
-				ok, err := hasModifiedFiles.validate(context.Background(), layers, pkgs, dist)
+				ok, findings, err := hasModifiedFiles.validate(context.Background(), layers, pkgs, dist)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(ok).To(BeFalse())
+				Expect(findings).To(ConsistOf(HaveField("Message", ContainSubstring("different OS release"))))
 			})
 		})
 		When("the package architecture changes", func() {
@@ -390,9 +401,10 @@ var _ = Describe("HasModifiedFiles", func() {
 				}
 			})
 			It("should fail because of different architectures dist", func() {
-				ok, err := hasModifiedFiles.validate(context.Background(), layers, pkgs, dist)
+				ok, findings, err := hasModifiedFiles.validate(context.Background(), layers, pkgs, dist)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(ok).To(BeFalse())
+				Expect(findings).To(ConsistOf(HaveField("Message", ContainSubstring("different architecture"))))
 			})
 		})
 		When("release dist does not match installed OS", func() {
@@ -442,7 +454,7 @@ var _ = Describe("HasModifiedFiles", func() {
 						ctx = logr.NewContext(context.Background(), logger)
 					})
 					It("should warn but not fail", func() {
-						ok, err := hasModifiedFiles.validate(ctx, layers, pkgs, dist)
+						ok, _, err := hasModifiedFiles.validate(ctx, layers, pkgs, dist)
 						Expect(err).ToNot(HaveOccurred())
 						Expect(ok).To(BeTrue())
 						Expect(logOutput.String()).To(ContainSubstring("WARN"))
@@ -466,7 +478,7 @@ var _ = Describe("HasModifiedFiles", func() {
 			zeroLayers = append([]string{"zerolayer"}, layers...)
 		})
 		It("should ignore it", func() {
-			ok, err := hasModifiedFiles.validate(context.Background(), zeroLayers, zeroPkgRef, dist)
+			ok, _, err := hasModifiedFiles.validate(context.Background(), zeroLayers, zeroPkgRef, dist)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(ok).To(BeTrue())
 		})
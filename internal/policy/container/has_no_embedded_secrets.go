@@ -0,0 +1,331 @@
+package container
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/go-logr/logr"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/secrets"
+)
+
+const (
+	// maxSecretFileSize is the size of the largest file scanned for secrets.
+	// Larger files are almost always archives, databases or other data.
+	maxSecretFileSize = 1 << 20
+	// binarySniffSize is how much of a file is inspected to determine that
+	// it is binary, and so not scanned.
+	binarySniffSize = 8000
+	// opaqueWhiteout marks a directory whose contents in lower layers are
+	// removed.
+	opaqueWhiteout = whiteoutPrefix + whiteoutPrefix + ".opq"
+)
+
+var (
+	_ check.Check            = &HasNoEmbeddedSecretsCheck{}
+	_ check.FindingsReporter = &HasNoEmbeddedSecretsCheck{}
+)
+
+// HasNoEmbeddedSecretsCheck evaluates that no layer of the image, and not its
+// config, contains credentials such as private keys, cloud access keys or
+// registry auths. Files deleted by a later layer are scanned too, since they
+// are still shipped in the layer that added them.
+type HasNoEmbeddedSecretsCheck struct {
+	allowlist secrets.Allowlist
+}
+
+// secretReport is written to the secrets.json artifact. It never contains the
+// secrets themselves.
+type secretReport struct {
+	Secrets []embeddedSecret `json:"secrets"`
+}
+
+// embeddedSecret is a secret found in the image.
+type embeddedSecret struct {
+	Rule        string `json:"rule"`
+	Description string `json:"description"`
+	// Location is where the secret was found: file, env or history.
+	Location string `json:"location"`
+	// Layer is the index of the layer the file is in.
+	Layer       int    `json:"layer,omitempty"`
+	LayerDigest string `json:"layer_digest,omitempty"`
+	Path        string `json:"path,omitempty"`
+	// Deleted is true if a later layer deletes the file.
+	Deleted bool `json:"deleted,omitempty"`
+	// Object identifies the config entry the secret was found in, or the
+	// file, as in the finding.
+	Object        string `json:"object"`
+	Line          int    `json:"line"`
+	Redacted      string `json:"redacted"`
+	Fingerprint   string `json:"fingerprint"`
+	Allowed       bool   `json:"allowed"`
+	Justification string `json:"justification,omitempty"`
+}
+
+// layerWhiteouts are the paths a layer deletes from the layers below it.
+type layerWhiteouts struct {
+	// deleted are removed, along with their contents if they are directories.
+	deleted []string
+	// opaque are directories whose contents are removed.
+	opaque []string
+}
+
+// removes returns true if w removes the file at p.
+func (w layerWhiteouts) removes(p string) bool {
+	for _, d := range w.deleted {
+		if p == d || strings.HasPrefix(p, d+"/") {
+			return true
+		}
+	}
+	for _, d := range w.opaque {
+		if d == "" || strings.HasPrefix(p, d+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// NewHasNoEmbeddedSecretsCheck returns a check that scans the image for secrets.
+// Secrets permitted by allowlist are reported, but do not fail the check.
+func NewHasNoEmbeddedSecretsCheck(allowlist secrets.Allowlist) *HasNoEmbeddedSecretsCheck {
+	return &HasNoEmbeddedSecretsCheck{allowlist: allowlist}
+}
+
+func (p *HasNoEmbeddedSecretsCheck) Validate(ctx context.Context, imgRef image.ImageReference) (bool, error) {
+	passed, _, err := p.ValidateWithFindings(ctx, imgRef)
+	return passed, err
+}
+
+// ValidateWithFindings reports each secret as a finding, with the layer and
+// path of the file or the config entry it was found in. Secrets are redacted.
+func (p *HasNoEmbeddedSecretsCheck) ValidateWithFindings(ctx context.Context, imgRef image.ImageReference) (bool, []check.Finding, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	if imgRef.ImageInfo == nil {
+		return false, nil, fmt.Errorf("image reference invalid")
+	}
+
+	layers, err := imgRef.ImageInfo.Layers()
+	if err != nil {
+		return false, nil, fmt.Errorf("could not read the image layers: %w", err)
+	}
+
+	report := secretReport{Secrets: []embeddedSecret{}}
+	whiteouts := make([]layerWhiteouts, len(layers))
+	for idx, layer := range layers {
+		found, w, err := p.scanLayer(idx, layer)
+		if err != nil {
+			return false, nil, err
+		}
+		report.Secrets = append(report.Secrets, found...)
+		whiteouts[idx] = w
+	}
+
+	for i, s := range report.Secrets {
+		if s.Location != "file" {
+			continue
+		}
+		for _, w := range whiteouts[s.Layer+1:] {
+			if w.removes(strings.TrimPrefix(s.Path, "/")) {
+				report.Secrets[i].Deleted = true
+				break
+			}
+		}
+	}
+
+	configFile, err := imgRef.ImageInfo.ConfigFile()
+	if err != nil {
+		return false, nil, fmt.Errorf("could not read the image config: %w", err)
+	}
+	for _, env := range configFile.Config.Env {
+		name, _, _ := strings.Cut(env, "=")
+		report.Secrets = append(report.Secrets, p.scan("", []byte(env), "env", "env:"+name)...)
+	}
+	for idx, history := range configFile.History {
+		report.Secrets = append(report.Secrets, p.scan("", []byte(history.CreatedBy), "history", fmt.Sprintf("history:%d", idx))...)
+	}
+
+	var findings []check.Finding
+	for _, s := range report.Secrets {
+		findings = append(findings, secretFinding(s))
+	}
+
+	if artifactWriter := artifacts.WriterFromContext(ctx); artifactWriter != nil {
+		reportJSON, err := json.MarshalIndent(report, "", "    ")
+		if err != nil {
+			//coverage:ignore
+			return false, nil, fmt.Errorf("could not marshal the secrets report: %w", err)
+		}
+		if _, err := artifactWriter.WriteFile(check.DefaultSecretsFilename, bytes.NewReader(reportJSON)); err != nil {
+			//coverage:ignore
+			return false, nil, fmt.Errorf("could not write the secrets report: %w", err)
+		}
+	}
+
+	passed := true
+	for _, s := range report.Secrets {
+		if !s.Allowed {
+			passed = false
+		}
+	}
+
+	logger.V(log.DBG).Info("embedded secrets found", "secretCount", len(report.Secrets))
+	return passed, findings, nil
+}
+
+// scanLayer returns the secrets in the regular files of layer, and the paths
+// the layer deletes.
+func (p *HasNoEmbeddedSecretsCheck) scanLayer(idx int, layer v1.Layer) ([]embeddedSecret, layerWhiteouts, error) {
+	var whiteouts layerWhiteouts
+
+	digest, err := layer.Digest()
+	if err != nil {
+		//coverage:ignore
+		return nil, whiteouts, fmt.Errorf("unable to retrieve digest for layer %d: %w", idx, err)
+	}
+
+	layerReader, err := layer.Uncompressed()
+	if err != nil {
+		return nil, whiteouts, fmt.Errorf("reading layer contents: %w", err)
+	}
+	defer layerReader.Close()
+
+	var found []embeddedSecret
+	tarReader := tar.NewReader(layerReader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, whiteouts, fmt.Errorf("reading tar: %w", err)
+		}
+
+		name := strings.TrimPrefix(path.Clean("/"+header.Name), "/")
+		dirname, basename := path.Split(name)
+		dirname = strings.TrimSuffix(dirname, "/")
+		switch {
+		case basename == opaqueWhiteout:
+			whiteouts.opaque = append(whiteouts.opaque, dirname)
+			continue
+		case strings.HasPrefix(basename, whiteoutPrefix):
+			whiteouts.deleted = append(whiteouts.deleted, path.Join(dirname, strings.TrimPrefix(basename, whiteoutPrefix)))
+			continue
+		}
+
+		if header.Typeflag != tar.TypeReg || header.Size > maxSecretFileSize {
+			continue
+		}
+		contents, err := io.ReadAll(tarReader)
+		if err != nil {
+			return nil, whiteouts, fmt.Errorf("reading %s from layer %d: %w", name, idx, err)
+		}
+		if bytes.IndexByte(contents[:min(len(contents), binarySniffSize)], 0) >= 0 {
+			continue
+		}
+
+		for _, s := range p.scan("/"+name, contents, "file", "/"+name) {
+			s.Layer = idx
+			s.LayerDigest = digest.String()
+			found = append(found, s)
+		}
+	}
+
+	return found, whiteouts, nil
+}
+
+// scan returns the secrets in text, found at object. filePath is the path of
+// the file text was read from, or empty if text is not a file.
+func (p *HasNoEmbeddedSecretsCheck) scan(filePath string, text []byte, location string, object string) []embeddedSecret {
+	var found []embeddedSecret
+	for _, m := range secrets.Scan(filePath, text) {
+		allowance, allowed := p.allowlist.Allows(m, filePath)
+		found = append(found, embeddedSecret{
+			Rule:          m.Rule,
+			Description:   m.Description,
+			Location:      location,
+			Path:          filePath,
+			Object:        object,
+			Line:          m.Line,
+			Redacted:      m.Redacted(),
+			Fingerprint:   m.Fingerprint(),
+			Allowed:       allowed,
+			Justification: allowance.Justification,
+		})
+	}
+	return found
+}
+
+func secretFinding(s embeddedSecret) check.Finding {
+	var message string
+	switch s.Location {
+	case "file":
+		message = fmt.Sprintf("%s found in layer %d (%s) at line %d: %s", s.Description, s.Layer, shortDigest(s.LayerDigest), s.Line, s.Redacted)
+		if s.Deleted {
+			message += " (deleted by a later layer, but still shipped in this one)"
+		}
+	case "env":
+		message = fmt.Sprintf("%s found in the image config environment: %s", s.Description, s.Redacted)
+	default:
+		message = fmt.Sprintf("%s found in the image history: %s", s.Description, s.Redacted)
+	}
+
+	if s.Allowed {
+		return check.Finding{
+			Message:  fmt.Sprintf("%s, allowed: %s", message, s.Justification),
+			Object:   s.Object,
+			Severity: check.SeverityInfo,
+		}
+	}
+	return check.Finding{
+		Message:  fmt.Sprintf("%s, fingerprint %s", message, s.Fingerprint),
+		Object:   s.Object,
+		Severity: check.SeverityError,
+	}
+}
+
+// shortDigest abbreviates digest, e.g. sha256:0123456789ab.
+func shortDigest(digest string) string {
+	algorithm, hex, _ := strings.Cut(digest, ":")
+	return algorithm + ":" + hex[:min(len(hex), 12)]
+}
+
+func (p *HasNoEmbeddedSecretsCheck) Name() string {
+	return "HasNoEmbeddedSecrets"
+}
+
+func (p *HasNoEmbeddedSecretsCheck) Metadata() check.Metadata {
+	return check.Metadata{
+		ID:               "PFLT-CNT-012",
+		PolicyVersion:    "1.0",
+		Categories:       []check.Category{check.CategorySecurity},
+		Description:      "Checking that no layer of the image, and not its config, contains private keys, cloud credentials, registry auths, kubeconfigs or tokens.",
+		Level:            "best",
+		KnowledgeBaseURL: certDocumentationURL,
+		CheckURL:         certDocumentationURL,
+	}
+}
+
+func (p *HasNoEmbeddedSecretsCheck) Help() check.HelpText {
+	return check.HelpText{
+		Message:    "Check HasNoEmbeddedSecrets encountered an error. Please review the preflight.log file for more information.",
+		Suggestion: "Remove the secrets named in the findings from the layer that adds them, and rotate them, since deleting a file in a later layer does not remove it from the image. Inject credentials at runtime, e.g. with build secrets or Kubernetes Secrets, instead. Secrets that are not sensitive may be allowed with a secrets allowlist.",
+	}
+}
+
+func (p *HasNoEmbeddedSecretsCheck) RequiredFilePatterns() []string {
+	//coverage:ignore
+	return nil
+}
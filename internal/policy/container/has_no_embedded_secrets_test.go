@@ -0,0 +1,261 @@
+package container
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	fakecranev1 "github.com/google/go-containerregistry/pkg/v1/fake"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/secrets"
+)
+
+// The secrets below are split so that the test sources are not themselves
+// reported by secret scanners.
+const (
+	testSSHKey   = "-----BEGIN OPENSSH " + "PRIVATE KEY-----\nb3BlbnNzaC1rZXktdjEAAAAABG5vbmUAAAAEbm9uZQAAAAAAAAABAAAAMwAAAAtzc2gtZW\n-----END OPENSSH PRIVATE KEY-----\n"
+	testAWSKeyID = "AKIA" + "IOSFODNN7EXAMPLE"
+	testToken    = "Zx9qT3vL8mN2pR7s" + "W4yB6kD1"
+)
+
+// layerEntry is a file, or a whiteout if contents is nil, in a test layer.
+type layerEntry struct {
+	name     string
+	contents []byte
+}
+
+func testLayer(entries ...layerEntry) v1.Layer {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		Expect(tw.WriteHeader(&tar.Header{Name: e.name, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(e.contents))})).To(Succeed())
+		_, err := tw.Write(e.contents)
+		Expect(err).ToNot(HaveOccurred())
+	}
+	Expect(tw.Close()).To(Succeed())
+	return static.NewLayer(buf.Bytes(), types.DockerUncompressedLayer)
+}
+
+type unreadableLayer struct {
+	FakeLayer
+}
+
+func (unreadableLayer) Uncompressed() (io.ReadCloser, error) {
+	return nil, errors.New("unreadable")
+}
+
+var _ = Describe("HasNoEmbeddedSecrets", func() {
+	var (
+		hasNoEmbeddedSecrets *HasNoEmbeddedSecretsCheck
+		aw                   *artifacts.MapWriter
+		ctx                  context.Context
+		img                  v1.Image
+	)
+
+	BeforeEach(func() {
+		hasNoEmbeddedSecrets = NewHasNoEmbeddedSecretsCheck(nil)
+
+		var err error
+		aw, err = artifacts.NewMapWriter()
+		Expect(err).ToNot(HaveOccurred())
+		ctx = artifacts.ContextWithWriter(context.Background(), aw)
+
+		img, err = mutate.AppendLayers(empty.Image,
+			testLayer(
+				layerEntry{name: "etc/os-release", contents: []byte("NAME=\"Red Hat Enterprise Linux\"\n")},
+				layerEntry{name: "usr/bin/app", contents: append([]byte{0x7f, 'E', 'L', 'F', 0}, []byte("API_TOKEN="+testToken)...)},
+			),
+			testLayer(layerEntry{name: "./opt/app/config.yaml", contents: []byte("name: app\n")}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	Context("When the image has no secrets", func() {
+		It("should pass Validate", func() {
+			ok, findings, err := hasNoEmbeddedSecrets.ValidateWithFindings(ctx, image.ImageReference{ImageInfo: img})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(findings).To(BeEmpty())
+		})
+
+		It("should write an empty artifact", func() {
+			_, _, err := hasNoEmbeddedSecrets.ValidateWithFindings(ctx, image.ImageReference{ImageInfo: img})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(aw.Files()).To(HaveKey(check.DefaultSecretsFilename))
+
+			var report secretReport
+			Expect(json.NewDecoder(aw.Files()[check.DefaultSecretsFilename]).Decode(&report)).To(Succeed())
+			Expect(report.Secrets).To(BeEmpty())
+		})
+	})
+
+	Context("When a layer has a secret that a later layer deletes", func() {
+		var keyLayer v1.Layer
+
+		BeforeEach(func() {
+			keyLayer = testLayer(layerEntry{name: "root/.ssh/id_ed25519", contents: []byte(testSSHKey)})
+			var err error
+			img, err = mutate.AppendLayers(img, keyLayer, testLayer(layerEntry{name: "root/.ssh/.wh.id_ed25519"}))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should not pass Validate, and report the layer and path of the secret", func() {
+			ok, findings, err := hasNoEmbeddedSecrets.ValidateWithFindings(ctx, image.ImageReference{ImageInfo: img})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+			Expect(findings).To(HaveLen(1))
+
+			digest, err := keyLayer.Digest()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(findings[0].Object).To(Equal("/root/.ssh/id_ed25519"))
+			Expect(findings[0].Severity).To(Equal(check.SeverityError))
+			Expect(findings[0].Message).To(HavePrefix("private key found in layer 2 (sha256:" + digest.Hex[:12] + ") at line 1: b3Bl******** (deleted by a later layer, but still shipped in this one), fingerprint sha256:"))
+			Expect(findings[0].Message).ToNot(ContainSubstring("AAAAB"))
+		})
+
+		It("should write the redacted secret to the artifact", func() {
+			_, _, err := hasNoEmbeddedSecrets.ValidateWithFindings(ctx, image.ImageReference{ImageInfo: img})
+			Expect(err).ToNot(HaveOccurred())
+
+			report := aw.Files()[check.DefaultSecretsFilename]
+			Expect(report).ToNot(BeNil())
+			contents, err := io.ReadAll(report)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(contents)).ToNot(ContainSubstring("AAAAB"))
+
+			var r secretReport
+			Expect(json.Unmarshal(contents, &r)).To(Succeed())
+			Expect(r.Secrets).To(HaveLen(1))
+			Expect(r.Secrets[0].Layer).To(Equal(2))
+			Expect(r.Secrets[0].Path).To(Equal("/root/.ssh/id_ed25519"))
+			Expect(r.Secrets[0].Deleted).To(BeTrue())
+			Expect(r.Secrets[0].Redacted).To(Equal("b3Bl********"))
+		})
+
+		It("should report secrets permitted by the allowlist without failing", func() {
+			hasNoEmbeddedSecrets = NewHasNoEmbeddedSecretsCheck(secrets.Allowlist{
+				{Rule: "private-key", Path: "/root/.ssh/*", Justification: "a key for tests"},
+			})
+			ok, findings, err := hasNoEmbeddedSecrets.ValidateWithFindings(ctx, image.ImageReference{ImageInfo: img})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(findings).To(HaveLen(1))
+			Expect(findings[0].Severity).To(Equal(check.SeverityInfo))
+			Expect(findings[0].Message).To(HaveSuffix(", allowed: a key for tests"))
+		})
+	})
+
+	DescribeTable("should determine whether a later layer deletes a secret",
+		func(whiteout string, deleted bool) {
+			var err error
+			img, err = mutate.AppendLayers(img,
+				testLayer(layerEntry{name: "opt/app/.env", contents: []byte("API_TOKEN=" + testToken)}),
+				testLayer(layerEntry{name: whiteout}),
+			)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, _, err = hasNoEmbeddedSecrets.ValidateWithFindings(ctx, image.ImageReference{ImageInfo: img})
+			Expect(err).ToNot(HaveOccurred())
+
+			var report secretReport
+			Expect(json.NewDecoder(aw.Files()[check.DefaultSecretsFilename]).Decode(&report)).To(Succeed())
+			Expect(report.Secrets).To(HaveLen(1))
+			Expect(report.Secrets[0].Deleted).To(Equal(deleted))
+		},
+		Entry("when the file is deleted", "opt/app/.wh..env", true),
+		Entry("when its directory is deleted", "opt/.wh.app", true),
+		Entry("when its directory is made opaque", "opt/app/.wh..wh..opq", true),
+		Entry("when the root directory is made opaque", ".wh..wh..opq", true),
+		Entry("when another file is deleted", "opt/app/.wh.config.yaml", false),
+		Entry("when another directory is made opaque", "opt/other/.wh..wh..opq", false),
+	)
+
+	Context("When the image config has secrets", func() {
+		BeforeEach(func() {
+			cfg, err := img.ConfigFile()
+			Expect(err).ToNot(HaveOccurred())
+			cfg = cfg.DeepCopy()
+			cfg.Config.Env = []string{"PATH=/usr/bin", "AWS_ACCESS_KEY_ID=" + testAWSKeyID}
+			cfg.History = []v1.History{
+				{CreatedBy: "/bin/sh -c #(nop) ADD file:abc in /"},
+				{CreatedBy: "/bin/sh -c curl -H 'X-Api-Token: " + testToken + "' https://example.com"},
+			}
+			img, err = mutate.ConfigFile(img, cfg)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should not pass Validate, and report the entry of each secret", func() {
+			ok, findings, err := hasNoEmbeddedSecrets.ValidateWithFindings(ctx, image.ImageReference{ImageInfo: img})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+			Expect(findings).To(HaveLen(2))
+			Expect(findings[0].Object).To(Equal("env:AWS_ACCESS_KEY_ID"))
+			Expect(findings[0].Message).To(HavePrefix("AWS access key ID found in the image config environment: AKIA********, fingerprint sha256:"))
+			Expect(findings[1].Object).To(Equal("history:1"))
+			Expect(findings[1].Message).To(HavePrefix("high-entropy secret found in the image history: Zx9q********, fingerprint sha256:"))
+		})
+	})
+
+	Context("When the image cannot be read", func() {
+		It("should fail without an image", func() {
+			_, _, err := hasNoEmbeddedSecrets.ValidateWithFindings(ctx, image.ImageReference{})
+			Expect(err).To(MatchError("image reference invalid"))
+		})
+
+		It("should fail if the layers cannot be listed", func() {
+			fakeImage := fakecranev1.FakeImage{}
+			fakeImage.LayersReturns(nil, errors.New("no layers"))
+			_, err := hasNoEmbeddedSecrets.Validate(ctx, image.ImageReference{ImageInfo: &fakeImage})
+			Expect(err).To(MatchError(ContainSubstring("could not read the image layers")))
+		})
+
+		It("should fail if the config cannot be read", func() {
+			fakeImage := fakecranev1.FakeImage{}
+			fakeImage.ConfigFileReturns(nil, errors.New("no config"))
+			_, err := hasNoEmbeddedSecrets.Validate(ctx, image.ImageReference{ImageInfo: &fakeImage})
+			Expect(err).To(MatchError(ContainSubstring("could not read the image config")))
+		})
+
+		It("should fail if a layer cannot be read", func() {
+			fakeImage := fakecranev1.FakeImage{}
+			fakeImage.LayersReturns([]v1.Layer{unreadableLayer{}}, nil)
+			_, err := hasNoEmbeddedSecrets.Validate(ctx, image.ImageReference{ImageInfo: &fakeImage})
+			Expect(err).To(MatchError(ContainSubstring("reading layer contents")))
+		})
+
+		It("should fail if a layer is not a tar", func() {
+			fakeImage := fakecranev1.FakeImage{}
+			fakeImage.LayersReturns([]v1.Layer{static.NewLayer([]byte("not a tar"), types.DockerUncompressedLayer)}, nil)
+			_, err := hasNoEmbeddedSecrets.Validate(ctx, image.ImageReference{ImageInfo: &fakeImage})
+			Expect(err).To(MatchError(ContainSubstring("reading tar")))
+		})
+
+		It("should fail if a file in a layer is truncated", func() {
+			var buf bytes.Buffer
+			tw := tar.NewWriter(&buf)
+			Expect(tw.WriteHeader(&tar.Header{Name: "opt/app/.env", Typeflag: tar.TypeReg, Mode: 0o644, Size: 1024})).To(Succeed())
+			_, err := tw.Write([]byte("API_TOKEN="))
+			Expect(err).ToNot(HaveOccurred())
+
+			fakeImage := fakecranev1.FakeImage{}
+			fakeImage.LayersReturns([]v1.Layer{static.NewLayer(buf.Bytes(), types.DockerUncompressedLayer)}, nil)
+			_, err = hasNoEmbeddedSecrets.Validate(ctx, image.ImageReference{ImageInfo: &fakeImage})
+			Expect(err).To(MatchError(ContainSubstring("reading opt/app/.env from layer 0")))
+		})
+	})
+
+	AssertMetaData(&HasNoEmbeddedSecretsCheck{})
+})
@@ -0,0 +1,173 @@
+package container
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/go-logr/logr"
+	rpmdb "github.com/knqyf263/go-rpmdb/pkg"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/advisory"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/rpm"
+)
+
+// securityDataURL is where Red Hat publishes the CSAF and OVAL datasets the
+// check is executed against.
+const securityDataURL = "https://access.redhat.com/security/data"
+
+var (
+	_ check.Check            = &HasNoFixableVulnerabilitiesCheck{}
+	_ check.FindingsReporter = &HasNoFixableVulnerabilitiesCheck{}
+)
+
+// HasNoFixableVulnerabilitiesCheck evaluates that the image does not contain RPMs
+// affected by Critical or Important vulnerabilities that a released advisory fixes.
+// Advisories are read from a local dataset, so no network access is made.
+type HasNoFixableVulnerabilitiesCheck struct {
+	advisories     *advisory.Dataset
+	getPackageList packageListFunc
+}
+
+// vulnerabilityReport is written to the vulnerabilities.json artifact.
+type vulnerabilityReport struct {
+	Vulnerabilities []vulnerability `json:"vulnerabilities"`
+}
+
+// vulnerability is a fixable vulnerability in an installed package.
+type vulnerability struct {
+	CVE      string `json:"cve"`
+	Severity string `json:"severity"`
+	Advisory string `json:"advisory,omitempty"`
+	Package  string `json:"package"`
+	// Nvra identifies the installed package, as in the RPM manifest.
+	Nvra      string `json:"nvra"`
+	Installed string `json:"installed"`
+	FixedIn   string `json:"fixed_in"`
+	// Reported is true if the vulnerability is severe enough to fail the
+	// check.
+	Reported bool `json:"reported"`
+}
+
+// NewHasNoFixableVulnerabilitiesCheck returns a check that matches the RPMs in the
+// image against advisories.
+func NewHasNoFixableVulnerabilitiesCheck(advisories *advisory.Dataset) *HasNoFixableVulnerabilitiesCheck {
+	return &HasNoFixableVulnerabilitiesCheck{advisories: advisories, getPackageList: rpm.GetPackageList}
+}
+
+func (p *HasNoFixableVulnerabilitiesCheck) Validate(ctx context.Context, imgRef image.ImageReference) (bool, error) {
+	passed, _, err := p.ValidateWithFindings(ctx, imgRef)
+	return passed, err
+}
+
+// ValidateWithFindings reports each fixable Critical or Important vulnerability as a
+// finding. The check is skipped for images without an RPM database.
+func (p *HasNoFixableVulnerabilitiesCheck) ValidateWithFindings(ctx context.Context, imgRef image.ImageReference) (bool, []check.Finding, error) {
+	pkgList, err := p.getPackageList(ctx, imgRef.ImageFSPath)
+	if err != nil {
+		return false, nil, fmt.Errorf("%w: no RPM database found: %v", check.ErrCheckSkipped, err)
+	}
+
+	return p.validate(ctx, pkgList)
+}
+
+func (p *HasNoFixableVulnerabilitiesCheck) validate(ctx context.Context, pkgList []*rpmdb.PackageInfo) (bool, []check.Finding, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	report := vulnerabilityReport{Vulnerabilities: []vulnerability{}}
+	for _, pkg := range pkgList {
+		installed := advisory.EVR{Version: pkg.Version, Release: pkg.Release}
+		if pkg.Epoch != nil {
+			installed.Epoch = *pkg.Epoch
+		}
+		for _, fix := range p.advisories.Match(pkg.Name, pkg.Arch, installed) {
+			report.Vulnerabilities = append(report.Vulnerabilities, vulnerability{
+				CVE:       fix.CVE,
+				Severity:  string(fix.Severity),
+				Advisory:  fix.Advisory,
+				Package:   pkg.Name,
+				Nvra:      fmt.Sprintf("%s-%s-%s.%s", pkg.Name, pkg.Version, pkg.Release, pkg.Arch),
+				Installed: installed.String(),
+				FixedIn:   fix.FixedIn.String(),
+				Reported:  fix.Severity.AtLeast(advisory.SeverityImportant),
+			})
+		}
+	}
+
+	// Most severe first, so the artifact can be read from the top.
+	slices.SortStableFunc(report.Vulnerabilities, func(a, b vulnerability) int {
+		if c := advisory.Severity(b.Severity).Compare(advisory.Severity(a.Severity)); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Nvra, b.Nvra); c != 0 {
+			return c
+		}
+		return strings.Compare(a.CVE, b.CVE)
+	})
+
+	var findings []check.Finding
+	for _, v := range report.Vulnerabilities {
+		if !v.Reported {
+			continue
+		}
+		message := fmt.Sprintf("%s (%s) is fixed in %s-%s", v.CVE, v.Severity, v.Package, v.FixedIn)
+		if v.Advisory != "" {
+			message += " by " + v.Advisory
+		}
+		findings = append(findings, check.Finding{
+			Message:  message,
+			Object:   v.Nvra,
+			Severity: check.SeverityError,
+		})
+	}
+
+	if artifactWriter := artifacts.WriterFromContext(ctx); artifactWriter != nil {
+		reportJSON, err := json.MarshalIndent(report, "", "    ")
+		if err != nil {
+			//coverage:ignore
+			return false, nil, fmt.Errorf("could not marshal the vulnerability report: %w", err)
+		}
+		if _, err := artifactWriter.WriteFile(check.DefaultVulnerabilitiesFilename, bytes.NewReader(reportJSON)); err != nil {
+			//coverage:ignore
+			return false, nil, fmt.Errorf("could not write the vulnerability report: %w", err)
+		}
+	}
+
+	logger.V(log.DBG).Info("fixable vulnerabilities found", "vulnerabilityCount", len(report.Vulnerabilities), "reportedCount", len(findings))
+	return len(findings) == 0, findings, nil
+}
+
+func (p *HasNoFixableVulnerabilitiesCheck) Name() string {
+	return "HasNoFixableVulnerabilities"
+}
+
+func (p *HasNoFixableVulnerabilitiesCheck) Metadata() check.Metadata {
+	return check.Metadata{
+		ID:               "PFLT-CNT-011",
+		PolicyVersion:    "1.0",
+		Categories:       []check.Category{check.CategorySecurity, check.CategoryPackaging},
+		Description:      "Checking that the image does not contain RPMs affected by Critical or Important vulnerabilities that a released advisory fixes.",
+		Level:            "best",
+		KnowledgeBaseURL: securityDataURL,
+		CheckURL:         securityDataURL,
+	}
+}
+
+func (p *HasNoFixableVulnerabilitiesCheck) Help() check.HelpText {
+	return check.HelpText{
+		Message:    "Check HasNoFixableVulnerabilities encountered an error. Please review the preflight.log file for more information.",
+		Suggestion: "Update the packages named in the findings to at least the version the vulnerability is fixed in, e.g. by rebuilding on an updated base image or running dnf update. Every fixable vulnerability is listed in vulnerabilities.json.",
+	}
+}
+
+func (p *HasNoFixableVulnerabilitiesCheck) RequiredFilePatterns() []string {
+	//coverage:ignore
+	return rpm.RpmdbPaths
+}
@@ -0,0 +1,125 @@
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	rpmdb "github.com/knqyf263/go-rpmdb/pkg"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/advisory"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+)
+
+var _ = Describe("HasNoFixableVulnerabilities", func() {
+	var (
+		hasNoFixableVulnerabilities *HasNoFixableVulnerabilitiesCheck
+		pkgList                     []*rpmdb.PackageInfo
+		aw                          *artifacts.MapWriter
+		ctx                         context.Context
+	)
+
+	BeforeEach(func() {
+		epoch := 1
+		pkgList = []*rpmdb.PackageInfo{
+			{Name: "glibc", Version: "2.34", Release: "83.el9_3", Arch: "x86_64"},
+			{Name: "openssl-libs", Epoch: &epoch, Version: "3.0.7", Release: "24.el9", Arch: "x86_64"},
+			{Name: "bash", Version: "5.1.8", Release: "6.el9", Arch: "x86_64"},
+		}
+		dataset := advisory.NewDataset(
+			advisory.Fix{Advisory: "RHSA-2024:0100", CVE: "CVE-2023-6246", Severity: advisory.SeverityImportant, Package: "glibc", FixedIn: advisory.ParseEVR("2.34-83.el9_3.7")},
+			advisory.Fix{Advisory: "RHSA-2024:1234", CVE: "CVE-2023-5678", Severity: advisory.SeverityModerate, Package: "openssl-libs", FixedIn: advisory.ParseEVR("1:3.0.7-25.el9_3")},
+			advisory.Fix{CVE: "CVE-2024-0727", Severity: advisory.SeverityCritical, Package: "openssl-libs", FixedIn: advisory.ParseEVR("1:3.0.7-25.el9_3")},
+			advisory.Fix{Advisory: "RHSA-2023:1111", CVE: "CVE-2022-3715", Severity: advisory.SeverityCritical, Package: "bash", FixedIn: advisory.ParseEVR("5.1.8-6.el9")},
+		)
+		hasNoFixableVulnerabilities = NewHasNoFixableVulnerabilitiesCheck(dataset)
+		hasNoFixableVulnerabilities.getPackageList = func(_ context.Context, _ string) ([]*rpmdb.PackageInfo, error) {
+			return pkgList, nil
+		}
+
+		var err error
+		aw, err = artifacts.NewMapWriter()
+		Expect(err).ToNot(HaveOccurred())
+		ctx = artifacts.ContextWithWriter(context.Background(), aw)
+	})
+
+	Context("When packages have fixable Critical or Important vulnerabilities", func() {
+		It("should not pass Validate, and report each of them", func() {
+			ok, findings, err := hasNoFixableVulnerabilities.ValidateWithFindings(ctx, image.ImageReference{ImageFSPath: "/fake"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+			Expect(findings).To(Equal([]check.Finding{
+				{Message: "CVE-2024-0727 (Critical) is fixed in openssl-libs-1:3.0.7-25.el9_3", Object: "openssl-libs-3.0.7-24.el9.x86_64", Severity: check.SeverityError},
+				{Message: "CVE-2023-6246 (Important) is fixed in glibc-2.34-83.el9_3.7 by RHSA-2024:0100", Object: "glibc-2.34-83.el9_3.x86_64", Severity: check.SeverityError},
+			}))
+		})
+
+		It("should write every fixable vulnerability to the artifact", func() {
+			_, _, err := hasNoFixableVulnerabilities.ValidateWithFindings(ctx, image.ImageReference{ImageFSPath: "/fake"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(aw.Files()).To(HaveKey(check.DefaultVulnerabilitiesFilename))
+
+			var report vulnerabilityReport
+			Expect(json.NewDecoder(aw.Files()[check.DefaultVulnerabilitiesFilename]).Decode(&report)).To(Succeed())
+			Expect(report.Vulnerabilities).To(HaveLen(3))
+			Expect(report.Vulnerabilities[2]).To(Equal(vulnerability{
+				CVE:       "CVE-2023-5678",
+				Severity:  "Moderate",
+				Advisory:  "RHSA-2024:1234",
+				Package:   "openssl-libs",
+				Nvra:      "openssl-libs-3.0.7-24.el9.x86_64",
+				Installed: "1:3.0.7-24.el9",
+				FixedIn:   "1:3.0.7-25.el9_3",
+				Reported:  false,
+			}))
+		})
+	})
+
+	Context("When packages only have fixable Moderate or Low vulnerabilities", func() {
+		BeforeEach(func() {
+			pkgList = pkgList[1:]
+			pkgList[0].Release = "25.el9_3"
+			pkgList = append(pkgList, &rpmdb.PackageInfo{Name: "glibc", Version: "2.34", Release: "83.el9_3.7", Arch: "x86_64"})
+		})
+		It("should pass Validate", func() {
+			ok, err := hasNoFixableVulnerabilities.Validate(ctx, image.ImageReference{ImageFSPath: "/fake"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+		})
+	})
+
+	Context("When vulnerabilities are only fixed in another RHEL stream", func() {
+		BeforeEach(func() {
+			epoch := 1
+			pkgList = []*rpmdb.PackageInfo{
+				{Name: "openssl-libs", Epoch: &epoch, Version: "1.1.1k", Release: "9.el8", Arch: "x86_64"},
+			}
+			hasNoFixableVulnerabilities.advisories = advisory.NewDataset(
+				advisory.Fix{CVE: "CVE-2024-0727", Severity: advisory.SeverityCritical, Package: "openssl-libs", FixedIn: advisory.ParseEVR("1:3.0.7-25.el9_3"), Stream: "el9", Arch: "x86_64"},
+			)
+		})
+		It("should pass Validate", func() {
+			ok, err := hasNoFixableVulnerabilities.Validate(ctx, image.ImageReference{ImageFSPath: "/fake"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+		})
+	})
+
+	Context("When the image has no RPM database", func() {
+		BeforeEach(func() {
+			hasNoFixableVulnerabilities.getPackageList = func(_ context.Context, _ string) ([]*rpmdb.PackageInfo, error) {
+				return nil, errors.New("could not find rpm db/packages")
+			}
+		})
+		It("should be skipped", func() {
+			_, err := hasNoFixableVulnerabilities.Validate(ctx, image.ImageReference{ImageFSPath: "/fake"})
+			Expect(err).To(MatchError(check.ErrCheckSkipped))
+		})
+	})
+
+	AssertMetaData(&HasNoFixableVulnerabilitiesCheck{})
+})
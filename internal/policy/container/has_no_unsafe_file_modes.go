@@ -0,0 +1,206 @@
+package container
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/go-logr/logr"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
+)
+
+// The special mode bits of a tar header, as in c_ISUID, c_ISGID and c_ISVTX.
+const (
+	tarModeSetuid = 0o4000
+	tarModeSetgid = 0o2000
+	tarModeSticky = 0o1000
+)
+
+var (
+	_ check.Check            = &HasNoUnsafeFileModesCheck{}
+	_ check.FindingsReporter = &HasNoUnsafeFileModesCheck{}
+)
+
+// HasNoUnsafeFileModesCheck evaluates that the image does not add setuid or
+// setgid executables, world-writable files, world-writable directories
+// without the sticky bit, or device nodes to its base image. The first layer
+// is the base image, as in HasModifiedFilesCheck. Files a later layer adds
+// again, with the modes they have in the base image, e.g. when a package is
+// updated, are permitted.
+type HasNoUnsafeFileModesCheck struct{}
+
+// unsafeFileReport is written to the unsafe-files.json artifact.
+type unsafeFileReport struct {
+	Files []unsafeFile `json:"files"`
+}
+
+// unsafeFile is a file with an unsafe mode added by the image.
+type unsafeFile struct {
+	Path   string   `json:"path"`
+	Issues []string `json:"issues"`
+	Mode   string   `json:"mode"`
+	Owner  string   `json:"owner"`
+	// Layer is the index of the layer that added the file.
+	Layer       int    `json:"layer"`
+	LayerDigest string `json:"layer_digest"`
+}
+
+func (p *HasNoUnsafeFileModesCheck) Validate(ctx context.Context, imgRef image.ImageReference) (bool, error) {
+	passed, _, err := p.ValidateWithFindings(ctx, imgRef)
+	return passed, err
+}
+
+// ValidateWithFindings reports each file with an unsafe mode that the image adds
+// to its base image, with the layer that added it.
+func (p *HasNoUnsafeFileModesCheck) ValidateWithFindings(ctx context.Context, imgRef image.ImageReference) (bool, []check.Finding, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	if imgRef.ImageInfo == nil {
+		return false, nil, fmt.Errorf("image reference invalid")
+	}
+
+	layers, err := imgRef.ImageInfo.Layers()
+	if err != nil {
+		return false, nil, fmt.Errorf("could not read the image layers: %w", err)
+	}
+
+	files := layerFiles{}
+	base := layerFiles{}
+	digests := make([]string, 0, len(layers))
+	for idx, layer := range layers {
+		digest, err := layer.Digest()
+		if err != nil {
+			//coverage:ignore
+			return false, nil, fmt.Errorf("unable to retrieve digest for layer %d: %w", idx, err)
+		}
+		digests = append(digests, digest.String())
+
+		if err := files.apply(idx, layer); err != nil {
+			return false, nil, err
+		}
+		if idx == 0 {
+			base = maps.Clone(files)
+		}
+	}
+
+	report := unsafeFileReport{Files: []unsafeFile{}}
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		file := files[name]
+		issues := unsafeModeIssues(file.header)
+		if len(issues) == 0 || file.layer == 0 {
+			continue
+		}
+		if baseFile, ok := base[name]; ok && isSubset(issues, unsafeModeIssues(baseFile.header)) {
+			logger.V(log.TRC).Info("permitting unsafe mode present in the base image", "path", name, "layer", file.layer)
+			continue
+		}
+		report.Files = append(report.Files, unsafeFile{
+			Path:        "/" + name,
+			Issues:      issues,
+			Mode:        file.header.FileInfo().Mode().String(),
+			Owner:       fmt.Sprintf("%d:%d", file.header.Uid, file.header.Gid),
+			Layer:       file.layer,
+			LayerDigest: digests[file.layer],
+		})
+	}
+
+	var findings []check.Finding
+	for _, f := range report.Files {
+		findings = append(findings, check.Finding{
+			Message:  fmt.Sprintf("%s added in layer %d (%s) (mode %s, owner %s)", strings.Join(f.Issues, ", "), f.Layer, shortDigest(f.LayerDigest), f.Mode, f.Owner),
+			Object:   f.Path,
+			Severity: check.SeverityError,
+		})
+	}
+
+	if artifactWriter := artifacts.WriterFromContext(ctx); artifactWriter != nil {
+		reportJSON, err := json.MarshalIndent(report, "", "    ")
+		if err != nil {
+			//coverage:ignore
+			return false, nil, fmt.Errorf("could not marshal the unsafe files report: %w", err)
+		}
+		if _, err := artifactWriter.WriteFile(check.DefaultUnsafeFilesFilename, bytes.NewReader(reportJSON)); err != nil {
+			//coverage:ignore
+			return false, nil, fmt.Errorf("could not write the unsafe files report: %w", err)
+		}
+	}
+
+	logger.V(log.DBG).Info("files with unsafe modes found", "fileCount", len(report.Files))
+	return len(findings) == 0, findings, nil
+}
+
+// unsafeModeIssues describes what is unsafe about the mode of the file
+// header describes.
+func unsafeModeIssues(header *tar.Header) []string {
+	var issues []string
+	executable := header.Mode&0o111 != 0
+	worldWritable := header.Mode&0o002 != 0 && header.Mode&tarModeSticky == 0
+	switch header.Typeflag {
+	case tar.TypeReg:
+		if executable && header.Mode&tarModeSetuid != 0 {
+			issues = append(issues, "setuid executable")
+		}
+		if executable && header.Mode&tarModeSetgid != 0 {
+			issues = append(issues, "setgid executable")
+		}
+		if worldWritable {
+			issues = append(issues, "world-writable file")
+		}
+	case tar.TypeDir:
+		if worldWritable {
+			issues = append(issues, "world-writable directory without the sticky bit")
+		}
+	case tar.TypeChar:
+		issues = append(issues, "character device")
+	case tar.TypeBlock:
+		issues = append(issues, "block device")
+	}
+	return issues
+}
+
+// isSubset returns true if every element of a is in b.
+func isSubset(a, b []string) bool {
+	for _, s := range a {
+		if !slices.Contains(b, s) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *HasNoUnsafeFileModesCheck) Name() string {
+	return "HasNoUnsafeFileModes"
+}
+
+func (p *HasNoUnsafeFileModesCheck) Metadata() check.Metadata {
+	return check.Metadata{
+		ID:               "PFLT-CNT-014",
+		PolicyVersion:    "1.0",
+		Categories:       []check.Category{check.CategorySecurity},
+		Description:      "Checking that the image does not add setuid or setgid executables, world-writable files or directories without the sticky bit, or device nodes to its base image.",
+		Level:            check.LevelWarn,
+		KnowledgeBaseURL: certDocumentationURL,
+		CheckURL:         certDocumentationURL,
+	}
+}
+
+func (p *HasNoUnsafeFileModesCheck) Help() check.HelpText {
+	return check.HelpText{
+		Message:    "Check HasNoUnsafeFileModes encountered an error. Please review the preflight.log file for more information.",
+		Suggestion: "Remove the setuid and setgid bits from the executables named in the findings, e.g. with chmod ug-s, make world-writable paths group-writable instead, or set the sticky bit on shared directories, and do not add device nodes to the image. Every file is listed in unsafe-files.json.",
+	}
+}
+
+func (p *HasNoUnsafeFileModesCheck) RequiredFilePatterns() []string {
+	//coverage:ignore
+	return nil
+}
@@ -0,0 +1,153 @@
+package container
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"errors"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	fakecranev1 "github.com/google/go-containerregistry/pkg/v1/fake"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+)
+
+func fileHeader(name string, mode int64) *tar.Header {
+	return &tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: mode}
+}
+
+var _ = Describe("HasNoUnsafeFileModes", func() {
+	var (
+		hasNoUnsafeFileModes *HasNoUnsafeFileModesCheck
+		aw                   *artifacts.MapWriter
+		ctx                  context.Context
+		baseLayer            v1.Layer
+		img                  v1.Image
+	)
+
+	BeforeEach(func() {
+		hasNoUnsafeFileModes = &HasNoUnsafeFileModesCheck{}
+
+		var err error
+		aw, err = artifacts.NewMapWriter()
+		Expect(err).ToNot(HaveOccurred())
+		ctx = artifacts.ContextWithWriter(context.Background(), aw)
+
+		baseLayer = headerLayer(
+			dirHeader("tmp/", 0o1777, 0, 0),
+			dirHeader("usr/bin/", 0o755, 0, 0),
+			fileHeader("usr/bin/passwd", 0o4755),
+			fileHeader("usr/bin/ls", 0o755),
+			&tar.Header{Name: "dev/null", Typeflag: tar.TypeChar, Mode: 0o666, Devmajor: 1, Devminor: 3},
+		)
+		img, err = mutate.AppendLayers(empty.Image, baseLayer)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	Context("When the image only has the unsafe modes of its base image", func() {
+		BeforeEach(func() {
+			var err error
+			img, err = mutate.AppendLayers(img, headerLayer(
+				fileHeader("usr/bin/passwd", 0o4755),
+				dirHeader("tmp/", 0o1777, 0, 0),
+				fileHeader("usr/bin/notes", 0o4644),
+				dirHeader("opt/app/", 0o775, 1001, 0),
+			))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should pass Validate, and write an empty artifact", func() {
+			ok, findings, err := hasNoUnsafeFileModes.ValidateWithFindings(ctx, image.ImageReference{ImageInfo: img})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(findings).To(BeEmpty())
+
+			var report unsafeFileReport
+			Expect(json.NewDecoder(aw.Files()[check.DefaultUnsafeFilesFilename]).Decode(&report)).To(Succeed())
+			Expect(report.Files).To(BeEmpty())
+		})
+	})
+
+	Context("When the image adds unsafe modes", func() {
+		var appLayer v1.Layer
+
+		BeforeEach(func() {
+			appLayer = headerLayer(
+				fileHeader("usr/local/bin/tool", 0o4755),
+				fileHeader("usr/local/bin/removed", 0o4755),
+				fileHeader("usr/bin/ls", 0o6755),
+				dirHeader("opt/shared/", 0o777, 0, 0),
+				&tar.Header{Name: "opt/app.log", Typeflag: tar.TypeReg, Mode: 0o666, Uid: 1001, Gid: 0},
+				&tar.Header{Name: "dev/sda", Typeflag: tar.TypeBlock, Mode: 0o660, Devmajor: 8},
+			)
+			var err error
+			img, err = mutate.AppendLayers(img, appLayer, headerLayer(fileHeader("usr/local/bin/.wh.removed", 0)))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should not pass Validate, and report each file with the layer that added it", func() {
+			ok, findings, err := hasNoUnsafeFileModes.ValidateWithFindings(ctx, image.ImageReference{ImageInfo: img})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+
+			digest, err := appLayer.Digest()
+			Expect(err).ToNot(HaveOccurred())
+			layer := "layer 1 (sha256:" + digest.Hex[:12] + ")"
+			Expect(findings).To(Equal([]check.Finding{
+				{Message: "block device added in " + layer + " (mode Drw-rw----, owner 0:0)", Object: "/dev/sda", Severity: check.SeverityError},
+				{Message: "world-writable file added in " + layer + " (mode -rw-rw-rw-, owner 1001:0)", Object: "/opt/app.log", Severity: check.SeverityError},
+				{Message: "world-writable directory without the sticky bit added in " + layer + " (mode drwxrwxrwx, owner 0:0)", Object: "/opt/shared", Severity: check.SeverityError},
+				{Message: "setuid executable, setgid executable added in " + layer + " (mode ugrwxr-xr-x, owner 0:0)", Object: "/usr/bin/ls", Severity: check.SeverityError},
+				{Message: "setuid executable added in " + layer + " (mode urwxr-xr-x, owner 0:0)", Object: "/usr/local/bin/tool", Severity: check.SeverityError},
+			}))
+		})
+
+		It("should write every file to the artifact", func() {
+			_, _, err := hasNoUnsafeFileModes.ValidateWithFindings(ctx, image.ImageReference{ImageInfo: img})
+			Expect(err).ToNot(HaveOccurred())
+
+			digest, err := appLayer.Digest()
+			Expect(err).ToNot(HaveOccurred())
+			var report unsafeFileReport
+			Expect(json.NewDecoder(aw.Files()[check.DefaultUnsafeFilesFilename]).Decode(&report)).To(Succeed())
+			Expect(report.Files).To(HaveLen(5))
+			Expect(report.Files[4]).To(Equal(unsafeFile{
+				Path:        "/usr/local/bin/tool",
+				Issues:      []string{"setuid executable"},
+				Mode:        "urwxr-xr-x",
+				Owner:       "0:0",
+				Layer:       1,
+				LayerDigest: digest.String(),
+			}))
+		})
+	})
+
+	Context("When the image can not be read", func() {
+		It("should fail without an image", func() {
+			_, err := hasNoUnsafeFileModes.Validate(ctx, image.ImageReference{})
+			Expect(err).To(MatchError("image reference invalid"))
+		})
+
+		It("should fail if the layers can not be listed", func() {
+			fakeImage := fakecranev1.FakeImage{}
+			fakeImage.LayersReturns(nil, errors.New("no layers"))
+			_, err := hasNoUnsafeFileModes.Validate(ctx, image.ImageReference{ImageInfo: &fakeImage})
+			Expect(err).To(MatchError(ContainSubstring("could not read the image layers")))
+		})
+
+		It("should fail if a layer can not be read", func() {
+			fakeImage := fakecranev1.FakeImage{}
+			fakeImage.LayersReturns([]v1.Layer{baseLayer, unreadableLayer{}}, nil)
+			_, err := hasNoUnsafeFileModes.Validate(ctx, image.ImageReference{ImageInfo: &fakeImage})
+			Expect(err).To(MatchError(ContainSubstring("reading layer 1: reading layer contents")))
+		})
+	})
+
+	AssertMetaData(&HasNoUnsafeFileModesCheck{})
+})
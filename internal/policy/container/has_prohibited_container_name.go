@@ -51,6 +51,9 @@ func (p HasProhibitedContainerName) Name() string {
 
 func (p HasProhibitedContainerName) Metadata() check.Metadata {
 	return check.Metadata{
+		ID:               "PFLT-CNT-010",
+		PolicyVersion:    "1.0",
+		Categories:       []check.Category{check.CategoryMetadata},
 		Description:      "Checking if the container-name violates Red Hat trademark.",
 		Level:            "good",
 		KnowledgeBaseURL: certDocumentationURL,
@@ -55,6 +55,9 @@ func (p *HasNoProhibitedLabelsCheck) Name() string {
 
 func (p *HasNoProhibitedLabelsCheck) Metadata() check.Metadata {
 	return check.Metadata{
+		ID:               "PFLT-CNT-006",
+		PolicyVersion:    "1.0",
+		Categories:       []check.Category{check.CategoryMetadata},
 		Description:      "Checking if the labels (name, vendor, maintainer) violate Red Hat trademark.",
 		Level:            "good",
 		KnowledgeBaseURL: certDocumentationURL,
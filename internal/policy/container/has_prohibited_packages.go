@@ -14,7 +14,10 @@ import (
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/rpm"
 )
 
-var _ check.Check = &HasNoProhibitedPackagesCheck{}
+var (
+	_ check.Check            = &HasNoProhibitedPackagesCheck{}
+	_ check.FindingsReporter = &HasNoProhibitedPackagesCheck{}
+)
 
 // HasProhibitedPackages evaluates that the image does not contain prohibited packages,
 // which refers to packages that are not redistributable without an appropriate license.
@@ -31,9 +34,15 @@ func NewHasNoProhibitedPackagesCheck() *HasNoProhibitedPackagesCheck {
 }
 
 func (p *HasNoProhibitedPackagesCheck) Validate(ctx context.Context, imgRef image.ImageReference) (bool, error) {
+	passed, _, err := p.ValidateWithFindings(ctx, imgRef)
+	return passed, err
+}
+
+// ValidateWithFindings reports each prohibited package as a finding.
+func (p *HasNoProhibitedPackagesCheck) ValidateWithFindings(ctx context.Context, imgRef image.ImageReference) (bool, []check.Finding, error) {
 	pkgList, err := p.getDataToValidate(ctx, imgRef.ImageFSPath)
 	if err != nil {
-		return false, fmt.Errorf("unable to get a list of all packages in the image: %v", err)
+		return false, nil, fmt.Errorf("unable to get a list of all packages in the image: %v", err)
 	}
 
 	return p.validate(ctx, pkgList)
@@ -54,7 +63,7 @@ func (p *HasNoProhibitedPackagesCheck) getDataToValidate(ctx context.Context, di
 }
 
 //nolint:unparam // ctx is unused. Keep for future use.
-func (p *HasNoProhibitedPackagesCheck) validate(ctx context.Context, pkgList []string) (bool, error) {
+func (p *HasNoProhibitedPackagesCheck) validate(ctx context.Context, pkgList []string) (bool, []check.Finding, error) {
 	logger := logr.FromContextOrDiscard(ctx)
 
 	var prohibitedPackages []string
@@ -76,7 +85,16 @@ func (p *HasNoProhibitedPackagesCheck) validate(ctx context.Context, pkgList []s
 		logger.V(log.DBG).Info("prohibited packages found", "packageCount", len(prohibitedPackages), "packageList", prohibitedPackages)
 	}
 
-	return len(prohibitedPackages) == 0, nil
+	var findings []check.Finding
+	for _, pkg := range prohibitedPackages {
+		findings = append(findings, check.Finding{
+			Message:  "package is not redistributable",
+			Object:   pkg,
+			Severity: check.SeverityError,
+		})
+	}
+
+	return len(prohibitedPackages) == 0, findings, nil
 }
 
 func (p *HasNoProhibitedPackagesCheck) Name() string {
@@ -85,6 +103,9 @@ func (p *HasNoProhibitedPackagesCheck) Name() string {
 
 func (p *HasNoProhibitedPackagesCheck) Metadata() check.Metadata {
 	return check.Metadata{
+		ID:               "PFLT-CNT-004",
+		PolicyVersion:    "1.0",
+		Categories:       []check.Category{check.CategoryLicensing, check.CategoryPackaging},
 		Description:      "Checks to ensure that the image in use does not include prohibited packages, such as Red Hat Enterprise Linux (RHEL) kernel packages.",
 		Level:            "best",
 		KnowledgeBaseURL: certDocumentationURL,
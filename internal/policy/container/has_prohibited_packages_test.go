@@ -8,6 +8,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
 )
 
@@ -31,7 +32,7 @@ var _ = Describe("HasNoProhibitedPackages", func() {
 	Describe("Checking if it has an prohibited packages", func() {
 		Context("When there are no prohibited packages found", func() {
 			It("should pass validate", func() {
-				ok, err := hasNoProhibitedPackages.validate(context.TODO(), pkgList)
+				ok, _, err := hasNoProhibitedPackages.validate(context.TODO(), pkgList)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(ok).To(BeTrue())
 			})
@@ -42,10 +43,19 @@ var _ = Describe("HasNoProhibitedPackages", func() {
 				pkgs = append(pkgList, "grub")
 			})
 			It("should not pass Validate", func() {
-				ok, err := hasNoProhibitedPackages.validate(context.TODO(), pkgs)
+				ok, _, err := hasNoProhibitedPackages.validate(context.TODO(), pkgs)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(ok).To(BeFalse())
 			})
+			It("should report the package as a finding", func() {
+				_, findings, err := hasNoProhibitedPackages.validate(context.TODO(), pkgs)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(findings).To(Equal([]check.Finding{{
+					Message:  "package is not redistributable",
+					Object:   "grub",
+					Severity: check.SeverityError,
+				}}))
+			})
 		})
 		Context("When there is a prohibited package in the glob list found", func() {
 			var pkgs []string
@@ -53,7 +63,7 @@ var _ = Describe("HasNoProhibitedPackages", func() {
 				pkgs = append(pkgList, "kpatch2121")
 			})
 			It("should not pass Validate", func() {
-				ok, err := hasNoProhibitedPackages.validate(context.TODO(), pkgs)
+				ok, _, err := hasNoProhibitedPackages.validate(context.TODO(), pkgs)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(ok).To(BeFalse())
 			})
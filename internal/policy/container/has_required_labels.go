@@ -13,37 +13,51 @@ import (
 
 var requiredLabels = []string{"name", "vendor", "version", "release", "summary", "description", "maintainer"}
 
-var _ check.Check = &HasRequiredLabelsCheck{}
+var (
+	_ check.Check            = &HasRequiredLabelsCheck{}
+	_ check.FindingsReporter = &HasRequiredLabelsCheck{}
+)
 
 // HasRequiredLabelsCheck evaluates the image manifest to ensure that the appropriate metadata
 // labels are present on the image asset as it exists in its current container registry.
 type HasRequiredLabelsCheck struct{}
 
 func (p *HasRequiredLabelsCheck) Validate(ctx context.Context, imgRef image.ImageReference) (bool, error) {
+	passed, _, err := p.ValidateWithFindings(ctx, imgRef)
+	return passed, err
+}
+
+// ValidateWithFindings reports each missing label as a finding.
+func (p *HasRequiredLabelsCheck) ValidateWithFindings(ctx context.Context, imgRef image.ImageReference) (bool, []check.Finding, error) {
 	labels, err := getContainerLabels(imgRef.ImageInfo)
 	if err != nil {
-		return false, fmt.Errorf("could not retrieve image labels: %v", err)
+		return false, nil, fmt.Errorf("could not retrieve image labels: %v", err)
 	}
 
 	return p.validate(ctx, labels)
 }
 
-func (p *HasRequiredLabelsCheck) validate(ctx context.Context, labels map[string]string) (bool, error) {
+func (p *HasRequiredLabelsCheck) validate(ctx context.Context, labels map[string]string) (bool, []check.Finding, error) {
 	logger := logr.FromContextOrDiscard(ctx)
 
 	missingLabels := []string{}
+	var findings []check.Finding
 	for _, label := range requiredLabels {
 		if labels[label] == "" {
 			missingLabels = append(missingLabels, label)
+			findings = append(findings, check.Finding{
+				Message:  "required label is missing or empty",
+				Object:   label,
+				Severity: check.SeverityError,
+			})
 		}
 	}
 
-	// TODO: We should be reporting this in the results, not in a log message
 	if len(missingLabels) > 0 {
 		logger.V(log.DBG).Info("expected labels are missing", "missingLabels", missingLabels)
 	}
 
-	return len(missingLabels) == 0, nil
+	return len(missingLabels) == 0, findings, nil
 }
 
 func (p *HasRequiredLabelsCheck) Name() string {
@@ -52,6 +66,9 @@ func (p *HasRequiredLabelsCheck) Name() string {
 
 func (p *HasRequiredLabelsCheck) Metadata() check.Metadata {
 	return check.Metadata{
+		ID:               "PFLT-CNT-005",
+		PolicyVersion:    "1.0",
+		Categories:       []check.Category{check.CategoryMetadata},
 		Description:      "Checking if the required labels (name, vendor, version, release, summary, description, maintainer) are present in the container metadata",
 		Level:            "good",
 		KnowledgeBaseURL: certDocumentationURL,
@@ -9,6 +9,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
 )
 
@@ -79,6 +80,16 @@ var _ = Describe("HasRequiredLabels", func() {
 				Expect(err).ToNot(HaveOccurred())
 				Expect(ok).To(BeFalse())
 			})
+			It("should report the missing label as a finding", func() {
+				ok, findings, err := hasRequiredLabelsCheck.ValidateWithFindings(context.TODO(), imageRef)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ok).To(BeFalse())
+				Expect(findings).To(Equal([]check.Finding{{
+					Message:  "required label is missing or empty",
+					Object:   "description",
+					Severity: check.SeverityError,
+				}}))
+			})
 		})
 
 		Context("When ConfigFile returns an error", func() {
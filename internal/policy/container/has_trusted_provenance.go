@@ -0,0 +1,176 @@
+package container
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/option"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/provenance"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/signature"
+)
+
+const slsaProvenanceURL = "https://slsa.dev/spec/v1.0/provenance"
+
+var (
+	_ check.Check            = &HasTrustedProvenanceCheck{}
+	_ check.FindingsReporter = &HasTrustedProvenanceCheck{}
+)
+
+// HasTrustedProvenanceCheck evaluates that the image has a SLSA provenance
+// attestation signed by one of a set of trusted keys, and that the
+// provenance satisfies a set of rules, e.g. that the builder is allowed.
+// Attestations are read from the registry with the same options and
+// credentials the image was pulled with. No transparency log is consulted.
+type HasTrustedProvenanceCheck struct {
+	keys        signature.Keyring
+	rules       provenance.Rules
+	craneConfig option.CraneConfig
+}
+
+// provenanceReport is written to the provenance.json artifact.
+type provenanceReport struct {
+	// Digests are the digests attestations were looked up for: the image,
+	// and the manifest list it was selected from, if any.
+	Digests      []string                `json:"digests"`
+	Attestations []provenanceReportEntry `json:"attestations"`
+}
+
+// provenanceReportEntry is an attestation, and the rules it violates.
+type provenanceReportEntry struct {
+	provenance.Attestation
+	Violations []string `json:"violations,omitempty"`
+}
+
+// NewHasTrustedProvenanceCheck returns a check that verifies the provenance
+// attestations of the image against keys, and evaluates them with rules.
+// craneConfig configures access to the registry.
+func NewHasTrustedProvenanceCheck(keys signature.Keyring, rules provenance.Rules, craneConfig option.CraneConfig) *HasTrustedProvenanceCheck {
+	return &HasTrustedProvenanceCheck{keys: keys, rules: rules, craneConfig: craneConfig}
+}
+
+func (p *HasTrustedProvenanceCheck) Validate(ctx context.Context, imgRef image.ImageReference) (bool, error) {
+	passed, _, err := p.ValidateWithFindings(ctx, imgRef)
+	return passed, err
+}
+
+// ValidateWithFindings reports a summary of each verified provenance
+// attestation, and each rule it violates, and the reason any other
+// attestation is not verified. The check passes if a verified attestation
+// violates no rules. Images that were not pulled from a registry skip the
+// check.
+func (p *HasTrustedProvenanceCheck) ValidateWithFindings(ctx context.Context, imgRef image.ImageReference) (bool, []check.Finding, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+	repo, digests, options, err := attachmentSubjects(ctx, imgRef, p.craneConfig)
+	if err != nil {
+		return false, nil, err
+	}
+
+	report := provenanceReport{Attestations: []provenanceReportEntry{}}
+	var findings []check.Finding
+	verified, permitted := 0, 0
+	for _, d := range digests {
+		attestations, err := provenance.Verify(ctx, repo, d, p.keys, options...)
+		if err != nil {
+			return false, nil, fmt.Errorf("could not verify the provenance of %s: %w", d, err)
+		}
+		report.Digests = append(report.Digests, d.String())
+
+		for _, a := range attestations {
+			entry := provenanceReportEntry{Attestation: a}
+			if !a.Verified {
+				findings = append(findings, check.Finding{
+					Message:  "attestation not verified: " + a.Reason,
+					Object:   a.Source,
+					Severity: check.SeverityWarning,
+				})
+				report.Attestations = append(report.Attestations, entry)
+				continue
+			}
+
+			verified++
+			findings = append(findings, check.Finding{
+				Message:  fmt.Sprintf("%s provenance signed by %s: %s", a.PredicateType, a.Signer, a.Provenance),
+				Object:   a.Source,
+				Severity: check.SeverityInfo,
+			})
+			entry.Violations = p.rules.Evaluate(*a.Provenance)
+			for _, v := range entry.Violations {
+				findings = append(findings, check.Finding{
+					Message:  v,
+					Object:   a.Source,
+					Severity: check.SeverityError,
+				})
+			}
+			if len(entry.Violations) == 0 {
+				permitted++
+			}
+			report.Attestations = append(report.Attestations, entry)
+		}
+		if permitted > 0 {
+			break
+		}
+	}
+	switch {
+	case len(report.Attestations) == 0:
+		findings = append(findings, check.Finding{
+			Message:  fmt.Sprintf("no SLSA provenance attestations were found for %s", strings.Join(report.Digests, " or ")),
+			Severity: check.SeverityError,
+		})
+	case verified == 0:
+		findings = append(findings, check.Finding{
+			Message:  fmt.Sprintf("none of the %d provenance attestations found was signed by a trusted key", len(report.Attestations)),
+			Severity: check.SeverityError,
+		})
+	}
+
+	if artifactWriter := artifacts.WriterFromContext(ctx); artifactWriter != nil {
+		reportJSON, err := json.MarshalIndent(report, "", "    ")
+		if err != nil {
+			//coverage:ignore
+			return false, nil, fmt.Errorf("could not marshal the provenance report: %w", err)
+		}
+		if _, err := artifactWriter.WriteFile(check.DefaultProvenanceFilename, bytes.NewReader(reportJSON)); err != nil {
+			//coverage:ignore
+			return false, nil, fmt.Errorf("could not write the provenance report: %w", err)
+		}
+	}
+
+	logger.V(log.DBG).Info("provenance verified", "attestationCount", len(report.Attestations), "verifiedCount", verified, "permittedCount", permitted)
+	return permitted > 0, findings, nil
+}
+
+func (p *HasTrustedProvenanceCheck) Name() string {
+	return "HasTrustedProvenance"
+}
+
+func (p *HasTrustedProvenanceCheck) Metadata() check.Metadata {
+	return check.Metadata{
+		ID:               "PFLT-CNT-016",
+		PolicyVersion:    "1.0",
+		Categories:       []check.Category{check.CategorySecurity},
+		Description:      "Checking that the image has SLSA provenance signed by a trusted key, from an allowed builder and source.",
+		Level:            "best",
+		KnowledgeBaseURL: slsaProvenanceURL,
+		CheckURL:         slsaProvenanceURL,
+	}
+}
+
+func (p *HasTrustedProvenanceCheck) Help() check.HelpText {
+	return check.HelpText{
+		Message:    "Check HasTrustedProvenance encountered an error. Please review the preflight.log file for more information.",
+		Suggestion: "Build the image with a builder that attaches signed SLSA provenance, such as Tekton Chains in Konflux, from an allowed source repository, and hermetically if required. Every attestation found is listed in provenance.json.",
+	}
+}
+
+func (p *HasTrustedProvenanceCheck) RequiredFilePatterns() []string {
+	return nil
+}
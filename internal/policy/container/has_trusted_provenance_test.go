@@ -0,0 +1,242 @@
+package container
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/provenance"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/runtime"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/signature"
+)
+
+// cosignAttest pushes a SLSA v0.2 provenance attestation of the image with
+// digest in repo, signed with priv, as cosign attest does.
+func cosignAttest(repo name.Repository, digest v1.Hash, priv *ecdsa.PrivateKey, builderID string, hermetic string) {
+	statement, err := json.Marshal(map[string]any{
+		"_type":         "https://in-toto.io/Statement/v0.1",
+		"subject":       []any{map[string]any{"name": repo.Name(), "digest": map[string]string{digest.Algorithm: digest.Hex}}},
+		"predicateType": provenance.PredicateSLSAv02,
+		"predicate": map[string]any{
+			"builder":    map[string]any{"id": builderID},
+			"invocation": map[string]any{"parameters": map[string]any{"hermetic": hermetic}},
+			"materials":  []any{map[string]any{"uri": "git+https://github.com/org/app.git", "digest": map[string]string{"sha1": "9f1c1d0"}}},
+		},
+	})
+	Expect(err).ToNot(HaveOccurred())
+	payloadType := "application/vnd.in-toto+json"
+	pae := fmt.Sprintf("DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(statement), statement)
+	hash := sha256.Sum256([]byte(pae))
+	sig, err := ecdsa.SignASN1(rand.Reader, priv, hash[:])
+	Expect(err).ToNot(HaveOccurred())
+	envelope, err := json.Marshal(map[string]any{
+		"payloadType": payloadType,
+		"payload":     base64.StdEncoding.EncodeToString(statement),
+		"signatures":  []any{map[string]string{"sig": base64.StdEncoding.EncodeToString(sig)}},
+	})
+	Expect(err).ToNot(HaveOccurred())
+
+	img, err := mutate.AppendLayers(empty.Image, static.NewLayer(envelope, provenance.DSSEMediaType))
+	Expect(err).ToNot(HaveOccurred())
+	tag := repo.Tag(strings.Replace(digest.String(), ":", "-", 1) + provenance.AttestationTagSuffix)
+	Expect(remote.Write(tag, img)).To(Succeed())
+}
+
+var _ = Describe("HasTrustedProvenance", func() {
+	const konfluxBuilder = "https://konflux-ci.dev/pipelines"
+	var (
+		hasTrustedProvenance *HasTrustedProvenanceCheck
+		keys                 signature.Keyring
+		aw                   *artifacts.MapWriter
+		ctx                  context.Context
+		repo                 name.Repository
+		imgRef               image.ImageReference
+		digest               v1.Hash
+		priv                 *ecdsa.PrivateKey
+	)
+
+	BeforeEach(func() {
+		s := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+		DeferCleanup(s.Close)
+		u, err := url.Parse(s.URL)
+		Expect(err).ToNot(HaveOccurred())
+
+		repo, err = name.NewRepository(u.Host + "/org/app")
+		Expect(err).ToNot(HaveOccurred())
+		img, err := random.Image(1024, 1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(remote.Write(repo.Tag("1.0"), img)).To(Succeed())
+		digest, err = img.Digest()
+		Expect(err).ToNot(HaveOccurred())
+		imgRef = image.ImageReference{
+			ImageInfo:       img,
+			ImageRegistry:   u.Host,
+			ImageRepository: "org/app",
+			ImageTagOrSha:   "1.0",
+		}
+
+		priv, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).ToNot(HaveOccurred())
+		der, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+		Expect(err).ToNot(HaveOccurred())
+		keys, err = signature.ParseKeyring(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+		Expect(err).ToNot(HaveOccurred())
+		rules := provenance.Rules{
+			AllowedBuilders:           []string{"https://konflux-ci.dev/*"},
+			AllowedSourceRepositories: []string{"https://github.com/org/*"},
+			RequireHermetic:           true,
+		}
+		hasTrustedProvenance = NewHasTrustedProvenanceCheck(keys, rules, &runtime.Config{})
+
+		aw, err = artifacts.NewMapWriter()
+		Expect(err).ToNot(HaveOccurred())
+		ctx = artifacts.ContextWithWriter(context.Background(), aw)
+	})
+
+	Context("When the image has permitted provenance signed by a trusted key", func() {
+		BeforeEach(func() {
+			cosignAttest(repo, digest, priv, konfluxBuilder, "true")
+		})
+		It("should pass Validate, and report the provenance summary", func() {
+			ok, findings, err := hasTrustedProvenance.ValidateWithFindings(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(findings).To(Equal([]check.Finding{{
+				Message:  fmt.Sprintf("%s provenance signed by %s: built by %s from https://github.com/org/app@9f1c1d0, hermetic: true, 1 materials", provenance.PredicateSLSAv02, keys[0].Fingerprint, konfluxBuilder),
+				Object:   repo.Tag("sha256-" + digest.Hex + ".att").String(),
+				Severity: check.SeverityInfo,
+			}}))
+		})
+		It("should write every attestation to the artifact", func() {
+			_, _, err := hasTrustedProvenance.ValidateWithFindings(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(aw.Files()).To(HaveKey(check.DefaultProvenanceFilename))
+
+			var report provenanceReport
+			Expect(json.NewDecoder(aw.Files()[check.DefaultProvenanceFilename]).Decode(&report)).To(Succeed())
+			Expect(report.Digests).To(Equal([]string{digest.String()}))
+			Expect(report.Attestations).To(HaveLen(1))
+			Expect(report.Attestations[0].Provenance.BuilderID).To(Equal(konfluxBuilder))
+			Expect(report.Attestations[0].Violations).To(BeEmpty())
+		})
+	})
+
+	Context("When the provenance violates the rules", func() {
+		BeforeEach(func() {
+			cosignAttest(repo, digest, priv, "https://example.com/builder", "false")
+		})
+		It("should not pass Validate, and report each violation", func() {
+			ok, findings, err := hasTrustedProvenance.ValidateWithFindings(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+			Expect(findings).To(HaveLen(3))
+			Expect(findings[0].Severity).To(Equal(check.SeverityInfo))
+			Expect(findings[1:]).To(Equal([]check.Finding{
+				{Message: `builder "https://example.com/builder" is not allowed`, Object: findings[0].Object, Severity: check.SeverityError},
+				{Message: "the build was not hermetic", Object: findings[0].Object, Severity: check.SeverityError},
+			}))
+		})
+	})
+
+	Context("When the provenance is signed by an untrusted key", func() {
+		BeforeEach(func() {
+			other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			Expect(err).ToNot(HaveOccurred())
+			cosignAttest(repo, digest, other, konfluxBuilder, "true")
+		})
+		It("should not pass Validate", func() {
+			ok, findings, err := hasTrustedProvenance.ValidateWithFindings(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+			Expect(findings).To(HaveLen(2))
+			Expect(findings[0].Severity).To(Equal(check.SeverityWarning))
+			Expect(findings[0].Message).To(Equal("attestation not verified: the envelope was not signed by a trusted key"))
+			Expect(findings[1]).To(Equal(check.Finding{Message: "none of the 1 provenance attestations found was signed by a trusted key", Severity: check.SeverityError}))
+		})
+	})
+
+	Context("When the image has no provenance", func() {
+		It("should not pass Validate", func() {
+			ok, err := hasTrustedProvenance.Validate(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+		})
+		It("should report the digests that were looked up", func() {
+			listDigest := v1.Hash{Algorithm: "sha256", Hex: strings.Repeat("0", 64)}
+			imgRef.ManifestListDigest = listDigest.String()
+			_, findings, err := hasTrustedProvenance.ValidateWithFindings(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(findings).To(Equal([]check.Finding{{
+				Message:  fmt.Sprintf("no SLSA provenance attestations were found for %s or %s", digest, listDigest),
+				Severity: check.SeverityError,
+			}}))
+		})
+	})
+
+	Context("When the manifest list of the image has provenance", func() {
+		It("should pass Validate", func() {
+			listDigest := v1.Hash{Algorithm: "sha256", Hex: strings.Repeat("0", 64)}
+			imgRef.ManifestListDigest = listDigest.String()
+			cosignAttest(repo, listDigest, priv, konfluxBuilder, "true")
+			ok, err := hasTrustedProvenance.Validate(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+		})
+	})
+
+	Context("When the image was loaded from a local path", func() {
+		It("should skip the check", func() {
+			imgRef.Local = true
+			_, err := hasTrustedProvenance.Validate(ctx, imgRef)
+			Expect(err).To(MatchError(check.ErrCheckSkipped))
+		})
+	})
+
+	Context("When the attestations can not be read", func() {
+		It("should return an error", func() {
+			forbidden := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusForbidden)
+			}))
+			DeferCleanup(forbidden.Close)
+			u, err := url.Parse(forbidden.URL)
+			Expect(err).ToNot(HaveOccurred())
+			imgRef.ImageRegistry = u.Host
+			_, err = hasTrustedProvenance.Validate(ctx, imgRef)
+			Expect(err).To(MatchError(ContainSubstring("could not verify the provenance of " + digest.String())))
+		})
+	})
+
+	It("should describe the check", func() {
+		Expect(hasTrustedProvenance.Name()).To(Equal("HasTrustedProvenance"))
+		Expect(hasTrustedProvenance.Metadata().ID).To(Equal("PFLT-CNT-016"))
+		Expect(hasTrustedProvenance.Help().Suggestion).To(ContainSubstring("provenance.json"))
+		Expect(hasTrustedProvenance.RequiredFilePatterns()).To(BeNil())
+	})
+})
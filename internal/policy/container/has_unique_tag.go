@@ -39,6 +39,11 @@ func (p *hasUniqueTagCheck) Validate(ctx context.Context, imgRef image.ImageRefe
 	var err error
 	// if sha or latest tag is passed in `/tags/list` must be exposed and available to validate that the image is being tagged properly
 	if strings.HasPrefix(imgRef.ImageTagOrSha, "sha256:") || imgRef.ImageTagOrSha == "latest" {
+		// listing tags requires a registry, which is not available for local images
+		if imgRef.Local {
+			return false, fmt.Errorf("%w: listing tags for %s requires a registry, but the image was loaded from a local path", check.ErrCheckSkipped, imgRepo)
+		}
+
 		tags, err = p.getDataToValidate(ctx, imgRepo)
 		if err != nil {
 			return false, fmt.Errorf("failed to get tags list for %s: %v", imgRepo, err)
@@ -124,6 +129,9 @@ func (p *hasUniqueTagCheck) Name() string {
 
 func (p *hasUniqueTagCheck) Metadata() check.Metadata {
 	return check.Metadata{
+		ID:               "PFLT-CNT-002",
+		PolicyVersion:    "1.0",
+		Categories:       []check.Category{check.CategoryMetadata},
 		Description:      "Checking if container has a tag other than 'latest', so that the image can be uniquely identified.",
 		Level:            "best",
 		KnowledgeBaseURL: certDocumentationURL,
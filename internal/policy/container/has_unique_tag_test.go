@@ -17,6 +17,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
 )
 
@@ -121,6 +122,14 @@ var _ = Describe("UniqueTag", func() {
 			})
 		})
 
+		Context("When the image was loaded from a local path", func() {
+			It("should skip when the registry must be queried", func() {
+				ok, err := hasUniqueTagCheck.Validate(context.TODO(), image.ImageReference{ImageRepository: "local", ImageTagOrSha: "latest", Local: true})
+				Expect(err).To(MatchError(check.ErrCheckSkipped))
+				Expect(ok).To(BeFalse())
+			})
+		})
+
 		Context("When the image name is invalid", func() {
 			It("should return a parse error", func() {
 				ok, err := hasUniqueTagCheck.Validate(context.TODO(), image.ImageReference{ImageRegistry: "INVALID@@@", ImageRepository: "!!!bad", ImageTagOrSha: "sha256:12345"})
@@ -0,0 +1,160 @@
+package container
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/go-logr/logr"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/option"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/signature"
+)
+
+const cosignVerifyURL = "https://docs.sigstore.dev/cosign/verifying/verify/"
+
+var (
+	_ check.Check            = &HasVerifiedSignatureCheck{}
+	_ check.FindingsReporter = &HasVerifiedSignatureCheck{}
+)
+
+// HasVerifiedSignatureCheck evaluates that the image has a cosign signature
+// made by one of a set of trusted keys. Signatures are read from the registry
+// with the same options and credentials the image was pulled with. No
+// transparency log is consulted.
+type HasVerifiedSignatureCheck struct {
+	keys        signature.Keyring
+	craneConfig option.CraneConfig
+}
+
+// signatureReport is written to the signatures.json artifact.
+type signatureReport struct {
+	// Digests are the digests signatures were looked up for: the image, and
+	// the manifest list it was selected from, if any.
+	Digests    []string              `json:"digests"`
+	Signatures []signature.Signature `json:"signatures"`
+}
+
+// NewHasVerifiedSignatureCheck returns a check that verifies the signatures of
+// the image against keys. craneConfig configures access to the registry.
+func NewHasVerifiedSignatureCheck(keys signature.Keyring, craneConfig option.CraneConfig) *HasVerifiedSignatureCheck {
+	return &HasVerifiedSignatureCheck{keys: keys, craneConfig: craneConfig}
+}
+
+func (p *HasVerifiedSignatureCheck) Validate(ctx context.Context, imgRef image.ImageReference) (bool, error) {
+	passed, _, err := p.ValidateWithFindings(ctx, imgRef)
+	return passed, err
+}
+
+// ValidateWithFindings reports each signature found, with its signer and
+// annotations if it is verified, or the reason it is not. Images that were
+// not pulled from a registry skip the check.
+func (p *HasVerifiedSignatureCheck) ValidateWithFindings(ctx context.Context, imgRef image.ImageReference) (bool, []check.Finding, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+	repo, digests, options, err := attachmentSubjects(ctx, imgRef, p.craneConfig)
+	if err != nil {
+		return false, nil, err
+	}
+
+	report := signatureReport{Signatures: []signature.Signature{}}
+	for _, d := range digests {
+		signatures, err := signature.Verify(ctx, repo, d, p.keys, options...)
+		if err != nil {
+			return false, nil, fmt.Errorf("could not verify the signatures of %s: %w", d, err)
+		}
+		report.Digests = append(report.Digests, d.String())
+		report.Signatures = append(report.Signatures, signatures...)
+		if slices.ContainsFunc(signatures, func(s signature.Signature) bool { return s.Verified }) {
+			break
+		}
+	}
+
+	var findings []check.Finding
+	verified := 0
+	for _, s := range report.Signatures {
+		if !s.Verified {
+			findings = append(findings, check.Finding{
+				Message:  "signature not verified: " + s.Reason,
+				Object:   s.Source,
+				Severity: check.SeverityWarning,
+			})
+			continue
+		}
+		verified++
+		message := fmt.Sprintf("signed by %s for %s", s.Signer, s.DockerReference)
+		if len(s.Annotations) > 0 {
+			message += " with annotations " + formatAnnotations(s.Annotations)
+		}
+		findings = append(findings, check.Finding{
+			Message:  message,
+			Object:   s.Source,
+			Severity: check.SeverityInfo,
+		})
+	}
+	if verified == 0 {
+		message := fmt.Sprintf("no cosign signatures were found for %s", strings.Join(report.Digests, " or "))
+		if len(report.Signatures) > 0 {
+			message = fmt.Sprintf("none of the %d signatures found was made by a trusted key", len(report.Signatures))
+		}
+		findings = append(findings, check.Finding{Message: message, Severity: check.SeverityError})
+	}
+
+	if artifactWriter := artifacts.WriterFromContext(ctx); artifactWriter != nil {
+		reportJSON, err := json.MarshalIndent(report, "", "    ")
+		if err != nil {
+			//coverage:ignore
+			return false, nil, fmt.Errorf("could not marshal the signature report: %w", err)
+		}
+		if _, err := artifactWriter.WriteFile(check.DefaultSignaturesFilename, bytes.NewReader(reportJSON)); err != nil {
+			//coverage:ignore
+			return false, nil, fmt.Errorf("could not write the signature report: %w", err)
+		}
+	}
+
+	logger.V(log.DBG).Info("signatures verified", "signatureCount", len(report.Signatures), "verifiedCount", verified)
+	return verified > 0, findings, nil
+}
+
+// formatAnnotations returns annotations as key=value pairs, sorted by key.
+func formatAnnotations(annotations map[string]any) string {
+	pairs := make([]string, 0, len(annotations))
+	for _, k := range slices.Sorted(maps.Keys(annotations)) {
+		pairs = append(pairs, fmt.Sprintf("%s=%v", k, annotations[k]))
+	}
+	return strings.Join(pairs, ", ")
+}
+
+func (p *HasVerifiedSignatureCheck) Name() string {
+	return "HasVerifiedSignature"
+}
+
+func (p *HasVerifiedSignatureCheck) Metadata() check.Metadata {
+	return check.Metadata{
+		ID:               "PFLT-CNT-015",
+		PolicyVersion:    "1.0",
+		Categories:       []check.Category{check.CategorySecurity},
+		Description:      "Checking that the image has a cosign signature made by a trusted key.",
+		Level:            "best",
+		KnowledgeBaseURL: cosignVerifyURL,
+		CheckURL:         cosignVerifyURL,
+	}
+}
+
+func (p *HasVerifiedSignatureCheck) Help() check.HelpText {
+	return check.HelpText{
+		Message:    "Check HasVerifiedSignature encountered an error. Please review the preflight.log file for more information.",
+		Suggestion: "Sign the image with cosign sign --key, using the private key of one of the trusted public keys, and push the signature to the registry the image is in. Every signature found is listed in signatures.json.",
+	}
+}
+
+func (p *HasVerifiedSignatureCheck) RequiredFilePatterns() []string {
+	return nil
+}
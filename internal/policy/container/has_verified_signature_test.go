@@ -0,0 +1,232 @@
+package container
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/runtime"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/signature"
+)
+
+// cosignSign pushes a cosign signature of the image with digest in repo,
+// made with priv.
+func cosignSign(repo name.Repository, digest v1.Hash, priv *ecdsa.PrivateKey, optional map[string]any) {
+	payload, err := json.Marshal(map[string]any{
+		"critical": map[string]any{
+			"identity": map[string]any{"docker-reference": repo.Name()},
+			"image":    map[string]any{"docker-manifest-digest": digest.String()},
+			"type":     "cosign container image signature",
+		},
+		"optional": optional,
+	})
+	Expect(err).ToNot(HaveOccurred())
+	hash := sha256.Sum256(payload)
+	sig, err := ecdsa.SignASN1(rand.Reader, priv, hash[:])
+	Expect(err).ToNot(HaveOccurred())
+
+	img, err := mutate.Append(empty.Image, mutate.Addendum{
+		Layer:       static.NewLayer(payload, signature.SimpleSigningMediaType),
+		Annotations: map[string]string{signature.SignatureAnnotation: base64.StdEncoding.EncodeToString(sig)},
+	})
+	Expect(err).ToNot(HaveOccurred())
+	tag := repo.Tag(strings.Replace(digest.String(), ":", "-", 1) + signature.SignatureTagSuffix)
+	Expect(remote.Write(tag, img)).To(Succeed())
+}
+
+// digestErrorImage is an image whose digest can not be computed.
+type digestErrorImage struct {
+	v1.Image
+}
+
+func (digestErrorImage) Digest() (v1.Hash, error) {
+	return v1.Hash{}, errors.New("digest error")
+}
+
+var _ = Describe("HasVerifiedSignature", func() {
+	var (
+		hasVerifiedSignature *HasVerifiedSignatureCheck
+		aw                   *artifacts.MapWriter
+		ctx                  context.Context
+		repo                 name.Repository
+		imgRef               image.ImageReference
+		digest               v1.Hash
+		priv                 *ecdsa.PrivateKey
+	)
+
+	BeforeEach(func() {
+		s := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+		DeferCleanup(s.Close)
+		u, err := url.Parse(s.URL)
+		Expect(err).ToNot(HaveOccurred())
+
+		repo, err = name.NewRepository(u.Host + "/org/app")
+		Expect(err).ToNot(HaveOccurred())
+		img, err := random.Image(1024, 1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(remote.Write(repo.Tag("1.0"), img)).To(Succeed())
+		digest, err = img.Digest()
+		Expect(err).ToNot(HaveOccurred())
+		imgRef = image.ImageReference{
+			ImageInfo:       img,
+			ImageRegistry:   u.Host,
+			ImageRepository: "org/app",
+			ImageTagOrSha:   "1.0",
+		}
+
+		priv, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).ToNot(HaveOccurred())
+		der, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+		Expect(err).ToNot(HaveOccurred())
+		keys, err := signature.ParseKeyring(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+		Expect(err).ToNot(HaveOccurred())
+		hasVerifiedSignature = NewHasVerifiedSignatureCheck(keys, &runtime.Config{})
+
+		aw, err = artifacts.NewMapWriter()
+		Expect(err).ToNot(HaveOccurred())
+		ctx = artifacts.ContextWithWriter(context.Background(), aw)
+	})
+
+	Context("When the image is signed by a trusted key", func() {
+		BeforeEach(func() {
+			cosignSign(repo, digest, priv, map[string]any{"release": "1.0", "build": 42})
+		})
+		It("should pass Validate, and report the signer and annotations", func() {
+			ok, findings, err := hasVerifiedSignature.ValidateWithFindings(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(findings).To(HaveLen(1))
+			Expect(findings[0].Severity).To(Equal(check.SeverityInfo))
+			Expect(findings[0].Object).To(HaveSuffix(":sha256-" + digest.Hex + ".sig"))
+			Expect(findings[0].Message).To(MatchRegexp(`^signed by sha256:[0-9a-f]{64} for ` + repo.Name() + ` with annotations build=42, release=1.0$`))
+		})
+		It("should write every signature to the artifact", func() {
+			_, _, err := hasVerifiedSignature.ValidateWithFindings(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(aw.Files()).To(HaveKey(check.DefaultSignaturesFilename))
+
+			var report signatureReport
+			Expect(json.NewDecoder(aw.Files()[check.DefaultSignaturesFilename]).Decode(&report)).To(Succeed())
+			Expect(report.Digests).To(Equal([]string{digest.String()}))
+			Expect(report.Signatures).To(HaveLen(1))
+			Expect(report.Signatures[0].Verified).To(BeTrue())
+		})
+	})
+
+	Context("When the image is signed by an untrusted key", func() {
+		BeforeEach(func() {
+			other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			Expect(err).ToNot(HaveOccurred())
+			cosignSign(repo, digest, other, nil)
+		})
+		It("should not pass Validate", func() {
+			ok, findings, err := hasVerifiedSignature.ValidateWithFindings(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+			Expect(findings).To(HaveLen(2))
+			Expect(findings[0].Severity).To(Equal(check.SeverityWarning))
+			Expect(findings[0].Message).To(Equal("signature not verified: the signature was not made by a trusted key"))
+			Expect(findings[1]).To(Equal(check.Finding{Message: "none of the 1 signatures found was made by a trusted key", Severity: check.SeverityError}))
+		})
+	})
+
+	Context("When the image is not signed", func() {
+		It("should not pass Validate", func() {
+			ok, err := hasVerifiedSignature.Validate(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+		})
+		It("should report the digests that were looked up", func() {
+			listDigest := v1.Hash{Algorithm: "sha256", Hex: strings.Repeat("0", 64)}
+			imgRef.ManifestListDigest = listDigest.String()
+			_, findings, err := hasVerifiedSignature.ValidateWithFindings(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(findings).To(Equal([]check.Finding{{
+				Message:  fmt.Sprintf("no cosign signatures were found for %s or %s", digest, listDigest),
+				Severity: check.SeverityError,
+			}}))
+		})
+	})
+
+	Context("When the manifest list of the image is signed", func() {
+		It("should pass Validate", func() {
+			listDigest := v1.Hash{Algorithm: "sha256", Hex: strings.Repeat("0", 64)}
+			imgRef.ManifestListDigest = listDigest.String()
+			cosignSign(repo, listDigest, priv, nil)
+			ok, findings, err := hasVerifiedSignature.ValidateWithFindings(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(findings[0].Message).To(HaveSuffix(" for " + repo.Name()))
+		})
+	})
+
+	Context("When the image was loaded from a local path", func() {
+		It("should skip the check", func() {
+			imgRef.Local = true
+			_, err := hasVerifiedSignature.Validate(ctx, imgRef)
+			Expect(err).To(MatchError(check.ErrCheckSkipped))
+		})
+	})
+
+	Context("When the signatures can not be read", func() {
+		It("should return an error", func() {
+			forbidden := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusForbidden)
+			}))
+			DeferCleanup(forbidden.Close)
+			u, err := url.Parse(forbidden.URL)
+			Expect(err).ToNot(HaveOccurred())
+			imgRef.ImageRegistry = u.Host
+			_, err = hasVerifiedSignature.Validate(ctx, imgRef)
+			Expect(err).To(MatchError(ContainSubstring("could not verify the signatures of " + digest.String())))
+		})
+		It("should return an error on an invalid repository", func() {
+			imgRef.ImageRepository = "Invalid"
+			_, err := hasVerifiedSignature.Validate(ctx, imgRef)
+			Expect(err).To(MatchError(ContainSubstring("failed to parse repository name")))
+		})
+	})
+
+	Context("When the image digest can not be computed", func() {
+		It("should return an error", func() {
+			imgRef.ImageInfo = digestErrorImage{imgRef.ImageInfo}
+			_, err := hasVerifiedSignature.Validate(ctx, imgRef)
+			Expect(err).To(MatchError(ContainSubstring("could not get the image digest")))
+		})
+	})
+
+	It("should describe the check", func() {
+		Expect(hasVerifiedSignature.Name()).To(Equal("HasVerifiedSignature"))
+		Expect(hasVerifiedSignature.Metadata().ID).To(Equal("PFLT-CNT-015"))
+		Expect(hasVerifiedSignature.Help().Suggestion).To(ContainSubstring("signatures.json"))
+		Expect(hasVerifiedSignature.RequiredFilePatterns()).To(BeNil())
+	})
+})
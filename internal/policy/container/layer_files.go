@@ -0,0 +1,142 @@
+package container
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// maxSymlinkHops is the number of symbolic links followed when resolving a
+// path, as in Linux.
+const maxSymlinkHops = 40
+
+// layerFiles are the files in an image, by path without a leading slash,
+// after its layers are applied in order.
+type layerFiles map[string]layerFile
+
+// layerFile is the tar header of a file, and the index of the layer that
+// added it.
+type layerFile struct {
+	header *tar.Header
+	layer  int
+}
+
+// readLayerFiles applies the tar headers of each of layers, in order. Files
+// are not read, so only their metadata, such as mode and ownership, is known.
+func readLayerFiles(layers []v1.Layer) (layerFiles, error) {
+	files := layerFiles{}
+	for idx, layer := range layers {
+		if err := files.apply(idx, layer); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// apply applies the tar headers of layer, the layer at index idx, to f.
+func (f layerFiles) apply(idx int, layer v1.Layer) error {
+	headers, err := readLayerHeaders(layer)
+	if err != nil {
+		return fmt.Errorf("reading layer %d: %w", idx, err)
+	}
+
+	// Whiteouts only remove files from the layers below, so they are
+	// applied before the files of the layer are added.
+	var added []*tar.Header
+	for _, header := range headers {
+		dirname, basename := path.Split(header.Name)
+		dirname = strings.TrimSuffix(dirname, "/")
+		switch {
+		case basename == opaqueWhiteout:
+			f.remove(dirname, false)
+		case strings.HasPrefix(basename, whiteoutPrefix):
+			f.remove(path.Join(dirname, strings.TrimPrefix(basename, whiteoutPrefix)), true)
+		default:
+			added = append(added, header)
+		}
+	}
+	for _, header := range added {
+		f[header.Name] = layerFile{header: header, layer: idx}
+	}
+	return nil
+}
+
+// readLayerHeaders returns the tar headers of the entries of layer. Names are
+// cleaned, and have no leading slash. The root directory is omitted.
+func readLayerHeaders(layer v1.Layer) ([]*tar.Header, error) {
+	layerReader, err := layer.Uncompressed()
+	if err != nil {
+		return nil, fmt.Errorf("reading layer contents: %w", err)
+	}
+	defer layerReader.Close()
+
+	var headers []*tar.Header
+	tarReader := tar.NewReader(layerReader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading tar: %w", err)
+		}
+		header.Name = strings.TrimPrefix(path.Clean("/"+header.Name), "/")
+		if header.Name == "" {
+			continue
+		}
+		headers = append(headers, header)
+	}
+	return headers, nil
+}
+
+// remove removes the descendants of dir, and dir itself if self is set.
+func (f layerFiles) remove(dir string, self bool) {
+	if self {
+		delete(f, dir)
+	}
+	for name := range f {
+		if dir == "" || strings.HasPrefix(name, dir+"/") {
+			delete(f, name)
+		}
+	}
+}
+
+// resolve returns the path p refers to once symbolic links are followed, and
+// its header. The header is nil if the file does not exist, e.g. because it
+// is created at runtime.
+func (f layerFiles) resolve(p string) (string, *tar.Header) {
+	parts := splitPath(p)
+	resolved := ""
+	hops := 0
+	for i := 0; i < len(parts); i++ {
+		candidate := path.Join(resolved, parts[i])
+		file, ok := f[candidate]
+		if ok && file.header.Typeflag == tar.TypeSymlink && hops < maxSymlinkHops {
+			hops++
+			target := file.header.Linkname
+			if !path.IsAbs(target) {
+				target = path.Join("/", resolved, target)
+			}
+			parts = append(splitPath(target), parts[i+1:]...)
+			resolved = ""
+			i = -1
+			continue
+		}
+		resolved = candidate
+	}
+	return "/" + resolved, f[resolved].header
+}
+
+// splitPath returns the components of the cleaned path p.
+func splitPath(p string) []string {
+	p = strings.TrimPrefix(path.Clean("/"+p), "/")
+	if p == "" {
+		return nil
+	}
+	return strings.Split(p, "/")
+}
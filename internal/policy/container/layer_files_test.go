@@ -0,0 +1,94 @@
+package container
+
+import (
+	"archive/tar"
+	"bytes"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// headerLayer returns a layer of empty entries with the given headers.
+func headerLayer(headers ...*tar.Header) v1.Layer {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, h := range headers {
+		Expect(tw.WriteHeader(h)).To(Succeed())
+	}
+	Expect(tw.Close()).To(Succeed())
+	return static.NewLayer(buf.Bytes(), types.DockerUncompressedLayer)
+}
+
+func dirHeader(name string, mode int64, uid, gid int) *tar.Header {
+	return &tar.Header{Name: name, Typeflag: tar.TypeDir, Mode: mode, Uid: uid, Gid: gid}
+}
+
+func symlinkHeader(name, target string) *tar.Header {
+	return &tar.Header{Name: name, Typeflag: tar.TypeSymlink, Linkname: target, Mode: 0o777}
+}
+
+var _ = Describe("Layer files", func() {
+	var files layerFiles
+
+	BeforeEach(func() {
+		var err error
+		files, err = readLayerFiles([]v1.Layer{
+			headerLayer(
+				dirHeader("./", 0o755, 0, 0),
+				dirHeader("./opt/", 0o755, 0, 0),
+				dirHeader("./opt/app/", 0o755, 0, 0),
+				dirHeader("./opt/app/data/", 0o755, 0, 0),
+				dirHeader("./var/", 0o755, 0, 0),
+				dirHeader("./var/cache/", 0o755, 0, 0),
+				dirHeader("./var/cache/app/", 0o755, 0, 0),
+				dirHeader("./srv/", 0o755, 0, 0),
+				dirHeader("./srv/old/", 0o755, 0, 0),
+			),
+			headerLayer(
+				dirHeader("opt/app/data/", 0o775, 1001, 0),
+				&tar.Header{Name: "var/cache/.wh..wh..opq", Typeflag: tar.TypeReg},
+				&tar.Header{Name: ".wh.srv", Typeflag: tar.TypeReg},
+				symlinkHeader("data", "opt/app/data"),
+				symlinkHeader("opt/app/current", "../app"),
+				symlinkHeader("loop", "loop"),
+			),
+		})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should apply the headers of later layers over earlier ones", func() {
+		Expect(files["opt/app/data"].header.Mode).To(Equal(int64(0o775)))
+		Expect(files["opt/app/data"].header.Uid).To(Equal(1001))
+		Expect(files["opt/app/data"].layer).To(Equal(1))
+		Expect(files).ToNot(HaveKey(""))
+	})
+
+	It("should remove whited-out files", func() {
+		Expect(files).To(HaveKey("var/cache"))
+		Expect(files).ToNot(HaveKey("var/cache/app"))
+		Expect(files).ToNot(HaveKey("srv"))
+		Expect(files).ToNot(HaveKey("srv/old"))
+	})
+
+	DescribeTable("should resolve paths through symbolic links",
+		func(p string, expected string, exists bool) {
+			resolved, header := files.resolve(p)
+			Expect(resolved).To(Equal(expected))
+			Expect(header != nil).To(Equal(exists))
+		},
+		Entry("a path without links", "/opt/app", "/opt/app", true),
+		Entry("an absolute link", "/data", "/opt/app/data", true),
+		Entry("a relative link in a parent", "/opt/app/current/data/", "/opt/app/data", true),
+		Entry("a path that does not exist", "/opt/app/logs", "/opt/app/logs", false),
+		Entry("a link loop", "/loop", "/loop", true),
+		Entry("the root directory", "/", "/", false),
+	)
+
+	It("should remove the contents of the root directory made opaque", func() {
+		files.remove("", false)
+		Expect(files).To(BeEmpty())
+	})
+})
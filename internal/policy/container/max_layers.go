@@ -45,6 +45,9 @@ func (p *MaxLayersCheck) Name() string {
 
 func (p *MaxLayersCheck) Metadata() check.Metadata {
 	return check.Metadata{
+		ID:               "PFLT-CNT-003",
+		PolicyVersion:    "1.0",
+		Categories:       []check.Category{check.CategoryPackaging},
 		Description:      fmt.Sprintf("Checking if container has less than %d layers.  Too many layers within the container images can degrade container performance.", acceptableLayerMax),
 		Level:            "better",
 		KnowledgeBaseURL: certDocumentationURL,
@@ -0,0 +1,86 @@
+package container
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	passwdPath = "/etc/passwd"
+	groupPath  = "/etc/group"
+)
+
+// passwdEntry is a user defined in /etc/passwd.
+type passwdEntry struct {
+	name string
+	uid  int
+	gid  int
+	home string
+}
+
+// groupEntry is a group defined in /etc/group.
+type groupEntry struct {
+	name string
+	gid  int
+}
+
+// readPasswd returns the users defined in the /etc/passwd of the image
+// filesystem at fsPath. An image without /etc/passwd defines no users.
+func readPasswd(fsPath string) ([]passwdEntry, error) {
+	var users []passwdEntry
+	err := readColonFile(fsPath, passwdPath, 7, func(fields []string) {
+		uid, uidErr := strconv.Atoi(fields[2])
+		gid, gidErr := strconv.Atoi(fields[3])
+		if uidErr != nil || gidErr != nil {
+			return
+		}
+		users = append(users, passwdEntry{name: fields[0], uid: uid, gid: gid, home: fields[5]})
+	})
+	return users, err
+}
+
+// readGroup returns the groups defined in the /etc/group of the image
+// filesystem at fsPath. An image without /etc/group defines no groups.
+func readGroup(fsPath string) ([]groupEntry, error) {
+	var groups []groupEntry
+	err := readColonFile(fsPath, groupPath, 4, func(fields []string) {
+		gid, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return
+		}
+		groups = append(groups, groupEntry{name: fields[0], gid: gid})
+	})
+	return groups, err
+}
+
+// readColonFile calls fn with the fields of each line of the file at path in
+// the image filesystem at fsPath that has at least minFields colon-separated
+// fields. Comments, blank lines and malformed lines are skipped, as the C
+// library does.
+func readColonFile(fsPath string, path string, minFields int, fn func(fields []string)) error {
+	b, err := os.ReadFile(filepath.Join(fsPath, path))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not read %s: %w", path, err)
+	}
+
+	for _, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, ":")
+		if len(fields) < minFields {
+			continue
+		}
+		fn(fields)
+	}
+	return nil
+}
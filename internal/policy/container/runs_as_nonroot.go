@@ -3,6 +3,8 @@ package container
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/go-logr/logr"
 	cranev1 "github.com/google/go-containerregistry/pkg/v1"
@@ -11,19 +13,58 @@ import (
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
 )
 
-var _ check.Check = &RunAsNonRootCheck{}
+var (
+	_ check.Check            = &RunAsNonRootCheck{}
+	_ check.FindingsReporter = &RunAsNonRootCheck{}
+)
 
 // RunAsNonRootCheck evaluates the image to determine that the runtime UID is not 0,
-// which correlates to the root user.
+// which correlates to the root user. Named users and groups are resolved
+// against the /etc/passwd and /etc/group of the image.
 type RunAsNonRootCheck struct{}
 
+// runtimeUser is the USER of an image, resolved to numeric IDs.
+type runtimeUser struct {
+	// user and group are as specified by USER. group is empty if USER
+	// does not specify one.
+	user, group string
+	uid, gid    int
+	// home is the home directory of the user in /etc/passwd.
+	home string
+	// userDefined and groupDefined are true if the user and group are
+	// defined in /etc/passwd and /etc/group. A numeric group is always
+	// considered defined.
+	userDefined, groupDefined bool
+	// resolved is false if the user is a name that is not defined, and so
+	// has no UID.
+	resolved bool
+	// unset is true if the image does not specify a USER.
+	unset bool
+}
+
 func (p *RunAsNonRootCheck) Validate(ctx context.Context, imgRef image.ImageReference) (bool, error) {
+	passed, _, err := p.ValidateWithFindings(ctx, imgRef)
+	return passed, err
+}
+
+// ValidateWithFindings reports the UID and GID USER resolves to, and warns
+// when the user or group is not defined in the image.
+func (p *RunAsNonRootCheck) ValidateWithFindings(ctx context.Context, imgRef image.ImageReference) (bool, []check.Finding, error) {
 	user, err := p.getDataToValidate(imgRef.ImageInfo)
 	if err != nil {
-		return false, fmt.Errorf("could not get validation data: %w", err)
+		return false, nil, fmt.Errorf("could not get validation data: %w", err)
 	}
 
-	return p.validate(ctx, user)
+	passwd, err := readPasswd(imgRef.ImageFSPath)
+	if err != nil {
+		return false, nil, fmt.Errorf("could not get validation data: %w", err)
+	}
+	groups, err := readGroup(imgRef.ImageFSPath)
+	if err != nil {
+		return false, nil, fmt.Errorf("could not get validation data: %w", err)
+	}
+
+	return p.validate(ctx, resolveUser(user, passwd, groups))
 }
 
 func (p *RunAsNonRootCheck) getDataToValidate(image cranev1.Image) (string, error) {
@@ -34,23 +75,99 @@ func (p *RunAsNonRootCheck) getDataToValidate(image cranev1.Image) (string, erro
 	return configFile.Config.User, nil
 }
 
-func (p *RunAsNonRootCheck) validate(ctx context.Context, user string) (bool, error) {
+// resolveUser resolves spec, a USER of the form user[:group], to numeric IDs
+// as container runtimes do. An empty USER is root. A user that is not
+// defined, but numeric, runs with that UID and GID 0.
+func resolveUser(spec string, passwd []passwdEntry, groups []groupEntry) runtimeUser {
+	if spec == "" {
+		return runtimeUser{user: "root", userDefined: true, groupDefined: true, resolved: true, unset: true}
+	}
+
+	name, group, _ := strings.Cut(spec, ":")
+	u := runtimeUser{user: name, group: group}
+
+	for _, entry := range passwd {
+		if entry.name == name || strconv.Itoa(entry.uid) == name {
+			u.uid, u.gid, u.home = entry.uid, entry.gid, entry.home
+			u.userDefined, u.resolved = true, true
+			break
+		}
+	}
+	if !u.userDefined {
+		if uid, err := strconv.Atoi(name); err == nil {
+			u.uid, u.resolved = uid, true
+		} else if name == "root" {
+			// root is UID 0 whether or not the image defines it.
+			u.resolved = true
+		}
+	}
+
+	if group == "" {
+		u.groupDefined = true
+		return u
+	}
+	if gid, err := strconv.Atoi(group); err == nil {
+		u.gid, u.groupDefined = gid, true
+		return u
+	}
+	for _, entry := range groups {
+		if entry.name == group {
+			u.gid, u.groupDefined = entry.gid, true
+			break
+		}
+	}
+	return u
+}
+
+func (p *RunAsNonRootCheck) validate(ctx context.Context, user runtimeUser) (bool, []check.Finding, error) {
 	logger := logr.FromContextOrDiscard(ctx)
 
-	if user == "" {
+	var findings []check.Finding
+	if !user.userDefined {
+		findings = append(findings, check.Finding{
+			Message:  fmt.Sprintf("user %s is not defined in %s", user.user, passwdPath),
+			Object:   user.user,
+			Severity: check.SeverityWarning,
+		})
+	}
+	if !user.groupDefined {
+		findings = append(findings, check.Finding{
+			Message:  fmt.Sprintf("group %s is not defined in %s", user.group, groupPath),
+			Object:   user.group,
+			Severity: check.SeverityWarning,
+		})
+	}
+
+	if !user.resolved {
+		logger.Info(fmt.Sprintf("USER %s could not be resolved to a UID", user.user))
+		return true, findings, nil
+	}
+
+	if user.unset {
 		logger.Info("detected empty USER. Presumed to be running as root")
 		logger.Info("USER value must be provided and be a non-root value for this check to pass")
-		return false, nil
+		return false, append(findings, check.Finding{
+			Message:  "USER is not set, so the container runs as root (UID 0, GID 0)",
+			Severity: check.SeverityError,
+		}), nil
 	}
 
-	if user == "0" || user == "root" {
-		logger.Info("detected USER specified as root or UID 0")
+	if user.uid == 0 {
+		logger.Info("detected USER resolving to root or UID 0")
 		logger.Info("USER other than root is required for this check to pass")
-		return false, nil
+		return false, append(findings, check.Finding{
+			Message:  fmt.Sprintf("USER %s runs as root (UID %d, GID %d)", user.user, user.uid, user.gid),
+			Object:   user.user,
+			Severity: check.SeverityError,
+		}), nil
 	}
 
-	logger.Info(fmt.Sprintf("USER %s specified that is non-root", user))
-	return true, nil
+	logger.Info(fmt.Sprintf("USER %s specified that is non-root", user.user), "uid", user.uid, "gid", user.gid)
+	return true, append(findings, check.Finding{
+		Message:  fmt.Sprintf("USER %s runs as UID %d, GID %d", user.user, user.uid, user.gid),
+		Object:   user.user,
+		Severity: check.SeverityInfo,
+	}), nil
 }
 
 func (p *RunAsNonRootCheck) Name() string {
@@ -59,6 +176,9 @@ func (p *RunAsNonRootCheck) Name() string {
 
 func (p *RunAsNonRootCheck) Metadata() check.Metadata {
 	return check.Metadata{
+		ID:               "PFLT-CNT-007",
+		PolicyVersion:    "1.0",
+		Categories:       []check.Category{check.CategorySecurity},
 		Description:      "Checking if container runs as the root user because a container that does not specify a non-root user will fail the automatic certification, and will be subject to a manual review before the container can be approved for publication",
 		Level:            "best",
 		KnowledgeBaseURL: certDocumentationURL,
@@ -69,11 +189,11 @@ func (p *RunAsNonRootCheck) Metadata() check.Metadata {
 func (p *RunAsNonRootCheck) Help() check.HelpText {
 	return check.HelpText{
 		Message:    "Check RunAsNonRoot encountered an error. Please review the preflight.log file for more information.",
-		Suggestion: "Indicate a specific USER in the dockerfile or containerfile",
+		Suggestion: "Indicate a specific USER in the dockerfile or containerfile, whose UID is not 0. Named users must be defined in /etc/passwd, and named groups in /etc/group.",
 	}
 }
 
 func (p *RunAsNonRootCheck) RequiredFilePatterns() []string {
 	//coverage:ignore
-	return nil
+	return []string{passwdPath, groupPath}
 }
@@ -3,15 +3,33 @@ package container
 import (
 	"context"
 	"errors"
+	"os"
+	"path/filepath"
 
 	cranev1 "github.com/google/go-containerregistry/pkg/v1"
 	fakecranev1 "github.com/google/go-containerregistry/pkg/v1/fake"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
 )
 
+const (
+	testPasswd = `# users
+root:x:0:0:root:/root:/bin/bash
+toor:x:0:0:root alias:/root:/bin/bash
+appuser:x:1001:1001::/opt/app-root/src:/sbin/nologin
+malformed:x
+baduid:x:abc:0::/:/sbin/nologin
+`
+	testGroup = `root:x:0:
+appgroup:x:1002:appuser
+
+badgid:x:abc:
+`
+)
+
 func userConfigFile(user string) (*cranev1.ConfigFile, error) {
 	return &cranev1.ConfigFile{
 		Config: cranev1.Config{
@@ -47,6 +65,7 @@ var _ = Describe("RunAsNonRoot", func() {
 			ConfigFileStub: configFileWithGoodUser,
 		}
 		imageRef.ImageInfo = &fakeImage
+		imageRef.ImageFSPath = GinkgoT().TempDir()
 	})
 
 	Describe("Checking manifest user is not root", func() {
@@ -117,5 +136,88 @@ var _ = Describe("RunAsNonRoot", func() {
 		})
 	})
 
+	Describe("Resolving USER against /etc/passwd and /etc/group", func() {
+		withUser := func(user string) {
+			imageRef.ImageInfo = &fakecranev1.FakeImage{
+				ConfigFileStub: func() (*cranev1.ConfigFile, error) {
+					return userConfigFile(user)
+				},
+			}
+		}
+
+		Context("When the image defines users and groups", func() {
+			BeforeEach(func() {
+				Expect(os.MkdirAll(filepath.Join(imageRef.ImageFSPath, "etc"), 0o755)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(imageRef.ImageFSPath, "etc", "passwd"), []byte(testPasswd), 0o644)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(imageRef.ImageFSPath, "etc", "group"), []byte(testGroup), 0o644)).To(Succeed())
+			})
+
+			DescribeTable("should report the UID and GID USER resolves to",
+				func(user string, expectedPass bool, expected []check.Finding) {
+					withUser(user)
+					ok, findings, err := runAsNonRoot.ValidateWithFindings(context.TODO(), imageRef)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(ok).To(Equal(expectedPass))
+					Expect(findings).To(Equal(expected))
+				},
+				Entry("a named user", "appuser", true, []check.Finding{
+					{Message: "USER appuser runs as UID 1001, GID 1001", Object: "appuser", Severity: check.SeverityInfo},
+				}),
+				Entry("a defined UID", "1001", true, []check.Finding{
+					{Message: "USER 1001 runs as UID 1001, GID 1001", Object: "1001", Severity: check.SeverityInfo},
+				}),
+				Entry("a named user and group", "appuser:appgroup", true, []check.Finding{
+					{Message: "USER appuser runs as UID 1001, GID 1002", Object: "appuser", Severity: check.SeverityInfo},
+				}),
+				Entry("a named user and GID", "appuser:0", true, []check.Finding{
+					{Message: "USER appuser runs as UID 1001, GID 0", Object: "appuser", Severity: check.SeverityInfo},
+				}),
+				Entry("a named user whose UID is 0", "toor", false, []check.Finding{
+					{Message: "USER toor runs as root (UID 0, GID 0)", Object: "toor", Severity: check.SeverityError},
+				}),
+				Entry("a UID that is not defined", "1000", true, []check.Finding{
+					{Message: "user 1000 is not defined in /etc/passwd", Object: "1000", Severity: check.SeverityWarning},
+					{Message: "USER 1000 runs as UID 1000, GID 0", Object: "1000", Severity: check.SeverityInfo},
+				}),
+				Entry("a named user that is not defined", "missing", true, []check.Finding{
+					{Message: "user missing is not defined in /etc/passwd", Object: "missing", Severity: check.SeverityWarning},
+				}),
+				Entry("a group that is not defined", "appuser:missing", true, []check.Finding{
+					{Message: "group missing is not defined in /etc/group", Object: "missing", Severity: check.SeverityWarning},
+					{Message: "USER appuser runs as UID 1001, GID 1001", Object: "appuser", Severity: check.SeverityInfo},
+				}),
+				Entry("no user", "", false, []check.Finding{
+					{Message: "USER is not set, so the container runs as root (UID 0, GID 0)", Severity: check.SeverityError},
+				}),
+			)
+		})
+
+		Context("When the image does not define users", func() {
+			It("should fail for root", func() {
+				withUser("root")
+				ok, findings, err := runAsNonRoot.ValidateWithFindings(context.TODO(), imageRef)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ok).To(BeFalse())
+				Expect(findings).To(ContainElement(check.Finding{Message: "USER root runs as root (UID 0, GID 0)", Object: "root", Severity: check.SeverityError}))
+			})
+		})
+
+		Context("When /etc/passwd can not be read", func() {
+			It("should return an error", func() {
+				Expect(os.MkdirAll(filepath.Join(imageRef.ImageFSPath, "etc", "passwd"), 0o755)).To(Succeed())
+				_, err := runAsNonRoot.Validate(context.TODO(), imageRef)
+				Expect(err).To(MatchError(ContainSubstring("could not read /etc/passwd")))
+			})
+		})
+
+		Context("When /etc/group can not be read", func() {
+			It("should return an error", func() {
+				Expect(os.MkdirAll(filepath.Join(imageRef.ImageFSPath, "etc", "group"), 0o755)).To(Succeed())
+				_, err := runAsNonRoot.Validate(context.TODO(), imageRef)
+				Expect(err).To(MatchError(ContainSubstring("could not read /etc/group")))
+			})
+		})
+	})
+
 	AssertMetaData(&runAsNonRoot)
 })
@@ -0,0 +1,203 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/go-logr/logr"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
+)
+
+// arbitraryUIDDocumentationURL describes how OpenShift assigns UIDs, and how
+// images support them.
+const arbitraryUIDDocumentationURL = "https://docs.openshift.com/container-platform/latest/openshift_images/create-images.html#use-uid_create-images"
+
+var (
+	_ check.Check            = &SupportsArbitraryUIDCheck{}
+	_ check.FindingsReporter = &SupportsArbitraryUIDCheck{}
+)
+
+// SupportsArbitraryUIDCheck evaluates that the paths the image is likely to
+// write to are owned by group 0 and group-writable. OpenShift runs containers
+// with an arbitrary UID that is a member of group 0, so these paths are not
+// writable otherwise.
+type SupportsArbitraryUIDCheck struct {
+	// paths are checked in addition to WORKDIR, VOLUMEs and HOME.
+	paths []string
+}
+
+// writablePath is a path the image is likely to write to.
+type writablePath struct {
+	path string
+	// source is why the path is checked, e.g. WORKDIR.
+	source string
+}
+
+// NewSupportsArbitraryUIDCheck returns a check that verifies WORKDIR, each
+// VOLUME, the HOME of USER, and paths are writable by group 0.
+func NewSupportsArbitraryUIDCheck(paths []string) *SupportsArbitraryUIDCheck {
+	return &SupportsArbitraryUIDCheck{paths: paths}
+}
+
+func (p *SupportsArbitraryUIDCheck) Validate(ctx context.Context, imgRef image.ImageReference) (bool, error) {
+	passed, _, err := p.ValidateWithFindings(ctx, imgRef)
+	return passed, err
+}
+
+// ValidateWithFindings reports each path that is not writable by group 0,
+// with its mode and owner. The mode and owner are read from the layers, since
+// the extracted filesystem does not preserve them.
+func (p *SupportsArbitraryUIDCheck) ValidateWithFindings(ctx context.Context, imgRef image.ImageReference) (bool, []check.Finding, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	if imgRef.ImageInfo == nil {
+		return false, nil, fmt.Errorf("image reference invalid")
+	}
+
+	configFile, err := imgRef.ImageInfo.ConfigFile()
+	if err != nil {
+		return false, nil, fmt.Errorf("could not retrieve ConfigFile from Image: %w", err)
+	}
+
+	var paths []writablePath
+	if configFile.Config.WorkingDir != "" {
+		paths = append(paths, writablePath{path: configFile.Config.WorkingDir, source: "WORKDIR"})
+	}
+	volumes := make([]string, 0, len(configFile.Config.Volumes))
+	for volume := range configFile.Config.Volumes {
+		volumes = append(volumes, volume)
+	}
+	slices.Sort(volumes)
+	for _, volume := range volumes {
+		paths = append(paths, writablePath{path: volume, source: "VOLUME"})
+	}
+	home, err := p.home(imgRef.ImageFSPath, configFile.Config.User, configFile.Config.Env)
+	if err != nil {
+		return false, nil, err
+	}
+	if home != "" {
+		paths = append(paths, writablePath{path: home, source: "HOME"})
+	}
+	for _, configured := range p.paths {
+		paths = append(paths, writablePath{path: configured, source: "configured path"})
+	}
+
+	layers, err := imgRef.ImageInfo.Layers()
+	if err != nil {
+		return false, nil, fmt.Errorf("could not read the image layers: %w", err)
+	}
+	files, err := readLayerFiles(layers)
+	if err != nil {
+		return false, nil, err
+	}
+
+	passed := true
+	var findings []check.Finding
+	checked := map[string]bool{}
+	for _, wp := range paths {
+		// The root directory is never expected to be writable, and is the
+		// default WORKDIR and HOME.
+		cleaned := path.Clean("/" + wp.path)
+		if cleaned == "/" || checked[cleaned] {
+			continue
+		}
+		checked[cleaned] = true
+
+		resolved, header := files.resolve(cleaned)
+		object := cleaned
+		if resolved != cleaned {
+			object = fmt.Sprintf("%s -> %s", cleaned, resolved)
+		}
+		if header == nil {
+			logger.V(log.DBG).Info("writable path does not exist in the image", "path", cleaned, "source", wp.source)
+			findings = append(findings, check.Finding{
+				Message:  fmt.Sprintf("%s %s does not exist in the image, and will be created at runtime", wp.source, cleaned),
+				Object:   object,
+				Severity: check.SeverityInfo,
+			})
+			continue
+		}
+
+		mode := header.FileInfo().Mode()
+		owner := fmt.Sprintf("%d:%d", header.Uid, header.Gid)
+		var problems []string
+		if header.Gid != 0 {
+			problems = append(problems, "not owned by group 0")
+		}
+		// Directories must be searchable, as well as writable, by the group.
+		required := int64(0o020)
+		if mode.IsDir() {
+			required = 0o030
+		}
+		if header.Mode&required != required {
+			problems = append(problems, "not group-writable")
+		}
+
+		if len(problems) == 0 {
+			findings = append(findings, check.Finding{
+				Message:  fmt.Sprintf("%s %s is writable by group 0 (mode %s, owner %s)", wp.source, cleaned, mode, owner),
+				Object:   object,
+				Severity: check.SeverityInfo,
+			})
+			continue
+		}
+		passed = false
+		findings = append(findings, check.Finding{
+			Message:  fmt.Sprintf("%s %s is %s (mode %s, owner %s)", wp.source, cleaned, strings.Join(problems, " and "), mode, owner),
+			Object:   object,
+			Severity: check.SeverityError,
+		})
+	}
+
+	return passed, findings, nil
+}
+
+// home returns the home directory of user: the HOME in env if set, or the
+// one in /etc/passwd. It is empty if the user is not defined.
+func (p *SupportsArbitraryUIDCheck) home(fsPath string, user string, env []string) (string, error) {
+	for _, e := range env {
+		if home, ok := strings.CutPrefix(e, "HOME="); ok {
+			return home, nil
+		}
+	}
+
+	passwd, err := readPasswd(fsPath)
+	if err != nil {
+		return "", err
+	}
+	return resolveUser(user, passwd, nil).home, nil
+}
+
+func (p *SupportsArbitraryUIDCheck) Name() string {
+	return "SupportsArbitraryUID"
+}
+
+func (p *SupportsArbitraryUIDCheck) Metadata() check.Metadata {
+	return check.Metadata{
+		ID:               "PFLT-CNT-013",
+		PolicyVersion:    "1.0",
+		Categories:       []check.Category{check.CategorySecurity, check.CategoryCluster},
+		Description:      "Checking that WORKDIR, VOLUMEs and the HOME of USER are owned by group 0 and group-writable, so that the image runs with the arbitrary UID OpenShift assigns.",
+		Level:            check.LevelWarn,
+		KnowledgeBaseURL: arbitraryUIDDocumentationURL,
+		CheckURL:         arbitraryUIDDocumentationURL,
+	}
+}
+
+func (p *SupportsArbitraryUIDCheck) Help() check.HelpText {
+	return check.HelpText{
+		Message:    "Check SupportsArbitraryUID encountered an error. Please review the preflight.log file for more information.",
+		Suggestion: "Make the paths named in the findings owned by group 0 and group-writable, e.g. with chgrp -R 0 <path> && chmod -R g=u <path> in the layer that creates them.",
+	}
+}
+
+func (p *SupportsArbitraryUIDCheck) RequiredFilePatterns() []string {
+	//coverage:ignore
+	return []string{passwdPath}
+}
@@ -0,0 +1,168 @@
+package container
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	fakecranev1 "github.com/google/go-containerregistry/pkg/v1/fake"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+)
+
+var _ = Describe("SupportsArbitraryUID", func() {
+	var (
+		supportsArbitraryUID *SupportsArbitraryUIDCheck
+		imgRef               image.ImageReference
+		cfg                  v1.Config
+	)
+
+	BeforeEach(func() {
+		supportsArbitraryUID = NewSupportsArbitraryUIDCheck(nil)
+		cfg = v1.Config{User: "1001", WorkingDir: "/opt/app-root/src"}
+
+		fsPath := GinkgoT().TempDir()
+		Expect(os.MkdirAll(filepath.Join(fsPath, "etc"), 0o755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(fsPath, "etc", "passwd"), []byte("default:x:1001:0::/opt/app-root/src:/sbin/nologin\n"), 0o644)).To(Succeed())
+		imgRef = image.ImageReference{ImageFSPath: fsPath}
+	})
+
+	// withImage builds the image of the test from cfg, with a base layer of
+	// root-owned directories and a layer that prepares the application.
+	withImage := func(app ...*tar.Header) {
+		img, err := mutate.AppendLayers(empty.Image,
+			headerLayer(
+				dirHeader("opt/", 0o755, 0, 0),
+				dirHeader("var/", 0o755, 0, 0),
+				dirHeader("var/lib/", 0o755, 0, 0),
+			),
+			headerLayer(app...),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		img, err = mutate.Config(img, cfg)
+		Expect(err).ToNot(HaveOccurred())
+		imgRef.ImageInfo = img
+	}
+
+	Context("When the paths the image writes to are writable by group 0", func() {
+		BeforeEach(func() {
+			cfg.Volumes = map[string]struct{}{"/var/lib/data": {}, "/var/lib/cache": {}}
+			withImage(
+				dirHeader("opt/app-root/", 0o775, 1001, 0),
+				dirHeader("opt/app-root/src/", 0o775, 1001, 0),
+				dirHeader("var/lib/data/", 0o2775, 1001, 0),
+			)
+		})
+
+		It("should pass Validate, and report each path", func() {
+			ok, findings, err := supportsArbitraryUID.ValidateWithFindings(context.TODO(), imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(findings).To(Equal([]check.Finding{
+				{Message: "WORKDIR /opt/app-root/src is writable by group 0 (mode drwxrwxr-x, owner 1001:0)", Object: "/opt/app-root/src", Severity: check.SeverityInfo},
+				{Message: "VOLUME /var/lib/cache does not exist in the image, and will be created at runtime", Object: "/var/lib/cache", Severity: check.SeverityInfo},
+				{Message: "VOLUME /var/lib/data is writable by group 0 (mode dgrwxrwxr-x, owner 1001:0)", Object: "/var/lib/data", Severity: check.SeverityInfo},
+			}))
+		})
+	})
+
+	Context("When the paths the image writes to are not writable by group 0", func() {
+		BeforeEach(func() {
+			cfg.Env = []string{"HOME=/home/app"}
+			supportsArbitraryUID = NewSupportsArbitraryUIDCheck([]string{"/var/lib/app", "/opt/app-root/src", "/"})
+			withImage(
+				dirHeader("opt/app-root/", 0o755, 1001, 1001),
+				dirHeader("opt/app-root/src/", 0o755, 1001, 1001),
+				dirHeader("home/", 0o755, 0, 0),
+				dirHeader("home/app/", 0o770, 1001, 0),
+				&tar.Header{Name: "var/lib/app.db", Typeflag: tar.TypeReg, Mode: 0o644, Uid: 1001, Gid: 0},
+				symlinkHeader("var/lib/app", "app.db"),
+			)
+		})
+
+		It("should not pass Validate, and report the mode and owner of each offender", func() {
+			ok, findings, err := supportsArbitraryUID.ValidateWithFindings(context.TODO(), imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+			Expect(findings).To(Equal([]check.Finding{
+				{Message: "WORKDIR /opt/app-root/src is not owned by group 0 and not group-writable (mode drwxr-xr-x, owner 1001:1001)", Object: "/opt/app-root/src", Severity: check.SeverityError},
+				{Message: "HOME /home/app is writable by group 0 (mode drwxrwx---, owner 1001:0)", Object: "/home/app", Severity: check.SeverityInfo},
+				{Message: "configured path /var/lib/app is not group-writable (mode -rw-r--r--, owner 1001:0)", Object: "/var/lib/app -> /var/lib/app.db", Severity: check.SeverityError},
+			}))
+		})
+	})
+
+	Context("When the HOME of USER is not writable by group 0", func() {
+		BeforeEach(func() {
+			cfg.WorkingDir = ""
+			withImage(dirHeader("opt/app-root/src/", 0o700, 1001, 0))
+		})
+
+		It("should not pass Validate", func() {
+			ok, findings, err := supportsArbitraryUID.ValidateWithFindings(context.TODO(), imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+			Expect(findings).To(Equal([]check.Finding{
+				{Message: "HOME /opt/app-root/src is not group-writable (mode drwx------, owner 1001:0)", Object: "/opt/app-root/src", Severity: check.SeverityError},
+			}))
+		})
+	})
+
+	Context("When the image can not be read", func() {
+		It("should fail without an image", func() {
+			_, err := supportsArbitraryUID.Validate(context.TODO(), image.ImageReference{})
+			Expect(err).To(MatchError("image reference invalid"))
+		})
+
+		It("should fail if the config can not be read", func() {
+			fakeImage := fakecranev1.FakeImage{}
+			fakeImage.ConfigFileReturns(nil, errors.New("no config"))
+			_, err := supportsArbitraryUID.Validate(context.TODO(), image.ImageReference{ImageInfo: &fakeImage})
+			Expect(err).To(MatchError(ContainSubstring("could not retrieve ConfigFile from Image")))
+		})
+
+		It("should fail if /etc/passwd can not be read", func() {
+			Expect(os.Remove(filepath.Join(imgRef.ImageFSPath, "etc", "passwd"))).To(Succeed())
+			Expect(os.Mkdir(filepath.Join(imgRef.ImageFSPath, "etc", "passwd"), 0o755)).To(Succeed())
+			withImage()
+			_, err := supportsArbitraryUID.Validate(context.TODO(), imgRef)
+			Expect(err).To(MatchError(ContainSubstring("could not read /etc/passwd")))
+		})
+
+		It("should fail if the layers can not be listed", func() {
+			fakeImage := fakecranev1.FakeImage{}
+			fakeImage.ConfigFileReturns(&v1.ConfigFile{}, nil)
+			fakeImage.LayersReturns(nil, errors.New("no layers"))
+			_, err := supportsArbitraryUID.Validate(context.TODO(), image.ImageReference{ImageInfo: &fakeImage})
+			Expect(err).To(MatchError(ContainSubstring("could not read the image layers")))
+		})
+
+		It("should fail if a layer can not be read", func() {
+			fakeImage := fakecranev1.FakeImage{}
+			fakeImage.ConfigFileReturns(&v1.ConfigFile{}, nil)
+			fakeImage.LayersReturns([]v1.Layer{unreadableLayer{}}, nil)
+			_, err := supportsArbitraryUID.Validate(context.TODO(), image.ImageReference{ImageInfo: &fakeImage})
+			Expect(err).To(MatchError(ContainSubstring("reading layer 0: reading layer contents")))
+		})
+
+		It("should fail if a layer is not a tar", func() {
+			fakeImage := fakecranev1.FakeImage{}
+			fakeImage.ConfigFileReturns(&v1.ConfigFile{}, nil)
+			fakeImage.LayersReturns([]v1.Layer{static.NewLayer([]byte("not a tar"), types.DockerUncompressedLayer)}, nil)
+			_, err := supportsArbitraryUID.Validate(context.TODO(), image.ImageReference{ImageInfo: &fakeImage})
+			Expect(err).To(MatchError(ContainSubstring("reading tar")))
+		})
+	})
+
+	AssertMetaData(&SupportsArbitraryUIDCheck{})
+})
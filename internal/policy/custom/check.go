@@ -0,0 +1,226 @@
+package custom
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/dustin/go-humanize"
+	rpmdb "github.com/knqyf263/go-rpmdb/pkg"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+)
+
+var (
+	_ check.Check            = &ruleCheck{}
+	_ check.FindingsReporter = &ruleCheck{}
+)
+
+// packageListFunc is the signature used to retrieve the RPM package list.
+type packageListFunc func(ctx context.Context, dir string) ([]*rpmdb.PackageInfo, error)
+
+// ruleCheck is a check compiled from a Rule.
+type ruleCheck struct {
+	rule                 Rule
+	pattern              *regexp.Regexp
+	maxSize              int64
+	requiredFilePatterns []string
+	getPackageList       packageListFunc
+}
+
+func (c *ruleCheck) Validate(ctx context.Context, imgRef image.ImageReference) (bool, error) {
+	passed, _, err := c.ValidateWithFindings(ctx, imgRef)
+	return passed, err
+}
+
+// ValidateWithFindings reports each violation of the rule as a finding.
+func (c *ruleCheck) ValidateWithFindings(ctx context.Context, imgRef image.ImageReference) (bool, []check.Finding, error) {
+	var findings []check.Finding
+	var err error
+	switch c.rule.Type {
+	case RuleLabel:
+		findings, err = c.validateLabel(imgRef)
+	case RuleFilePresent:
+		findings, err = c.validateFilePresent(imgRef)
+	case RuleFileAbsent:
+		findings, err = c.validateFileAbsent(imgRef)
+	case RuleForbiddenPackage:
+		findings, err = c.validatePackages(ctx, imgRef)
+	case RuleMaxImageSize:
+		findings, err = c.validateSize(imgRef)
+	case RuleForbiddenEnv:
+		findings, err = c.validateEnv(imgRef)
+	}
+	if err != nil {
+		return false, nil, err
+	}
+
+	return len(findings) == 0, findings, nil
+}
+
+func (c *ruleCheck) validateLabel(imgRef image.ImageReference) ([]check.Finding, error) {
+	configFile, err := imgRef.ImageInfo.ConfigFile()
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve image config: %w", err)
+	}
+
+	value, ok := configFile.Config.Labels[c.rule.Label]
+	switch {
+	case !ok:
+		return []check.Finding{c.finding("label is not set", c.rule.Label)}, nil
+	case c.pattern != nil && !c.pattern.MatchString(value):
+		return []check.Finding{c.finding(fmt.Sprintf("label value %q does not match %s", value, c.pattern), c.rule.Label)}, nil
+	}
+
+	return nil, nil
+}
+
+// matchingFiles returns the files extracted from the image that match the
+// rule's path, as absolute paths.
+func (c *ruleCheck) matchingFiles(imgRef image.ImageReference) ([]string, error) {
+	matches, err := doublestar.Glob(os.DirFS(imgRef.ImageFSPath), strings.TrimLeft(c.rule.Path, "/"))
+	if err != nil {
+		//coverage:ignore
+		return nil, fmt.Errorf("could not search image files: %w", err)
+	}
+
+	for i, m := range matches {
+		matches[i] = "/" + m
+	}
+
+	return matches, nil
+}
+
+func (c *ruleCheck) validateFilePresent(imgRef image.ImageReference) ([]check.Finding, error) {
+	matches, err := c.matchingFiles(imgRef)
+	if err != nil {
+		//coverage:ignore
+		return nil, err
+	}
+
+	if len(matches) == 0 {
+		return []check.Finding{c.finding("required file does not exist", c.rule.Path)}, nil
+	}
+
+	return nil, nil
+}
+
+func (c *ruleCheck) validateFileAbsent(imgRef image.ImageReference) ([]check.Finding, error) {
+	matches, err := c.matchingFiles(imgRef)
+	if err != nil {
+		//coverage:ignore
+		return nil, err
+	}
+
+	findings := make([]check.Finding, 0, len(matches))
+	for _, m := range matches {
+		findings = append(findings, c.finding("file must not exist", m))
+	}
+
+	return findings, nil
+}
+
+func (c *ruleCheck) validatePackages(ctx context.Context, imgRef image.ImageReference) ([]check.Finding, error) {
+	pkgList, err := c.getPackageList(ctx, imgRef.ImageFSPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not get rpm list: %w", err)
+	}
+
+	var findings []check.Finding
+	for _, pkg := range pkgList {
+		if slices.ContainsFunc(c.rule.Packages, func(pattern string) bool {
+			// Patterns are validated by compileRule.
+			matched, _ := doublestar.Match(pattern, pkg.Name)
+			return matched
+		}) {
+			findings = append(findings, c.finding("package is forbidden", pkg.Name))
+		}
+	}
+
+	return findings, nil
+}
+
+func (c *ruleCheck) validateSize(imgRef image.ImageReference) ([]check.Finding, error) {
+	layers, err := imgRef.ImageInfo.Layers()
+	if err != nil {
+		return nil, fmt.Errorf("could not get image layers: %w", err)
+	}
+
+	var size int64
+	for _, layer := range layers {
+		layerSize, err := layer.Size()
+		if err != nil {
+			return nil, fmt.Errorf("could not get layer size: %w", err)
+		}
+		size += layerSize
+	}
+
+	if size > c.maxSize {
+		return []check.Finding{c.finding(fmt.Sprintf("image size %s exceeds the maximum of %s", humanize.Bytes(uint64(size)), humanize.Bytes(uint64(c.maxSize))), "")}, nil
+	}
+
+	return nil, nil
+}
+
+func (c *ruleCheck) validateEnv(imgRef image.ImageReference) ([]check.Finding, error) {
+	configFile, err := imgRef.ImageInfo.ConfigFile()
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve image config: %w", err)
+	}
+
+	var findings []check.Finding
+	for _, env := range configFile.Config.Env {
+		name, _, _ := strings.Cut(env, "=")
+		if slices.Contains(c.rule.Env, name) {
+			findings = append(findings, c.finding("environment variable must not be set", name))
+		}
+	}
+
+	return findings, nil
+}
+
+// finding returns a finding about object, with a severity matching the
+// check's level.
+func (c *ruleCheck) finding(message, object string) check.Finding {
+	severity := check.SeverityError
+	if c.rule.Level != check.LevelBest {
+		severity = check.SeverityWarning
+	}
+	return check.Finding{Message: message, Object: object, Severity: severity}
+}
+
+func (c *ruleCheck) Name() string {
+	return c.rule.Name
+}
+
+func (c *ruleCheck) Metadata() check.Metadata {
+	description := c.rule.Description
+	if description == "" {
+		description = fmt.Sprintf("Custom %s check.", c.rule.Type)
+	}
+	return check.Metadata{
+		Description:      description,
+		Level:            c.rule.Level,
+		KnowledgeBaseURL: c.rule.KnowledgeBaseURL,
+	}
+}
+
+func (c *ruleCheck) Help() check.HelpText {
+	message := c.rule.Help
+	if message == "" {
+		message = fmt.Sprintf("Check %s failed. Please review the findings in the results for more information.", c.rule.Name)
+	}
+	return check.HelpText{
+		Message:    message,
+		Suggestion: c.rule.Suggestion,
+	}
+}
+
+func (c *ruleCheck) RequiredFilePatterns() []string {
+	return c.requiredFilePatterns
+}
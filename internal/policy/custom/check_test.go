@@ -0,0 +1,174 @@
+package custom
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+
+	cranev1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	rpmdb "github.com/knqyf263/go-rpmdb/pkg"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+)
+
+var _ = Describe("Custom checks", func() {
+	var imgRef image.ImageReference
+	BeforeEach(func() {
+		img, err := random.Image(1024, 2)
+		Expect(err).ToNot(HaveOccurred())
+		img, err = mutate.Config(img, cranev1.Config{
+			Labels: map[string]string{"com.example.team": "team-platform"},
+			Env:    []string{"PATH=/usr/bin", "DEBUG=1"},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		fsPath := GinkgoT().TempDir()
+		Expect(os.MkdirAll(filepath.Join(fsPath, "etc", "ssh"), 0o755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(fsPath, "etc", "ssh", "ssh_host_rsa_key"), nil, 0o600)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(fsPath, "etc", "ssh", "ssh_host_ed25519_key"), nil, 0o600)).To(Succeed())
+
+		imgRef = image.ImageReference{ImageInfo: img, ImageFSPath: fsPath}
+	})
+
+	compileRule := func(rule Rule) *ruleCheck {
+		c, err := compile(rule)
+		Expect(err).ToNot(HaveOccurred())
+		return c
+	}
+
+	DescribeTable("when validating an image",
+		func(rule Rule, expectedPassed bool, expectedFindings []check.Finding) {
+			c := compileRule(rule)
+			c.getPackageList = func(context.Context, string) ([]*rpmdb.PackageInfo, error) {
+				return []*rpmdb.PackageInfo{{Name: "bash"}, {Name: "telnet-server"}}, nil
+			}
+			passed, findings, err := c.ValidateWithFindings(context.TODO(), imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(passed).To(Equal(expectedPassed))
+			Expect(findings).To(Equal(expectedFindings))
+
+			passed, err = c.Validate(context.TODO(), imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(passed).To(Equal(expectedPassed))
+		},
+		Entry("label is set", Rule{Name: "A", Type: RuleLabel, Label: "com.example.team"}, true, nil),
+		Entry("label matches", Rule{Name: "A", Type: RuleLabel, Label: "com.example.team", Pattern: "^team-"}, true, nil),
+		Entry("label is not set", Rule{Name: "A", Type: RuleLabel, Label: "com.example.owner"}, false, []check.Finding{
+			{Message: "label is not set", Object: "com.example.owner", Severity: check.SeverityError},
+		}),
+		Entry("label does not match", Rule{Name: "A", Type: RuleLabel, Label: "com.example.team", Pattern: "^squad-"}, false, []check.Finding{
+			{Message: `label value "team-platform" does not match ^squad-`, Object: "com.example.team", Severity: check.SeverityError},
+		}),
+		Entry("required file exists", Rule{Name: "A", Type: RuleFilePresent, Path: "/etc/ssh/ssh_host_rsa_key"}, true, nil),
+		Entry("required file does not exist", Rule{Name: "A", Type: RuleFilePresent, Path: "/usr/local/bin/entrypoint.sh", Level: check.LevelWarn}, false, []check.Finding{
+			{Message: "required file does not exist", Object: "/usr/local/bin/entrypoint.sh", Severity: check.SeverityWarning},
+		}),
+		Entry("forbidden files exist", Rule{Name: "A", Type: RuleFileAbsent, Path: "/etc/ssh/*_key"}, false, []check.Finding{
+			{Message: "file must not exist", Object: "/etc/ssh/ssh_host_ed25519_key", Severity: check.SeverityError},
+			{Message: "file must not exist", Object: "/etc/ssh/ssh_host_rsa_key", Severity: check.SeverityError},
+		}),
+		Entry("forbidden file does not exist", Rule{Name: "A", Type: RuleFileAbsent, Path: "/root/.bash_history"}, true, []check.Finding{}),
+		Entry("forbidden package is installed", Rule{Name: "A", Type: RuleForbiddenPackage, Packages: []string{"telnet*"}}, false, []check.Finding{
+			{Message: "package is forbidden", Object: "telnet-server", Severity: check.SeverityError},
+		}),
+		Entry("forbidden package matches a brace pattern", Rule{Name: "A", Type: RuleForbiddenPackage, Packages: []string{"{telnet,rsh}-server"}}, false, []check.Finding{
+			{Message: "package is forbidden", Object: "telnet-server", Severity: check.SeverityError},
+		}),
+		Entry("forbidden package is not installed", Rule{Name: "A", Type: RuleForbiddenPackage, Packages: []string{"nmap"}}, true, nil),
+		Entry("forbidden env var is set", Rule{Name: "A", Type: RuleForbiddenEnv, Env: []string{"DEBUG"}}, false, []check.Finding{
+			{Message: "environment variable must not be set", Object: "DEBUG", Severity: check.SeverityError},
+		}),
+		Entry("forbidden env var is not set", Rule{Name: "A", Type: RuleForbiddenEnv, Env: []string{"TRACE"}}, true, nil),
+	)
+
+	Context("when the package list can not be read", func() {
+		It("should return an error", func() {
+			c := compileRule(Rule{Name: "A", Type: RuleForbiddenPackage, Packages: []string{"telnet"}})
+			c.getPackageList = func(context.Context, string) ([]*rpmdb.PackageInfo, error) {
+				return nil, errors.New("no rpmdb")
+			}
+			_, _, err := c.ValidateWithFindings(context.TODO(), imgRef)
+			Expect(err).To(MatchError(ContainSubstring("could not get rpm list")))
+		})
+	})
+
+	Context("when the image config can not be read", func() {
+		BeforeEach(func() {
+			imgRef.ImageInfo = brokenImage{Image: imgRef.ImageInfo}
+		})
+		It("should return an error for label rules", func() {
+			_, _, err := compileRule(Rule{Name: "A", Type: RuleLabel, Label: "foo"}).ValidateWithFindings(context.TODO(), imgRef)
+			Expect(err).To(MatchError(ContainSubstring("could not retrieve image config")))
+		})
+		It("should return an error for env rules", func() {
+			_, _, err := compileRule(Rule{Name: "A", Type: RuleForbiddenEnv, Env: []string{"DEBUG"}}).ValidateWithFindings(context.TODO(), imgRef)
+			Expect(err).To(MatchError(ContainSubstring("could not retrieve image config")))
+		})
+		It("should return an error for size rules", func() {
+			_, _, err := compileRule(Rule{Name: "A", Type: RuleMaxImageSize, MaxSize: "1MB"}).ValidateWithFindings(context.TODO(), imgRef)
+			Expect(err).To(MatchError(ContainSubstring("could not get image layers")))
+		})
+	})
+
+	Context("when checking the image size", func() {
+		BeforeEach(func() {
+			imgRef.ImageInfo = sizedImage{Image: imgRef.ImageInfo, layers: []cranev1.Layer{sizedLayer{size: 300_000_000}, sizedLayer{size: 250_000_000}}}
+		})
+		It("should pass if the image is small enough", func() {
+			passed, findings, err := compileRule(Rule{Name: "A", Type: RuleMaxImageSize, MaxSize: "1GB"}).ValidateWithFindings(context.TODO(), imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(passed).To(BeTrue())
+			Expect(findings).To(BeEmpty())
+		})
+		It("should fail if the image is too large", func() {
+			passed, findings, err := compileRule(Rule{Name: "A", Type: RuleMaxImageSize, MaxSize: "500MB"}).ValidateWithFindings(context.TODO(), imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(passed).To(BeFalse())
+			Expect(findings).To(Equal([]check.Finding{{Message: "image size 550 MB exceeds the maximum of 500 MB", Severity: check.SeverityError}}))
+		})
+		It("should return an error if a layer size can not be read", func() {
+			imgRef.ImageInfo = sizedImage{Image: imgRef.ImageInfo, layers: []cranev1.Layer{sizedLayer{err: errors.New("broken")}}}
+			_, _, err := compileRule(Rule{Name: "A", Type: RuleMaxImageSize, MaxSize: "1MB"}).ValidateWithFindings(context.TODO(), imgRef)
+			Expect(err).To(MatchError(ContainSubstring("could not get layer size")))
+		})
+	})
+})
+
+// brokenImage fails to return its config and layers.
+type brokenImage struct {
+	cranev1.Image
+}
+
+func (brokenImage) ConfigFile() (*cranev1.ConfigFile, error) {
+	return nil, errors.New("broken")
+}
+
+func (brokenImage) Layers() ([]cranev1.Layer, error) {
+	return nil, errors.New("broken")
+}
+
+// sizedImage has layers of a fixed size.
+type sizedImage struct {
+	cranev1.Image
+	layers []cranev1.Layer
+}
+
+func (i sizedImage) Layers() ([]cranev1.Layer, error) {
+	return i.layers, nil
+}
+
+type sizedLayer struct {
+	cranev1.Layer
+	size int64
+	err  error
+}
+
+func (l sizedLayer) Size() (int64, error) {
+	return l.size, l.err
+}
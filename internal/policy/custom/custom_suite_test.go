@@ -0,0 +1,13 @@
+package custom
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestCustom(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Custom Checks Suite")
+}
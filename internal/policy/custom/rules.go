@@ -0,0 +1,192 @@
+// Package custom compiles checks declared in a rules file, so that checks
+// beyond those in Red Hat's policies can be executed and reported alongside
+// them.
+package custom
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/dustin/go-humanize"
+	"sigs.k8s.io/yaml"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/rpm"
+)
+
+// RuleType identifies what a rule checks.
+type RuleType string
+
+const (
+	// RuleLabel requires a label to be set, optionally to a value matching
+	// Pattern.
+	RuleLabel RuleType = "label"
+	// RuleFilePresent requires a file matching Path to exist.
+	RuleFilePresent RuleType = "file-present"
+	// RuleFileAbsent requires that no file matching Path exists.
+	RuleFileAbsent RuleType = "file-absent"
+	// RuleForbiddenPackage requires that no installed RPM matches Packages.
+	RuleForbiddenPackage RuleType = "forbidden-package"
+	// RuleMaxImageSize requires the compressed size of the image layers to
+	// be at most MaxSize.
+	RuleMaxImageSize RuleType = "max-image-size"
+	// RuleForbiddenEnv requires that none of the variables in Env are set in
+	// the image config.
+	RuleForbiddenEnv RuleType = "forbidden-env"
+)
+
+// Rules is the contents of a rules file.
+type Rules struct {
+	Checks []Rule `json:"checks"`
+}
+
+// Rule declares a single check. Type determines which of the rule specific
+// fields apply.
+type Rule struct {
+	Name        string   `json:"name"`
+	Type        RuleType `json:"type"`
+	Description string   `json:"description,omitempty"`
+	// Level is one of best, warn or optional. Defaults to best.
+	Level            string `json:"level,omitempty"`
+	Help             string `json:"help,omitempty"`
+	Suggestion       string `json:"suggestion,omitempty"`
+	KnowledgeBaseURL string `json:"knowledgeBaseURL,omitempty"`
+
+	// Label is the name of the label a label rule requires.
+	Label string `json:"label,omitempty"`
+	// Pattern is a regular expression the value of Label must match.
+	Pattern string `json:"pattern,omitempty"`
+	// Path is a file path or glob, e.g. /etc/ssh/*_key.
+	Path string `json:"path,omitempty"`
+	// Packages are the names or globs of forbidden packages, e.g. telnet*.
+	Packages []string `json:"packages,omitempty"`
+	// MaxSize is the maximum image size, e.g. 500MB or 1GiB.
+	MaxSize string `json:"maxSize,omitempty"`
+	// Env are the names of forbidden environment variables.
+	Env []string `json:"env,omitempty"`
+}
+
+// Load reads the rules file at path and compiles its rules into checks.
+func Load(path string) ([]check.Check, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read custom checks: %w", err)
+	}
+
+	checks, err := Parse(b)
+	if err != nil {
+		return nil, fmt.Errorf("invalid custom checks in %s: %w", path, err)
+	}
+
+	return checks, nil
+}
+
+// Parse compiles the rules in the YAML or JSON document b into checks.
+func Parse(b []byte) ([]check.Check, error) {
+	var rules Rules
+	if err := yaml.UnmarshalStrict(b, &rules); err != nil {
+		return nil, err
+	}
+
+	checks := make([]check.Check, 0, len(rules.Checks))
+	names := make([]string, 0, len(rules.Checks))
+	for i, rule := range rules.Checks {
+		c, err := compile(rule)
+		if err != nil {
+			return nil, fmt.Errorf("check %d (%q): %w", i, rule.Name, err)
+		}
+		if slices.Contains(names, rule.Name) {
+			return nil, fmt.Errorf("check %d: duplicate check name %q", i, rule.Name)
+		}
+		names = append(names, rule.Name)
+		checks = append(checks, c)
+	}
+
+	return checks, nil
+}
+
+// Append loads the rules file at path and appends its checks to checks,
+// marked with check.Custom. An error is returned if a custom check has the
+// same name as one in checks.
+func Append(checks []check.Check, path string) ([]check.Check, error) {
+	custom, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, c := range custom {
+		if slices.ContainsFunc(checks, func(existing check.Check) bool { return existing.Name() == c.Name() }) {
+			return nil, fmt.Errorf("custom check %q has the same name as a policy check", c.Name())
+		}
+		custom[i] = check.Custom(c)
+	}
+
+	return append(slices.Clone(checks), custom...), nil
+}
+
+// compile validates rule and returns the check it declares.
+func compile(rule Rule) (*ruleCheck, error) {
+	if rule.Name == "" {
+		return nil, errors.New("name is required")
+	}
+
+	switch rule.Level {
+	case "":
+		rule.Level = check.LevelBest
+	case check.LevelBest, check.LevelWarn, check.LevelOptional:
+	default:
+		return nil, fmt.Errorf("level must be one of %s, %s or %s", check.LevelBest, check.LevelWarn, check.LevelOptional)
+	}
+
+	c := &ruleCheck{rule: rule}
+	switch rule.Type {
+	case RuleLabel:
+		if rule.Label == "" {
+			return nil, errors.New("label is required")
+		}
+		if rule.Pattern != "" {
+			pattern, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern: %w", err)
+			}
+			c.pattern = pattern
+		}
+	case RuleFilePresent, RuleFileAbsent:
+		if rule.Path == "" {
+			return nil, errors.New("path is required")
+		}
+		if !doublestar.ValidatePattern(rule.Path) {
+			return nil, fmt.Errorf("invalid path pattern %q", rule.Path)
+		}
+		c.requiredFilePatterns = []string{rule.Path}
+	case RuleForbiddenPackage:
+		if len(rule.Packages) == 0 {
+			return nil, errors.New("packages are required")
+		}
+		for _, pkg := range rule.Packages {
+			if !doublestar.ValidatePattern(pkg) {
+				return nil, fmt.Errorf("invalid package pattern %q", pkg)
+			}
+		}
+		c.requiredFilePatterns = rpm.RpmdbPaths
+		c.getPackageList = rpm.GetPackageList
+	case RuleMaxImageSize:
+		size, err := humanize.ParseBytes(rule.MaxSize)
+		if err != nil {
+			return nil, fmt.Errorf("invalid maxSize %q: %w", rule.MaxSize, err)
+		}
+		c.maxSize = int64(size)
+	case RuleForbiddenEnv:
+		if len(rule.Env) == 0 {
+			return nil, errors.New("env is required")
+		}
+	default:
+		return nil, fmt.Errorf("unknown type %q", rule.Type)
+	}
+
+	return c, nil
+}
@@ -0,0 +1,139 @@
+package custom
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/rpm"
+)
+
+var _ = Describe("Custom check rules", func() {
+	Context("when parsing valid rules", func() {
+		rules := []byte(`
+checks:
+- name: HasTeamLabel
+  type: label
+  label: com.example.team
+  pattern: '^team-[a-z]+$'
+  description: Images must be labelled with the owning team.
+  suggestion: Add a com.example.team label.
+- name: HasEntrypoint
+  type: file-present
+  path: /usr/local/bin/entrypoint.sh
+  level: warn
+- name: NoSSHHostKeys
+  type: file-absent
+  path: /etc/ssh/*_key
+- name: NoTelnet
+  type: forbidden-package
+  packages: [telnet*]
+- name: SmallImage
+  type: max-image-size
+  maxSize: 500MB
+- name: NoDebug
+  type: forbidden-env
+  env: [DEBUG]
+`)
+		It("should compile a check for every rule", func() {
+			checks, err := Parse(rules)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(checks).To(HaveLen(6))
+
+			names := make([]string, 0, len(checks))
+			for _, c := range checks {
+				names = append(names, c.Name())
+			}
+			Expect(names).To(Equal([]string{"HasTeamLabel", "HasEntrypoint", "NoSSHHostKeys", "NoTelnet", "SmallImage", "NoDebug"}))
+		})
+		It("should use the metadata from the rule", func() {
+			checks, err := Parse(rules)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(checks[0].Metadata()).To(Equal(check.Metadata{Description: "Images must be labelled with the owning team.", Level: check.LevelBest}))
+			Expect(checks[0].Help().Suggestion).To(Equal("Add a com.example.team label."))
+			Expect(checks[1].Metadata().Level).To(Equal(check.LevelWarn))
+			Expect(checks[1].Metadata().Description).To(Equal("Custom file-present check."))
+			Expect(checks[1].Help().Message).To(ContainSubstring("Check HasEntrypoint failed"))
+		})
+		It("should require the files the rules inspect", func() {
+			checks, err := Parse(rules)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(checks[0].RequiredFilePatterns()).To(BeEmpty())
+			Expect(checks[1].RequiredFilePatterns()).To(Equal([]string{"/usr/local/bin/entrypoint.sh"}))
+			Expect(checks[3].RequiredFilePatterns()).To(Equal(rpm.RpmdbPaths))
+		})
+	})
+
+	DescribeTable("when parsing invalid rules",
+		func(rules string, expected string) {
+			_, err := Parse([]byte(rules))
+			Expect(err).To(MatchError(ContainSubstring(expected)))
+		},
+		Entry("unknown field", "checks:\n- name: A\n  type: label\n  lable: foo\n", "unknown field"),
+		Entry("missing name", "checks:\n- type: label\n  label: foo\n", "name is required"),
+		Entry("duplicate name", "checks:\n- {name: A, type: label, label: foo}\n- {name: A, type: label, label: bar}\n", "duplicate check name"),
+		Entry("unknown type", "checks:\n- {name: A, type: magic}\n", `unknown type "magic"`),
+		Entry("invalid level", "checks:\n- {name: A, type: label, label: foo, level: must}\n", "level must be one of"),
+		Entry("missing label", "checks:\n- {name: A, type: label}\n", "label is required"),
+		Entry("invalid pattern", "checks:\n- {name: A, type: label, label: foo, pattern: '('}\n", "invalid pattern"),
+		Entry("missing path", "checks:\n- {name: A, type: file-absent}\n", "path is required"),
+		Entry("invalid path", "checks:\n- {name: A, type: file-present, path: '/etc/[a'}\n", "invalid path pattern"),
+		Entry("missing packages", "checks:\n- {name: A, type: forbidden-package}\n", "packages are required"),
+		Entry("invalid package", "checks:\n- {name: A, type: forbidden-package, packages: ['[a']}\n", "invalid package pattern"),
+		Entry("invalid size", "checks:\n- {name: A, type: max-image-size, maxSize: big}\n", "invalid maxSize"),
+		Entry("missing env", "checks:\n- {name: A, type: forbidden-env}\n", "env is required"),
+	)
+
+	Context("when loading rules from a file", func() {
+		var dir string
+		BeforeEach(func() {
+			dir = GinkgoT().TempDir()
+		})
+		It("should read the checks", func() {
+			path := filepath.Join(dir, "rules.yaml")
+			Expect(os.WriteFile(path, []byte("checks:\n- {name: A, type: forbidden-env, env: [DEBUG]}\n"), 0o644)).To(Succeed())
+			checks, err := Load(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(checks).To(HaveLen(1))
+		})
+		It("should fail if the file does not exist", func() {
+			_, err := Load(filepath.Join(dir, "missing.yaml"))
+			Expect(err).To(MatchError(ContainSubstring("could not read custom checks")))
+		})
+		It("should name the file if the rules are invalid", func() {
+			path := filepath.Join(dir, "rules.yaml")
+			Expect(os.WriteFile(path, []byte("checks:\n- {name: A}\n"), 0o644)).To(Succeed())
+			_, err := Load(path)
+			Expect(err).To(MatchError(ContainSubstring("invalid custom checks in " + path)))
+		})
+	})
+
+	Context("when appending custom checks to a policy", func() {
+		var path string
+		BeforeEach(func() {
+			path = filepath.Join(GinkgoT().TempDir(), "rules.yaml")
+			Expect(os.WriteFile(path, []byte("checks:\n- {name: NoDebug, type: forbidden-env, env: [DEBUG]}\n"), 0o644)).To(Succeed())
+		})
+		It("should append the custom checks", func() {
+			policy := []check.Check{check.NewGenericCheck("HasLicense", nil, check.Metadata{}, check.HelpText{}, nil)}
+			checks, err := Append(policy, path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(checks).To(HaveLen(2))
+			Expect(checks[1].Name()).To(Equal("NoDebug"))
+			Expect(check.IsCustom(checks[1])).To(BeTrue())
+			Expect(policy).To(HaveLen(1))
+		})
+		It("should refuse a custom check with the name of a policy check", func() {
+			policy := []check.Check{check.NewGenericCheck("NoDebug", nil, check.Metadata{}, check.HelpText{}, nil)}
+			_, err := Append(policy, path)
+			Expect(err).To(MatchError(ContainSubstring("same name as a policy check")))
+		})
+		It("should fail if the rules can not be loaded", func() {
+			_, err := Append(nil, filepath.Join(GinkgoT().TempDir(), "missing.yaml"))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
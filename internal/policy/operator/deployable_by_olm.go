@@ -854,6 +854,9 @@ func (p *DeployableByOlmCheck) Name() string {
 
 func (p *DeployableByOlmCheck) Metadata() check.Metadata {
 	return check.Metadata{
+		ID:               "PFLT-OPR-003",
+		PolicyVersion:    "1.0",
+		Categories:       []check.Category{check.CategoryCluster},
 		Description:      "Checking if the operator could be deployed by OLM",
 		Level:            "best",
 		KnowledgeBaseURL: "https://sdk.operatorframework.io/docs/olm-integration/testing-deployment/",
@@ -239,6 +239,9 @@ var AssertMetaData = func(check check.Check) {
 			Expect(meta.CheckURL).ToNot(BeEmpty())
 			Expect(meta.Description).ToNot(BeEmpty())
 			Expect(meta.KnowledgeBaseURL).ToNot(BeEmpty())
+			Expect(meta.ID).ToNot(BeEmpty())
+			Expect(meta.PolicyVersion).ToNot(BeEmpty())
+			Expect(meta.Categories).ToNot(BeEmpty())
 			// Level is optional.
 		})
 
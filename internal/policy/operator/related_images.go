@@ -83,6 +83,9 @@ func (p *RelatedImagesCheck) Name() string {
 
 func (p *RelatedImagesCheck) Metadata() check.Metadata {
 	return check.Metadata{
+		ID:               "PFLT-OPR-007",
+		PolicyVersion:    "1.0",
+		Categories:       []check.Category{check.CategoryMetadata},
 		Description:      "Check that all images in the CSV are listed in RelatedImages section. Currently, this check is not enforced.",
 		Level:            "optional",
 		KnowledgeBaseURL: "https://access.redhat.com/documentation/en-us/red_hat_software_certification/2024/html-single/red_hat_openshift_software_certification_policy_guide/index#con-operator-requirements_openshift-sw-cert-policy-products-managed",
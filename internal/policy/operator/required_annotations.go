@@ -90,6 +90,9 @@ func (h RequiredAnnotations) Name() string {
 
 func (h RequiredAnnotations) Metadata() check.Metadata {
 	return check.Metadata{
+		ID:               "PFLT-OPR-009",
+		PolicyVersion:    "1.0",
+		Categories:       []check.Category{check.CategoryMetadata},
 		Description:      "Checks that the CSV has all of the required feature annotations.",
 		Level:            check.LevelBest,
 		KnowledgeBaseURL: "https://access.redhat.com/documentation/en-us/red_hat_software_certification/2024/html-single/red_hat_openshift_software_certification_policy_guide/index#con-operator-requirements_openshift-sw-cert-policy-products-managed",
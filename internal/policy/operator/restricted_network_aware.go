@@ -96,7 +96,10 @@ func (p FollowsRestrictedNetworkEnablementGuidelines) Name() string {
 
 func (p FollowsRestrictedNetworkEnablementGuidelines) Metadata() check.Metadata {
 	return check.Metadata{
-		Description: "Checks for indicators that this bundle has implemented guidelines to indicate readiness for running in a disconnected cluster, or a cluster with a restricted network.",
+		ID:            "PFLT-OPR-008",
+		PolicyVersion: "1.0",
+		Categories:    []check.Category{check.CategoryCluster, check.CategoryMetadata},
+		Description:   "Checks for indicators that this bundle has implemented guidelines to indicate readiness for running in a disconnected cluster, or a cluster with a restricted network.",
 		// TODO: If this check is enforced and no longer optional, we need to identify ways to reduce false failures that may be caused by
 		// developers injecting related images in other ways.
 		Level:            "optional",
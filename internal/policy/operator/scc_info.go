@@ -69,6 +69,9 @@ func (p *securityContextConstraintsInCSV) Name() string {
 
 func (p *securityContextConstraintsInCSV) Metadata() check.Metadata {
 	return check.Metadata{
+		ID:               "PFLT-OPR-006",
+		PolicyVersion:    "1.0",
+		Categories:       []check.Category{check.CategorySecurity, check.CategoryCluster},
 		Description:      "Evaluates the csv and logs a message if a non default security context constraint is needed by the operator",
 		Level:            "optional",
 		KnowledgeBaseURL: "https://redhat-connect.gitbook.io/certified-operator-guide/troubleshooting-and-resources/sccs", // Placeholder
@@ -56,6 +56,9 @@ func (p *ScorecardBasicSpecCheck) Name() string {
 
 func (p *ScorecardBasicSpecCheck) Metadata() check.Metadata {
 	return check.Metadata{
+		ID:               "PFLT-OPR-001",
+		PolicyVersion:    "1.0",
+		Categories:       []check.Category{check.CategoryCluster},
 		Description:      "Check to make sure that all CRs have a spec block.",
 		Level:            "best",
 		KnowledgeBaseURL: "https://sdk.operatorframework.io/docs/testing-operators/scorecard/#overview",
@@ -56,6 +56,9 @@ func (p *ScorecardOlmSuiteCheck) Name() string {
 
 func (p *ScorecardOlmSuiteCheck) Metadata() check.Metadata {
 	return check.Metadata{
+		ID:               "PFLT-OPR-002",
+		PolicyVersion:    "1.0",
+		Categories:       []check.Category{check.CategoryCluster},
 		Description:      "Operator-sdk scorecard OLM Test Suite Check",
 		Level:            "best",
 		KnowledgeBaseURL: "https://sdk.operatorframework.io/docs/testing-operators/scorecard/#overview",
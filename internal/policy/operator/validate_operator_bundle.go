@@ -58,6 +58,9 @@ func (p *ValidateOperatorBundleCheck) Name() string {
 
 func (p *ValidateOperatorBundleCheck) Metadata() check.Metadata {
 	return check.Metadata{
+		ID:               "PFLT-OPR-004",
+		PolicyVersion:    "1.0",
+		Categories:       []check.Category{check.CategoryMetadata},
 		Description:      "Validating Bundle image that checks if it can validate the content and format of the operator bundle",
 		Level:            "best",
 		KnowledgeBaseURL: "https://sdk.operatorframework.io/docs/olm-integration/tutorial-bundle/",
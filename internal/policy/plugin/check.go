@@ -0,0 +1,120 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+)
+
+var (
+	_ check.Check            = &pluginCheck{}
+	_ check.FindingsReporter = &pluginCheck{}
+)
+
+// pluginCheck adapts a plugin executable to a check.
+type pluginCheck struct {
+	executable string
+	desc       Description
+}
+
+func (c *pluginCheck) Validate(ctx context.Context, imgRef image.ImageReference) (bool, error) {
+	passed, _, err := c.ValidateWithFindings(ctx, imgRef)
+	return passed, err
+}
+
+// ValidateWithFindings sends the image to the plugin and returns the result
+// it replies with.
+func (c *pluginCheck) ValidateWithFindings(ctx context.Context, imgRef image.ImageReference) (bool, []check.Finding, error) {
+	configFile, err := imgRef.ImageInfo.ConfigFile()
+	if err != nil {
+		return false, nil, fmt.Errorf("could not retrieve image config: %w", err)
+	}
+
+	digest, err := imgRef.ImageInfo.Digest()
+	if err != nil {
+		return false, nil, fmt.Errorf("could not retrieve image digest: %w", err)
+	}
+
+	req := Request{
+		ProtocolVersion: ProtocolVersion,
+		Operation:       OperationValidate,
+		Image: &Image{
+			URI:                imgRef.ImageURI,
+			Registry:           imgRef.ImageRegistry,
+			Repository:         imgRef.ImageRepository,
+			TagOrDigest:        imgRef.ImageTagOrSha,
+			Digest:             digest.String(),
+			ManifestListDigest: imgRef.ManifestListDigest,
+			FSPath:             imgRef.ImageFSPath,
+			Config:             configFile,
+		},
+	}
+
+	var result Result
+	if err := invoke(ctx, c.executable, req, &result); err != nil {
+		return false, nil, err
+	}
+
+	switch {
+	case result.Error != "":
+		return false, nil, fmt.Errorf("plugin %s: %s", c.executable, result.Error)
+	case result.Skipped != "":
+		return false, nil, fmt.Errorf("%w: %s", check.ErrCheckSkipped, result.Skipped)
+	}
+
+	return result.Passed, result.Findings, nil
+}
+
+func (c *pluginCheck) Name() string {
+	return c.desc.Name
+}
+
+func (c *pluginCheck) Metadata() check.Metadata {
+	return c.desc.Metadata
+}
+
+func (c *pluginCheck) Help() check.HelpText {
+	return c.desc.Help
+}
+
+func (c *pluginCheck) RequiredFilePatterns() []string {
+	return c.desc.RequiredFilePatterns
+}
+
+// invoke runs executable with req on its stdin and decodes its stdout into
+// resp. The plugin is killed if ctx is done before it exits.
+func invoke(ctx context.Context, executable string, req Request, resp any) error {
+	in, err := json.Marshal(req)
+	if err != nil {
+		//coverage:ignore
+		return fmt.Errorf("could not encode plugin request: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, executable)
+	cmd.Stdin = bytes.NewReader(in)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctxErr := context.Cause(ctx); ctxErr != nil {
+			return fmt.Errorf("plugin %s: %w", executable, ctxErr)
+		}
+		if stderr.Len() > 0 {
+			return fmt.Errorf("plugin %s failed: %w: %s", executable, err, strings.TrimSpace(stderr.String()))
+		}
+		return fmt.Errorf("plugin %s failed: %w", executable, err)
+	}
+
+	if err := json.Unmarshal(stdout.Bytes(), resp); err != nil {
+		return fmt.Errorf("plugin %s replied with an invalid %s response: %w", executable, req.Operation, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,161 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	cranev1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+)
+
+var _ = Describe("Plugin checks", func() {
+	var (
+		dir    string
+		imgRef image.ImageReference
+	)
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+
+		img, err := random.Image(1024, 1)
+		Expect(err).ToNot(HaveOccurred())
+		img, err = mutate.Config(img, cranev1.Config{User: "1001", Labels: map[string]string{"name": "example"}})
+		Expect(err).ToNot(HaveOccurred())
+
+		imgRef = image.ImageReference{
+			ImageURI:        "quay.io/example/image:latest",
+			ImageFSPath:     "/tmp/fs",
+			ImageInfo:       img,
+			ImageRegistry:   "quay.io",
+			ImageRepository: "example/image",
+			ImageTagOrSha:   "latest",
+		}
+	})
+
+	// loadPlugin writes a plugin that runs validate and describes it.
+	loadPlugin := func(validate string) *pluginCheck {
+		c, err := load(context.TODO(), writePlugin(dir, "plugin", `{"name":"A"}`, validate))
+		Expect(err).ToNot(HaveOccurred())
+		return c
+	}
+
+	It("should send the image to the plugin", func() {
+		requestPath := filepath.Join(GinkgoT().TempDir(), "request.json")
+		c := loadPlugin(fmt.Sprintf(`printf '%%s' "$input" > %s; printf '{"passed":true}'`, requestPath))
+
+		passed, err := c.Validate(context.TODO(), imgRef)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(passed).To(BeTrue())
+
+		b, err := os.ReadFile(requestPath)
+		Expect(err).ToNot(HaveOccurred())
+		var req Request
+		Expect(json.Unmarshal(b, &req)).To(Succeed())
+
+		digest, err := imgRef.ImageInfo.Digest()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(req.ProtocolVersion).To(Equal(ProtocolVersion))
+		Expect(req.Operation).To(Equal(OperationValidate))
+		Expect(req.Image).ToNot(BeNil())
+		Expect(req.Image.URI).To(Equal("quay.io/example/image:latest"))
+		Expect(req.Image.Registry).To(Equal("quay.io"))
+		Expect(req.Image.Repository).To(Equal("example/image"))
+		Expect(req.Image.TagOrDigest).To(Equal("latest"))
+		Expect(req.Image.Digest).To(Equal(digest.String()))
+		Expect(req.Image.FSPath).To(Equal("/tmp/fs"))
+		Expect(req.Image.Config.Config.User).To(Equal("1001"))
+		Expect(req.Image.Config.Config.Labels).To(HaveKeyWithValue("name", "example"))
+	})
+
+	It("should return the findings the plugin replies with", func() {
+		c := loadPlugin(`printf '{"passed":false,"findings":[{"message":"private key found","object":"/etc/ssl/key.pem","severity":"error"}]}'`)
+
+		passed, findings, err := c.ValidateWithFindings(context.TODO(), imgRef)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(passed).To(BeFalse())
+		Expect(findings).To(Equal([]check.Finding{{Message: "private key found", Object: "/etc/ssl/key.pem", Severity: check.SeverityError}}))
+	})
+
+	It("should skip the check if the plugin replies that it was skipped", func() {
+		c := loadPlugin(`printf '{"skipped":"no python packages installed"}'`)
+
+		_, _, err := c.ValidateWithFindings(context.TODO(), imgRef)
+		Expect(err).To(MatchError(check.ErrCheckSkipped))
+		Expect(err).To(MatchError(ContainSubstring("no python packages installed")))
+	})
+
+	It("should return an error if the plugin replies with one", func() {
+		c := loadPlugin(`printf '{"error":"could not open the rpmdb"}'`)
+
+		_, _, err := c.ValidateWithFindings(context.TODO(), imgRef)
+		Expect(err).To(MatchError(ContainSubstring("could not open the rpmdb")))
+	})
+
+	It("should return an error if the plugin fails", func() {
+		c := loadPlugin(`echo 'Traceback' >&2; exit 1`)
+
+		_, _, err := c.ValidateWithFindings(context.TODO(), imgRef)
+		Expect(err).To(MatchError(ContainSubstring("exit status 1: Traceback")))
+	})
+
+	It("should return an error if the plugin replies with invalid JSON", func() {
+		c := loadPlugin(`echo 'passed'`)
+
+		_, _, err := c.ValidateWithFindings(context.TODO(), imgRef)
+		Expect(err).To(MatchError(ContainSubstring("invalid validate response")))
+	})
+
+	It("should stop the plugin when the context is done", func() {
+		c := loadPlugin(`sleep 10`)
+
+		ctx, cancel := context.WithCancelCause(context.TODO())
+		cancel(check.ErrCheckTimedOut)
+		_, _, err := c.ValidateWithFindings(ctx, imgRef)
+		Expect(err).To(MatchError(check.ErrCheckTimedOut))
+	})
+
+	Context("when the image can not be read", func() {
+		var c *pluginCheck
+		BeforeEach(func() {
+			c = loadPlugin(`printf '{"passed":true}'`)
+		})
+		It("should return an error if the config can not be read", func() {
+			imgRef.ImageInfo = brokenImage{Image: imgRef.ImageInfo}
+			_, _, err := c.ValidateWithFindings(context.TODO(), imgRef)
+			Expect(err).To(MatchError(ContainSubstring("could not retrieve image config")))
+		})
+		It("should return an error if the digest can not be read", func() {
+			imgRef.ImageInfo = undigestedImage{Image: imgRef.ImageInfo}
+			_, _, err := c.ValidateWithFindings(context.TODO(), imgRef)
+			Expect(err).To(MatchError(ContainSubstring("could not retrieve image digest")))
+		})
+	})
+})
+
+// brokenImage fails to return its config.
+type brokenImage struct {
+	cranev1.Image
+}
+
+func (brokenImage) ConfigFile() (*cranev1.ConfigFile, error) {
+	return nil, errors.New("broken")
+}
+
+// undigestedImage fails to return its digest.
+type undigestedImage struct {
+	cranev1.Image
+}
+
+func (undigestedImage) Digest() (cranev1.Hash, error) {
+	return cranev1.Hash{}, errors.New("broken")
+}
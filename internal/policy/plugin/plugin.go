@@ -0,0 +1,161 @@
+// Package plugin runs checks implemented by executables outside of preflight.
+// Plugins are discovered on a plugin path and exchange a single JSON document
+// with preflight over stdin and stdout each time they are invoked.
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/go-logr/logr"
+	cranev1 "github.com/google/go-containerregistry/pkg/v1"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
+)
+
+// ProtocolVersion is the version of the protocol sent to plugins in every
+// request.
+const ProtocolVersion = "v1"
+
+// Operation is the action a plugin is asked to perform.
+type Operation string
+
+const (
+	// OperationDescribe asks the plugin to reply with a Description.
+	OperationDescribe Operation = "describe"
+	// OperationValidate asks the plugin to validate the image in the
+	// request and reply with a Result.
+	OperationValidate Operation = "validate"
+)
+
+// Request is written to a plugin's stdin.
+type Request struct {
+	ProtocolVersion string    `json:"protocolVersion"`
+	Operation       Operation `json:"operation"`
+	// Image is set for OperationValidate.
+	Image *Image `json:"image,omitempty"`
+}
+
+// Image describes the image under test.
+type Image struct {
+	// URI is the reference the image was pulled from.
+	URI                string `json:"uri"`
+	Registry           string `json:"registry"`
+	Repository         string `json:"repository"`
+	TagOrDigest        string `json:"tagOrDigest"`
+	Digest             string `json:"digest"`
+	ManifestListDigest string `json:"manifestListDigest,omitempty"`
+	// FSPath is the directory the files matching the plugin's required file
+	// patterns were extracted to.
+	FSPath string `json:"fsPath"`
+	// Config is the image's config file, as stored in the registry.
+	Config *cranev1.ConfigFile `json:"config"`
+}
+
+// Description is read from a plugin's stdout in reply to
+// OperationDescribe.
+type Description struct {
+	Name                 string         `json:"name"`
+	Metadata             check.Metadata `json:"metadata"`
+	Help                 check.HelpText `json:"help"`
+	RequiredFilePatterns []string       `json:"requiredFilePatterns,omitempty"`
+}
+
+// Result is read from a plugin's stdout in reply to OperationValidate.
+type Result struct {
+	Passed   bool            `json:"passed"`
+	Findings []check.Finding `json:"findings,omitempty"`
+	// Skipped is the reason the check could not be evaluated against the
+	// image. The check is reported as skipped when it is set.
+	Skipped string `json:"skipped,omitempty"`
+	// Error is set when the plugin failed to validate the image. The check
+	// is reported as errored when it is set.
+	Error string `json:"error,omitempty"`
+}
+
+// Discover returns a check for every executable in the directories of
+// pluginPath, which is separated by os.PathListSeparator. Each plugin is
+// invoked once to describe itself.
+func Discover(ctx context.Context, pluginPath string) ([]check.Check, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	var checks []check.Check
+	for _, dir := range filepath.SplitList(pluginPath) {
+		if dir == "" {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("could not read plugin directory: %w", err)
+		}
+
+		for _, entry := range entries {
+			info, err := entry.Info()
+			if err != nil {
+				//coverage:ignore
+				return nil, fmt.Errorf("could not stat plugin: %w", err)
+			}
+			if !info.Mode().IsRegular() || info.Mode().Perm()&0o111 == 0 {
+				logger.V(log.DBG).Info("ignoring non-executable file on plugin path", "path", filepath.Join(dir, entry.Name()))
+				continue
+			}
+
+			c, err := load(ctx, filepath.Join(dir, entry.Name()))
+			if err != nil {
+				return nil, err
+			}
+			if slices.ContainsFunc(checks, func(existing check.Check) bool { return existing.Name() == c.Name() }) {
+				return nil, fmt.Errorf("plugin %s: duplicate check name %q", c.executable, c.Name())
+			}
+			checks = append(checks, c)
+		}
+	}
+
+	return checks, nil
+}
+
+// Append discovers the plugins on pluginPath and appends their checks to
+// checks, marked with check.Custom. An error is returned if a plugin has the
+// same name as a check in checks.
+func Append(ctx context.Context, checks []check.Check, pluginPath string) ([]check.Check, error) {
+	plugins, err := Discover(ctx, pluginPath)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, p := range plugins {
+		if slices.ContainsFunc(checks, func(existing check.Check) bool { return existing.Name() == p.Name() }) {
+			return nil, fmt.Errorf("plugin check %q has the same name as a policy check", p.Name())
+		}
+		plugins[i] = check.Custom(p)
+	}
+
+	return append(slices.Clone(checks), plugins...), nil
+}
+
+// load describes the plugin at executable and returns its check.
+func load(ctx context.Context, executable string) (*pluginCheck, error) {
+	var desc Description
+	if err := invoke(ctx, executable, Request{ProtocolVersion: ProtocolVersion, Operation: OperationDescribe}, &desc); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(desc.Name) == "" {
+		return nil, fmt.Errorf("plugin %s: name is required", executable)
+	}
+
+	switch desc.Metadata.Level {
+	case "":
+		desc.Metadata.Level = check.LevelBest
+	case check.LevelBest, check.LevelWarn, check.LevelOptional:
+	default:
+		return nil, fmt.Errorf("plugin %s: level must be one of %s, %s or %s", executable, check.LevelBest, check.LevelWarn, check.LevelOptional)
+	}
+
+	return &pluginCheck{executable: executable, desc: desc}, nil
+}
@@ -0,0 +1,130 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+)
+
+// writePlugin writes a shell script plugin to dir that replies to describe
+// requests with description, and runs validate for any other request. The
+// request is available to validate as $input.
+func writePlugin(dir, name, description, validate string) string {
+	path := filepath.Join(dir, name)
+	script := fmt.Sprintf(`#!/bin/sh
+input=$(cat)
+case "$input" in
+*'"operation":"describe"'*)
+  printf '%%s' '%s'
+  ;;
+*)
+  %s
+  ;;
+esac
+`, description, validate)
+	Expect(os.WriteFile(path, []byte(script), 0o755)).To(Succeed())
+	return path
+}
+
+var _ = Describe("Plugin discovery", func() {
+	var dir string
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+	})
+
+	It("should describe every executable on the plugin path", func() {
+		writePlugin(dir, "b-plugin", `{"name":"HasNoSecrets","metadata":{"description":"Secrets are not present.","level":"warn"},"help":{"message":"Secrets found.","suggestion":"Remove them."},"requiredFilePatterns":["/etc/**"]}`, "")
+		writePlugin(dir, "a-plugin", `{"name":"HasTeamOwner"}`, "")
+		Expect(os.WriteFile(filepath.Join(dir, "README.md"), []byte("not a plugin"), 0o644)).To(Succeed())
+		Expect(os.Mkdir(filepath.Join(dir, "subdir"), 0o755)).To(Succeed())
+
+		checks, err := Discover(context.TODO(), dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(checks).To(HaveLen(2))
+
+		Expect(checks[0].Name()).To(Equal("HasTeamOwner"))
+		Expect(checks[0].Metadata().Level).To(Equal(check.LevelBest))
+		Expect(checks[0].RequiredFilePatterns()).To(BeEmpty())
+
+		Expect(checks[1].Name()).To(Equal("HasNoSecrets"))
+		Expect(checks[1].Metadata()).To(Equal(check.Metadata{Description: "Secrets are not present.", Level: check.LevelWarn}))
+		Expect(checks[1].Help()).To(Equal(check.HelpText{Message: "Secrets found.", Suggestion: "Remove them."}))
+		Expect(checks[1].RequiredFilePatterns()).To(Equal([]string{"/etc/**"}))
+	})
+
+	It("should search every directory on the plugin path", func() {
+		other := GinkgoT().TempDir()
+		writePlugin(dir, "a", `{"name":"A"}`, "")
+		writePlugin(other, "b", `{"name":"B"}`, "")
+
+		checks, err := Discover(context.TODO(), strings.Join([]string{dir, "", other}, string(os.PathListSeparator)))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(checks).To(HaveLen(2))
+		Expect(checks[1].Name()).To(Equal("B"))
+	})
+
+	It("should send the protocol version and operation", func() {
+		requestPath := filepath.Join(GinkgoT().TempDir(), "request.json")
+		Expect(os.WriteFile(filepath.Join(dir, "a"), []byte(fmt.Sprintf("#!/bin/sh\ncat > %s\nprintf '{\"name\":\"A\"}'\n", requestPath)), 0o755)).To(Succeed())
+
+		_, err := Discover(context.TODO(), dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.ReadFile(requestPath)).To(MatchJSON(`{"protocolVersion":"v1","operation":"describe"}`))
+	})
+
+	DescribeTable("when a plugin can not be described",
+		func(script string, expected string) {
+			Expect(os.WriteFile(filepath.Join(dir, "plugin"), []byte("#!/bin/sh\ncat > /dev/null\n"+script), 0o755)).To(Succeed())
+			_, err := Discover(context.TODO(), dir)
+			Expect(err).To(MatchError(ContainSubstring(expected)))
+		},
+		Entry("exits with an error", "echo 'something broke' >&2\nexit 3\n", "exit status 3: something broke"),
+		Entry("exits with an error silently", "exit 3\n", "failed: exit status 3"),
+		Entry("replies with invalid JSON", "echo 'not json'\n", "invalid describe response"),
+		Entry("replies without a name", "echo '{}'\n", "name is required"),
+		Entry("replies with an invalid level", `echo '{"name":"A","metadata":{"level":"must"}}'`+"\n", "level must be one of"),
+	)
+
+	It("should refuse plugins with the same name", func() {
+		writePlugin(dir, "a", `{"name":"A"}`, "")
+		writePlugin(dir, "b", `{"name":"A"}`, "")
+		_, err := Discover(context.TODO(), dir)
+		Expect(err).To(MatchError(ContainSubstring(`duplicate check name "A"`)))
+	})
+
+	It("should fail if a plugin directory can not be read", func() {
+		_, err := Discover(context.TODO(), filepath.Join(dir, "missing"))
+		Expect(err).To(MatchError(ContainSubstring("could not read plugin directory")))
+	})
+
+	Context("when appending plugin checks to a policy", func() {
+		BeforeEach(func() {
+			writePlugin(dir, "a", `{"name":"HasTeamOwner"}`, "")
+		})
+		It("should append the plugin checks", func() {
+			policy := []check.Check{check.NewGenericCheck("HasLicense", nil, check.Metadata{}, check.HelpText{}, nil)}
+			checks, err := Append(context.TODO(), policy, dir)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(checks).To(HaveLen(2))
+			Expect(checks[1].Name()).To(Equal("HasTeamOwner"))
+			Expect(check.IsCustom(checks[1])).To(BeTrue())
+			Expect(policy).To(HaveLen(1))
+		})
+		It("should refuse a plugin with the name of a policy check", func() {
+			policy := []check.Check{check.NewGenericCheck("HasTeamOwner", nil, check.Metadata{}, check.HelpText{}, nil)}
+			_, err := Append(context.TODO(), policy, dir)
+			Expect(err).To(MatchError(ContainSubstring("same name as a policy check")))
+		})
+		It("should fail if the plugins can not be discovered", func() {
+			_, err := Append(context.TODO(), nil, filepath.Join(dir, "missing"))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
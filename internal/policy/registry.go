@@ -0,0 +1,250 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// Kind is the type of asset a policy's checks are executed against.
+type Kind string
+
+const (
+	KindContainer Kind = "container"
+	KindOperator  Kind = "operator"
+)
+
+// Definition declares a policy as a named composition of checks. A policy
+// may inherit the checks of another policy, remove some of them with
+// Exclude, and add its own with Checks.
+type Definition struct {
+	// Name is the name the policy is selected by.
+	Name Policy
+	// Description is shown when the policy is listed.
+	Description string
+	// Kind is the type of asset the policy applies to. It may be omitted
+	// when Inherits is set, in which case it is inherited.
+	Kind Kind
+	// Inherits is the name of the policy whose checks are inherited.
+	Inherits Policy
+	// Checks are the names of the checks added to the policy, after the
+	// inherited checks.
+	Checks []string
+	// Exclude are the names of inherited checks that are removed from the
+	// policy.
+	Exclude []string
+}
+
+// Exception describes the exceptions granted to a certification project,
+// which determine the container policy its images are checked against.
+type Exception struct {
+	// Scratch is set for projects that certify scratch images.
+	Scratch bool
+	// Privileged is set for projects whose containers require host level
+	// access.
+	Privileged bool
+}
+
+// Registry resolves the checks of policies by name.
+type Registry struct {
+	definitions map[Policy]Definition
+	order       []Policy
+	exceptions  map[Exception]Policy
+}
+
+var defaultRegistry = mustNewRegistry(
+	Definition{
+		Name:        PolicyOperator,
+		Description: "invoked on operator bundles",
+		Kind:        KindOperator,
+		Checks: []string{
+			"ScorecardBasicSpecCheck",
+			"ScorecardOlmSuiteCheck",
+			"DeployableByOLM",
+			"ValidateOperatorBundle",
+			"BundleImageRefsAreCertified",
+			"SecurityContextConstraintsInCSV",
+			"AllImageRefsInRelatedImages",
+			"FollowsRestrictedNetworkEnablementGuidelines",
+			"RequiredAnnotations",
+		},
+	},
+	Definition{
+		Name:        PolicyContainer,
+		Description: "invoked on container images",
+		Kind:        KindContainer,
+		Checks: []string{
+			"HasLicense",
+			"HasUniqueTag",
+			"LayerCountAcceptable",
+			"HasNoProhibitedPackages",
+			"HasRequiredLabel",
+			"HasNoProhibitedLabels",
+			"RunAsNonRoot",
+			"HasModifiedFiles",
+			"BasedOnUbi",
+			"HasProhibitedContainerName",
+		},
+	},
+	Definition{
+		Name:        PolicyRoot,
+		Description: "automatically applied for container images if preflight determines a root exception flag has been added to your Red Hat Connect project",
+		Inherits:    PolicyContainer,
+		Exclude:     []string{"RunAsNonRoot"},
+	},
+	Definition{
+		Name:        PolicyScratchNonRoot,
+		Description: "automatically applied for container checks if preflight determines a scratch exception flag has been added to your Red Hat Connect project",
+		Inherits:    PolicyContainer,
+		Exclude:     []string{"HasNoProhibitedPackages", "HasModifiedFiles", "BasedOnUbi"},
+	},
+	Definition{
+		Name:        PolicyScratchRoot,
+		Description: "automatically applied for container checks if preflight determines scratch and root exception flags have both been added to your Red Hat Connect project",
+		Inherits:    PolicyScratchNonRoot,
+		Exclude:     []string{"RunAsNonRoot"},
+	},
+	Definition{
+		Name:        PolicyKonflux,
+		Description: "invoked on container images built in a Konflux pipeline",
+		Inherits:    PolicyContainer,
+		Exclude:     []string{"HasNoProhibitedLabels", "HasProhibitedContainerName"},
+	},
+).withExceptions(map[Exception]Policy{
+	{}:                                PolicyContainer,
+	{Privileged: true}:                PolicyRoot,
+	{Scratch: true}:                   PolicyScratchNonRoot,
+	{Scratch: true, Privileged: true}: PolicyScratchRoot,
+})
+
+// DefaultRegistry returns the registry of Red Hat's policies.
+func DefaultRegistry() *Registry {
+	return defaultRegistry
+}
+
+func mustNewRegistry(definitions ...Definition) *Registry {
+	r, err := (&Registry{}).With(definitions...)
+	if err != nil {
+		//coverage:ignore
+		panic(err)
+	}
+	return r
+}
+
+func (r *Registry) withExceptions(exceptions map[Exception]Policy) *Registry {
+	r.exceptions = exceptions
+	return r
+}
+
+// With returns a copy of r that additionally contains definitions. An error
+// is returned if a definition is invalid, or redefines an existing policy.
+func (r *Registry) With(definitions ...Definition) (*Registry, error) {
+	next := &Registry{
+		definitions: maps.Clone(r.definitions),
+		order:       slices.Clone(r.order),
+		exceptions:  r.exceptions,
+	}
+	if next.definitions == nil {
+		next.definitions = make(map[Policy]Definition, len(definitions))
+	}
+
+	for _, d := range definitions {
+		if d.Name == "" {
+			return nil, errors.New("policy name is required")
+		}
+		if _, ok := next.definitions[d.Name]; ok {
+			return nil, fmt.Errorf("policy %q is already defined", d.Name)
+		}
+		if d.Inherits == "" && d.Kind == "" {
+			return nil, fmt.Errorf("policy %q: kind is required when no policy is inherited", d.Name)
+		}
+		if d.Kind != "" && d.Kind != KindContainer && d.Kind != KindOperator {
+			return nil, fmt.Errorf("policy %q: kind must be one of %s or %s", d.Name, KindContainer, KindOperator)
+		}
+		next.definitions[d.Name] = d
+		next.order = append(next.order, d.Name)
+	}
+
+	// Resolve every new policy now, so that errors surface when the
+	// policies are defined rather than when they are used.
+	for _, d := range definitions {
+		if _, _, err := next.resolve(d.Name, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return next, nil
+}
+
+// Definitions returns the definitions in the registry, in the order they
+// were defined.
+func (r *Registry) Definitions() []Definition {
+	definitions := make([]Definition, 0, len(r.order))
+	for _, p := range r.order {
+		definitions = append(definitions, r.definitions[p])
+	}
+	return definitions
+}
+
+// Kind returns the kind of policy p. An error is returned if p is unknown.
+func (r *Registry) Kind(p Policy) (Kind, error) {
+	kind, _, err := r.resolve(p, nil)
+	return kind, err
+}
+
+// Checks returns the names of the checks in policy p, in order. An error is
+// returned if p is unknown.
+func (r *Registry) Checks(p Policy) ([]string, error) {
+	_, checks, err := r.resolve(p, nil)
+	return checks, err
+}
+
+// ForException returns the container policy applied to a project that was
+// granted e.
+func (r *Registry) ForException(e Exception) Policy {
+	return r.exceptions[e]
+}
+
+// resolve returns the kind and checks of policy p. seen contains the
+// policies that inherit p, to detect cycles.
+func (r *Registry) resolve(p Policy, seen []Policy) (Kind, []string, error) {
+	if slices.Contains(seen, p) {
+		return "", nil, fmt.Errorf("policy %q inherits itself", p)
+	}
+
+	d, ok := r.definitions[p]
+	if !ok {
+		return "", nil, fmt.Errorf("policy %q is unknown", p)
+	}
+
+	kind := d.Kind
+	var checks []string
+	if d.Inherits != "" {
+		inheritedKind, inherited, err := r.resolve(d.Inherits, append(seen, p))
+		if err != nil {
+			return "", nil, err
+		}
+		if kind != "" && kind != inheritedKind {
+			return "", nil, fmt.Errorf("policy %q is a %s policy, but inherits %s policy %q", p, kind, inheritedKind, d.Inherits)
+		}
+		kind = inheritedKind
+		checks = inherited
+	}
+
+	for _, name := range d.Exclude {
+		if !slices.Contains(checks, name) {
+			return "", nil, fmt.Errorf("policy %q excludes check %q, which it does not inherit", p, name)
+		}
+	}
+	checks = slices.DeleteFunc(slices.Clone(checks), func(name string) bool { return slices.Contains(d.Exclude, name) })
+
+	for _, name := range d.Checks {
+		if slices.Contains(checks, name) {
+			return "", nil, fmt.Errorf("policy %q includes check %q more than once", p, name)
+		}
+		checks = append(checks, name)
+	}
+
+	return kind, checks, nil
+}
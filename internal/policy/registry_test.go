@@ -0,0 +1,128 @@
+package policy
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Policy registry", func() {
+	Context("with Red Hat's policies", func() {
+		DescribeTable("should resolve the checks of inherited policies",
+			func(p Policy, kind Kind, expected []string) {
+				k, err := DefaultRegistry().Kind(p)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(k).To(Equal(kind))
+				checks, err := DefaultRegistry().Checks(p)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(checks).To(Equal(expected))
+			},
+			Entry("root", PolicyRoot, KindContainer, []string{
+				"HasLicense",
+				"HasUniqueTag",
+				"LayerCountAcceptable",
+				"HasNoProhibitedPackages",
+				"HasRequiredLabel",
+				"HasNoProhibitedLabels",
+				"HasModifiedFiles",
+				"BasedOnUbi",
+				"HasProhibitedContainerName",
+			}),
+			Entry("scratch root", PolicyScratchRoot, KindContainer, []string{
+				"HasLicense",
+				"HasUniqueTag",
+				"LayerCountAcceptable",
+				"HasRequiredLabel",
+				"HasNoProhibitedLabels",
+				"HasProhibitedContainerName",
+			}),
+			Entry("operator", PolicyOperator, KindOperator, []string{
+				"ScorecardBasicSpecCheck",
+				"ScorecardOlmSuiteCheck",
+				"DeployableByOLM",
+				"ValidateOperatorBundle",
+				"BundleImageRefsAreCertified",
+				"SecurityContextConstraintsInCSV",
+				"AllImageRefsInRelatedImages",
+				"FollowsRestrictedNetworkEnablementGuidelines",
+				"RequiredAnnotations",
+			}),
+		)
+
+		It("should list the policies in the order they were defined", func() {
+			names := []Policy{}
+			for _, d := range DefaultRegistry().Definitions() {
+				names = append(names, d.Name)
+			}
+			Expect(names).To(Equal([]Policy{PolicyOperator, PolicyContainer, PolicyRoot, PolicyScratchNonRoot, PolicyScratchRoot, PolicyKonflux}))
+		})
+
+		DescribeTable("should resolve the policy for a project's exceptions",
+			func(e Exception, expected Policy) {
+				Expect(DefaultRegistry().ForException(e)).To(Equal(expected))
+			},
+			Entry("no exceptions", Exception{}, PolicyContainer),
+			Entry("privileged", Exception{Privileged: true}, PolicyRoot),
+			Entry("scratch", Exception{Scratch: true}, PolicyScratchNonRoot),
+			Entry("scratch and privileged", Exception{Scratch: true, Privileged: true}, PolicyScratchRoot),
+		)
+
+		It("should fail for unknown policies", func() {
+			_, err := DefaultRegistry().Checks("foo")
+			Expect(err).To(MatchError(ContainSubstring(`policy "foo" is unknown`)))
+			_, err = DefaultRegistry().Kind("foo")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("with user-defined policies", func() {
+		It("should resolve policies that inherit Red Hat's and each other", func() {
+			r, err := DefaultRegistry().With(
+				Definition{Name: "strict", Inherits: "relaxed", Checks: []string{"HasTeamLabel"}},
+				Definition{Name: "relaxed", Inherits: PolicyContainer, Exclude: []string{"RunAsNonRoot", "BasedOnUbi"}},
+			)
+			Expect(err).ToNot(HaveOccurred())
+
+			checks, err := r.Checks("strict")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(checks).To(Equal([]string{
+				"HasLicense",
+				"HasUniqueTag",
+				"LayerCountAcceptable",
+				"HasNoProhibitedPackages",
+				"HasRequiredLabel",
+				"HasNoProhibitedLabels",
+				"HasModifiedFiles",
+				"HasProhibitedContainerName",
+				"HasTeamLabel",
+			}))
+			Expect(r.Kind("strict")).To(Equal(KindContainer))
+			Expect(r.Definitions()).To(HaveLen(8))
+		})
+
+		It("should not change the registry they are added to", func() {
+			_, err := DefaultRegistry().With(Definition{Name: "mine", Kind: KindContainer, Checks: []string{"HasLicense"}})
+			Expect(err).ToNot(HaveOccurred())
+			_, err = DefaultRegistry().Checks("mine")
+			Expect(err).To(HaveOccurred())
+		})
+
+		DescribeTable("should refuse invalid policies",
+			func(expected string, definitions ...Definition) {
+				_, err := DefaultRegistry().With(definitions...)
+				Expect(err).To(MatchError(ContainSubstring(expected)))
+			},
+			Entry("without a name", "policy name is required", Definition{Kind: KindContainer}),
+			Entry("redefining a policy", `policy "container" is already defined`, Definition{Name: PolicyContainer, Kind: KindContainer}),
+			Entry("without a kind", "kind is required", Definition{Name: "mine", Checks: []string{"HasLicense"}}),
+			Entry("with an unknown kind", "kind must be one of", Definition{Name: "mine", Kind: "helm"}),
+			Entry("inheriting an unknown policy", `policy "missing" is unknown`, Definition{Name: "mine", Inherits: "missing"}),
+			Entry("inheriting itself", "inherits itself",
+				Definition{Name: "a", Inherits: "b"},
+				Definition{Name: "b", Inherits: "a"},
+			),
+			Entry("of a different kind than it inherits", "is a operator policy, but inherits container policy", Definition{Name: "mine", Kind: KindOperator, Inherits: PolicyContainer}),
+			Entry("excluding a check it does not inherit", `excludes check "DeployableByOLM"`, Definition{Name: "mine", Inherits: PolicyContainer, Exclude: []string{"DeployableByOLM"}}),
+			Entry("including a check twice", `includes check "HasLicense" more than once`, Definition{Name: "mine", Inherits: PolicyContainer, Checks: []string{"HasLicense"}}),
+		)
+	})
+})
@@ -0,0 +1,59 @@
+package provenance
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/signature"
+)
+
+// envelope is a DSSE envelope, as cosign attaches attestations in.
+type envelope struct {
+	PayloadType string              `json:"payloadType"`
+	Payload     string              `json:"payload"`
+	Signatures  []envelopeSignature `json:"signatures"`
+}
+
+type envelopeSignature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+// pae returns the pre-authentication encoding of a DSSE payload, which is
+// what is signed.
+func pae(payloadType string, payload []byte) []byte {
+	return fmt.Appendf(nil, "DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload)
+}
+
+// verifyEnvelope returns the payload of the DSSE envelope b, and the key in
+// keys that signed it.
+func verifyEnvelope(b []byte, keys signature.Keyring) ([]byte, signature.Key, error) {
+	var env envelope
+	if err := json.Unmarshal(b, &env); err != nil {
+		return nil, signature.Key{}, fmt.Errorf("could not parse the DSSE envelope: %w", err)
+	}
+	if env.PayloadType != inTotoPayloadType {
+		return nil, signature.Key{}, fmt.Errorf("the payload is a %q, not a %q", env.PayloadType, inTotoPayloadType)
+	}
+	payload, err := base64.StdEncoding.DecodeString(env.Payload)
+	if err != nil {
+		return nil, signature.Key{}, fmt.Errorf("the payload is not base64 encoded: %w", err)
+	}
+	if len(env.Signatures) == 0 {
+		return nil, signature.Key{}, errors.New("the envelope has no signatures")
+	}
+
+	message := pae(env.PayloadType, payload)
+	for _, s := range env.Signatures {
+		sig, err := base64.StdEncoding.DecodeString(s.Sig)
+		if err != nil {
+			continue
+		}
+		if key, ok := keys.Verify(message, sig); ok {
+			return payload, key, nil
+		}
+	}
+	return nil, signature.Key{}, errors.New("the envelope was not signed by a trusted key")
+}
@@ -0,0 +1,344 @@
+// Package provenance verifies the SLSA provenance cosign attaches to an image
+// as in-toto attestations. Attestations are read from the registry the image
+// is in, and their DSSE envelopes are verified against public keys supplied
+// by the user, without consulting a transparency log.
+package provenance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/signature"
+)
+
+const (
+	// DSSEMediaType is the media type of the layers of a cosign attestation
+	// manifest. Each is a DSSE envelope.
+	DSSEMediaType = "application/vnd.dsse.envelope.v1+json"
+	// AttestationArtifactType is the artifact type of attestations attached
+	// with the OCI referrers API.
+	AttestationArtifactType = "application/vnd.dev.cosign.artifact.att.v1+json"
+	// AttestationTagSuffix is the suffix of the tag attestations are
+	// attached with.
+	AttestationTagSuffix = ".att"
+
+	// The versions of SLSA provenance that are understood.
+	PredicateSLSAv02 = "https://slsa.dev/provenance/v0.2"
+	PredicateSLSAv1  = "https://slsa.dev/provenance/v1"
+
+	inTotoPayloadType = "application/vnd.in-toto+json"
+)
+
+// Attestation is a provenance attestation of an image.
+type Attestation struct {
+	// Source is the manifest the attestation was read from.
+	Source   string `json:"source"`
+	Verified bool   `json:"verified"`
+	// Reason explains why an attestation is not verified.
+	Reason string `json:"reason,omitempty"`
+	// Signer and KeyFingerprint identify the key that signed the envelope.
+	Signer         string      `json:"signer,omitempty"`
+	KeyFingerprint string      `json:"key_fingerprint,omitempty"`
+	PredicateType  string      `json:"predicate_type,omitempty"`
+	Provenance     *Provenance `json:"provenance,omitempty"`
+}
+
+// Provenance summarizes how an image was built, in terms common to each
+// version of SLSA provenance.
+type Provenance struct {
+	BuilderID string `json:"builder_id"`
+	BuildType string `json:"build_type,omitempty"`
+	// SourceRepository is the git repository the image was built from,
+	// e.g. https://github.com/org/app, and SourceRevision the commit.
+	SourceRepository string `json:"source_repository,omitempty"`
+	SourceRevision   string `json:"source_revision,omitempty"`
+	// Hermetic is nil if the provenance does not record whether the build
+	// was hermetic, i.e. had no network access.
+	Hermetic   *bool  `json:"hermetic,omitempty"`
+	StartedOn  string `json:"started_on,omitempty"`
+	FinishedOn string `json:"finished_on,omitempty"`
+	// Materials is the number of materials, or resolved dependencies, the
+	// build used.
+	Materials int `json:"materials"`
+}
+
+// String returns a one line summary of p.
+func (p Provenance) String() string {
+	hermetic := "unknown"
+	if p.Hermetic != nil {
+		hermetic = strconv.FormatBool(*p.Hermetic)
+	}
+	source := p.SourceRepository
+	if source == "" {
+		source = "unknown"
+	}
+	if p.SourceRevision != "" {
+		source += "@" + p.SourceRevision
+	}
+	s := fmt.Sprintf("built by %s from %s, hermetic: %s, %d materials", p.BuilderID, source, hermetic, p.Materials)
+	if p.BuildType != "" {
+		s += ", build type " + p.BuildType
+	}
+	if p.FinishedOn != "" {
+		s += ", finished " + p.FinishedOn
+	}
+	return s
+}
+
+// statement is an in-toto statement.
+type statement struct {
+	Type          string          `json:"_type"`
+	Subject       []subject       `json:"subject"`
+	PredicateType string          `json:"predicateType"`
+	Predicate     json.RawMessage `json:"predicate"`
+}
+
+type subject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// Verify returns the provenance attestations of the image with digest in
+// repo, each verified against keys. Attestations of other predicate types,
+// such as SBOMs, are not returned once their envelope is verified.
+func Verify(ctx context.Context, repo name.Repository, digest v1.Hash, keys signature.Keyring, opts ...remote.Option) ([]Attestation, error) {
+	artifacts, err := signature.Artifacts(ctx, repo, digest, AttestationTagSuffix, AttestationArtifactType, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var attestations []Attestation
+	for _, artifact := range artifacts {
+		manifest, err := artifact.Image.Manifest()
+		if err != nil {
+			//coverage:ignore
+			return nil, fmt.Errorf("could not read %s: %w", artifact.Source, err)
+		}
+		for _, layer := range manifest.Layers {
+			if layer.MediaType != DSSEMediaType {
+				continue
+			}
+			a, ok := verifyLayer(artifact.Image, layer, digest, keys)
+			if !ok {
+				continue
+			}
+			a.Source = artifact.Source
+			attestations = append(attestations, a)
+		}
+	}
+	return attestations, nil
+}
+
+// verifyLayer verifies the attestation in layer of img, and returns false if
+// it is not provenance.
+func verifyLayer(img v1.Image, layer v1.Descriptor, digest v1.Hash, keys signature.Keyring) (Attestation, bool) {
+	b, err := signature.ReadLayer(img, layer)
+	if err != nil {
+		return Attestation{Reason: fmt.Sprintf("could not read the envelope: %v", err)}, true
+	}
+	payload, key, err := verifyEnvelope(b, keys)
+	if err != nil {
+		return Attestation{Reason: err.Error()}, true
+	}
+
+	// The statement is only trusted once its envelope is verified.
+	a := Attestation{Signer: key.Signer(), KeyFingerprint: key.Fingerprint}
+	var st statement
+	if err := json.Unmarshal(payload, &st); err != nil {
+		a.Reason = fmt.Sprintf("could not parse the statement: %v", err)
+		return a, true
+	}
+	a.PredicateType = st.PredicateType
+
+	var p *Provenance
+	switch st.PredicateType {
+	case PredicateSLSAv02:
+		p, err = parseV02(st.Predicate)
+	case PredicateSLSAv1:
+		p, err = parseV1(st.Predicate)
+	default:
+		return a, false
+	}
+	if err != nil {
+		a.Reason = fmt.Sprintf("could not parse the predicate: %v", err)
+		return a, true
+	}
+	a.Provenance = p
+
+	if !hasSubject(st.Subject, digest) {
+		a.Reason = fmt.Sprintf("the statement is not about %s", digest)
+		return a, true
+	}
+	a.Verified = true
+	return a, true
+}
+
+// hasSubject returns true if subjects include the artifact with digest.
+func hasSubject(subjects []subject, digest v1.Hash) bool {
+	for _, s := range subjects {
+		if s.Digest[digest.Algorithm] == digest.Hex {
+			return true
+		}
+	}
+	return false
+}
+
+// predicateV02 is the subset of a SLSA v0.2 provenance predicate that is
+// summarized.
+type predicateV02 struct {
+	Builder struct {
+		ID string `json:"id"`
+	} `json:"builder"`
+	BuildType  string `json:"buildType"`
+	Invocation struct {
+		ConfigSource material `json:"configSource"`
+		Parameters   any      `json:"parameters"`
+	} `json:"invocation"`
+	Metadata struct {
+		BuildStartedOn  string `json:"buildStartedOn"`
+		BuildFinishedOn string `json:"buildFinishedOn"`
+	} `json:"metadata"`
+	Materials []material `json:"materials"`
+}
+
+// material is a material of a SLSA v0.2 build, or a resolved dependency of a
+// SLSA v1 build.
+type material struct {
+	URI    string            `json:"uri"`
+	Digest map[string]string `json:"digest"`
+}
+
+func parseV02(raw json.RawMessage) (*Provenance, error) {
+	var pred predicateV02
+	if err := json.Unmarshal(raw, &pred); err != nil {
+		return nil, err
+	}
+	p := &Provenance{
+		BuilderID:  pred.Builder.ID,
+		BuildType:  pred.BuildType,
+		Hermetic:   hermetic(pred.Invocation.Parameters),
+		StartedOn:  pred.Metadata.BuildStartedOn,
+		FinishedOn: pred.Metadata.BuildFinishedOn,
+		Materials:  len(pred.Materials),
+	}
+	p.SourceRepository, p.SourceRevision = source(append(pred.Materials, pred.Invocation.ConfigSource))
+	return p, nil
+}
+
+// predicateV1 is the subset of a SLSA v1 provenance predicate that is
+// summarized.
+type predicateV1 struct {
+	BuildDefinition struct {
+		BuildType            string     `json:"buildType"`
+		ExternalParameters   any        `json:"externalParameters"`
+		ResolvedDependencies []material `json:"resolvedDependencies"`
+	} `json:"buildDefinition"`
+	RunDetails struct {
+		Builder struct {
+			ID string `json:"id"`
+		} `json:"builder"`
+		Metadata struct {
+			StartedOn  string `json:"startedOn"`
+			FinishedOn string `json:"finishedOn"`
+		} `json:"metadata"`
+	} `json:"runDetails"`
+}
+
+func parseV1(raw json.RawMessage) (*Provenance, error) {
+	var pred predicateV1
+	if err := json.Unmarshal(raw, &pred); err != nil {
+		return nil, err
+	}
+	p := &Provenance{
+		BuilderID:  pred.RunDetails.Builder.ID,
+		BuildType:  pred.BuildDefinition.BuildType,
+		Hermetic:   hermetic(pred.BuildDefinition.ExternalParameters),
+		StartedOn:  pred.RunDetails.Metadata.StartedOn,
+		FinishedOn: pred.RunDetails.Metadata.FinishedOn,
+		Materials:  len(pred.BuildDefinition.ResolvedDependencies),
+	}
+	p.SourceRepository, p.SourceRevision = source(pred.BuildDefinition.ResolvedDependencies)
+	return p, nil
+}
+
+// source returns the repository and commit of the first git material, e.g.
+// git+https://github.com/org/app.git@refs/heads/main with a sha1 digest.
+func source(materials []material) (string, string) {
+	for _, m := range materials {
+		uri, ok := strings.CutPrefix(m.URI, "git+")
+		if !ok {
+			continue
+		}
+		revision := m.Digest["sha1"]
+		if revision == "" {
+			revision = m.Digest["gitCommit"]
+		}
+		// The revision may follow the repository path, e.g. app.git@main.
+		if scheme, rest, ok := strings.Cut(uri, "://"); ok {
+			if host, repoPath, ok := strings.Cut(rest, "/"); ok {
+				repoPath, rev, hasRev := strings.Cut(repoPath, "@")
+				if hasRev && revision == "" {
+					revision = rev
+				}
+				uri = scheme + "://" + host + "/" + repoPath
+			}
+		}
+		return strings.TrimSuffix(uri, ".git"), revision
+	}
+	return "", ""
+}
+
+// hermetic returns the value of the "hermetic" parameter in params, or nil if
+// there is none. Parameters may be nested, and be a map of names to values,
+// or a list of name and value pairs, as Tekton records them.
+func hermetic(params any) *bool {
+	var found *bool
+	var walk func(v any)
+	walk = func(v any) {
+		if found != nil {
+			return
+		}
+		switch v := v.(type) {
+		case map[string]any:
+			if name, ok := v["name"].(string); ok && name == "hermetic" {
+				found = parseBool(v["value"])
+				return
+			}
+			if value, ok := v["hermetic"]; ok {
+				found = parseBool(value)
+				return
+			}
+			for _, k := range slices.Sorted(maps.Keys(v)) {
+				walk(v[k])
+			}
+		case []any:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	walk(params)
+	return found
+}
+
+// parseBool returns the boolean v is, or represents as a string.
+func parseBool(v any) *bool {
+	switch v := v.(type) {
+	case bool:
+		return &v
+	case string:
+		if b, err := strconv.ParseBool(v); err == nil {
+			return &b
+		}
+	}
+	return nil
+}
@@ -0,0 +1,301 @@
+package provenance
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/signature"
+)
+
+// inTotoStatement returns a statement about the image with digest, with the
+// predicate in the testdata file named predicateFile.
+func inTotoStatement(digest v1.Hash, predicateType string, predicateFile string) []byte {
+	predicate, err := os.ReadFile("testdata/" + predicateFile)
+	Expect(err).ToNot(HaveOccurred())
+	b, err := json.Marshal(statement{
+		Type:          "https://in-toto.io/Statement/v0.1",
+		Subject:       []subject{{Name: "quay.io/org/app", Digest: map[string]string{digest.Algorithm: digest.Hex}}},
+		PredicateType: predicateType,
+		Predicate:     predicate,
+	})
+	Expect(err).ToNot(HaveOccurred())
+	return b
+}
+
+// dsseEnvelope returns payload in a DSSE envelope signed with priv.
+func dsseEnvelope(priv *ecdsa.PrivateKey, payloadType string, payload []byte) []byte {
+	hash := sha256.Sum256(pae(payloadType, payload))
+	sig, err := ecdsa.SignASN1(rand.Reader, priv, hash[:])
+	Expect(err).ToNot(HaveOccurred())
+	b, err := json.Marshal(envelope{
+		PayloadType: payloadType,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+		Signatures:  []envelopeSignature{{Sig: base64.StdEncoding.EncodeToString(sig)}},
+	})
+	Expect(err).ToNot(HaveOccurred())
+	return b
+}
+
+// attestationImage returns a cosign attestation manifest with a DSSE layer
+// for each of envelopes.
+func attestationImage(envelopes ...[]byte) v1.Image {
+	img := mutate.MediaType(empty.Image, types.OCIManifestSchema1)
+	for _, e := range envelopes {
+		var err error
+		img, err = mutate.AppendLayers(img, static.NewLayer(e, DSSEMediaType))
+		Expect(err).ToNot(HaveOccurred())
+	}
+	return img
+}
+
+var _ = Describe("Verify", func() {
+	var (
+		ctx    context.Context
+		repo   name.Repository
+		digest v1.Hash
+		priv   *ecdsa.PrivateKey
+		keys   signature.Keyring
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		s := httptest.NewServer(registry.New(
+			registry.Logger(log.New(io.Discard, "", 0)),
+			registry.WithReferrersSupport(true),
+		))
+		DeferCleanup(s.Close)
+		u, err := url.Parse(s.URL)
+		Expect(err).ToNot(HaveOccurred())
+
+		repo, err = name.NewRepository(u.Host + "/org/app")
+		Expect(err).ToNot(HaveOccurred())
+		img, err := random.Image(1024, 1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(remote.Write(repo.Tag("1.0"), img)).To(Succeed())
+		digest, err = img.Digest()
+		Expect(err).ToNot(HaveOccurred())
+
+		priv, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).ToNot(HaveOccurred())
+		der, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+		Expect(err).ToNot(HaveOccurred())
+		keys, err = signature.ParseKeyring(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	attestationTag := func() name.Tag {
+		return repo.Tag(strings.Replace(digest.String(), ":", "-", 1) + AttestationTagSuffix)
+	}
+
+	It("should find no attestations of an image without any", func() {
+		attestations, err := Verify(ctx, repo, digest, keys)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(attestations).To(BeEmpty())
+	})
+
+	It("should fail when the attestations can not be read", func() {
+		forbidden := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		DeferCleanup(forbidden.Close)
+		u, err := url.Parse(forbidden.URL)
+		Expect(err).ToNot(HaveOccurred())
+		other, err := name.NewRepository(u.Host + "/org/app")
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = Verify(ctx, other, digest, keys)
+		Expect(err).To(MatchError(ContainSubstring("could not read")))
+	})
+
+	It("should verify SLSA v0.2 provenance attached with a tag", func() {
+		hermetic := true
+		env := dsseEnvelope(priv, inTotoPayloadType, inTotoStatement(digest, PredicateSLSAv02, "slsa-v0.2.json"))
+		Expect(remote.Write(attestationTag(), attestationImage(env))).To(Succeed())
+
+		attestations, err := Verify(ctx, repo, digest, keys)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(attestations).To(Equal([]Attestation{{
+			Source:         attestationTag().String(),
+			Verified:       true,
+			Signer:         keys[0].Fingerprint,
+			KeyFingerprint: keys[0].Fingerprint,
+			PredicateType:  PredicateSLSAv02,
+			Provenance: &Provenance{
+				BuilderID:        "https://tekton.dev/chains/v2",
+				BuildType:        "tekton.dev/v1beta1/PipelineRun",
+				SourceRepository: "https://github.com/org/app",
+				SourceRevision:   "9f1c1d0e2b3a4c5d6e7f8a9b0c1d2e3f4a5b6c7d",
+				Hermetic:         &hermetic,
+				StartedOn:        "2024-05-01T16:00:00Z",
+				FinishedOn:       "2024-05-01T16:12:30Z",
+				Materials:        2,
+			},
+		}}))
+	})
+
+	It("should verify SLSA v1 provenance attached as a referrer", func() {
+		hermetic := false
+		subject, err := remote.Head(repo.Digest(digest.String()))
+		Expect(err).ToNot(HaveOccurred())
+		env := dsseEnvelope(priv, inTotoPayloadType, inTotoStatement(digest, PredicateSLSAv1, "slsa-v1.json"))
+		att := mutate.Subject(mutate.ConfigMediaType(attestationImage(env), AttestationArtifactType), *subject).(v1.Image)
+		attDigest, err := att.Digest()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(remote.Write(repo.Digest(attDigest.String()), att)).To(Succeed())
+
+		attestations, err := Verify(ctx, repo, digest, keys)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(attestations).To(HaveLen(1))
+		Expect(attestations[0].Source).To(Equal(repo.Digest(attDigest.String()).String()))
+		Expect(attestations[0].Verified).To(BeTrue())
+		Expect(*attestations[0].Provenance).To(Equal(Provenance{
+			BuilderID:        "https://konflux-ci.dev/pipelines",
+			BuildType:        "https://tekton.dev/chains/v2/slsa-tekton",
+			SourceRepository: "https://github.com/org/app",
+			SourceRevision:   "9f1c1d0e2b3a4c5d6e7f8a9b0c1d2e3f4a5b6c7d",
+			Hermetic:         &hermetic,
+			StartedOn:        "2024-05-01T16:00:00Z",
+			FinishedOn:       "2024-05-01T16:12:30Z",
+			Materials:        2,
+		}))
+	})
+
+	It("should skip verified attestations that are not provenance", func() {
+		sbom := dsseEnvelope(priv, inTotoPayloadType, inTotoStatement(digest, "https://spdx.dev/Document", "slsa-v1.json"))
+		img, err := mutate.AppendLayers(attestationImage(sbom), static.NewLayer([]byte("{}"), types.OCILayer))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(remote.Write(attestationTag(), img)).To(Succeed())
+
+		attestations, err := Verify(ctx, repo, digest, keys)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(attestations).To(BeEmpty())
+	})
+
+	It("should report attestations that are not verified", func() {
+		other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).ToNot(HaveOccurred())
+		otherDigest := v1.Hash{Algorithm: "sha256", Hex: strings.Repeat("0", 64)}
+		v02 := inTotoStatement(digest, PredicateSLSAv02, "slsa-v0.2.json")
+		badSig, err := json.Marshal(envelope{PayloadType: inTotoPayloadType, Payload: base64.StdEncoding.EncodeToString(v02), Signatures: []envelopeSignature{{Sig: "!"}}})
+		Expect(err).ToNot(HaveOccurred())
+		noSigs, err := json.Marshal(envelope{PayloadType: inTotoPayloadType, Payload: base64.StdEncoding.EncodeToString(v02)})
+		Expect(err).ToNot(HaveOccurred())
+		badPayload, err := json.Marshal(envelope{PayloadType: inTotoPayloadType, Payload: "!"})
+		Expect(err).ToNot(HaveOccurred())
+		badV02 := []byte(fmt.Sprintf(`{"predicateType":%q,"predicate":{"builder":"x"}}`, PredicateSLSAv02))
+		badV1 := []byte(fmt.Sprintf(`{"predicateType":%q,"predicate":{"runDetails":"x"}}`, PredicateSLSAv1))
+
+		Expect(remote.Write(attestationTag(), attestationImage(
+			dsseEnvelope(other, inTotoPayloadType, v02),
+			dsseEnvelope(priv, "text/plain", v02),
+			[]byte("not json"),
+			badPayload,
+			noSigs,
+			badSig,
+			dsseEnvelope(priv, inTotoPayloadType, []byte("not json")),
+			dsseEnvelope(priv, inTotoPayloadType, badV02),
+			dsseEnvelope(priv, inTotoPayloadType, badV1),
+			dsseEnvelope(priv, inTotoPayloadType, inTotoStatement(otherDigest, PredicateSLSAv02, "slsa-v0.2.json")),
+			[]byte(strings.Repeat("x", 4<<20+1)),
+		))).To(Succeed())
+
+		attestations, err := Verify(ctx, repo, digest, keys)
+		Expect(err).ToNot(HaveOccurred())
+		reasons := make([]string, 0, len(attestations))
+		for _, a := range attestations {
+			Expect(a.Verified).To(BeFalse())
+			reasons = append(reasons, a.Reason)
+		}
+		Expect(reasons).To(HaveExactElements(
+			"the envelope was not signed by a trusted key",
+			`the payload is a "text/plain", not a "application/vnd.in-toto+json"`,
+			HavePrefix("could not parse the DSSE envelope"),
+			HavePrefix("the payload is not base64 encoded"),
+			"the envelope has no signatures",
+			"the envelope was not signed by a trusted key",
+			HavePrefix("could not parse the statement"),
+			HavePrefix("could not parse the predicate"),
+			HavePrefix("could not parse the predicate"),
+			"the statement is not about "+digest.String(),
+			HavePrefix("could not read the envelope"),
+		))
+		Expect(attestations[9].Provenance).ToNot(BeNil())
+		Expect(attestations[9].Signer).To(Equal(keys[0].Fingerprint))
+	})
+})
+
+var _ = Describe("Provenance", func() {
+	DescribeTable("finding the source repository",
+		func(materials []material, repository string, revision string) {
+			r, rev := source(materials)
+			Expect(r).To(Equal(repository))
+			Expect(rev).To(Equal(revision))
+		},
+		Entry("no materials", nil, "", ""),
+		Entry("no git materials", []material{{URI: "oci://quay.io/org/task"}}, "", ""),
+		Entry("revision in the URI", []material{{URI: "git+https://github.com/org/app@v1.0"}}, "https://github.com/org/app", "v1.0"),
+		Entry("user in the URI", []material{{URI: "git+ssh://git@github.com/org/app.git", Digest: map[string]string{"sha1": "abc"}}}, "ssh://git@github.com/org/app", "abc"),
+		Entry("not a URL", []material{{URI: "git+github.com:org/app.git"}}, "github.com:org/app", ""),
+	)
+
+	DescribeTable("finding the hermetic parameter",
+		func(params string, expected any) {
+			var v any
+			Expect(json.Unmarshal([]byte(params), &v)).To(Succeed())
+			h := hermetic(v)
+			if expected == nil {
+				Expect(h).To(BeNil())
+				return
+			}
+			Expect(h).ToNot(BeNil())
+			Expect(*h).To(Equal(expected))
+		},
+		Entry("boolean", `{"hermetic": true}`, true),
+		Entry("nested", `{"a": {"b": [{"hermetic": "false"}]}}`, false),
+		Entry("name and value", `[{"name": "other", "value": "x"}, {"name": "hermetic", "value": "1"}]`, true),
+		Entry("first found", `{"a": {"hermetic": false}, "b": {"hermetic": true}}`, false),
+		Entry("not a boolean", `{"hermetic": "maybe"}`, nil),
+		Entry("not recorded", `{"parameters": {}}`, nil),
+	)
+
+	It("should summarize provenance in one line", func() {
+		hermetic := true
+		Expect(Provenance{
+			BuilderID:        "https://konflux-ci.dev/pipelines",
+			BuildType:        "https://tekton.dev/chains/v2/slsa-tekton",
+			SourceRepository: "https://github.com/org/app",
+			SourceRevision:   "9f1c1d0",
+			Hermetic:         &hermetic,
+			FinishedOn:       "2024-05-01T16:12:30Z",
+			Materials:        2,
+		}.String()).To(Equal("built by https://konflux-ci.dev/pipelines from https://github.com/org/app@9f1c1d0, hermetic: true, 2 materials, build type https://tekton.dev/chains/v2/slsa-tekton, finished 2024-05-01T16:12:30Z"))
+		Expect(Provenance{BuilderID: "builder"}.String()).To(Equal("built by builder from unknown, hermetic: unknown, 0 materials"))
+	})
+})
@@ -0,0 +1,70 @@
+package provenance
+
+import (
+	"fmt"
+	"path"
+	"slices"
+)
+
+// Rules restrict the provenance an image may have. Patterns may contain
+// path.Match wildcards, e.g. https://github.com/org/*.
+type Rules struct {
+	// AllowedBuilders, if not empty, are the only builder IDs permitted.
+	AllowedBuilders []string
+	// AllowedSourceRepositories, if not empty, are the only source
+	// repositories permitted.
+	AllowedSourceRepositories []string
+	// RequireHermetic requires the provenance to record that the build was
+	// hermetic.
+	RequireHermetic bool
+}
+
+// IsZero returns true if r permits any provenance.
+func (r Rules) IsZero() bool {
+	return len(r.AllowedBuilders) == 0 && len(r.AllowedSourceRepositories) == 0 && !r.RequireHermetic
+}
+
+// Validate returns an error if a pattern in r is malformed.
+func (r Rules) Validate() error {
+	for _, pattern := range slices.Concat(r.AllowedBuilders, r.AllowedSourceRepositories) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid provenance pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// Evaluate returns the reasons p is not permitted by r, if any.
+func (r Rules) Evaluate(p Provenance) []string {
+	var violations []string
+	if len(r.AllowedBuilders) > 0 && !matchAny(r.AllowedBuilders, p.BuilderID) {
+		violations = append(violations, fmt.Sprintf("builder %q is not allowed", p.BuilderID))
+	}
+	if len(r.AllowedSourceRepositories) > 0 {
+		switch {
+		case p.SourceRepository == "":
+			violations = append(violations, "the source repository is not recorded")
+		case !matchAny(r.AllowedSourceRepositories, p.SourceRepository):
+			violations = append(violations, fmt.Sprintf("source repository %q is not allowed", p.SourceRepository))
+		}
+	}
+	if r.RequireHermetic {
+		switch {
+		case p.Hermetic == nil:
+			violations = append(violations, "whether the build was hermetic is not recorded")
+		case !*p.Hermetic:
+			violations = append(violations, "the build was not hermetic")
+		}
+	}
+	return violations
+}
+
+func matchAny(patterns []string, s string) bool {
+	for _, pattern := range patterns {
+		// Patterns are checked by Validate.
+		if ok, _ := path.Match(pattern, s); ok {
+			return true
+		}
+	}
+	return false
+}
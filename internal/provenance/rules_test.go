@@ -0,0 +1,50 @@
+package provenance
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Rules", func() {
+	hermetic, notHermetic := true, false
+	provenance := Provenance{
+		BuilderID:        "https://konflux-ci.dev/pipelines",
+		SourceRepository: "https://github.com/org/app",
+		Hermetic:         &hermetic,
+	}
+
+	It("should be zero without any rules", func() {
+		Expect(Rules{}.IsZero()).To(BeTrue())
+		Expect(Rules{RequireHermetic: true}.IsZero()).To(BeFalse())
+		Expect(Rules{}.Evaluate(Provenance{})).To(BeEmpty())
+	})
+
+	It("should reject malformed patterns", func() {
+		Expect(Rules{AllowedBuilders: []string{"https://konflux-ci.dev/*"}}.Validate()).To(Succeed())
+		Expect(Rules{AllowedSourceRepositories: []string{"["}}.Validate()).To(MatchError(ContainSubstring(`invalid provenance pattern "["`)))
+	})
+
+	DescribeTable("evaluating provenance",
+		func(rules Rules, p Provenance, violations []string) {
+			Expect(rules.Evaluate(p)).To(Equal(violations))
+		},
+		Entry("permitted",
+			Rules{AllowedBuilders: []string{"https://tekton.dev/*", "https://konflux-ci.dev/*"}, AllowedSourceRepositories: []string{"https://github.com/org/*"}, RequireHermetic: true},
+			provenance, nil),
+		Entry("builder not allowed",
+			Rules{AllowedBuilders: []string{"https://tekton.dev/*"}},
+			provenance, []string{`builder "https://konflux-ci.dev/pipelines" is not allowed`}),
+		Entry("source repository not allowed",
+			Rules{AllowedSourceRepositories: []string{"https://github.com/other/*"}},
+			provenance, []string{`source repository "https://github.com/org/app" is not allowed`}),
+		Entry("source repository not recorded",
+			Rules{AllowedSourceRepositories: []string{"https://github.com/org/*"}},
+			Provenance{}, []string{"the source repository is not recorded"}),
+		Entry("not hermetic",
+			Rules{RequireHermetic: true},
+			Provenance{Hermetic: &notHermetic}, []string{"the build was not hermetic"}),
+		Entry("hermetic not recorded",
+			Rules{RequireHermetic: true},
+			Provenance{}, []string{"whether the build was hermetic is not recorded"}),
+	)
+})
@@ -1,7 +1,9 @@
 package runtime
 
 import (
+	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
@@ -21,6 +23,38 @@ type Config struct {
 	Artifacts      string
 	WriteJUnit     bool
 	TempDir        string
+	// CheckParallelism is the maximum number of checks to execute at the
+	// same time. Zero means the policy's default is used.
+	CheckParallelism int
+	// CacheDir is where image layers are cached across runs. If empty,
+	// layers are only cached for the duration of a run.
+	CacheDir string
+	// CacheMaxSize is the size in bytes CacheDir is pruned to after a run.
+	CacheMaxSize int64
+	// CheckTimeout bounds how long each check may run. Zero means checks
+	// are not bounded.
+	CheckTimeout time.Duration
+	// CheckTimeouts overrides CheckTimeout for the checks it names.
+	CheckTimeouts map[string]time.Duration
+	// Checks, if set, limits the run to the checks it names.
+	Checks []string
+	// SkipChecks names checks that are excluded from the run.
+	SkipChecks []string
+	// RerunFailed is the path to the results of an earlier run. If set,
+	// only the checks that did not pass in that run are executed.
+	RerunFailed string
+	// CustomChecks is the path to a rules file declaring checks that are
+	// executed in addition to those in the policy.
+	CustomChecks string
+	// PluginPath is a list of directories containing plugin executables,
+	// separated by the OS path list separator.
+	PluginPath string
+	// Policies are user-defined policies, which may be selected with Policy
+	// in addition to Red Hat's.
+	Policies []policy.Definition
+	// Waivers is the path to a waivers file. Failures of the checks it
+	// names are reported as warnings until the waivers expire.
+	Waivers string
 	// Container-Specific Fields
 	CertificationComponentID string
 	PyxisHost                string
@@ -32,6 +66,45 @@ type Config struct {
 	Offline                  bool
 	ManifestListDigest       string
 	Konflux                  bool
+	LocalImageReference      string
+	// PlatformParallelism is the maximum number of platforms of a manifest
+	// list to check at the same time.
+	PlatformParallelism int
+	// AllowedLicenses, if set, are the only SPDX licenses HasLicense
+	// permits.
+	AllowedLicenses []string
+	// DeniedLicenses are SPDX licenses HasLicense never permits.
+	DeniedLicenses []string
+	// Advisories is the path to a CSAF or OVAL advisory dataset. If set,
+	// installed RPMs are checked for fixable vulnerabilities.
+	Advisories string
+	// ScanSecrets executes HasNoEmbeddedSecrets.
+	ScanSecrets bool
+	// SecretsAllowlist is the path to a file describing the secrets
+	// HasNoEmbeddedSecrets permits. If set, secrets are scanned for.
+	SecretsAllowlist string
+	// CheckArbitraryUID executes SupportsArbitraryUID.
+	CheckArbitraryUID bool
+	// WritablePaths are checked by SupportsArbitraryUID, in addition to
+	// WORKDIR, VOLUMEs and HOME.
+	WritablePaths []string
+	// SBOMFormats are the formats an SBOM of the image is written to the
+	// artifacts directory in.
+	SBOMFormats []string
+	// CheckFileModes executes HasNoUnsafeFileModes.
+	CheckFileModes bool
+	// SignatureKeys is the path to the public keys the image must be signed
+	// with. If set, the signatures of the image are verified.
+	SignatureKeys string
+	// ProvenanceKeys is the path to the public keys the SLSA provenance of
+	// the image must be signed with. If set, the provenance is verified.
+	ProvenanceKeys string
+	// AllowedBuilders and AllowedSourceRepositories, if set, are patterns
+	// the builder ID and source repository of the provenance must match.
+	AllowedBuilders           []string
+	AllowedSourceRepositories []string
+	// RequireHermetic requires the provenance to record a hermetic build.
+	RequireHermetic bool
 	// Operator-Specific Fields
 	Namespace           string
 	ServiceAccount      string
@@ -62,11 +135,77 @@ func NewConfigFrom(vcfg viper.Viper) (*Config, error) {
 	cfg.Artifacts = vcfg.GetString("artifacts")
 	cfg.WriteJUnit = vcfg.GetBool("junit")
 	cfg.TempDir = vcfg.GetString("tempDir")
+	cfg.CheckParallelism = vcfg.GetInt("check_parallelism")
+	cfg.CacheDir = vcfg.GetString("cache_dir")
+	cfg.CacheMaxSize = int64(vcfg.GetSizeInBytes("cache_max_size"))
+	cfg.CheckTimeout = vcfg.GetDuration("check_timeout")
+	checkTimeouts, err := parseCheckTimeouts(vcfg)
+	if err != nil {
+		return nil, err
+	}
+	cfg.CheckTimeouts = checkTimeouts
+	cfg.Checks = splitList(vcfg.GetStringSlice("checks"))
+	cfg.SkipChecks = splitList(vcfg.GetStringSlice("skip_checks"))
+	cfg.RerunFailed = vcfg.GetString("rerun_failed")
+	cfg.CustomChecks = vcfg.GetString("custom_checks")
+	cfg.PluginPath = vcfg.GetString("plugin_path")
+	cfg.Policy = policy.Policy(vcfg.GetString("policy"))
+	cfg.Waivers = vcfg.GetString("waivers")
+	if err := vcfg.UnmarshalKey("policies", &cfg.Policies); err != nil {
+		return nil, fmt.Errorf("invalid policies: %w", err)
+	}
 	cfg.storeContainerPolicyConfiguration(vcfg)
 	cfg.storeOperatorPolicyConfiguration(vcfg)
 	return &cfg, nil
 }
 
+// splitList splits each of vals on commas, so that lists may be provided
+// either as multiple values or as a single comma separated value, as is the
+// case for environment variables. Empty elements are dropped.
+func splitList(vals []string) []string {
+	var list []string
+	for _, val := range vals {
+		for _, elem := range strings.Split(val, ",") {
+			if elem = strings.TrimSpace(elem); elem != "" {
+				list = append(list, elem)
+			}
+		}
+	}
+	return list
+}
+
+// parseCheckTimeouts reads per-check timeouts from viper. They may be set as a
+// map of check names to durations in a config file, or as a comma separated
+// list of name=duration pairs, e.g. HasLicense=30s,RunAsNonRoot=1m.
+func parseCheckTimeouts(vcfg viper.Viper) (map[string]time.Duration, error) {
+	raw := vcfg.GetStringMapString("check_timeouts")
+	if s := vcfg.GetString("check_timeouts"); len(raw) == 0 && s != "" {
+		raw = make(map[string]string)
+		for _, pair := range strings.Split(s, ",") {
+			name, value, ok := strings.Cut(pair, "=")
+			if !ok {
+				return nil, fmt.Errorf("invalid check timeout %q: expected name=duration", pair)
+			}
+			raw[strings.TrimSpace(name)] = strings.TrimSpace(value)
+		}
+	}
+
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	timeouts := make(map[string]time.Duration, len(raw))
+	for name, value := range raw {
+		timeout, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout for check %s: %w", name, err)
+		}
+		timeouts[name] = timeout
+	}
+
+	return timeouts, nil
+}
+
 // storeContainerPolicyConfiguration reads container-policy-specific config
 // items in viper, normalizes them, and stores them in Config.
 func (c *Config) storeContainerPolicyConfiguration(vcfg viper.Viper) {
@@ -78,6 +217,22 @@ func (c *Config) storeContainerPolicyConfiguration(vcfg viper.Viper) {
 	c.Insecure = vcfg.GetBool("insecure")
 	c.Offline = vcfg.GetBool("offline")
 	c.Konflux = vcfg.GetBool("konflux")
+	c.LocalImageReference = vcfg.GetString("local_image_reference")
+	c.PlatformParallelism = vcfg.GetInt("platform_parallelism")
+	c.AllowedLicenses = splitList(vcfg.GetStringSlice("allowed_licenses"))
+	c.DeniedLicenses = splitList(vcfg.GetStringSlice("denied_licenses"))
+	c.Advisories = vcfg.GetString("advisories")
+	c.ScanSecrets = vcfg.GetBool("scan_secrets")
+	c.SecretsAllowlist = vcfg.GetString("secrets_allowlist")
+	c.CheckArbitraryUID = vcfg.GetBool("check_arbitrary_uid")
+	c.WritablePaths = splitList(vcfg.GetStringSlice("writable_paths"))
+	c.SBOMFormats = splitList(vcfg.GetStringSlice("sbom_format"))
+	c.CheckFileModes = vcfg.GetBool("check_file_modes")
+	c.SignatureKeys = vcfg.GetString("signature_keys")
+	c.ProvenanceKeys = vcfg.GetString("provenance_keys")
+	c.AllowedBuilders = splitList(vcfg.GetStringSlice("allowed_builders"))
+	c.AllowedSourceRepositories = splitList(vcfg.GetStringSlice("allowed_source_repositories"))
+	c.RequireHermetic = vcfg.GetBool("require_hermetic")
 }
 
 // storeOperatorPolicyConfiguration reads operator-policy-specific config
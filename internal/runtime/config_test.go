@@ -3,10 +3,13 @@ package runtime
 import (
 	"os"
 	"reflect"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/spf13/viper"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/policy"
 )
 
 var _ = Describe("Viper to Runtime Config", func() {
@@ -28,6 +31,39 @@ var _ = Describe("Viper to Runtime Config", func() {
 		expectedRuntimeCfg.Artifacts = "artifacts"
 		baseViperCfg.Set("junit", true)
 		expectedRuntimeCfg.WriteJUnit = true
+		baseViperCfg.Set("check_parallelism", 2)
+		expectedRuntimeCfg.CheckParallelism = 2
+		baseViperCfg.Set("cache_dir", "/var/cache/preflight")
+		expectedRuntimeCfg.CacheDir = "/var/cache/preflight"
+		baseViperCfg.Set("cache_max_size", "2GB")
+		expectedRuntimeCfg.CacheMaxSize = 2 << 30
+		baseViperCfg.Set("check_timeout", "5m")
+		expectedRuntimeCfg.CheckTimeout = 5 * time.Minute
+		baseViperCfg.Set("check_timeouts", "HasLicense=30s, RunAsNonRoot=1m")
+		expectedRuntimeCfg.CheckTimeouts = map[string]time.Duration{
+			"HasLicense":   30 * time.Second,
+			"RunAsNonRoot": time.Minute,
+		}
+		baseViperCfg.Set("checks", []string{"HasLicense", "RunAsNonRoot,HasUniqueTag"})
+		expectedRuntimeCfg.Checks = []string{"HasLicense", "RunAsNonRoot", "HasUniqueTag"}
+		baseViperCfg.Set("skip_checks", "HasLicense, ")
+		expectedRuntimeCfg.SkipChecks = []string{"HasLicense"}
+		baseViperCfg.Set("rerun_failed", "/tmp/results.json")
+		expectedRuntimeCfg.RerunFailed = "/tmp/results.json"
+		baseViperCfg.Set("custom_checks", "/tmp/rules.yaml")
+		expectedRuntimeCfg.CustomChecks = "/tmp/rules.yaml"
+		baseViperCfg.Set("plugin_path", "/opt/preflight/plugins")
+		expectedRuntimeCfg.PluginPath = "/opt/preflight/plugins"
+		baseViperCfg.Set("waivers", "waivers.yaml")
+		expectedRuntimeCfg.Waivers = "waivers.yaml"
+		baseViperCfg.Set("policy", "strict")
+		expectedRuntimeCfg.Policy = "strict"
+		baseViperCfg.Set("policies", []map[string]any{
+			{"name": "strict", "description": "our policy", "inherits": "container", "checks": []string{"HasTeamLabel"}, "exclude": []string{"BasedOnUbi"}},
+		})
+		expectedRuntimeCfg.Policies = []policy.Definition{
+			{Name: "strict", Description: "our policy", Inherits: policy.PolicyContainer, Checks: []string{"HasTeamLabel"}, Exclude: []string{"BasedOnUbi"}},
+		}
 
 		baseViperCfg.Set("pyxis_api_token", "apitoken")
 		expectedRuntimeCfg.PyxisAPIToken = "apitoken"
@@ -41,6 +77,38 @@ var _ = Describe("Viper to Runtime Config", func() {
 		expectedRuntimeCfg.Platform = "s390x"
 		baseViperCfg.Set("insecure", true)
 		expectedRuntimeCfg.Insecure = true
+		baseViperCfg.Set("local_image_reference", "quay.io/repo/image:tag")
+		expectedRuntimeCfg.LocalImageReference = "quay.io/repo/image:tag"
+		baseViperCfg.Set("platform_parallelism", 2)
+		expectedRuntimeCfg.PlatformParallelism = 2
+		baseViperCfg.Set("allowed_licenses", []string{"MIT,Apache-2.0", "BSD-*"})
+		expectedRuntimeCfg.AllowedLicenses = []string{"MIT", "Apache-2.0", "BSD-*"}
+		baseViperCfg.Set("denied_licenses", "AGPL-3.0")
+		expectedRuntimeCfg.DeniedLicenses = []string{"AGPL-3.0"}
+		baseViperCfg.Set("advisories", "/data/csaf")
+		expectedRuntimeCfg.Advisories = "/data/csaf"
+		baseViperCfg.Set("scan_secrets", true)
+		expectedRuntimeCfg.ScanSecrets = true
+		baseViperCfg.Set("secrets_allowlist", "secrets-allowlist.yaml")
+		expectedRuntimeCfg.SecretsAllowlist = "secrets-allowlist.yaml"
+		baseViperCfg.Set("check_arbitrary_uid", true)
+		expectedRuntimeCfg.CheckArbitraryUID = true
+		baseViperCfg.Set("writable_paths", []string{"/var/cache/app", "/tmp/app"})
+		expectedRuntimeCfg.WritablePaths = []string{"/var/cache/app", "/tmp/app"}
+		baseViperCfg.Set("sbom_format", "spdx,cyclonedx")
+		expectedRuntimeCfg.SBOMFormats = []string{"spdx", "cyclonedx"}
+		baseViperCfg.Set("check_file_modes", true)
+		expectedRuntimeCfg.CheckFileModes = true
+		baseViperCfg.Set("signature_keys", "/etc/pki/cosign.pub")
+		expectedRuntimeCfg.SignatureKeys = "/etc/pki/cosign.pub"
+		baseViperCfg.Set("provenance_keys", "/etc/pki/konflux.pub")
+		expectedRuntimeCfg.ProvenanceKeys = "/etc/pki/konflux.pub"
+		baseViperCfg.Set("allowed_builders", "https://konflux-ci.dev/*")
+		expectedRuntimeCfg.AllowedBuilders = []string{"https://konflux-ci.dev/*"}
+		baseViperCfg.Set("allowed_source_repositories", []string{"https://github.com/org/*"})
+		expectedRuntimeCfg.AllowedSourceRepositories = []string{"https://github.com/org/*"}
+		baseViperCfg.Set("require_hermetic", true)
+		expectedRuntimeCfg.RequireHermetic = true
 
 		baseViperCfg.Set("namespace", "myns")
 		expectedRuntimeCfg.Namespace = "myns"
@@ -68,12 +136,37 @@ var _ = Describe("Viper to Runtime Config", func() {
 		})
 	})
 
+	It("should reject invalid policies", func() {
+		baseViperCfg.Set("policies", "strict")
+		_, err := NewConfigFrom(*baseViperCfg)
+		Expect(err).To(MatchError(ContainSubstring("invalid policies")))
+	})
+
+	Context("With per-check timeouts", func() {
+		It("should read them from a map", func() {
+			baseViperCfg.Set("check_timeouts", map[string]any{"haslicense": "10s"})
+			cfg, err := NewConfigFrom(*baseViperCfg)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cfg.CheckTimeouts).To(Equal(map[string]time.Duration{"haslicense": 10 * time.Second}))
+		})
+		It("should reject a pair without a duration", func() {
+			baseViperCfg.Set("check_timeouts", "HasLicense")
+			_, err := NewConfigFrom(*baseViperCfg)
+			Expect(err).To(MatchError(ContainSubstring("expected name=duration")))
+		})
+		It("should reject an invalid duration", func() {
+			baseViperCfg.Set("check_timeouts", "HasLicense=soon")
+			_, err := NewConfigFrom(*baseViperCfg)
+			Expect(err).To(MatchError(ContainSubstring("invalid timeout for check HasLicense")))
+		})
+	})
+
 	It("should only have 26 struct keys for tests to be valid", func() {
 		// If this test fails, it means a developer has added or removed
 		// keys from runtime.Config, and so these tests may no longer be
 		// accurate in confirming that the derived configuration from viper
 		// matches.
 		keys := reflect.TypeOf(Config{}).NumField()
-		Expect(keys).To(Equal(28))
+		Expect(keys).To(Equal(56))
 	})
 })
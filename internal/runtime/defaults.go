@@ -6,4 +6,13 @@ var (
 	DefaultCSVTimeout          = 180 * time.Second
 	DefaultSubscriptionTimeout = 180 * time.Second
 	DefaultScorecardWaitTime   = "240"
+	// DefaultCheckParallelism is the number of container checks executed at
+	// the same time when the user has not configured a value.
+	DefaultCheckParallelism = 4
+	// DefaultPlatformParallelism is the number of platforms of a manifest
+	// list checked at the same time when the user has not configured a value.
+	DefaultPlatformParallelism = 4
+	// DefaultCacheMaxSize is the size in bytes a persistent layer cache is
+	// pruned to after a run when the user has not configured a value.
+	DefaultCacheMaxSize int64 = 10 << 30
 )
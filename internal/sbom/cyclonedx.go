@@ -0,0 +1,157 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/version"
+)
+
+type cdxDocument struct {
+	BOMFormat    string          `json:"bomFormat"`
+	SpecVersion  string          `json:"specVersion"`
+	SerialNumber string          `json:"serialNumber"`
+	Version      int             `json:"version"`
+	Metadata     cdxMetadata     `json:"metadata"`
+	Components   []cdxComponent  `json:"components"`
+	Dependencies []cdxDependency `json:"dependencies"`
+}
+
+type cdxMetadata struct {
+	Timestamp string       `json:"timestamp"`
+	Tools     cdxTools     `json:"tools"`
+	Component cdxComponent `json:"component"`
+}
+
+type cdxTools struct {
+	Components []cdxComponent `json:"components"`
+}
+
+type cdxComponent struct {
+	BOMRef     string        `json:"bom-ref,omitempty"`
+	Type       string        `json:"type"`
+	Supplier   *cdxSupplier  `json:"supplier,omitempty"`
+	Name       string        `json:"name"`
+	Version    string        `json:"version,omitempty"`
+	Hashes     []cdxHash     `json:"hashes,omitempty"`
+	Licenses   []cdxLicense  `json:"licenses,omitempty"`
+	PURL       string        `json:"purl,omitempty"`
+	Pedigree   *cdxPedigree  `json:"pedigree,omitempty"`
+	Properties []cdxProperty `json:"properties,omitempty"`
+}
+
+type cdxSupplier struct {
+	Name string `json:"name"`
+}
+
+type cdxHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+// cdxLicense is either an SPDX license expression, or a license.
+type cdxLicense struct {
+	Expression string          `json:"expression,omitempty"`
+	License    *cdxLicenseName `json:"license,omitempty"`
+}
+
+// cdxLicenseName is a license that is not an SPDX license.
+type cdxLicenseName struct {
+	Name string `json:"name"`
+}
+
+type cdxPedigree struct {
+	Ancestors []cdxComponent `json:"ancestors"`
+}
+
+type cdxProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cdxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+// generateCycloneDX returns the CycloneDX 1.5 document of img. The image is
+// the component the document describes, and depends on every other
+// component. The source RPM a binary RPM was built from is its ancestor.
+func generateCycloneDX(img Image) ([]byte, error) {
+	doc := cdxDocument{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + uuid.NewString(),
+		Version:      1,
+		Metadata: cdxMetadata{
+			Timestamp: img.Created.UTC().Format("2006-01-02T15:04:05Z"),
+			Tools: cdxTools{Components: []cdxComponent{
+				{Type: "application", Name: toolName(), Version: version.Version.Version},
+			}},
+			Component: cdxComponent{
+				BOMRef:  "Image",
+				Type:    "container",
+				Name:    img.Reference,
+				Version: img.Digest,
+			},
+		},
+		Components: []cdxComponent{},
+	}
+	if hex, ok := strings.CutPrefix(img.Digest, "sha256:"); ok {
+		doc.Metadata.Component.Hashes = []cdxHash{{Alg: "SHA-256", Content: hex}}
+		if img.Repository != "" {
+			doc.Metadata.Component.PURL = imagePURL(img.Repository, img.Digest, img.Architecture)
+		}
+	}
+
+	pkgs, _ := packages(img)
+	image := cdxDependency{Ref: doc.Metadata.Component.BOMRef}
+	for _, p := range pkgs {
+		c := cdxPackage(p)
+		c.BOMRef = p.id
+		if p.md5 != "" {
+			c.Hashes = []cdxHash{{Alg: "MD5", Content: p.md5}}
+		}
+		if p.source != nil {
+			c.Pedigree = &cdxPedigree{Ancestors: []cdxComponent{cdxPackage(p.source)}}
+		}
+		if p.location != "" {
+			c.Properties = []cdxProperty{{Name: "preflight:location", Value: p.location}}
+		}
+		doc.Components = append(doc.Components, c)
+		image.DependsOn = append(image.DependsOn, c.BOMRef)
+	}
+	doc.Dependencies = []cdxDependency{image}
+
+	b, err := json.MarshalIndent(doc, "", "    ")
+	if err != nil {
+		//coverage:ignore
+		return nil, fmt.Errorf("could not marshal CycloneDX document: %w", err)
+	}
+	return b, nil
+}
+
+// cdxPackage returns the name, version, supplier, license and package URL of
+// p as a CycloneDX component.
+func cdxPackage(p *pkg) cdxComponent {
+	c := cdxComponent{
+		Type:    "library",
+		Name:    p.name,
+		Version: p.version,
+		PURL:    p.purl,
+	}
+	if p.supplier != "" {
+		c.Supplier = &cdxSupplier{Name: p.supplier}
+	}
+	if p.license != "" {
+		if expr, ok := licenseExpression(p.license); ok {
+			c.Licenses = []cdxLicense{{Expression: expr}}
+		} else {
+			c.Licenses = []cdxLicense{{License: &cdxLicenseName{Name: p.license}}}
+		}
+	}
+	return c
+}
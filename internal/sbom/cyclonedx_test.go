@@ -0,0 +1,83 @@
+package sbom
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/version"
+)
+
+var _ = Describe("CycloneDX", func() {
+	var doc cdxDocument
+
+	BeforeEach(func() {
+		b, err := Generate(FormatCycloneDX, testImage())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(json.Unmarshal(b, &doc)).To(Succeed())
+	})
+
+	It("should describe the image", func() {
+		Expect(doc.BOMFormat).To(Equal("CycloneDX"))
+		Expect(doc.SpecVersion).To(Equal("1.5"))
+		Expect(doc.SerialNumber).To(MatchRegexp(`^urn:uuid:[0-9a-f-]{36}$`))
+		Expect(doc.Metadata.Timestamp).To(Equal("2024-05-01T16:00:00Z"))
+		Expect(doc.Metadata.Tools.Components).To(Equal([]cdxComponent{{Type: "application", Name: "openshift-preflight", Version: version.Version.Version}}))
+		Expect(doc.Metadata.Component).To(Equal(cdxComponent{
+			BOMRef:  "Image",
+			Type:    "container",
+			Name:    "registry.example.com/org/app:1.0",
+			Version: testDigest,
+			Hashes:  []cdxHash{{Alg: "SHA-256", Content: testDigest[len("sha256:"):]}},
+			PURL:    "pkg:oci/app@sha256%3A" + testDigest[len("sha256:"):] + "?arch=amd64&repository_url=registry.example.com%2Forg%2Fapp",
+		}))
+	})
+
+	It("should include the license, checksum, package URL and source RPM of each RPM", func() {
+		Expect(doc.Components[0]).To(Equal(cdxComponent{
+			BOMRef:   "Package-1",
+			Type:     "library",
+			Supplier: &cdxSupplier{Name: "Red Hat, Inc."},
+			Name:     "bash",
+			Version:  "5.1.8-6.el9",
+			Hashes:   []cdxHash{{Alg: "MD5", Content: "d41d8cd98f00b204e9800998ecf8427e"}},
+			Licenses: []cdxLicense{{License: &cdxLicenseName{Name: "GPLv3+"}}},
+			PURL:     "pkg:rpm/redhat/bash@5.1.8-6.el9?arch=x86_64",
+			Pedigree: &cdxPedigree{Ancestors: []cdxComponent{{
+				Type:     "library",
+				Supplier: &cdxSupplier{Name: "Red Hat, Inc."},
+				Name:     "bash",
+				Version:  "5.1.8-6.el9",
+				Licenses: []cdxLicense{{License: &cdxLicenseName{Name: "GPLv3+"}}},
+				PURL:     "pkg:rpm/redhat/bash@5.1.8-6.el9?arch=src",
+			}}},
+		}))
+		Expect(doc.Components[3].Licenses).To(Equal([]cdxLicense{{Expression: "GPL-2.0-or-later AND (MIT OR BSD-3-Clause)"}}))
+		Expect(doc.Components[3].Pedigree).To(BeNil())
+	})
+
+	It("should include the components of the inventory", func() {
+		Expect(doc.Components).To(HaveLen(6))
+		Expect(doc.Components[4].PURL).To(Equal("pkg:golang/stdlib@1.22.1"))
+		Expect(doc.Components[4].Licenses).To(BeEmpty())
+		Expect(doc.Components[4].Properties).To(Equal([]cdxProperty{{Name: "preflight:location", Value: "/usr/local/bin/app, added in layer 2 (sha256:feed)"}}))
+	})
+
+	It("should make the image depend on each component", func() {
+		Expect(doc.Dependencies).To(Equal([]cdxDependency{{
+			Ref:       "Image",
+			DependsOn: []string{"Package-1", "Package-2", "Package-3", "Package-4", "Package-5", "Package-6"},
+		}}))
+	})
+
+	It("should describe an image without a digest", func() {
+		b, err := Generate(FormatCycloneDX, Image{Reference: "app.tar"})
+		Expect(err).ToNot(HaveOccurred())
+		var doc cdxDocument
+		Expect(json.Unmarshal(b, &doc)).To(Succeed())
+		Expect(doc.Metadata.Component.Hashes).To(BeEmpty())
+		Expect(doc.Metadata.Component.PURL).To(BeEmpty())
+		Expect(doc.Components).To(BeEmpty())
+	})
+})
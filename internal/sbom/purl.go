@@ -0,0 +1,97 @@
+package sbom
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/inventory"
+)
+
+// purlEscape percent-encodes s for use in a package URL segment. Unlike in
+// URL paths, "@", "+" and ":" are not permitted unencoded.
+func purlEscape(s string) string {
+	return strings.NewReplacer("@", "%40", "+", "%2B", ":", "%3A").Replace(url.PathEscape(s))
+}
+
+// rpmPURL returns the package URL of an RPM. RPMs built by Red Hat are in the
+// redhat namespace.
+func rpmPURL(vendor string, name string, epoch *int, ver string, rel string, arch string) string {
+	namespace := ""
+	if strings.HasPrefix(vendor, "Red Hat") {
+		namespace = "redhat/"
+	}
+	qualifiers := url.Values{}
+	if arch != "" {
+		qualifiers.Set("arch", arch)
+	}
+	if epoch != nil && *epoch != 0 {
+		qualifiers.Set("epoch", fmt.Sprint(*epoch))
+	}
+	return withQualifiers("pkg:rpm/"+namespace+purlEscape(name)+versionSuffix(rpmVersion(nil, ver, rel)), qualifiers)
+}
+
+// imagePURL returns the package URL of the image with digest in repository.
+func imagePURL(repository string, digest string, arch string) string {
+	qualifiers := url.Values{}
+	if arch != "" {
+		qualifiers.Set("arch", arch)
+	}
+	qualifiers.Set("repository_url", repository)
+	return withQualifiers(fmt.Sprintf("pkg:oci/%s@%s", purlEscape(path.Base(repository)), purlEscape(digest)), qualifiers)
+}
+
+// componentPURL returns the package URL of c, or an empty string if the
+// ecosystem of c has none, such as Java archives without Maven metadata.
+func componentPURL(c inventory.Component) string {
+	switch c.Ecosystem {
+	case inventory.EcosystemGo:
+		name := c.Name
+		ver := c.Version
+		if name == "stdlib" {
+			ver = strings.TrimPrefix(ver, "go")
+		}
+		return "pkg:golang/" + escapePath(name) + versionSuffix(ver)
+	case inventory.EcosystemPython:
+		// Python package names are normalized, as in PEP 503.
+		name := strings.ToLower(strings.NewReplacer("_", "-", ".", "-").Replace(c.Name))
+		return "pkg:pypi/" + purlEscape(name) + versionSuffix(c.Version)
+	case inventory.EcosystemNode:
+		return "pkg:npm/" + escapePath(c.Name) + versionSuffix(c.Version)
+	case inventory.EcosystemJava:
+		group, artifact, ok := strings.Cut(c.Name, ":")
+		if !ok {
+			return ""
+		}
+		return "pkg:maven/" + purlEscape(group) + "/" + purlEscape(artifact) + versionSuffix(c.Version)
+	}
+	return ""
+}
+
+// escapePath escapes each segment of the slash-separated p.
+func escapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, s := range segments {
+		segments[i] = purlEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+// versionSuffix returns the version component of a package URL, which is
+// omitted if the version is not known.
+func versionSuffix(ver string) string {
+	if ver == "" || ver == "(devel)" {
+		return ""
+	}
+	return "@" + purlEscape(ver)
+}
+
+// withQualifiers appends qualifiers to purl. They are sorted by key, as the
+// package URL specification requires.
+func withQualifiers(purl string, qualifiers url.Values) string {
+	if len(qualifiers) == 0 {
+		return purl
+	}
+	return purl + "?" + qualifiers.Encode()
+}
@@ -0,0 +1,214 @@
+// Package sbom describes the software in a container image as an SPDX 2.3 or
+// CycloneDX 1.5 JSON document. The RPM packages of the image, and the
+// components of its inventory, are included.
+package sbom
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	rpmdb "github.com/knqyf263/go-rpmdb/pkg"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/inventory"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/license"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/version"
+)
+
+// Format is an SBOM document format.
+type Format string
+
+const (
+	FormatSPDX      Format = "spdx"
+	FormatCycloneDX Format = "cyclonedx"
+)
+
+// formatNone selects no formats, so that SBOMs can be disabled where a format
+// is selected by default.
+const formatNone = "none"
+
+// ParseFormats returns the formats names. "none" selects no formats.
+func ParseFormats(names []string) ([]Format, error) {
+	var formats []Format
+	for _, name := range names {
+		switch f := Format(strings.ToLower(name)); f {
+		case FormatSPDX, FormatCycloneDX:
+			if !slices.Contains(formats, f) {
+				formats = append(formats, f)
+			}
+		case formatNone:
+			continue
+		default:
+			return nil, fmt.Errorf("unknown SBOM format %q: must be one of %s, %s or %s", name, FormatSPDX, FormatCycloneDX, formatNone)
+		}
+	}
+	return formats, nil
+}
+
+// Image is the image an SBOM describes.
+type Image struct {
+	// Reference is the image that was tested, e.g.
+	// registry.example.com/org/app:1.0.
+	Reference string
+	// Repository is the registry and repository of the image, e.g.
+	// registry.example.com/org/app. It is empty for images loaded from the
+	// local filesystem.
+	Repository string
+	// Digest is the digest of the image manifest.
+	Digest       string
+	Architecture string
+	// Packages are the RPM packages installed in the image.
+	Packages []*rpmdb.PackageInfo
+	// Inventory is the software in the image not installed with RPM.
+	Inventory *inventory.Inventory
+	// Created is when the SBOM is created.
+	Created time.Time
+}
+
+// Generate returns the SBOM of img in format.
+func Generate(format Format, img Image) ([]byte, error) {
+	switch format {
+	case FormatSPDX:
+		return generateSPDX(img)
+	case FormatCycloneDX:
+		return generateCycloneDX(img)
+	}
+	return nil, fmt.Errorf("unknown SBOM format %q", format)
+}
+
+// toolName names preflight as the creator of an SBOM.
+func toolName() string {
+	return path.Base(version.Version.Name)
+}
+
+// pkg is a package in an image, in a form common to both formats.
+type pkg struct {
+	// id is unique in the SBOM, and only contains letters, numbers, "."
+	// and "-".
+	id       string
+	name     string
+	version  string
+	supplier string
+	// license is the license of the package as the package declares it,
+	// which is not necessarily an SPDX license expression.
+	license string
+	md5     string
+	purl    string
+	// location describes where in the image the package was found.
+	location string
+	// source is the source RPM a binary RPM was built from.
+	source *pkg
+}
+
+// packages returns the packages of img, and the source RPMs they were built
+// from, in the order they are listed in an SBOM.
+func packages(img Image) ([]*pkg, []*pkg) {
+	var pkgs, sources []*pkg
+	sourcesByName := map[string]*pkg{}
+	for _, p := range img.Packages {
+		rpm := &pkg{
+			id:       fmt.Sprintf("Package-%d", len(pkgs)+1),
+			name:     p.Name,
+			version:  rpmVersion(p.Epoch, p.Version, p.Release),
+			supplier: p.Vendor,
+			license:  p.License,
+			md5:      p.SigMD5,
+			purl:     rpmPURL(p.Vendor, p.Name, p.Epoch, p.Version, p.Release, p.Arch),
+		}
+		if p.SourceRpm != "" {
+			source, ok := sourcesByName[p.SourceRpm]
+			if !ok {
+				source = sourceRPM(p, len(sources)+1)
+				sourcesByName[p.SourceRpm] = source
+				sources = append(sources, source)
+			}
+			rpm.source = source
+		}
+		pkgs = append(pkgs, rpm)
+	}
+
+	if img.Inventory != nil {
+		for _, c := range img.Inventory.Components {
+			location := c.Path
+			if c.Layer >= 0 {
+				location = fmt.Sprintf("%s, added in layer %d (%s)", c.Path, c.Layer, c.LayerDigest)
+			}
+			pkgs = append(pkgs, &pkg{
+				id:       fmt.Sprintf("Package-%d", len(pkgs)+1),
+				name:     c.Name,
+				version:  c.Version,
+				purl:     componentPURL(c),
+				location: location,
+			})
+		}
+	}
+	return pkgs, sources
+}
+
+// sourceRPM returns the source RPM binary RPM p was built from, e.g.
+// bash-5.1.8-6.el9.src.rpm.
+func sourceRPM(p *rpmdb.PackageInfo, n int) *pkg {
+	nvr := strings.TrimSuffix(p.SourceRpm, ".rpm")
+	nvr = strings.TrimSuffix(strings.TrimSuffix(nvr, ".src"), ".nosrc")
+	name, ver, rel := nvr, "", ""
+	if i := strings.LastIndex(nvr, "-"); i > 0 {
+		name, ver = nvr[:i], nvr[i+1:]
+		if j := strings.LastIndex(name, "-"); j > 0 {
+			name, ver, rel = name[:j], name[j+1:], ver
+		}
+	}
+	return &pkg{
+		id:       fmt.Sprintf("SourcePackage-%d", n),
+		name:     name,
+		version:  rpmVersion(p.Epoch, ver, rel),
+		supplier: p.Vendor,
+		license:  p.License,
+		purl:     rpmPURL(p.Vendor, name, p.Epoch, ver, rel, "src"),
+	}
+}
+
+// rpmVersion returns the [epoch:]version-release of an RPM.
+func rpmVersion(epoch *int, ver string, rel string) string {
+	v := ver
+	if rel != "" {
+		v += "-" + rel
+	}
+	if epoch != nil && *epoch != 0 {
+		v = fmt.Sprintf("%d:%s", *epoch, v)
+	}
+	return v
+}
+
+// licenseExpression returns declared as an SPDX license expression, and true
+// if each license it names is an SPDX identifier. RPM license tags use SPDX
+// identifiers and operators in recent releases, and Fedora short names,
+// such as GPLv2+, in older ones.
+func licenseExpression(declared string) (string, bool) {
+	fields := strings.Fields(strings.NewReplacer("(", " ( ", ")", " ) ").Replace(declared))
+	if len(fields) == 0 {
+		return "", false
+	}
+	for i, field := range fields {
+		switch strings.ToUpper(field) {
+		case "AND", "OR", "WITH":
+			fields[i] = strings.ToUpper(field)
+			continue
+		case "(", ")":
+			continue
+		}
+		// The "+" operator includes later versions of a license.
+		id, plus := strings.CutSuffix(field, "+")
+		canonical, ok := license.Identifier(id)
+		if !ok {
+			return "", false
+		}
+		if plus {
+			canonical += "+"
+		}
+		fields[i] = canonical
+	}
+	expr := strings.Join(fields, " ")
+	return strings.NewReplacer("( ", "(", " )", ")").Replace(expr), true
+}
@@ -0,0 +1,126 @@
+package sbom
+
+import (
+	"time"
+
+	rpmdb "github.com/knqyf263/go-rpmdb/pkg"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/inventory"
+)
+
+const testDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+// testImage returns an image with RPMs built from two source RPMs, and an
+// inventory with a component of each ecosystem.
+func testImage() Image {
+	epoch := 1
+	return Image{
+		Reference:    "registry.example.com/org/app:1.0",
+		Repository:   "registry.example.com/org/app",
+		Digest:       testDigest,
+		Architecture: "amd64",
+		Created:      time.Date(2024, 5, 1, 12, 0, 0, 0, time.FixedZone("EDT", -4*60*60)),
+		Packages: []*rpmdb.PackageInfo{
+			{Name: "bash", Version: "5.1.8", Release: "6.el9", Arch: "x86_64", SourceRpm: "bash-5.1.8-6.el9.src.rpm", License: "GPLv3+", Vendor: "Red Hat, Inc.", SigMD5: "d41d8cd98f00b204e9800998ecf8427e"},
+			{Name: "bash-doc", Version: "5.1.8", Release: "6.el9", Arch: "noarch", SourceRpm: "bash-5.1.8-6.el9.src.rpm", License: "GPLv3+", Vendor: "Red Hat, Inc."},
+			{Name: "openssl-libs", Epoch: &epoch, Version: "3.0.7", Release: "24.el9", Arch: "x86_64", SourceRpm: "openssl-3.0.7-24.el9.src.rpm", License: "apache-2.0", Vendor: "Red Hat, Inc."},
+			{Name: "custom", Version: "1.0", Release: "1", Arch: "x86_64", License: "GPL-2.0-or-later AND (MIT OR bsd-3-clause)"},
+		},
+		Inventory: &inventory.Inventory{Components: []inventory.Component{
+			{Ecosystem: inventory.EcosystemGo, Name: "stdlib", Version: "go1.22.1", Path: "/usr/local/bin/app", Layer: 2, LayerDigest: "sha256:feed"},
+			{Ecosystem: inventory.EcosystemJava, Name: "plain", Version: "2.0", Path: "/opt/app/plain.war", Layer: -1},
+		}},
+	}
+}
+
+var _ = Describe("SBOM", func() {
+	Context("When parsing formats", func() {
+		It("should return each format once", func() {
+			formats, err := ParseFormats([]string{"SPDX", "cyclonedx", "spdx", "none"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(formats).To(Equal([]Format{FormatSPDX, FormatCycloneDX}))
+		})
+
+		It("should return no formats for none", func() {
+			formats, err := ParseFormats([]string{"none"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(formats).To(BeEmpty())
+		})
+
+		It("should fail for an unknown format", func() {
+			_, err := ParseFormats([]string{"xml"})
+			Expect(err).To(MatchError(`unknown SBOM format "xml": must be one of spdx, cyclonedx or none`))
+		})
+	})
+
+	It("should fail to generate an unknown format", func() {
+		_, err := Generate(Format("xml"), testImage())
+		Expect(err).To(MatchError(`unknown SBOM format "xml"`))
+	})
+
+	Context("When listing packages", func() {
+		It("should share source RPMs between the binary RPMs built from them", func() {
+			pkgs, sources := packages(testImage())
+			Expect(pkgs).To(HaveLen(6))
+			Expect(sources).To(HaveLen(2))
+			Expect(pkgs[0].source).To(BeIdenticalTo(sources[0]))
+			Expect(pkgs[1].source).To(BeIdenticalTo(sources[0]))
+			Expect(pkgs[3].source).To(BeNil())
+			Expect(*sources[1]).To(Equal(pkg{
+				id:       "SourcePackage-2",
+				name:     "openssl",
+				version:  "1:3.0.7-24.el9",
+				supplier: "Red Hat, Inc.",
+				license:  "apache-2.0",
+				purl:     "pkg:rpm/redhat/openssl@3.0.7-24.el9?arch=src&epoch=1",
+			}))
+		})
+
+		It("should describe where each component was found", func() {
+			pkgs, _ := packages(testImage())
+			Expect(pkgs[4].location).To(Equal("/usr/local/bin/app, added in layer 2 (sha256:feed)"))
+			Expect(pkgs[5].location).To(Equal("/opt/app/plain.war"))
+		})
+
+		DescribeTable("should parse the name, version and release of a source RPM",
+			func(srpm string, name string, version string) {
+				source := sourceRPM(&rpmdb.PackageInfo{SourceRpm: srpm}, 1)
+				Expect(source.name).To(Equal(name))
+				Expect(source.version).To(Equal(version))
+			},
+			Entry("with a release", "python3.11-pip-22.3.1-4.el9.src.rpm", "python3.11-pip", "22.3.1-4.el9"),
+			Entry("without a source suffix", "zlib-1.2.11-40.el9.rpm", "zlib", "1.2.11-40.el9"),
+			Entry("without a release", "unreleased-1.0.nosrc.rpm", "unreleased", "1.0"),
+			Entry("without a version", "malformed.src.rpm", "malformed", ""),
+		)
+	})
+
+	DescribeTable("should convert declared licenses to SPDX license expressions",
+		func(declared string, expected string, ok bool) {
+			expr, valid := licenseExpression(declared)
+			Expect(valid).To(Equal(ok))
+			Expect(expr).To(Equal(expected))
+		},
+		Entry("an identifier", "mit", "MIT", true),
+		Entry("an identifier with later versions", "GPL-2.0+", "GPL-2.0+", true),
+		Entry("an expression", "GPL-2.0-or-later and (MIT or bsd-3-clause)", "GPL-2.0-or-later AND (MIT OR BSD-3-Clause)", true),
+		Entry("a Fedora short name", "GPLv2+ with exceptions", "", false),
+		Entry("nothing", "  ", "", false),
+	)
+
+	DescribeTable("should return the package URL of components",
+		func(c inventory.Component, purl string) {
+			Expect(componentPURL(c)).To(Equal(purl))
+		},
+		Entry("Go toolchain", inventory.Component{Ecosystem: inventory.EcosystemGo, Name: "stdlib", Version: "go1.22.1"}, "pkg:golang/stdlib@1.22.1"),
+		Entry("Go module", inventory.Component{Ecosystem: inventory.EcosystemGo, Name: "github.com/Example/mod", Version: "v2.0.0+incompatible"}, "pkg:golang/github.com/Example/mod@v2.0.0%2Bincompatible"),
+		Entry("Go main module", inventory.Component{Ecosystem: inventory.EcosystemGo, Name: "example.com/app", Version: "(devel)"}, "pkg:golang/example.com/app"),
+		Entry("Python", inventory.Component{Ecosystem: inventory.EcosystemPython, Name: "Foo_Bar.baz", Version: "1.0"}, "pkg:pypi/foo-bar-baz@1.0"),
+		Entry("Node", inventory.Component{Ecosystem: inventory.EcosystemNode, Name: "@types/node", Version: "20.1.0"}, "pkg:npm/%40types/node@20.1.0"),
+		Entry("Maven", inventory.Component{Ecosystem: inventory.EcosystemJava, Name: "org.example:app", Version: "1.2.3"}, "pkg:maven/org.example/app@1.2.3"),
+		Entry("Java without Maven metadata", inventory.Component{Ecosystem: inventory.EcosystemJava, Name: "plain", Version: "2.0"}, ""),
+		Entry("unknown ecosystem", inventory.Component{Ecosystem: "cobol", Name: "ledger"}, ""),
+	)
+})
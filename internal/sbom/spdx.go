@@ -0,0 +1,214 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/version"
+)
+
+const spdxNoAssertion = "NOASSERTION"
+
+// spdxInvalidIDChars are the characters not permitted in SPDX identifiers.
+var spdxInvalidIDChars = regexp.MustCompile(`[^A-Za-z0-9.-]`)
+
+type spdxDocument struct {
+	SPDXVersion                string                 `json:"spdxVersion"`
+	DataLicense                string                 `json:"dataLicense"`
+	SPDXID                     string                 `json:"SPDXID"`
+	Name                       string                 `json:"name"`
+	DocumentNamespace          string                 `json:"documentNamespace"`
+	CreationInfo               spdxCreationInfo       `json:"creationInfo"`
+	Packages                   []spdxPackage          `json:"packages"`
+	Relationships              []spdxRelationship     `json:"relationships"`
+	HasExtractedLicensingInfos []spdxExtractedLicense `json:"hasExtractedLicensingInfos,omitempty"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	SPDXID                string            `json:"SPDXID"`
+	Name                  string            `json:"name"`
+	VersionInfo           string            `json:"versionInfo,omitempty"`
+	Supplier              string            `json:"supplier"`
+	DownloadLocation      string            `json:"downloadLocation"`
+	FilesAnalyzed         bool              `json:"filesAnalyzed"`
+	Checksums             []spdxChecksum    `json:"checksums,omitempty"`
+	LicenseConcluded      string            `json:"licenseConcluded"`
+	LicenseDeclared       string            `json:"licenseDeclared"`
+	CopyrightText         string            `json:"copyrightText"`
+	Comment               string            `json:"comment,omitempty"`
+	ExternalRefs          []spdxExternalRef `json:"externalRefs,omitempty"`
+	PrimaryPackagePurpose string            `json:"primaryPackagePurpose,omitempty"`
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+type spdxExtractedLicense struct {
+	LicenseID     string `json:"licenseId"`
+	Name          string `json:"name"`
+	ExtractedText string `json:"extractedText"`
+}
+
+// generateSPDX returns the SPDX 2.3 document of img. The image is the package
+// the document describes, and contains every other package. Binary RPMs are
+// generated from their source RPM.
+func generateSPDX(img Image) ([]byte, error) {
+	doc := spdxDocument{
+		SPDXVersion: "SPDX-2.3",
+		DataLicense: "CC0-1.0",
+		SPDXID:      "SPDXRef-DOCUMENT",
+		Name:        img.Reference,
+		// The namespace must be unique, but need not be resolvable.
+		DocumentNamespace: "https://" + version.Version.Name + "/spdx/" + spdxInvalidIDChars.ReplaceAllString(img.Reference, "-") + "-" + uuid.NewString(),
+		CreationInfo: spdxCreationInfo{
+			Created:  img.Created.UTC().Format("2006-01-02T15:04:05Z"),
+			Creators: []string{"Tool: " + toolName() + "-" + version.Version.Version},
+		},
+	}
+
+	image := spdxPackage{
+		SPDXID:                "SPDXRef-Image",
+		Name:                  img.Reference,
+		VersionInfo:           img.Digest,
+		Supplier:              spdxNoAssertion,
+		DownloadLocation:      spdxNoAssertion,
+		LicenseConcluded:      spdxNoAssertion,
+		LicenseDeclared:       spdxNoAssertion,
+		CopyrightText:         spdxNoAssertion,
+		PrimaryPackagePurpose: "CONTAINER",
+	}
+	if hex, ok := strings.CutPrefix(img.Digest, "sha256:"); ok {
+		image.Checksums = []spdxChecksum{{Algorithm: "SHA256", ChecksumValue: hex}}
+		if img.Repository != "" {
+			image.ExternalRefs = purlRef(imagePURL(img.Repository, img.Digest, img.Architecture))
+		}
+	}
+	doc.Packages = append(doc.Packages, image)
+	doc.Relationships = append(doc.Relationships, spdxRelationship{
+		SPDXElementID:      doc.SPDXID,
+		RelationshipType:   "DESCRIBES",
+		RelatedSPDXElement: image.SPDXID,
+	})
+
+	licenses := spdxLicenses{ids: map[string]string{}, used: map[string]bool{}}
+	pkgs, sources := packages(img)
+	for _, p := range pkgs {
+		doc.Packages = append(doc.Packages, licenses.spdxPackage(p, ""))
+		doc.Relationships = append(doc.Relationships, spdxRelationship{
+			SPDXElementID:      image.SPDXID,
+			RelationshipType:   "CONTAINS",
+			RelatedSPDXElement: "SPDXRef-" + p.id,
+		})
+		if p.source != nil {
+			doc.Relationships = append(doc.Relationships, spdxRelationship{
+				SPDXElementID:      "SPDXRef-" + p.id,
+				RelationshipType:   "GENERATED_FROM",
+				RelatedSPDXElement: "SPDXRef-" + p.source.id,
+			})
+		}
+	}
+	for _, p := range sources {
+		doc.Packages = append(doc.Packages, licenses.spdxPackage(p, "SOURCE"))
+	}
+	doc.HasExtractedLicensingInfos = licenses.extracted
+
+	b, err := json.MarshalIndent(doc, "", "    ")
+	if err != nil {
+		//coverage:ignore
+		return nil, fmt.Errorf("could not marshal SPDX document: %w", err)
+	}
+	return b, nil
+}
+
+// spdxLicenses assigns LicenseRef identifiers to the declared licenses that
+// are not SPDX license expressions.
+type spdxLicenses struct {
+	// ids are the LicenseRef identifiers, by declared license.
+	ids       map[string]string
+	used      map[string]bool
+	extracted []spdxExtractedLicense
+}
+
+// spdxPackage returns p as an SPDX package with purpose.
+func (l *spdxLicenses) spdxPackage(p *pkg, purpose string) spdxPackage {
+	sp := spdxPackage{
+		SPDXID:                "SPDXRef-" + p.id,
+		Name:                  p.name,
+		VersionInfo:           p.version,
+		Supplier:              spdxNoAssertion,
+		DownloadLocation:      spdxNoAssertion,
+		LicenseConcluded:      spdxNoAssertion,
+		LicenseDeclared:       l.declared(p.license),
+		CopyrightText:         spdxNoAssertion,
+		PrimaryPackagePurpose: purpose,
+	}
+	if p.supplier != "" {
+		sp.Supplier = "Organization: " + p.supplier
+	}
+	if p.md5 != "" {
+		sp.Checksums = []spdxChecksum{{Algorithm: "MD5", ChecksumValue: p.md5}}
+	}
+	if p.purl != "" {
+		sp.ExternalRefs = purlRef(p.purl)
+	}
+	if p.location != "" {
+		sp.Comment = "Found in " + p.location
+	}
+	return sp
+}
+
+// declared returns the licenseDeclared of a package that declares license.
+func (l *spdxLicenses) declared(license string) string {
+	if license == "" {
+		return spdxNoAssertion
+	}
+	if expr, ok := licenseExpression(license); ok {
+		return expr
+	}
+	if id, ok := l.ids[license]; ok {
+		return id
+	}
+
+	base := strings.Trim(spdxInvalidIDChars.ReplaceAllString(license, "-"), "-")
+	if base == "" {
+		base = "license"
+	}
+	// Declared licenses that differ only in invalid characters, e.g.
+	// GPLv2 and GPLv2+, are distinct licenses.
+	id := "LicenseRef-" + base
+	for n := 2; l.used[id]; n++ {
+		id = fmt.Sprintf("LicenseRef-%s-%d", base, n)
+	}
+	l.ids[license] = id
+	l.used[id] = true
+	l.extracted = append(l.extracted, spdxExtractedLicense{LicenseID: id, Name: license, ExtractedText: license})
+	return id
+}
+
+// purlRef returns the external references of a package with purl.
+func purlRef(purl string) []spdxExternalRef {
+	return []spdxExternalRef{{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: purl}}
+}
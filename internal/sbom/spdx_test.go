@@ -0,0 +1,111 @@
+package sbom
+
+import (
+	"encoding/json"
+
+	rpmdb "github.com/knqyf263/go-rpmdb/pkg"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/version"
+)
+
+var _ = Describe("SPDX", func() {
+	var doc spdxDocument
+
+	BeforeEach(func() {
+		b, err := Generate(FormatSPDX, testImage())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(json.Unmarshal(b, &doc)).To(Succeed())
+	})
+
+	It("should describe the image", func() {
+		Expect(doc.SPDXVersion).To(Equal("SPDX-2.3"))
+		Expect(doc.DataLicense).To(Equal("CC0-1.0"))
+		Expect(doc.DocumentNamespace).To(MatchRegexp(`^https://github.com/redhat-openshift-ecosystem/openshift-preflight/spdx/registry.example.com-org-app-1.0-[0-9a-f-]{36}$`))
+		Expect(doc.CreationInfo).To(Equal(spdxCreationInfo{Created: "2024-05-01T16:00:00Z", Creators: []string{"Tool: openshift-preflight-" + version.Version.Version}}))
+		Expect(doc.Packages[0]).To(Equal(spdxPackage{
+			SPDXID:                "SPDXRef-Image",
+			Name:                  "registry.example.com/org/app:1.0",
+			VersionInfo:           testDigest,
+			Supplier:              spdxNoAssertion,
+			DownloadLocation:      spdxNoAssertion,
+			Checksums:             []spdxChecksum{{Algorithm: "SHA256", ChecksumValue: testDigest[len("sha256:"):]}},
+			LicenseConcluded:      spdxNoAssertion,
+			LicenseDeclared:       spdxNoAssertion,
+			CopyrightText:         spdxNoAssertion,
+			ExternalRefs:          purlRef("pkg:oci/app@sha256%3A" + testDigest[len("sha256:"):] + "?arch=amd64&repository_url=registry.example.com%2Forg%2Fapp"),
+			PrimaryPackagePurpose: "CONTAINER",
+		}))
+	})
+
+	It("should include the license, checksum and package URL of each RPM", func() {
+		Expect(doc.Packages[1]).To(Equal(spdxPackage{
+			SPDXID:           "SPDXRef-Package-1",
+			Name:             "bash",
+			VersionInfo:      "5.1.8-6.el9",
+			Supplier:         "Organization: Red Hat, Inc.",
+			DownloadLocation: spdxNoAssertion,
+			Checksums:        []spdxChecksum{{Algorithm: "MD5", ChecksumValue: "d41d8cd98f00b204e9800998ecf8427e"}},
+			LicenseConcluded: spdxNoAssertion,
+			LicenseDeclared:  "LicenseRef-GPLv3",
+			CopyrightText:    spdxNoAssertion,
+			ExternalRefs:     purlRef("pkg:rpm/redhat/bash@5.1.8-6.el9?arch=x86_64"),
+		}))
+		Expect(doc.Packages[3].LicenseDeclared).To(Equal("Apache-2.0"))
+		Expect(doc.Packages[3].VersionInfo).To(Equal("1:3.0.7-24.el9"))
+		Expect(doc.Packages[4].LicenseDeclared).To(Equal("GPL-2.0-or-later AND (MIT OR BSD-3-Clause)"))
+		Expect(doc.Packages[4].Supplier).To(Equal(spdxNoAssertion))
+		Expect(doc.Packages[4].ExternalRefs).To(Equal(purlRef("pkg:rpm/custom@1.0-1?arch=x86_64")))
+		Expect(doc.HasExtractedLicensingInfos).To(Equal([]spdxExtractedLicense{{LicenseID: "LicenseRef-GPLv3", Name: "GPLv3+", ExtractedText: "GPLv3+"}}))
+	})
+
+	It("should include the components of the inventory", func() {
+		Expect(doc.Packages[5].Name).To(Equal("stdlib"))
+		Expect(doc.Packages[5].ExternalRefs).To(Equal(purlRef("pkg:golang/stdlib@1.22.1")))
+		Expect(doc.Packages[5].Comment).To(Equal("Found in /usr/local/bin/app, added in layer 2 (sha256:feed)"))
+		Expect(doc.Packages[6].Name).To(Equal("plain"))
+		Expect(doc.Packages[6].ExternalRefs).To(BeEmpty())
+	})
+
+	It("should relate each RPM to the source RPM it was generated from", func() {
+		Expect(doc.Packages[7].SPDXID).To(Equal("SPDXRef-SourcePackage-1"))
+		Expect(doc.Packages[7].PrimaryPackagePurpose).To(Equal("SOURCE"))
+		Expect(doc.Packages[7].ExternalRefs).To(Equal(purlRef("pkg:rpm/redhat/bash@5.1.8-6.el9?arch=src")))
+		Expect(doc.Packages).To(HaveLen(9))
+		Expect(doc.Relationships).To(ContainElements(
+			spdxRelationship{SPDXElementID: "SPDXRef-DOCUMENT", RelationshipType: "DESCRIBES", RelatedSPDXElement: "SPDXRef-Image"},
+			spdxRelationship{SPDXElementID: "SPDXRef-Image", RelationshipType: "CONTAINS", RelatedSPDXElement: "SPDXRef-Package-6"},
+			spdxRelationship{SPDXElementID: "SPDXRef-Package-1", RelationshipType: "GENERATED_FROM", RelatedSPDXElement: "SPDXRef-SourcePackage-1"},
+			spdxRelationship{SPDXElementID: "SPDXRef-Package-2", RelationshipType: "GENERATED_FROM", RelatedSPDXElement: "SPDXRef-SourcePackage-1"},
+			spdxRelationship{SPDXElementID: "SPDXRef-Package-3", RelationshipType: "GENERATED_FROM", RelatedSPDXElement: "SPDXRef-SourcePackage-2"},
+		))
+		Expect(doc.Relationships).To(HaveLen(1 + 6 + 3))
+	})
+
+	It("should describe an image without a digest", func() {
+		b, err := Generate(FormatSPDX, Image{Reference: "app.tar"})
+		Expect(err).ToNot(HaveOccurred())
+		var doc spdxDocument
+		Expect(json.Unmarshal(b, &doc)).To(Succeed())
+		Expect(doc.Packages).To(HaveLen(1))
+		Expect(doc.Packages[0].Checksums).To(BeEmpty())
+		Expect(doc.Packages[0].ExternalRefs).To(BeEmpty())
+	})
+
+	It("should assign distinct LicenseRefs to licenses that differ in invalid characters", func() {
+		b, err := Generate(FormatSPDX, Image{Packages: []*rpmdb.PackageInfo{
+			{Name: "a", License: "GPLv2"},
+			{Name: "b", License: "GPLv2+"},
+			{Name: "c", License: "GPLv2"},
+			{Name: "d", License: "???"},
+		}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(json.Unmarshal(b, &doc)).To(Succeed())
+		Expect(doc.Packages[1].LicenseDeclared).To(Equal("LicenseRef-GPLv2"))
+		Expect(doc.Packages[2].LicenseDeclared).To(Equal("LicenseRef-GPLv2-2"))
+		Expect(doc.Packages[3].LicenseDeclared).To(Equal("LicenseRef-GPLv2"))
+		Expect(doc.Packages[4].LicenseDeclared).To(Equal("LicenseRef-license"))
+		Expect(doc.HasExtractedLicensingInfos).To(HaveLen(3))
+	})
+})
@@ -0,0 +1,107 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"sigs.k8s.io/yaml"
+)
+
+// Allowance permits secrets that are known not to be sensitive, such as the
+// test keys shipped by some packages. An allowance applies to a secret that
+// matches every field that is set.
+type Allowance struct {
+	// Rule is the ID of the rule that found the secret, e.g. private-key.
+	Rule string `json:"rule,omitempty"`
+	// Path is a glob the path of the file the secret was found in must
+	// match, e.g. /usr/lib/python3*/test/**. Secrets found in the image
+	// config never match a path.
+	Path string `json:"path,omitempty"`
+	// Fingerprint is the fingerprint of the secret, as reported when it was
+	// found, e.g. sha256:3a6e....
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// Justification explains why the secret is allowed.
+	Justification string `json:"justification"`
+}
+
+// Allowlist is a list of allowances.
+type Allowlist []Allowance
+
+// allowlistFile is the contents of an allowlist file.
+type allowlistFile struct {
+	Allow Allowlist `json:"allow"`
+}
+
+// LoadAllowlist reads the allowlist file at path.
+func LoadAllowlist(path string) (Allowlist, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read secrets allowlist: %w", err)
+	}
+
+	allowlist, err := ParseAllowlist(b)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secrets allowlist in %s: %w", path, err)
+	}
+
+	return allowlist, nil
+}
+
+// ParseAllowlist reads the allowlist in the YAML or JSON document b.
+func ParseAllowlist(b []byte) (Allowlist, error) {
+	var f allowlistFile
+	if err := yaml.UnmarshalStrict(b, &f); err != nil {
+		return nil, err
+	}
+
+	for i, a := range f.Allow {
+		if err := a.validate(); err != nil {
+			return nil, fmt.Errorf("allowance %d: %w", i, err)
+		}
+	}
+
+	return f.Allow, nil
+}
+
+func (a Allowance) validate() error {
+	if a.Rule == "" && a.Path == "" && a.Fingerprint == "" {
+		return errors.New("one of rule, path or fingerprint is required")
+	}
+	if a.Justification == "" {
+		return errors.New("justification is required")
+	}
+	if a.Rule != "" && !knownRule(a.Rule) {
+		return fmt.Errorf("unknown rule %q", a.Rule)
+	}
+	if a.Path != "" && !doublestar.ValidatePattern(a.Path) {
+		return fmt.Errorf("invalid path pattern %q", a.Path)
+	}
+	if a.Fingerprint != "" && !strings.HasPrefix(a.Fingerprint, "sha256:") {
+		return fmt.Errorf("invalid fingerprint %q: expected sha256:<hex>", a.Fingerprint)
+	}
+	return nil
+}
+
+// Allows returns the allowance in l that permits m, found in the file at
+// path. path is empty if m was not found in a file.
+func (l Allowlist) Allows(m Match, path string) (Allowance, bool) {
+	for _, a := range l {
+		if a.Rule != "" && a.Rule != m.Rule {
+			continue
+		}
+		if a.Fingerprint != "" && a.Fingerprint != m.Fingerprint() {
+			continue
+		}
+		if a.Path != "" {
+			// The pattern was validated when the allowlist was parsed.
+			if ok, _ := doublestar.Match(strings.TrimPrefix(a.Path, "/"), strings.TrimPrefix(path, "/")); path == "" || !ok {
+				continue
+			}
+		}
+		return a, true
+	}
+	return Allowance{}, false
+}
@@ -0,0 +1,98 @@
+package secrets
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Secrets allowlist", func() {
+	Context("when loading an allowlist", func() {
+		var dir string
+
+		BeforeEach(func() {
+			dir = GinkgoT().TempDir()
+		})
+
+		It("should load a valid allowlist", func() {
+			path := filepath.Join(dir, "allowlist.yaml")
+			Expect(os.WriteFile(path, []byte(`allow:
+- rule: private-key
+  path: /usr/lib/python3*/test/**
+  justification: test keys shipped by python3
+- fingerprint: sha256:2bb80d537b1da3e38bd30361aa855686bde0eacd7162fef6a25fe97bf527a25b
+  justification: a public sample credential
+`), 0o644)).To(Succeed())
+
+			allowlist, err := LoadAllowlist(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(allowlist).To(HaveLen(2))
+			Expect(allowlist[0]).To(Equal(Allowance{
+				Rule:          "private-key",
+				Path:          "/usr/lib/python3*/test/**",
+				Justification: "test keys shipped by python3",
+			}))
+		})
+
+		It("should fail if the allowlist cannot be read", func() {
+			_, err := LoadAllowlist(filepath.Join(dir, "missing.yaml"))
+			Expect(err).To(MatchError(ContainSubstring("could not read secrets allowlist")))
+		})
+
+		It("should fail if the allowlist is invalid", func() {
+			path := filepath.Join(dir, "allowlist.yaml")
+			Expect(os.WriteFile(path, []byte("allow:\n- rule: private-key\n"), 0o644)).To(Succeed())
+
+			_, err := LoadAllowlist(path)
+			Expect(err).To(MatchError(ContainSubstring("invalid secrets allowlist in " + path)))
+		})
+	})
+
+	DescribeTable("should reject invalid allowlists",
+		func(doc string, expected string) {
+			_, err := ParseAllowlist([]byte(doc))
+			Expect(err).To(MatchError(ContainSubstring(expected)))
+		},
+		Entry("that are not YAML", "allow: [", "error converting YAML to JSON"),
+		Entry("with unknown fields", "allow:\n- rule: private-key\n  reason: test\n", "unknown field"),
+		Entry("with an allowance that matches every secret", "allow:\n- justification: test\n", "allowance 0: one of rule, path or fingerprint is required"),
+		Entry("without a justification", "allow:\n- rule: private-key\n", "justification is required"),
+		Entry("with an unknown rule", "allow:\n- rule: password\n  justification: test\n", `unknown rule "password"`),
+		Entry("with an invalid path", "allow:\n- path: /opt/[\n  justification: test\n", `invalid path pattern "/opt/["`),
+		Entry("with an invalid fingerprint", "allow:\n- fingerprint: abc\n  justification: test\n", `invalid fingerprint "abc"`),
+	)
+
+	Context("when matching secrets", func() {
+		match := Match{Rule: "private-key", Secret: "secret"}
+		allowlist := Allowlist{
+			{Rule: "github-token", Justification: "any token"},
+			{Rule: "private-key", Path: "/usr/lib/python3*/test/**", Justification: "test keys"},
+			{Fingerprint: "sha256:2bb80d537b1da3e38bd30361aa855686bde0eacd7162fef6a25fe97bf527a25b", Path: "/etc/**", Justification: "sample"},
+		}
+
+		It("should allow a secret that matches every field of an allowance", func() {
+			allowance, ok := allowlist.Allows(match, "/usr/lib/python3.9/test/keycert.pem")
+			Expect(ok).To(BeTrue())
+			Expect(allowance.Justification).To(Equal("test keys"))
+
+			allowance, ok = allowlist.Allows(match, "etc/pki/sample.pem")
+			Expect(ok).To(BeTrue())
+			Expect(allowance.Justification).To(Equal("sample"))
+		})
+
+		It("should not allow a secret that matches some of the fields of an allowance", func() {
+			_, ok := allowlist.Allows(match, "/opt/app/key.pem")
+			Expect(ok).To(BeFalse())
+
+			_, ok = allowlist.Allows(Match{Rule: "private-key", Secret: "other"}, "/etc/key.pem")
+			Expect(ok).To(BeFalse())
+		})
+
+		It("should not allow a secret outside a file by its path", func() {
+			_, ok := allowlist.Allows(match, "")
+			Expect(ok).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,178 @@
+// Package secrets finds credentials, such as private keys, cloud access keys
+// and registry auths, in the contents of files and in other text.
+package secrets
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// rule describes a kind of secret.
+type rule struct {
+	// id identifies the rule in an allowlist, e.g. private-key.
+	id string
+	// description names the kind of secret the rule finds.
+	description string
+	// pattern finds the secret, in its last capture group.
+	pattern *regexp.Regexp
+	// paths, if set, are the globs a file's path must match for the rule
+	// to apply. Rules with paths never apply to text that is not a file.
+	paths []string
+	// requires, if set, must match the text for the rule to apply.
+	requires *regexp.Regexp
+	// minEntropy is the minimum Shannon entropy, in bits per character, of
+	// a secret. It excludes placeholders and other values that are
+	// unlikely to be real credentials.
+	minEntropy float64
+}
+
+// rules are the built-in rules, from most to least specific. A secret
+// found by more than one rule is reported by the first.
+var rules = []rule{
+	{
+		id:          "private-key",
+		description: "private key",
+		pattern:     regexp.MustCompile(`(?s)-----BEGIN[A-Z0-9 ]* PRIVATE KEY( BLOCK)?-----(.+?)-----END`),
+	},
+	{
+		id:          "aws-access-key-id",
+		description: "AWS access key ID",
+		pattern:     regexp.MustCompile(`\b((?:AKIA|ASIA)[0-9A-Z]{16})\b`),
+	},
+	{
+		id:          "aws-secret-access-key",
+		description: "AWS secret access key",
+		pattern:     regexp.MustCompile(`(?i)aws_?secret_?access_?key["']?\s*[:=]\s*["']?([A-Za-z0-9/+=]{40})\b`),
+	},
+	{
+		id:          "github-token",
+		description: "GitHub token",
+		pattern:     regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,255})\b`),
+	},
+	{
+		id:          "docker-config",
+		description: "container registry credentials",
+		pattern:     regexp.MustCompile(`"auth"\s*:\s*"([A-Za-z0-9+/=]{8,})"`),
+		paths:       []string{"**/.dockercfg", "**/.docker/config.json", "**/containers/auth.json"},
+	},
+	{
+		id:          "kubeconfig",
+		description: "kubeconfig credentials",
+		pattern:     regexp.MustCompile(`(?m)^\s*(?:client-key-data|token|password):\s*["']?([^\s"']{8,})`),
+		requires:    regexp.MustCompile(`(?m)^kind:\s*["']?Config["']?\s*$`),
+	},
+	{
+		id:          "generic-secret",
+		description: "high-entropy secret",
+		pattern:     regexp.MustCompile(`(?i)[a-z0-9_.-]*(?:secret|token|passwd|password|api_?key|access_?key)[a-z0-9_.-]*["']?\s*[:=]\s*["']?([A-Za-z0-9_\-+/=.~]{20,})`),
+		minEntropy:  4,
+	},
+}
+
+// Match is a secret found by a rule.
+type Match struct {
+	// Rule is the ID of the rule that found the secret.
+	Rule string
+	// Description names the kind of secret.
+	Description string
+	// Secret is the secret that was found. It should never be reported;
+	// use Redacted or Fingerprint instead.
+	Secret string
+	// Line is the line of the text the secret was found on, starting at 1.
+	Line int
+}
+
+// Redacted returns a form of the secret that can be safely reported. At most
+// four characters of the secret are revealed.
+func (m Match) Redacted() string {
+	shown := min(4, len(m.Secret)/4)
+	return m.Secret[:shown] + strings.Repeat("*", 8)
+}
+
+// Fingerprint identifies the secret without revealing it, so that it can
+// be allowed regardless of where it is found.
+func (m Match) Fingerprint() string {
+	sum := sha256.Sum256([]byte(m.Secret))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// Scan returns the secrets the built-in rules find in text. path is the path
+// of the file text was read from, or empty if text is not a file.
+func Scan(path string, text []byte) []Match {
+	var matches []Match
+	seen := map[string]bool{}
+	for _, r := range rules {
+		if !r.appliesTo(path, text) {
+			continue
+		}
+		for _, loc := range r.pattern.FindAllSubmatchIndex(text, -1) {
+			// The secret is the last capture group of the pattern.
+			start, end := loc[len(loc)-2], loc[len(loc)-1]
+			secret := strings.TrimSpace(string(text[start:end]))
+			if seen[secret] || entropy(secret) < r.minEntropy {
+				continue
+			}
+			seen[secret] = true
+			matches = append(matches, Match{
+				Rule:        r.id,
+				Description: r.description,
+				Secret:      secret,
+				Line:        bytes.Count(text[:start], []byte("\n")) + 1,
+			})
+		}
+	}
+	return matches
+}
+
+func (r rule) appliesTo(path string, text []byte) bool {
+	if len(r.paths) > 0 {
+		if path == "" {
+			return false
+		}
+		matched := false
+		for _, pattern := range r.paths {
+			if ok, _ := doublestar.Match(pattern, strings.TrimPrefix(path, "/")); ok {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return r.requires == nil || r.requires.Match(text)
+}
+
+// entropy returns the Shannon entropy of s, in bits per character.
+func entropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := map[rune]int{}
+	for _, r := range s {
+		counts[r]++
+	}
+	n := float64(len([]rune(s)))
+	var e float64
+	for _, c := range counts {
+		p := float64(c) / n
+		e -= p * math.Log2(p)
+	}
+	return e
+}
+
+// knownRule returns true if id is the ID of a built-in rule.
+func knownRule(id string) bool {
+	for _, r := range rules {
+		if r.id == id {
+			return true
+		}
+	}
+	return false
+}
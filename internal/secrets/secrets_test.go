@@ -0,0 +1,76 @@
+package secrets
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// The secrets below are split so that the test sources are not themselves
+// reported by secret scanners.
+const (
+	privateKey     = "-----BEGIN RSA " + "PRIVATE KEY-----\nMIIEowIBAAKCAQEAx4fK2Xz\nq9TfL3mN8pR2vW7sY4bK6dJ1\n-----END RSA PRIVATE KEY-----\n"
+	awsKeyID       = "AKIA" + "IOSFODNN7EXAMPLE"
+	awsSecret      = "wJalrXUtnFEMI/K7MDENG/" + "bPxRfiCYEXAMPLEKEY"
+	githubToken    = "ghp_" + "Zx9qT3vL8mN2pR7sW4yB6kD1fH5jG0aC2eU8"
+	genericSecret  = "Zx9qT3vL8mN2pR7s" + "W4yB6kD1"
+	registryAuth   = "dXNlcjpwYXNz" + "d29yZA=="
+	kubeconfigFile = `apiVersion: v1
+kind: Config
+users:
+- name: admin
+  user:
+    token: ` + "eyJhbGciOi" + `JSUzI1NiIsImtpZCI6
+`
+)
+
+var _ = Describe("Secret scanning", func() {
+	DescribeTable("should find secrets",
+		func(path string, text string, rule string, secret string, line int) {
+			matches := Scan(path, []byte(text))
+			Expect(matches).To(HaveLen(1))
+			Expect(matches[0].Rule).To(Equal(rule))
+			Expect(matches[0].Description).ToNot(BeEmpty())
+			Expect(matches[0].Secret).To(Equal(secret))
+			Expect(matches[0].Line).To(Equal(line))
+		},
+		Entry("a private key", "/etc/pki/tls/private/server.key", "\n"+privateKey, "private-key", "MIIEowIBAAKCAQEAx4fK2Xz\nq9TfL3mN8pR2vW7sY4bK6dJ1", 2),
+		Entry("an AWS access key ID", "", "key="+awsKeyID, "aws-access-key-id", awsKeyID, 1),
+		Entry("an AWS secret access key", "/root/.aws/credentials", "[default]\naws_secret_access_key = "+awsSecret+"\n", "aws-secret-access-key", awsSecret, 2),
+		Entry("a GitHub token", "/opt/app/.env", "GH="+githubToken, "github-token", githubToken, 1),
+		Entry("registry credentials", "/root/.docker/config.json", `{"auths":{"quay.io":{"auth":"`+registryAuth+`"}}}`, "docker-config", registryAuth, 1),
+		Entry("kubeconfig credentials", "/root/.kube/config", kubeconfigFile, "kubeconfig", "eyJhbGciOiJSUzI1NiIsImtpZCI6", 6),
+		Entry("a high-entropy secret", "", "API_TOKEN="+genericSecret, "generic-secret", genericSecret, 1),
+	)
+
+	DescribeTable("should not find secrets",
+		func(path string, text string) {
+			Expect(Scan(path, []byte(text))).To(BeEmpty())
+		},
+		Entry("in text without secrets", "/etc/os-release", "NAME=\"Red Hat Enterprise Linux\"\n"),
+		Entry("that are placeholders", "", "PASSWORD=changemechangemechangeme"),
+		Entry("registry credentials outside a registry config", "/opt/app/config.json", `{"auth":"`+registryAuth+`"}`),
+		Entry("registry credentials that are not a file", "", `{"auth":"`+registryAuth+`"}`),
+		Entry("kubeconfig credentials outside a kubeconfig", "/opt/app/config.yaml", "password: hunter2hunter2\n"),
+	)
+
+	It("should report a secret found by more than one rule once", func() {
+		matches := Scan("", []byte("aws_secret_access_key="+awsSecret+"\naws_secret_access_key="+awsSecret))
+		Expect(matches).To(HaveLen(1))
+		Expect(matches[0].Rule).To(Equal("aws-secret-access-key"))
+	})
+
+	It("should redact secrets", func() {
+		Expect(Match{Secret: githubToken}.Redacted()).To(Equal("ghp_********"))
+		Expect(Match{Secret: "abc"}.Redacted()).To(Equal("********"))
+	})
+
+	It("should fingerprint secrets", func() {
+		Expect(Match{Secret: "secret"}.Fingerprint()).To(Equal("sha256:2bb80d537b1da3e38bd30361aa855686bde0eacd7162fef6a25fe97bf527a25b"))
+	})
+
+	It("should compute the entropy of text", func() {
+		Expect(entropy("")).To(BeZero())
+		Expect(entropy("aaaa")).To(BeZero())
+		Expect(entropy("abcd")).To(Equal(2.0))
+	})
+})
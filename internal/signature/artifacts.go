@@ -0,0 +1,99 @@
+package signature
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+)
+
+// maxPayloadSize limits the size of the signature and attestation layers
+// that are read.
+const maxPayloadSize = 4 << 20
+
+// Artifact is a manifest attached to an image by cosign.
+type Artifact struct {
+	// Source is the reference the artifact was read from: a tag such as
+	// registry.example.com/org/app:sha256-<hex>.sig, or the digest of a
+	// referrer.
+	Source string
+	Image  v1.Image
+}
+
+// Artifacts returns the artifacts attached to the image with digest in repo.
+// cosign attaches them with a tag named for the digest, with suffix (e.g.
+// ".sig"), or with the OCI referrers API, with artifactType.
+func Artifacts(ctx context.Context, repo name.Repository, digest v1.Hash, suffix string, artifactType string, opts ...remote.Option) ([]Artifact, error) {
+	opts = append(opts, remote.WithContext(ctx))
+
+	var artifacts []Artifact
+	tag := repo.Tag(strings.Replace(digest.String(), ":", "-", 1) + suffix)
+	img, err := remote.Image(tag, opts...)
+	switch {
+	case err == nil:
+		artifacts = append(artifacts, Artifact{Source: tag.String(), Image: img})
+	case !isNotFound(err):
+		return nil, fmt.Errorf("could not read %s: %w", tag, err)
+	}
+
+	index, err := remote.Referrers(repo.Digest(digest.String()), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("could not list the referrers of %s: %w", digest, err)
+	}
+	manifest, err := index.IndexManifest()
+	if err != nil {
+		//coverage:ignore
+		return nil, fmt.Errorf("could not list the referrers of %s: %w", digest, err)
+	}
+	for _, desc := range manifest.Manifests {
+		if desc.ArtifactType != artifactType {
+			continue
+		}
+		ref := repo.Digest(desc.Digest.String())
+		img, err := remote.Image(ref, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("could not read %s: %w", ref, err)
+		}
+		artifacts = append(artifacts, Artifact{Source: ref.String(), Image: img})
+	}
+	return artifacts, nil
+}
+
+// isNotFound returns true if err is a registry response that a manifest does
+// not exist.
+func isNotFound(err error) bool {
+	var terr *transport.Error
+	return errors.As(err, &terr) && terr.StatusCode == http.StatusNotFound
+}
+
+// ReadLayer returns the contents of the layer of img described by desc, which
+// may be at most 4MiB.
+func ReadLayer(img v1.Image, desc v1.Descriptor) ([]byte, error) {
+	layer, err := img.LayerByDigest(desc.Digest)
+	if err != nil {
+		//coverage:ignore
+		return nil, err
+	}
+	rc, err := layer.Compressed()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	b, err := io.ReadAll(io.LimitReader(rc, maxPayloadSize+1))
+	if err != nil {
+		//coverage:ignore
+		return nil, err
+	}
+	if len(b) > maxPayloadSize {
+		return nil, fmt.Errorf("layer %s is larger than %d bytes", desc.Digest, maxPayloadSize)
+	}
+	return b, nil
+}
@@ -0,0 +1,140 @@
+package signature
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Key is a public key signatures are verified against.
+type Key struct {
+	PublicKey crypto.PublicKey
+	// Fingerprint is the SHA-256 digest of the DER encoded public key, e.g.
+	// sha256:1f0e8a....
+	Fingerprint string
+	// Identity is the subject of the certificate the key was read from: its
+	// email addresses and URIs, or else its common name. It is empty for
+	// bare public keys.
+	Identity string
+}
+
+// Signer names the signer of a signature key verifies.
+func (k Key) Signer() string {
+	if k.Identity != "" {
+		return k.Identity
+	}
+	return k.Fingerprint
+}
+
+// Keyring is a set of trusted public keys. A signature is verified if any of
+// them verifies it.
+type Keyring []Key
+
+// LoadKeyring reads the PEM encoded public keys and certificates in the file
+// at path, such as the cosign.pub written by cosign generate-key-pair.
+func LoadKeyring(path string) (Keyring, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read public keys: %w", err)
+	}
+	keys, err := ParseKeyring(data)
+	if err != nil {
+		return nil, fmt.Errorf("could not read public keys from %s: %w", path, err)
+	}
+	return keys, nil
+}
+
+// ParseKeyring returns the public keys and certificates PEM encoded in data.
+// ECDSA, RSA and Ed25519 keys are supported. Other PEM blocks are ignored.
+// The certificates are trusted as given: they are not verified against a
+// certificate authority.
+func ParseKeyring(data []byte) (Keyring, error) {
+	var keys Keyring
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+
+		var key Key
+		switch block.Type {
+		case "PUBLIC KEY":
+			pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse public key %d: %w", len(keys)+1, err)
+			}
+			key.PublicKey = pub
+		case "CERTIFICATE":
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse certificate %d: %w", len(keys)+1, err)
+			}
+			key.PublicKey = cert.PublicKey
+			key.Identity = certificateIdentity(cert)
+		default:
+			continue
+		}
+
+		switch key.PublicKey.(type) {
+		case *ecdsa.PublicKey, *rsa.PublicKey, ed25519.PublicKey:
+		default:
+			return nil, fmt.Errorf("public key %d: unsupported key type %T", len(keys)+1, key.PublicKey)
+		}
+		der, err := x509.MarshalPKIXPublicKey(key.PublicKey)
+		if err != nil {
+			//coverage:ignore
+			return nil, fmt.Errorf("public key %d: %w", len(keys)+1, err)
+		}
+		key.Fingerprint = fmt.Sprintf("sha256:%x", sha256.Sum256(der))
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no PEM encoded public keys or certificates found")
+	}
+	return keys, nil
+}
+
+// certificateIdentity returns the subject alternative names of cert that
+// identify a signer, or its common name if it has none.
+func certificateIdentity(cert *x509.Certificate) string {
+	identities := cert.EmailAddresses
+	for _, uri := range cert.URIs {
+		identities = append(identities, uri.String())
+	}
+	if len(identities) == 0 {
+		return cert.Subject.CommonName
+	}
+	return strings.Join(identities, ", ")
+}
+
+// Verify returns the key in k that verifies sig is a signature of message.
+// ECDSA and RSA signatures are of the SHA-256 digest of message, as cosign
+// creates them.
+func (k Keyring) Verify(message []byte, sig []byte) (Key, bool) {
+	digest := sha256.Sum256(message)
+	for _, key := range k {
+		var ok bool
+		switch pub := key.PublicKey.(type) {
+		case *ecdsa.PublicKey:
+			ok = ecdsa.VerifyASN1(pub, digest[:], sig)
+		case *rsa.PublicKey:
+			ok = rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig) == nil ||
+				rsa.VerifyPSS(pub, crypto.SHA256, digest[:], sig, nil) == nil
+		case ed25519.PublicKey:
+			ok = ed25519.Verify(pub, message, sig)
+		}
+		if ok {
+			return key, true
+		}
+	}
+	return Key{}, false
+}
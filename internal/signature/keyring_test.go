@@ -0,0 +1,155 @@
+package signature
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// publicKeyPEM returns pub PEM encoded.
+func publicKeyPEM(pub crypto.PublicKey) []byte {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	Expect(err).ToNot(HaveOccurred())
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+// certificatePEM returns a certificate for tmpl of the key of priv, self
+// signed, PEM encoded.
+func certificatePEM(priv *ecdsa.PrivateKey, tmpl *x509.Certificate) []byte {
+	tmpl.SerialNumber = big.NewInt(1)
+	tmpl.NotBefore = time.Now()
+	tmpl.NotAfter = time.Now().Add(time.Hour)
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &priv.PublicKey, priv)
+	Expect(err).ToNot(HaveOccurred())
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+// signECDSA signs message as cosign does with priv.
+func signECDSA(priv *ecdsa.PrivateKey, message []byte) []byte {
+	digest := sha256.Sum256(message)
+	sig, err := ecdsa.SignASN1(rand.Reader, priv, digest[:])
+	Expect(err).ToNot(HaveOccurred())
+	return sig
+}
+
+var _ = Describe("Keyring", func() {
+	var ecKey *ecdsa.PrivateKey
+
+	BeforeEach(func() {
+		var err error
+		ecKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	Context("loading a keyring", func() {
+		It("should read each public key and certificate", func() {
+			uri, err := url.Parse("https://github.com/org/app/.github/workflows/release.yaml@refs/heads/main")
+			Expect(err).ToNot(HaveOccurred())
+			data := publicKeyPEM(&ecKey.PublicKey)
+			data = append(data, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: []byte("ignored")})...)
+			data = append(data, certificatePEM(ecKey, &x509.Certificate{EmailAddresses: []string{"release@example.com"}, URIs: []*url.URL{uri}})...)
+			data = append(data, certificatePEM(ecKey, &x509.Certificate{Subject: pkix.Name{CommonName: "Example Release Key"}})...)
+			path := filepath.Join(GinkgoT().TempDir(), "cosign.pub")
+			Expect(os.WriteFile(path, data, 0o600)).To(Succeed())
+
+			keys, err := LoadKeyring(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(keys).To(HaveLen(3))
+			der, err := x509.MarshalPKIXPublicKey(&ecKey.PublicKey)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(keys[0].Fingerprint).To(Equal(fmt.Sprintf("sha256:%x", sha256.Sum256(der))))
+			Expect(keys[0].Signer()).To(Equal(keys[0].Fingerprint))
+			Expect(keys[1].Signer()).To(Equal("release@example.com, " + uri.String()))
+			Expect(keys[2].Signer()).To(Equal("Example Release Key"))
+		})
+		It("should fail when the file can not be read", func() {
+			_, err := LoadKeyring(filepath.Join(GinkgoT().TempDir(), "missing.pub"))
+			Expect(err).To(MatchError(ContainSubstring("could not read public keys")))
+		})
+		It("should fail when the file has no keys", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "cosign.pub")
+			Expect(os.WriteFile(path, []byte("not a key"), 0o600)).To(Succeed())
+			_, err := LoadKeyring(path)
+			Expect(err).To(MatchError(ContainSubstring("no PEM encoded public keys or certificates found")))
+		})
+		DescribeTable("should fail on invalid keys",
+			func(data []byte, expected string) {
+				_, err := ParseKeyring(data)
+				Expect(err).To(MatchError(ContainSubstring(expected)))
+			},
+			Entry("invalid public key", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: []byte("invalid")}), "could not parse public key 1"),
+			Entry("invalid certificate", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("invalid")}), "could not parse certificate 1"),
+		)
+		It("should fail on unsupported key types", func() {
+			x25519, err := ecdh.X25519().GenerateKey(rand.Reader)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = ParseKeyring(publicKeyPEM(x25519.PublicKey()))
+			Expect(err).To(MatchError(ContainSubstring("unsupported key type")))
+		})
+	})
+
+	Context("verifying a signature", func() {
+		message := []byte("payload")
+
+		It("should verify ECDSA signatures", func() {
+			keys, err := ParseKeyring(publicKeyPEM(&ecKey.PublicKey))
+			Expect(err).ToNot(HaveOccurred())
+			key, ok := keys.Verify(message, signECDSA(ecKey, message))
+			Expect(ok).To(BeTrue())
+			Expect(key).To(Equal(keys[0]))
+		})
+		It("should verify RSA signatures", func() {
+			rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+			Expect(err).ToNot(HaveOccurred())
+			keys, err := ParseKeyring(append(publicKeyPEM(&ecKey.PublicKey), publicKeyPEM(&rsaKey.PublicKey)...))
+			Expect(err).ToNot(HaveOccurred())
+
+			digest := sha256.Sum256(message)
+			pkcs1, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+			Expect(err).ToNot(HaveOccurred())
+			key, ok := keys.Verify(message, pkcs1)
+			Expect(ok).To(BeTrue())
+			Expect(key).To(Equal(keys[1]))
+
+			pss, err := rsa.SignPSS(rand.Reader, rsaKey, crypto.SHA256, digest[:], nil)
+			Expect(err).ToNot(HaveOccurred())
+			_, ok = keys.Verify(message, pss)
+			Expect(ok).To(BeTrue())
+		})
+		It("should verify Ed25519 signatures", func() {
+			pub, priv, err := ed25519.GenerateKey(rand.Reader)
+			Expect(err).ToNot(HaveOccurred())
+			keys, err := ParseKeyring(publicKeyPEM(pub))
+			Expect(err).ToNot(HaveOccurred())
+			_, ok := keys.Verify(message, ed25519.Sign(priv, message))
+			Expect(ok).To(BeTrue())
+		})
+		It("should not verify signatures of other keys or messages", func() {
+			other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			Expect(err).ToNot(HaveOccurred())
+			keys, err := ParseKeyring(publicKeyPEM(&ecKey.PublicKey))
+			Expect(err).ToNot(HaveOccurred())
+			_, ok := keys.Verify(message, signECDSA(other, message))
+			Expect(ok).To(BeFalse())
+			_, ok = keys.Verify([]byte("other payload"), signECDSA(ecKey, message))
+			Expect(ok).To(BeFalse())
+		})
+	})
+})
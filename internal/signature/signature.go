@@ -0,0 +1,134 @@
+// Package signature verifies the cosign signatures of an image against
+// public keys supplied by the user. Signatures are read from the registry the
+// image is in, and no transparency log is consulted, so that images can be
+// verified in disconnected environments.
+package signature
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+const (
+	// SimpleSigningMediaType is the media type of the layers of a cosign
+	// signature manifest. Each is a payload that was signed.
+	SimpleSigningMediaType = "application/vnd.dev.cosign.simplesigning.v1+json"
+	// SignatureAnnotation is the layer annotation holding the base64 encoded
+	// signature of the payload.
+	SignatureAnnotation = "dev.cosignproject.cosign/signature"
+	// SignatureArtifactType is the artifact type of signatures attached with
+	// the OCI referrers API.
+	SignatureArtifactType = "application/vnd.dev.cosign.artifact.sig.v1+json"
+	// SignatureTagSuffix is the suffix of the tag signatures are attached
+	// with.
+	SignatureTagSuffix = ".sig"
+
+	payloadType = "cosign container image signature"
+)
+
+// Signature is a signature of an image.
+type Signature struct {
+	// Source is the manifest the signature was read from.
+	Source   string `json:"source"`
+	Verified bool   `json:"verified"`
+	// Reason explains why a signature is not verified.
+	Reason string `json:"reason,omitempty"`
+	// DockerReference is the image the signer signed, as recorded in the
+	// payload.
+	DockerReference string `json:"docker_reference,omitempty"`
+	// Signer and KeyFingerprint identify the key that verified the
+	// signature.
+	Signer         string `json:"signer,omitempty"`
+	KeyFingerprint string `json:"key_fingerprint,omitempty"`
+	// Annotations are the optional claims of the payload, such as those
+	// added with cosign sign -a.
+	Annotations map[string]any `json:"annotations,omitempty"`
+}
+
+// payload is the simple signing payload cosign signs.
+type payload struct {
+	Critical struct {
+		Identity struct {
+			DockerReference string `json:"docker-reference"`
+		} `json:"identity"`
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+		Type string `json:"type"`
+	} `json:"critical"`
+	Optional map[string]any `json:"optional"`
+}
+
+// Verify returns the signatures of the image with digest in repo, each
+// verified against keys. A signature is verified if one of keys signed a
+// payload for digest.
+func Verify(ctx context.Context, repo name.Repository, digest v1.Hash, keys Keyring, opts ...remote.Option) ([]Signature, error) {
+	artifacts, err := Artifacts(ctx, repo, digest, SignatureTagSuffix, SignatureArtifactType, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var signatures []Signature
+	for _, artifact := range artifacts {
+		manifest, err := artifact.Image.Manifest()
+		if err != nil {
+			//coverage:ignore
+			return nil, fmt.Errorf("could not read %s: %w", artifact.Source, err)
+		}
+		for _, layer := range manifest.Layers {
+			if layer.MediaType != SimpleSigningMediaType {
+				continue
+			}
+			sig := verifyLayer(artifact.Image, layer, digest, keys)
+			sig.Source = artifact.Source
+			signatures = append(signatures, sig)
+		}
+	}
+	return signatures, nil
+}
+
+// verifyLayer verifies the signature of the payload in layer of img.
+func verifyLayer(img v1.Image, layer v1.Descriptor, digest v1.Hash, keys Keyring) Signature {
+	encoded, ok := layer.Annotations[SignatureAnnotation]
+	if !ok {
+		return Signature{Reason: "the payload has no signature"}
+	}
+	sig, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return Signature{Reason: fmt.Sprintf("the signature is not base64 encoded: %v", err)}
+	}
+	b, err := ReadLayer(img, layer)
+	if err != nil {
+		return Signature{Reason: fmt.Sprintf("could not read the payload: %v", err)}
+	}
+
+	// The payload is only trusted once its signature is verified.
+	key, ok := keys.Verify(b, sig)
+	if !ok {
+		return Signature{Reason: "the signature was not made by a trusted key"}
+	}
+	s := Signature{Signer: key.Signer(), KeyFingerprint: key.Fingerprint}
+
+	var p payload
+	if err := json.Unmarshal(b, &p); err != nil {
+		s.Reason = fmt.Sprintf("could not parse the payload: %v", err)
+		return s
+	}
+	s.DockerReference = p.Critical.Identity.DockerReference
+	s.Annotations = p.Optional
+	switch {
+	case p.Critical.Type != payloadType:
+		s.Reason = fmt.Sprintf("the payload is a %q, not a %q", p.Critical.Type, payloadType)
+	case p.Critical.Image.DockerManifestDigest != digest.String():
+		s.Reason = fmt.Sprintf("the payload is for %s, not %s", p.Critical.Image.DockerManifestDigest, digest)
+	default:
+		s.Verified = true
+	}
+	return s
+}
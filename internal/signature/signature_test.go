@@ -0,0 +1,252 @@
+package signature
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// simpleSigningPayload returns the payload cosign signs for the image with
+// digest.
+func simpleSigningPayload(reference string, digest v1.Hash, optional map[string]any) []byte {
+	p := payload{Optional: optional}
+	p.Critical.Identity.DockerReference = reference
+	p.Critical.Image.DockerManifestDigest = digest.String()
+	p.Critical.Type = payloadType
+	b, err := json.Marshal(p)
+	Expect(err).ToNot(HaveOccurred())
+	return b
+}
+
+// signatureImage returns a cosign signature manifest with a layer for each of
+// payloads, annotated with the signature of priv.
+func signatureImage(priv *ecdsa.PrivateKey, payloads ...[]byte) v1.Image {
+	img := mutate.MediaType(empty.Image, types.OCIManifestSchema1)
+	for _, p := range payloads {
+		var err error
+		img, err = mutate.Append(img, mutate.Addendum{
+			Layer:       static.NewLayer(p, SimpleSigningMediaType),
+			Annotations: map[string]string{SignatureAnnotation: base64.StdEncoding.EncodeToString(signECDSA(priv, p))},
+		})
+		Expect(err).ToNot(HaveOccurred())
+	}
+	return img
+}
+
+// denyingHandler responds with 403 Forbidden to the requests deny matches.
+type denyingHandler struct {
+	http.Handler
+	deny func(*http.Request) bool
+}
+
+func (h *denyingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.deny != nil && h.deny(r) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	h.Handler.ServeHTTP(w, r)
+}
+
+var _ = Describe("Verify", func() {
+	var (
+		ctx     context.Context
+		handler *denyingHandler
+		repo    name.Repository
+		digest  v1.Hash
+		priv    *ecdsa.PrivateKey
+		keys    Keyring
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		handler = &denyingHandler{Handler: registry.New(
+			registry.Logger(log.New(io.Discard, "", 0)),
+			registry.WithReferrersSupport(true),
+		)}
+		s := httptest.NewServer(handler)
+		DeferCleanup(s.Close)
+		u, err := url.Parse(s.URL)
+		Expect(err).ToNot(HaveOccurred())
+
+		repo, err = name.NewRepository(u.Host + "/org/app")
+		Expect(err).ToNot(HaveOccurred())
+		img, err := random.Image(1024, 1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(remote.Write(repo.Tag("1.0"), img)).To(Succeed())
+		digest, err = img.Digest()
+		Expect(err).ToNot(HaveOccurred())
+
+		priv, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).ToNot(HaveOccurred())
+		keys, err = ParseKeyring(publicKeyPEM(&priv.PublicKey))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	signatureTag := func() name.Tag {
+		return repo.Tag(strings.Replace(digest.String(), ":", "-", 1) + SignatureTagSuffix)
+	}
+
+	It("should find no signatures of an unsigned image", func() {
+		signatures, err := Verify(ctx, repo, digest, keys)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(signatures).To(BeEmpty())
+	})
+
+	It("should verify a signature attached with a tag", func() {
+		p := simpleSigningPayload(repo.Name()+":1.0", digest, map[string]any{"release": "1.0"})
+		Expect(remote.Write(signatureTag(), signatureImage(priv, p))).To(Succeed())
+
+		signatures, err := Verify(ctx, repo, digest, keys)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(signatures).To(Equal([]Signature{{
+			Source:          signatureTag().String(),
+			Verified:        true,
+			DockerReference: repo.Name() + ":1.0",
+			Signer:          keys[0].Fingerprint,
+			KeyFingerprint:  keys[0].Fingerprint,
+			Annotations:     map[string]any{"release": "1.0"},
+		}}))
+	})
+
+	It("should verify a signature attached as a referrer", func() {
+		subject, err := remote.Head(repo.Digest(digest.String()))
+		Expect(err).ToNot(HaveOccurred())
+		sig := mutate.ConfigMediaType(signatureImage(priv, simpleSigningPayload(repo.Name(), digest, nil)), SignatureArtifactType)
+		sig = mutate.Subject(sig, *subject).(v1.Image)
+		sigDigest, err := sig.Digest()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(remote.Write(repo.Digest(sigDigest.String()), sig)).To(Succeed())
+
+		// Referrers of other artifact types are not signatures.
+		other := mutate.Subject(mutate.ConfigMediaType(empty.Image, "application/vnd.example.sbom"), *subject).(v1.Image)
+		otherDigest, err := other.Digest()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(remote.Write(repo.Digest(otherDigest.String()), other)).To(Succeed())
+
+		signatures, err := Verify(ctx, repo, digest, keys)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(signatures).To(HaveLen(1))
+		Expect(signatures[0].Source).To(Equal(repo.Digest(sigDigest.String()).String()))
+		Expect(signatures[0].Verified).To(BeTrue())
+	})
+
+	It("should not verify signatures of other keys", func() {
+		other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(remote.Write(signatureTag(), signatureImage(other, simpleSigningPayload(repo.Name(), digest, nil)))).To(Succeed())
+
+		signatures, err := Verify(ctx, repo, digest, keys)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(signatures).To(Equal([]Signature{{
+			Source: signatureTag().String(),
+			Reason: "the signature was not made by a trusted key",
+		}}))
+	})
+
+	It("should not verify signed payloads of other images or types", func() {
+		otherDigest := v1.Hash{Algorithm: "sha256", Hex: strings.Repeat("0", 64)}
+		wrongType := []byte(fmt.Sprintf(`{"critical":{"image":{"docker-manifest-digest":%q},"type":"other"}}`, digest))
+		Expect(remote.Write(signatureTag(), signatureImage(priv,
+			simpleSigningPayload(repo.Name(), otherDigest, nil),
+			wrongType,
+			[]byte("not json"),
+		))).To(Succeed())
+
+		signatures, err := Verify(ctx, repo, digest, keys)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(signatures).To(HaveLen(3))
+		Expect(signatures[0].Reason).To(Equal(fmt.Sprintf("the payload is for %s, not %s", otherDigest, digest)))
+		Expect(signatures[0].Signer).To(Equal(keys[0].Fingerprint))
+		Expect(signatures[1].Reason).To(Equal(`the payload is a "other", not a "cosign container image signature"`))
+		Expect(signatures[2].Reason).To(HavePrefix("could not parse the payload"))
+		for _, s := range signatures {
+			Expect(s.Verified).To(BeFalse())
+		}
+	})
+
+	It("should report layers without a valid signature", func() {
+		p := simpleSigningPayload(repo.Name(), digest, nil)
+		img := mutate.MediaType(empty.Image, types.OCIManifestSchema1)
+		img, err := mutate.Append(img,
+			mutate.Addendum{Layer: static.NewLayer(p, SimpleSigningMediaType)},
+			mutate.Addendum{Layer: static.NewLayer(p, SimpleSigningMediaType), Annotations: map[string]string{SignatureAnnotation: "!"}},
+			mutate.Addendum{Layer: static.NewLayer([]byte(strings.Repeat("x", maxPayloadSize+1)), SimpleSigningMediaType), Annotations: map[string]string{SignatureAnnotation: ""}},
+			// Layers of other media types are not signatures.
+			mutate.Addendum{Layer: static.NewLayer([]byte("{}"), types.OCILayer)},
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(remote.Write(signatureTag(), img)).To(Succeed())
+
+		signatures, err := Verify(ctx, repo, digest, keys)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(signatures).To(HaveLen(3))
+		Expect(signatures[0].Reason).To(Equal("the payload has no signature"))
+		Expect(signatures[1].Reason).To(HavePrefix("the signature is not base64 encoded"))
+		Expect(signatures[2].Reason).To(MatchRegexp(`^could not read the payload: layer sha256:[0-9a-f]+ is larger than 4194304 bytes$`))
+	})
+
+	It("should report payloads that can not be read", func() {
+		Expect(remote.Write(signatureTag(), signatureImage(priv, simpleSigningPayload(repo.Name(), digest, nil)))).To(Succeed())
+		handler.deny = func(r *http.Request) bool {
+			return strings.Contains(r.URL.Path, "/blobs/")
+		}
+
+		signatures, err := Verify(ctx, repo, digest, keys)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(signatures).To(HaveLen(1))
+		Expect(signatures[0].Reason).To(HavePrefix("could not read the payload"))
+	})
+
+	It("should fail when the signature tag can not be read", func() {
+		handler.deny = func(r *http.Request) bool {
+			return strings.HasSuffix(r.URL.Path, SignatureTagSuffix)
+		}
+		_, err := Verify(ctx, repo, digest, keys)
+		Expect(err).To(MatchError(ContainSubstring("could not read " + signatureTag().String())))
+	})
+
+	It("should fail when the referrers can not be listed", func() {
+		handler.deny = func(r *http.Request) bool {
+			return strings.Contains(r.URL.Path, "/referrers/")
+		}
+		_, err := Verify(ctx, repo, digest, keys)
+		Expect(err).To(MatchError(ContainSubstring("could not list the referrers")))
+	})
+
+	It("should fail when a referrer can not be read", func() {
+		subject, err := remote.Head(repo.Digest(digest.String()))
+		Expect(err).ToNot(HaveOccurred())
+		sig := mutate.Subject(mutate.ConfigMediaType(signatureImage(priv), SignatureArtifactType), *subject).(v1.Image)
+		sigDigest, err := sig.Digest()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(remote.Write(repo.Digest(sigDigest.String()), sig)).To(Succeed())
+		handler.deny = func(r *http.Request) bool {
+			return strings.HasSuffix(r.URL.Path, "/manifests/"+sigDigest.String())
+		}
+
+		_, err = Verify(ctx, repo, digest, keys)
+		Expect(err).To(MatchError(ContainSubstring("could not read " + repo.Digest(sigDigest.String()).String())))
+	})
+})
@@ -0,0 +1,156 @@
+// Package waiver reads waivers, which temporarily downgrade the failure of a
+// check to a warning. Waivers are granted by a certification contact, and
+// are only applied until they expire.
+package waiver
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// dateLayout is the layout of an expiry date without a time, which expires
+// at the start of the day in UTC.
+const dateLayout = "2006-01-02"
+
+// Waiver downgrades the failure of a check to a warning.
+type Waiver struct {
+	// Check is the name of the check whose failure is waived.
+	Check string
+	// Image is an optional glob the image, or its registry and repository,
+	// must match, e.g. quay.io/example/*. If empty, the waiver applies to
+	// every image.
+	Image string
+	// Justification explains why the failure is waived.
+	Justification string
+	// Expires is when the waiver stops applying.
+	Expires time.Time
+}
+
+// Expired returns true if the waiver no longer applies at now.
+func (w Waiver) Expired(now time.Time) bool {
+	return !now.Before(w.Expires)
+}
+
+// Matches returns true if the waiver applies to the check named name, when
+// executed against an image referred to by any of images.
+func (w Waiver) Matches(name string, images ...string) bool {
+	if w.Check != name {
+		return false
+	}
+	if w.Image == "" {
+		return true
+	}
+	for _, image := range images {
+		// The pattern was validated when the waiver was parsed.
+		if ok, _ := path.Match(w.Image, image); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Lookup returns the waiver in waivers that applies to the check named
+// name, when executed against an image referred to by any of images. A
+// waiver that has not expired at now is preferred over one that has.
+func Lookup(waivers []Waiver, now time.Time, name string, images ...string) (Waiver, bool) {
+	var expired *Waiver
+	for i, w := range waivers {
+		if !w.Matches(name, images...) {
+			continue
+		}
+		if !w.Expired(now) {
+			return w, true
+		}
+		if expired == nil {
+			expired = &waivers[i]
+		}
+	}
+	if expired != nil {
+		return *expired, true
+	}
+	return Waiver{}, false
+}
+
+// file is the contents of a waivers file.
+type file struct {
+	Waivers []entry `json:"waivers"`
+}
+
+// entry is a single waiver, as declared in a waivers file.
+type entry struct {
+	Check         string `json:"check"`
+	Image         string `json:"image,omitempty"`
+	Justification string `json:"justification"`
+	// Expires is a date, e.g. 2025-06-30, or an RFC 3339 timestamp.
+	Expires string `json:"expires"`
+}
+
+// Load reads the waivers file at path.
+func Load(path string) ([]Waiver, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read waivers: %w", err)
+	}
+
+	waivers, err := Parse(b)
+	if err != nil {
+		return nil, fmt.Errorf("invalid waivers in %s: %w", path, err)
+	}
+
+	return waivers, nil
+}
+
+// Parse reads the waivers in the YAML or JSON document b.
+func Parse(b []byte) ([]Waiver, error) {
+	var f file
+	if err := yaml.UnmarshalStrict(b, &f); err != nil {
+		return nil, err
+	}
+
+	waivers := make([]Waiver, 0, len(f.Waivers))
+	for i, e := range f.Waivers {
+		w, err := parseEntry(e)
+		if err != nil {
+			return nil, fmt.Errorf("waiver %d (%q): %w", i, e.Check, err)
+		}
+		waivers = append(waivers, w)
+	}
+
+	return waivers, nil
+}
+
+// parseEntry validates e and returns the waiver it declares.
+func parseEntry(e entry) (Waiver, error) {
+	if e.Check == "" {
+		return Waiver{}, errors.New("check is required")
+	}
+	if e.Justification == "" {
+		return Waiver{}, errors.New("justification is required")
+	}
+	if e.Expires == "" {
+		return Waiver{}, errors.New("expires is required")
+	}
+	if _, err := path.Match(e.Image, ""); err != nil {
+		return Waiver{}, fmt.Errorf("invalid image pattern %q: %w", e.Image, err)
+	}
+
+	expires, err := time.Parse(dateLayout, e.Expires)
+	if err != nil {
+		expires, err = time.Parse(time.RFC3339, e.Expires)
+	}
+	if err != nil {
+		return Waiver{}, fmt.Errorf("invalid expiry %q: expected a date, e.g. 2025-06-30, or an RFC 3339 timestamp", e.Expires)
+	}
+
+	return Waiver{
+		Check:         e.Check,
+		Image:         e.Image,
+		Justification: e.Justification,
+		Expires:       expires,
+	}, nil
+}
@@ -0,0 +1,13 @@
+package waiver
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestWaiver(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Waiver Suite")
+}
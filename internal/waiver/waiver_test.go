@@ -0,0 +1,122 @@
+package waiver
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Waivers", func() {
+	Context("when parsing waivers", func() {
+		It("should read every waiver", func() {
+			waivers, err := Parse([]byte(`
+waivers:
+- check: RunAsNonRoot
+  image: quay.io/example/*
+  justification: Granted by our certification contact in case 01234567.
+  expires: 2025-06-30
+- check: HasLicense
+  justification: Licenses are being added.
+  expires: "2025-06-30T12:00:00+02:00"
+`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(waivers).To(Equal([]Waiver{
+				{
+					Check:         "RunAsNonRoot",
+					Image:         "quay.io/example/*",
+					Justification: "Granted by our certification contact in case 01234567.",
+					Expires:       time.Date(2025, time.June, 30, 0, 0, 0, 0, time.UTC),
+				},
+				{
+					Check:         "HasLicense",
+					Justification: "Licenses are being added.",
+					Expires:       time.Date(2025, time.June, 30, 12, 0, 0, 0, time.FixedZone("", 2*60*60)),
+				},
+			}))
+		})
+
+		DescribeTable("should refuse invalid waivers",
+			func(doc string, expected string) {
+				_, err := Parse([]byte(doc))
+				Expect(err).To(MatchError(ContainSubstring(expected)))
+			},
+			Entry("with unknown fields", "waivers:\n- {check: A, reason: none}\n", "unknown field"),
+			Entry("without a check", "waivers:\n- {justification: j, expires: 2025-06-30}\n", "check is required"),
+			Entry("without a justification", "waivers:\n- {check: A, expires: 2025-06-30}\n", "justification is required"),
+			Entry("without an expiry", "waivers:\n- {check: A, justification: j}\n", "expires is required"),
+			Entry("with an invalid expiry", "waivers:\n- {check: A, justification: j, expires: soon}\n", "invalid expiry"),
+			Entry("with an invalid image pattern", "waivers:\n- {check: A, image: 'quay.io/[', justification: j, expires: 2025-06-30}\n", "invalid image pattern"),
+		)
+	})
+
+	Context("when loading waivers", func() {
+		It("should read the waivers file", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "waivers.yaml")
+			Expect(os.WriteFile(path, []byte("waivers:\n- {check: A, justification: j, expires: 2025-06-30}\n"), 0o644)).To(Succeed())
+			waivers, err := Load(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(waivers).To(HaveLen(1))
+		})
+		It("should fail if the file can not be read", func() {
+			_, err := Load(filepath.Join(GinkgoT().TempDir(), "missing.yaml"))
+			Expect(err).To(MatchError(ContainSubstring("could not read waivers")))
+		})
+		It("should fail if the file is invalid", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "waivers.yaml")
+			Expect(os.WriteFile(path, []byte("waivers:\n- {check: A}\n"), 0o644)).To(Succeed())
+			_, err := Load(path)
+			Expect(err).To(MatchError(ContainSubstring("invalid waivers in " + path)))
+		})
+	})
+
+	Context("when matching waivers", func() {
+		expires := time.Date(2025, time.June, 30, 0, 0, 0, 0, time.UTC)
+		before := expires.Add(-time.Hour)
+
+		It("should match the check by name", func() {
+			w := Waiver{Check: "RunAsNonRoot"}
+			Expect(w.Matches("RunAsNonRoot", "quay.io/example/app:1.0")).To(BeTrue())
+			Expect(w.Matches("HasLicense", "quay.io/example/app:1.0")).To(BeFalse())
+		})
+
+		It("should match any of the image's references", func() {
+			w := Waiver{Check: "RunAsNonRoot", Image: "quay.io/example/*"}
+			Expect(w.Matches("RunAsNonRoot", "quay.io/example/app:1.0", "quay.io/example/app")).To(BeTrue())
+			Expect(w.Matches("RunAsNonRoot", "quay.io/other/app")).To(BeFalse())
+		})
+
+		It("should expire at its expiry", func() {
+			w := Waiver{Expires: expires}
+			Expect(w.Expired(before)).To(BeFalse())
+			Expect(w.Expired(expires)).To(BeTrue())
+		})
+
+		It("should prefer waivers that have not expired", func() {
+			waivers := []Waiver{
+				{Check: "RunAsNonRoot", Justification: "old", Expires: before},
+				{Check: "RunAsNonRoot", Justification: "new", Expires: expires},
+			}
+			w, ok := Lookup(waivers, before, "RunAsNonRoot")
+			Expect(ok).To(BeTrue())
+			Expect(w.Justification).To(Equal("new"))
+		})
+
+		It("should return an expired waiver if no other matches", func() {
+			waivers := []Waiver{
+				{Check: "RunAsNonRoot", Justification: "old", Expires: before},
+				{Check: "RunAsNonRoot", Justification: "older", Expires: before},
+			}
+			w, ok := Lookup(waivers, expires, "RunAsNonRoot")
+			Expect(ok).To(BeTrue())
+			Expect(w.Justification).To(Equal("old"))
+		})
+
+		It("should not return a waiver if none matches", func() {
+			_, ok := Lookup([]Waiver{{Check: "HasLicense", Expires: expires}}, before, "RunAsNonRoot")
+			Expect(ok).To(BeFalse())
+		})
+	})
+})
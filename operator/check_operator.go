@@ -11,7 +11,10 @@ import (
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/engine"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/policy"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/policy/custom"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/policy/plugin"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/runtime"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/waiver"
 )
 
 type Option = func(*operatorCheck)
@@ -25,6 +28,7 @@ func NewCheck(image, indeximage string, kubeconfig []byte, opts ...Option) *oper
 		scorecardWaitTime:   runtime.DefaultScorecardWaitTime,
 		csvTimeout:          runtime.DefaultCSVTimeout,
 		subscriptionTimeout: runtime.DefaultSubscriptionTimeout,
+		cacheMaxSize:        runtime.DefaultCacheMaxSize,
 	}
 
 	for _, opt := range opts {
@@ -38,22 +42,38 @@ func NewCheck(image, indeximage string, kubeconfig []byte, opts ...Option) *oper
 func (c operatorCheck) Run(ctx context.Context) (certification.Results, error) {
 	err := c.resolve(ctx)
 	if err != nil {
-		return certification.Results{}, err
+		return c.runFailed(ctx, err)
 	}
 
 	cfg := runtime.Config{
 		//coverage:ignore
-		Image:        c.image,
-		DockerConfig: c.dockerConfigFilePath,
-		Scratch:      true,
-		Bundle:       true,
-		Insecure:     c.insecure,
-		Platform:     goruntime.GOARCH,
-	}
-	eng, err := engine.New(ctx, c.checks, c.kubeconfig, cfg)
+		Image:            c.image,
+		DockerConfig:     c.dockerConfigFilePath,
+		Scratch:          true,
+		Bundle:           true,
+		Insecure:         c.insecure,
+		Platform:         goruntime.GOARCH,
+		CheckParallelism: c.checkParallelism,
+		CacheDir:         c.cacheDir,
+		CacheMaxSize:     c.cacheMaxSize,
+		CheckTimeout:     c.checkTimeout,
+		CheckTimeouts:    c.checkTimeouts,
+	}
+	engineOpts := []engine.Option{engine.WithObserver(c.observer)}
+	if c.previousResults != nil {
+		engineOpts = append(engineOpts, engine.WithPreviousResults(*c.previousResults))
+	}
+	if c.waiversFile != "" {
+		waivers, err := waiver.Load(c.waiversFile)
+		if err != nil {
+			return c.runFailed(ctx, err)
+		}
+		engineOpts = append(engineOpts, engine.WithWaivers(waivers))
+	}
+	eng, err := engine.New(ctx, c.checks, c.kubeconfig, cfg, engineOpts...)
 	if err != nil {
 		//coverage:ignore
-		return certification.Results{}, err
+		return c.runFailed(ctx, err)
 	}
 
 	// NOTE(): The engine reads the cluster's version, but requires the KUBECONFIG
@@ -73,6 +93,15 @@ func (c operatorCheck) Run(ctx context.Context) (certification.Results, error) {
 	return eng.Results(ctx), nil
 }
 
+// runFailed notifies the observer, if any, that the run ended with err before
+// the engine, which notifies it otherwise, executed the checks.
+func (c operatorCheck) runFailed(ctx context.Context, err error) (certification.Results, error) {
+	if c.observer != nil {
+		c.observer.Observe(ctx, certification.RunFinished{Err: err})
+	}
+	return certification.Results{}, err
+}
+
 func (c *operatorCheck) resolve(ctx context.Context) error {
 	if c.resolved {
 		return nil
@@ -88,6 +117,9 @@ func (c *operatorCheck) resolve(ctx context.Context) error {
 	}
 
 	c.policy = policy.PolicyOperator
+	if c.selectedPolicy != "" {
+		c.policy = c.selectedPolicy
+	}
 	newChecks, err := engine.InitializeOperatorChecks(ctx, c.policy, engine.OperatorCheckConfig{
 		ScorecardImage:          c.scorecardImage,
 		ScorecardWaitTime:       c.scorecardWaitTime,
@@ -99,11 +131,33 @@ func (c *operatorCheck) resolve(ctx context.Context) error {
 		Kubeconfig:              c.kubeconfig,
 		CSVTimeout:              c.csvTimeout,
 		SubscriptionTimeout:     c.subscriptionTimeout,
+		Policies:                c.policyDefinitions,
 	})
 	if err != nil {
-		//coverage:ignore
 		return fmt.Errorf("%w: %s", preflighterr.ErrCannotInitializeChecks, err)
 	}
+	if c.customChecksFile != "" {
+		newChecks, err = custom.Append(newChecks, c.customChecksFile)
+		if err != nil {
+			return fmt.Errorf("%w: %w", preflighterr.ErrCannotInitializeChecks, err)
+		}
+	}
+	if c.pluginPath != "" {
+		newChecks, err = plugin.Append(ctx, newChecks, c.pluginPath)
+		if err != nil {
+			return fmt.Errorf("%w: %w", preflighterr.ErrCannotInitializeChecks, err)
+		}
+	}
+	for _, additional := range c.additionalChecks {
+		newChecks = append(newChecks, check.Custom(additional))
+	}
+	if c.checkFilter != nil {
+		newChecks = check.ApplyFilter(newChecks, c.checkFilter)
+	}
+	newChecks, err = check.Filter(newChecks, c.includedChecks, c.excludedChecks)
+	if err != nil {
+		return fmt.Errorf("%w: %w", preflighterr.ErrCannotInitializeChecks, err)
+	}
 	c.checks = newChecks
 	c.resolved = true
 
@@ -190,6 +244,148 @@ func WithSubscriptionTimeout(subscriptionTimeout time.Duration) Option {
 	}
 }
 
+// WithCheckParallelism sets the maximum number of checks that are executed
+// at the same time. Operator checks run sequentially by default, since several
+// of them share the test cluster.
+func WithCheckParallelism(n int) Option {
+	return func(oc *operatorCheck) {
+		oc.checkParallelism = n
+	}
+}
+
+// WithCacheDir sets a directory where bundle image layers are cached, so they
+// do not need to be pulled again by later checks.
+func WithCacheDir(dir string) Option {
+	return func(oc *operatorCheck) {
+		oc.cacheDir = dir
+	}
+}
+
+// WithCacheMaxSize sets the size in bytes the cache directory is pruned to,
+// least recently used layers first, after the check has run.
+func WithCacheMaxSize(size int64) Option {
+	return func(oc *operatorCheck) {
+		oc.cacheMaxSize = size
+	}
+}
+
+// WithCheckTimeout bounds how long each check may run. Checks named in
+// perCheck use that timeout instead of timeout. A zero timeout leaves checks
+// unbounded. Checks that exceed their timeout are reported as timed out.
+func WithCheckTimeout(timeout time.Duration, perCheck map[string]time.Duration) Option {
+	return func(oc *operatorCheck) {
+		oc.checkTimeout = timeout
+		oc.checkTimeouts = perCheck
+	}
+}
+
+// WithIncludedChecks limits the run to the named checks. The remaining checks
+// in the policy are reported as skipped. Names must match a check's Name().
+func WithIncludedChecks(names ...string) Option {
+	return func(oc *operatorCheck) {
+		oc.includedChecks = names
+	}
+}
+
+// WithExcludedChecks excludes the named checks from the run. They are reported
+// as skipped. Names must match a check's Name().
+func WithExcludedChecks(names ...string) Option {
+	return func(oc *operatorCheck) {
+		oc.excludedChecks = names
+	}
+}
+
+// WithObserver registers o to receive events as the check runs: when the
+// image has been pulled and extracted, as each check starts and finishes,
+// and when the run has finished, including runs that fail before any check
+// is executed. Calls to o are never concurrent.
+func WithObserver(o certification.Observer) Option {
+	return func(oc *operatorCheck) {
+		oc.observer = o
+	}
+}
+
+// WithPreviousResults executes only the checks that failed, errored or timed
+// out in prev, or that were not part of it. The outcomes of the remaining
+// checks are carried over into the results. The run fails with
+// errors.ErrImageDigestChanged if the image digest differs from the one prev
+// was generated for.
+func WithPreviousResults(prev certification.PreviousResults) Option {
+	return func(oc *operatorCheck) {
+		oc.previousResults = &prev
+	}
+}
+
+// WithCustomChecks appends the checks declared in the rules file at path to
+// the checks in the policy. Custom checks are executed and reported like any
+// other check, and may be selected with WithIncludedChecks and
+// WithExcludedChecks.
+func WithCustomChecks(path string) Option {
+	return func(oc *operatorCheck) {
+		oc.customChecksFile = path
+	}
+}
+
+// WithPlugins appends a check for every plugin executable found in the
+// directories of pluginPath, which is separated by os.PathListSeparator.
+// Plugin checks are executed and reported like any other check, and may be
+// selected with WithIncludedChecks and WithExcludedChecks.
+func WithPlugins(pluginPath string) Option {
+	return func(oc *operatorCheck) {
+		oc.pluginPath = pluginPath
+	}
+}
+
+// WithAdditionalChecks appends checks to the checks in the policy. They are
+// executed and reported like any other check, and may be selected with
+// WithIncludedChecks and WithExcludedChecks. Their results are marked as
+// custom, and are never submitted to Red Hat.
+func WithAdditionalChecks(checks ...check.Check) Option {
+	return func(oc *operatorCheck) {
+		oc.additionalChecks = append(oc.additionalChecks, checks...)
+	}
+}
+
+// WithCheckFilter passes the resolved checks to filter, and executes the
+// checks it returns instead. filter may remove, reorder or wrap checks, or add
+// new ones. Checks that filter adds or wraps are marked as custom, and their
+// results are never submitted to Red Hat. Checks that filter removes are
+// reported as skipped, and the results of the run are never submitted to Red
+// Hat either. Checks named by WithIncludedChecks
+// and WithExcludedChecks are selected from the checks filter returns.
+func WithCheckFilter(filter func([]check.Check) []check.Check) Option {
+	return func(oc *operatorCheck) {
+		oc.checkFilter = filter
+	}
+}
+
+// WithPolicy executes the checks of policy p, instead of Red Hat's operator
+// policy. p may be one of Red Hat's policies, or one added with
+// WithPolicyDefinitions.
+func WithPolicy(p policy.Policy) Option {
+	return func(oc *operatorCheck) {
+		oc.selectedPolicy = p
+	}
+}
+
+// WithPolicyDefinitions adds user-defined policies that may be selected with
+// WithPolicy. A definition may inherit one of Red Hat's policies, or another
+// definition.
+func WithPolicyDefinitions(definitions ...policy.Definition) Option {
+	return func(oc *operatorCheck) {
+		oc.policyDefinitions = append(oc.policyDefinitions, definitions...)
+	}
+}
+
+// WithWaivers reports the failures of checks named in the waivers file at
+// path as warnings, until their waivers expire. Waived results are annotated
+// in every format, and are never submitted to Red Hat.
+func WithWaivers(path string) Option {
+	return func(oc *operatorCheck) {
+		oc.waiversFile = path
+	}
+}
+
 type operatorCheck struct {
 	// required
 	image      string
@@ -208,4 +404,20 @@ type operatorCheck struct {
 	policy                  policy.Policy
 	csvTimeout              time.Duration
 	subscriptionTimeout     time.Duration
+	checkParallelism        int
+	cacheDir                string
+	cacheMaxSize            int64
+	checkTimeout            time.Duration
+	checkTimeouts           map[string]time.Duration
+	includedChecks          []string
+	excludedChecks          []string
+	observer                certification.Observer
+	previousResults         *certification.PreviousResults
+	customChecksFile        string
+	pluginPath              string
+	additionalChecks        []check.Check
+	checkFilter             func([]check.Check) []check.Check
+	selectedPolicy          policy.Policy
+	waiversFile             string
+	policyDefinitions       []policy.Definition
 }
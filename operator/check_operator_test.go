@@ -2,11 +2,16 @@ package operator
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/certification"
 	preflighterr "github.com/redhat-openshift-ecosystem/openshift-preflight/errors"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/policy"
 )
 
 var _ = Describe("Operator Check initialization", func() {
@@ -44,6 +49,16 @@ var _ = Describe("Operator Check initialization", func() {
 			Expect(c.dockerConfigFilePath).To(Equal(dockerConfigFilePath))
 			Expect(c.insecure).To(Equal(insecure))
 		})
+		It("should store the observer", func() {
+			observer := certification.ObserverFunc(func(context.Context, certification.Event) {})
+			c := NewCheck("placeholder", "indeximage:latest", []byte("kubeconfig"), WithObserver(observer))
+			Expect(c.observer).ToNot(BeNil())
+		})
+		It("should store the previous results", func() {
+			c := NewCheck("placeholder", "indeximage:latest", []byte("kubeconfig"), WithPreviousResults(certification.PreviousResults{ImageDigest: "sha256:abc"}))
+			Expect(c.previousResults).ToNot(BeNil())
+			Expect(c.previousResults.ImageDigest).To(Equal("sha256:abc"))
+		})
 	})
 })
 
@@ -84,6 +99,110 @@ var _ = Describe("Operator Check Execution", func() {
 		})
 	})
 
+	When("custom checks are provided", func() {
+		var rulesPath string
+		BeforeEach(func() {
+			rulesPath = filepath.Join(GinkgoT().TempDir(), "rules.yaml")
+		})
+		It("should append the custom checks to the policy", func() {
+			Expect(os.WriteFile(rulesPath, []byte("checks:\n- {name: HasTeamLabel, type: label, label: com.example.team}\n"), 0o644)).To(Succeed())
+			chk := NewCheck("Image", "IndexImage", []byte("Kubeconfig"), WithCustomChecks(rulesPath))
+			_, checks, err := chk.List(context.TODO())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(checks).To(HaveLen(10))
+			Expect(checks[9].Name()).To(Equal("HasTeamLabel"))
+		})
+		It("should fail if the custom checks can not be read", func() {
+			chk := NewCheck("Image", "IndexImage", []byte("Kubeconfig"), WithCustomChecks(rulesPath))
+			_, _, err := chk.List(context.TODO())
+			Expect(err).To(MatchError(preflighterr.ErrCannotInitializeChecks))
+		})
+	})
+
+	When("additional checks and a check filter are provided", func() {
+		var extra check.Check
+		BeforeEach(func() {
+			extra = check.NewGenericCheck("HasTeamOwner", nil, check.Metadata{}, check.HelpText{}, nil)
+		})
+		It("should append the additional checks as custom checks", func() {
+			chk := NewCheck("Image", "IndexImage", []byte("Kubeconfig"), WithAdditionalChecks(extra))
+			_, checks, err := chk.List(context.TODO())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(checks).To(HaveLen(10))
+			Expect(checks[9].Name()).To(Equal("HasTeamOwner"))
+			Expect(check.IsCustom(checks[9])).To(BeTrue())
+			Expect(check.IsCustom(checks[0])).To(BeFalse())
+		})
+		It("should execute the checks the filter returns", func() {
+			chk := NewCheck("Image", "IndexImage", []byte("Kubeconfig"), WithAdditionalChecks(extra), WithCheckFilter(func(checks []check.Check) []check.Check {
+				return checks[len(checks)-2:]
+			}))
+			_, checks, err := chk.List(context.TODO())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(check.IsCustom(checks[0])).To(BeFalse())
+			Expect(check.IsCustom(checks[1])).To(BeTrue())
+			Expect(check.IsRemoved(checks[0])).To(BeFalse())
+			Expect(checks[2:]).ToNot(BeEmpty())
+			for _, c := range checks[2:] {
+				Expect(check.IsRemoved(c)).To(BeTrue())
+			}
+		})
+		It("should select checks by name from those the filter returns", func() {
+			chk := NewCheck("Image", "IndexImage", []byte("Kubeconfig"), WithCheckFilter(func(checks []check.Check) []check.Check {
+				return checks[:1]
+			}), WithIncludedChecks(extra.Name()))
+			_, _, err := chk.List(context.TODO())
+			Expect(err).To(MatchError(preflighterr.ErrUnknownCheck))
+		})
+	})
+
+	When("plugins are provided", func() {
+		var pluginDir string
+		BeforeEach(func() {
+			pluginDir = GinkgoT().TempDir()
+		})
+		It("should append the plugin checks to the policy", func() {
+			Expect(os.WriteFile(filepath.Join(pluginDir, "plugin"), []byte("#!/bin/sh\ncat > /dev/null\necho '{\"name\":\"HasNoSecrets\"}'\n"), 0o755)).To(Succeed())
+			chk := NewCheck("Image", "IndexImage", []byte("Kubeconfig"), WithPlugins(pluginDir))
+			_, checks, err := chk.List(context.TODO())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(checks).To(HaveLen(10))
+			Expect(checks[9].Name()).To(Equal("HasNoSecrets"))
+		})
+		It("should fail if the plugins can not be discovered", func() {
+			chk := NewCheck("Image", "IndexImage", []byte("Kubeconfig"), WithPlugins(filepath.Join(pluginDir, "missing")))
+			_, _, err := chk.List(context.TODO())
+			Expect(err).To(MatchError(preflighterr.ErrCannotInitializeChecks))
+		})
+	})
+
+	When("a policy is selected", func() {
+		It("should execute the checks of a user-defined policy", func() {
+			chk := NewCheck("Image", "IndexImage", []byte("Kubeconfig"), WithPolicy("offline"), WithPolicyDefinitions(policy.Definition{
+				Name:     "offline",
+				Inherits: policy.PolicyOperator,
+				Exclude:  []string{"ScorecardBasicSpecCheck", "ScorecardOlmSuiteCheck", "DeployableByOLM"},
+			}))
+			p, checks, err := chk.List(context.TODO())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(p).To(Equal("offline"))
+			Expect(checks).To(HaveLen(6))
+		})
+		It("should fail if the policy is not an operator policy", func() {
+			chk := NewCheck("Image", "IndexImage", []byte("Kubeconfig"), WithPolicy(policy.PolicyContainer))
+			_, _, err := chk.List(context.TODO())
+			Expect(err).To(MatchError(preflighterr.ErrCannotInitializeChecks))
+		})
+	})
+
+	When("waivers are provided", func() {
+		It("should fail if the waivers can not be loaded", func() {
+			chk := NewCheck("Image", "IndexImage", []byte("Kubeconfig"), WithWaivers(filepath.Join(GinkgoT().TempDir(), "missing.yaml")))
+			_, err := chk.Run(context.TODO())
+			Expect(err).To(MatchError(ContainSubstring("could not read waivers")))
+		})
+	})
+
 	When("Calling the check", func() {
 		It("should fail if you passed an empty image", func() {
 			chk := NewCheck("", "indeximage", []byte{})
@@ -91,6 +210,17 @@ var _ = Describe("Operator Check Execution", func() {
 			Expect(err).To(MatchError(preflighterr.ErrImageEmpty))
 		})
 
+		It("should notify the observer that the run finished if it fails before checks are executed", func() {
+			var events []certification.Event
+			observer := certification.ObserverFunc(func(_ context.Context, event certification.Event) {
+				events = append(events, event)
+			})
+			chk := NewCheck("", "indeximage", []byte{}, WithObserver(observer))
+			_, err := chk.Run(context.TODO())
+			Expect(err).To(MatchError(preflighterr.ErrImageEmpty))
+			Expect(events).To(Equal([]certification.Event{certification.RunFinished{Err: err}}))
+		})
+
 		It("should fail if you passed an empty kubeconfig", func() {
 			chk := NewCheck("image", "indeximage", nil)
 			_, err := chk.Run(context.TODO())
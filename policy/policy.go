@@ -0,0 +1,39 @@
+// Package policy exposes the policies preflight executes checks against, so
+// that library consumers can select a policy with container.WithPolicy or
+// operator.WithPolicy, and define their own with
+// container.WithPolicyDefinitions or operator.WithPolicyDefinitions.
+//
+// Results of a run against a policy other than the one Red Hat resolves for
+// a certification project should not be submitted to Red Hat.
+package policy
+
+import (
+	internalpolicy "github.com/redhat-openshift-ecosystem/openshift-preflight/internal/policy"
+)
+
+// Policy is the name of a policy.
+type Policy = internalpolicy.Policy
+
+// Definition declares a policy as a named composition of checks. A policy
+// may inherit the checks of another policy, remove some of them with
+// Exclude, and add its own with Checks.
+type Definition = internalpolicy.Definition
+
+// Kind is the type of asset a policy's checks are executed against.
+type Kind = internalpolicy.Kind
+
+// Red Hat's policies.
+const (
+	PolicyOperator       = internalpolicy.PolicyOperator
+	PolicyContainer      = internalpolicy.PolicyContainer
+	PolicyScratchNonRoot = internalpolicy.PolicyScratchNonRoot
+	PolicyScratchRoot    = internalpolicy.PolicyScratchRoot
+	PolicyRoot           = internalpolicy.PolicyRoot
+	PolicyKonflux        = internalpolicy.PolicyKonflux
+)
+
+// The kinds a Definition can be assigned.
+const (
+	KindContainer = internalpolicy.KindContainer
+	KindOperator  = internalpolicy.KindOperator
+)